@@ -53,14 +53,47 @@ export type ClassGameStats = {
   last_activity: string | null;
 };
 
-export type SubmitGameResultRequest = {
+// Описание игры из реестра сервера; уровни от 0 до level_count - 1
+export type GameDefinition = {
+  id: string;
+  name: string;
+  description: string;
+  level_count: number;
+  round_size: number;
+  scoring: 'correct_answers' | 'efficiency';
+  max_score: number;
+  max_time_seconds: number;
+};
+
+// Раунд игры: задания выдает сервер, правильные ответы остаются на сервере
+export type GameSession = {
+  id: number;
+  user_id: number;
   game_type: string;
   level: number;
-  score: number;
-  max_score: number;
-  time_spent: number;
-  correct_count: number;
-  total_count: number;
+  round: Record<string, unknown>;
+  started_at: string;
+  expires_at: string;
+  submitted_at: string | null;
+  game_result_id: number | null;
+  challenge_id: number | null;
+};
+
+// Проверка одного ответа: index - номер ответа в раунде (в Memory Cards - номер попытки)
+export type GameAnswerResult = {
+  index: number;
+  correct: boolean;
+  feedback?: {
+    correct: boolean;
+    given: string;
+    expected: string;
+  };
+};
+
+export type SubmitGameSessionResponse = GameResult & {
+  new_achievements: Record<string, unknown>[];
+  xp_awarded: number;
+  feedback?: Record<number, unknown>;
 };
 
 // Названия игр для отображения
@@ -88,9 +121,27 @@ export const levelNames: Record<number, string> = {
 };
 
 export const gamesAPI = {
-  // Сохранить результат игры
-  submitResult: async (data: SubmitGameResultRequest): Promise<GameResult> => {
-    const response = await apiClient.post('/games/results', data);
+  // Список игр и их уровней
+  getGames: async (): Promise<GameDefinition[]> => {
+    const response = await apiClient.get('/games');
+    return response.data;
+  },
+
+  // Начать раунд: сервер выбирает задания и считает время игры
+  startSession: async (gameType: string, level: number): Promise<GameSession> => {
+    const response = await apiClient.post('/games/sessions', { game_type: gameType, level });
+    return response.data;
+  },
+
+  // Отправить один ответ во время игры; сервер запоминает его и сообщает, верен ли он
+  answerSession: async (sessionId: number, answer: Record<string, unknown>): Promise<GameAnswerResult> => {
+    const response = await apiClient.post(`/games/sessions/${sessionId}/answers`, { answer });
+    return response.data;
+  },
+
+  // Завершить раунд; очки считает сервер по отправленным ответам
+  submitSession: async (sessionId: number, answers: Record<string, unknown> = {}): Promise<SubmitGameSessionResponse> => {
+    const response = await apiClient.post(`/games/sessions/${sessionId}/submit`, { answers });
     return response.data;
   },
 
@@ -4,15 +4,41 @@ export type LeaderboardEntry = {
   user_id: number;
   username: string;
   full_name: string;
+  avatar: string;
   class_display: string;
   total_points: number;
   completed_lessons: number;
   average_percentage: number;
+  game_points: number;
+  total_xp: number;
+  player_level: number;
   rank: number;
 }
 
+export type LeaderboardPeriod = 'all' | 'week' | 'month' | 'season';
+
+export type LeaderboardParams = {
+  level?: number;
+  level_letter?: string;
+  sort?: string;
+  period?: LeaderboardPeriod;
+  season_id?: number;
+  limit?: number;
+  offset?: number;
+};
+
+// Страница рейтинга; my_entry - своя строка, даже если она вне страницы
+export type LeaderboardPage = {
+  period: LeaderboardPeriod;
+  items: LeaderboardEntry[];
+  total: number;
+  limit: number;
+  offset: number;
+  my_entry: LeaderboardEntry | null;
+}
+
 export const leaderboardAPI = {
-  getLeaderboard: async (params?: { level?: number; level_letter?: string }): Promise<LeaderboardEntry[]> => {
+  getLeaderboard: async (params?: LeaderboardParams): Promise<LeaderboardPage> => {
     const response = await apiClient.get('/leaderboard', { params });
     return response.data;
   },
@@ -1,7 +1,7 @@
 import React, { useEffect, useState } from 'react';
 import { useTranslation } from 'react-i18next';
 import { leaderboardAPI } from '../api/leaderboard';
-import type { LeaderboardEntry } from '../api/leaderboard';
+import type { LeaderboardEntry, LeaderboardPage } from '../api/leaderboard';
 import { useAuth } from '../context/AuthContext';
 import { Trophy, Medal, Loader2 } from 'lucide-react';
 
@@ -13,8 +13,9 @@ const Leaderboard: React.FC = () => {
   const [myRank, setMyRank] = useState<number | null>(null);
 
   // Кеш для leaderboard
-  const [cache, setCache] = useState<{ data: LeaderboardEntry[]; timestamp: number } | null>(null);
+  const [cache, setCache] = useState<{ data: LeaderboardPage; timestamp: number } | null>(null);
   const CACHE_DURATION = 5 * 60 * 1000; // 5 минут
+  const LEADERBOARD_LIMIT = 50;
 
   useEffect(() => {
     loadLeaderboard();
@@ -23,22 +24,18 @@ const Leaderboard: React.FC = () => {
   const loadLeaderboard = async () => {
     const now = Date.now();
     if (cache && (now - cache.timestamp) < CACHE_DURATION) {
-      setLeaderboard(cache.data);
-      const myEntry = cache.data.find(entry => entry.user_id === user?.id);
-      if (myEntry) setMyRank(myEntry.rank);
+      setLeaderboard(cache.data.items);
+      setMyRank(cache.data.my_entry?.rank ?? null);
       setLoading(false);
       return;
     }
 
     try {
-      const data = await leaderboardAPI.getLeaderboard();
-      setLeaderboard(data);
-      
-      // Находим свой ранг
-      const myEntry = data.find(entry => entry.user_id === user?.id);
-      if (myEntry) {
-        setMyRank(myEntry.rank);
-      }
+      const data = await leaderboardAPI.getLeaderboard({ limit: LEADERBOARD_LIMIT });
+      setLeaderboard(data.items);
+
+      // Свой ранг приходит отдельно: своя строка может быть вне страницы
+      setMyRank(data.my_entry?.rank ?? null);
 
       // Сохраняем в кеш
       setCache({ data, timestamp: now });
@@ -0,0 +1,67 @@
+import { useCallback, useEffect, useRef, useState } from 'react';
+import { gamesAPI } from '../api/games';
+import type { GameAnswerResult, GameSession, SubmitGameSessionResponse } from '../api/games';
+
+// Раунд игры с сервера: задания приходят в session.round, каждый ответ
+// проверяет сервер, он же считает очки и время при завершении раунда
+export const useGameSession = <Round>(gameType: string, level: number) => {
+  const [session, setSession] = useState<GameSession | null>(null);
+  const [result, setResult] = useState<SubmitGameSessionResponse | null>(null);
+  const [error, setError] = useState<string | null>(null);
+  const [attempt, setAttempt] = useState(0);
+  const finishingRef = useRef(false);
+
+  useEffect(() => {
+    let cancelled = false;
+    finishingRef.current = false;
+    setSession(null);
+    setResult(null);
+    setError(null);
+
+    gamesAPI.startSession(gameType, level)
+      .then((started) => {
+        if (!cancelled) setSession(started);
+      })
+      .catch((err: any) => {
+        if (!cancelled) setError(err.response?.data?.error || 'Не удалось загрузить игру');
+      });
+
+    return () => {
+      cancelled = true;
+    };
+  }, [gameType, level, attempt]);
+
+  // Отправить один ответ; null, если сервер его не принял
+  const answer = useCallback(async (value: Record<string, unknown>): Promise<GameAnswerResult | null> => {
+    if (!session) return null;
+    try {
+      return await gamesAPI.answerSession(session.id, value);
+    } catch (err: any) {
+      setError(err.response?.data?.error || 'Не удалось отправить ответ');
+      return null;
+    }
+  }, [session]);
+
+  // Завершить раунд: сервер проверяет записанные ответы и сохраняет результат
+  const finish = useCallback(async () => {
+    if (!session || finishingRef.current) return;
+    finishingRef.current = true;
+    try {
+      setResult(await gamesAPI.submitSession(session.id));
+    } catch (err: any) {
+      setError(err.response?.data?.error || 'Не удалось сохранить результат');
+    }
+  }, [session]);
+
+  // Начать новый раунд с другими заданиями
+  const restart = useCallback(() => setAttempt((n) => n + 1), []);
+
+  return {
+    round: session ? (session.round as Round) : null,
+    result,
+    error,
+    answer,
+    finish,
+    restart,
+  };
+};
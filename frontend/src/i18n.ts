@@ -122,6 +122,7 @@ const resources = {
         selectLabel: 'Выбери уровень сложности:',
         statsTitle: 'Твои достижения',
         statsDesc: 'Статистика по играм скоро появится!',
+        levelUnavailable: 'Нет такого уровня: уровней в игре {{count}}',
         list: {
           'grammar-detective': {
             title: 'Grammar Detective',
@@ -192,15 +193,12 @@ const resources = {
             resultTitle: 'Отличная работа!',
             scoreDisplay: '{{score}} очков',
             percentOfMax: '{{percentage}}% от максимума',
-            tipTitle: 'Совет:',
-            tipText: 'Отвечай быстрее, чтобы получить больше очков!',
             playAgain: 'Играть снова',
             chooseAnother: 'Выбрать другую игру',
             timeoutMessage: '⏰ Время вышло!',
             correctMessagePoints: '✅ Правильно! +{{points}} очков',
             wrongMessage: '❌ Неправильно',
             explanationLabel: 'Объяснение:',
-            bonusText: '🚀 Бонус за быстрый ответ!',
             questionCounter: 'Вопрос {{current}} / {{total}}',
             pointsLabel: 'Очки',
             showResults: 'Показать результаты',
@@ -523,6 +521,7 @@ const resources = {
         selectLabel: 'Qiyinchilik darajasini tanlang:',
         statsTitle: 'Sizning yutuqlaringiz',
         statsDesc: 'Oʻyinlar statistikasi tez orada paydo boʻladi!',
+        levelUnavailable: "Bunday daraja yoʻq: oʻyinda {{count}} ta daraja",
         list: {
           'grammar-detective': {
             title: 'Grammar Detective',
@@ -582,15 +581,12 @@ const resources = {
             resultTitle: "Ajoyib ish!",
             scoreDisplay: '{{score}} ochko',
             percentOfMax: '{{percentage}}% maksimaldan',
-            tipTitle: 'Maslahat:',
-            tipText: "Javoblarni tezroq bering — ko'proq ball olasiz!",
             playAgain: "Yana o'ynash",
             chooseAnother: "Boshqa o'yinni tanlang",
             timeoutMessage: '⏰ Vaqt tugadi!',
             correctMessagePoints: "✅ To'g'ri! +{{points}} ball",
             wrongMessage: "❌ Noto'g'ri",
             explanationLabel: "Tushuntirish:",
-            bonusText: "🚀 Tez javob uchun bonus!",
             questionCounter: 'Savol {{current}} / {{total}}',
             pointsLabel: 'Ballar',
             showResults: 'Natijalarni ko‘rsatish',
@@ -1,65 +1,38 @@
-import React, { useState } from 'react';
+import React, { useEffect, useState } from 'react';
 import { useTranslation } from 'react-i18next';
 import { useNavigate } from 'react-router-dom';
 import Layout from '../components/Layout';
+import { gamesAPI } from '../api/games';
+import type { GameDefinition } from '../api/games';
 import { Gamepad2, Target, Puzzle, Brain, Zap, Trophy } from 'lucide-react';
 
-const games = [
-  {
-    id: 'grammar-detective',
-    title: 'Grammar Detective',
-    description: 'Найди ошибку в предложении',
-    icon: Target,
-    color: 'from-red-500 to-pink-500',
-  },
-  {
-    id: 'sentence-builder',
-    title: 'Sentence Builder',
-    description: 'Собери предложение из слов',
-    icon: Puzzle,
-    color: 'from-blue-500 to-cyan-500',
-  },
-  {
-    id: 'memory-cards',
-    title: 'Memory Cards',
-    description: 'Найди пары слово-перевод',
-    icon: Brain,
-    color: 'from-purple-500 to-pink-500',
-  },
-  {
-    id: 'fill-gap-race',
-    title: 'Fill the Gap Race',
-    description: 'Вставь пропущенное слово',
-    icon: Zap,
-    color: 'from-yellow-500 to-orange-500',
-  },
-  {
-    id: 'quiz-show',
-    title: 'Quiz Show',
-    description: 'Ответь на вопросы викторины',
-    icon: Trophy,
-    color: 'from-green-500 to-emerald-500',
-  },
-];
+// Оформление карточек; список игр и число уровней приходят с сервера
+const gameStyles: Record<string, { icon: React.ElementType; color: string }> = {
+  'grammar-detective': { icon: Target, color: 'from-red-500 to-pink-500' },
+  'sentence-builder': { icon: Puzzle, color: 'from-blue-500 to-cyan-500' },
+  'memory-cards': { icon: Brain, color: 'from-purple-500 to-pink-500' },
+  'fill-gap-race': { icon: Zap, color: 'from-yellow-500 to-orange-500' },
+  'quiz-show': { icon: Trophy, color: 'from-green-500 to-emerald-500' },
+};
 
 const GamesPage: React.FC = () => {
   const { t } = useTranslation();
   const navigate = useNavigate();
   const [selectedLevel, setSelectedLevel] = useState<number>(0);
+  const [games, setGames] = useState<GameDefinition[]>([]);
+
+  useEffect(() => {
+    gamesAPI.getGames()
+      .then(setGames)
+      .catch((err) => console.error('Failed to load games:', err));
+  }, []);
 
-  const levels = [
-    { value: 0, label: t('games.levels.0.label', { defaultValue: 'Level 0 - Beginner' }), description: t('games.levels.0.description', { defaultValue: 'Базовые артикли, множественное число' }) },
-    { value: 1, label: t('games.levels.1.label', { defaultValue: 'Level 1 - Beginner+' }), description: t('games.levels.1.description', { defaultValue: 'Исчисляемые/неисчисляемые, местоимения' }) },
-    { value: 2, label: t('games.levels.2.label', { defaultValue: 'Level 2 - Elementary' }), description: t('games.levels.2.description', { defaultValue: 'Present Simple, базовые времена' }) },
-    { value: 3, label: t('games.levels.3.label', { defaultValue: 'Level 3 - Elementary+' }), description: t('games.levels.3.description', { defaultValue: 'Present Continuous, притяжательные' }) },
-    { value: 4, label: t('games.levels.4.label', { defaultValue: 'Level 4 - Pre-Intermediate' }), description: t('games.levels.4.description', { defaultValue: 'Past Simple, Past Continuous' }) },
-    { value: 5, label: t('games.levels.5.label', { defaultValue: 'Level 5 - Pre-Intermediate+' }), description: t('games.levels.5.description', { defaultValue: 'Present Perfect, сравнения' }) },
-    { value: 6, label: t('games.levels.6.label', { defaultValue: 'Level 6 - Intermediate' }), description: t('games.levels.6.description', { defaultValue: 'Past Perfect, Future tenses' }) },
-    { value: 7, label: t('games.levels.7.label', { defaultValue: 'Level 7 - Intermediate+' }), description: t('games.levels.7.description', { defaultValue: 'Модальные глаголы, условные предложения' }) },
-    { value: 8, label: t('games.levels.8.label', { defaultValue: 'Level 8 - Upper-Intermediate' }), description: t('games.levels.8.description', { defaultValue: 'Пассивный залог, фразовые глаголы' }) },
-    { value: 9, label: t('games.levels.9.label', { defaultValue: 'Level 9 - Advanced' }), description: t('games.levels.9.description', { defaultValue: 'Косвенная речь, сложные структуры' }) },
-    { value: 10, label: t('games.levels.10.label', { defaultValue: 'Level 10 - Proficiency' }), description: t('games.levels.10.description', { defaultValue: 'Все грамматические конструкции' }) },
-  ];
+  const levelCount = Math.max(0, ...games.map((game) => game.level_count));
+  const levels = Array.from({ length: levelCount }, (_, value) => ({
+    value,
+    label: t(`games.levels.${value}.label`, { defaultValue: `Level ${value}` }),
+    description: t(`games.levels.${value}.description`, { defaultValue: '' }),
+  }));
 
   return (
     <Layout>
@@ -102,21 +75,26 @@ const GamesPage: React.FC = () => {
         {/* Games Grid */}
         <div className="grid grid-cols-2 sm:grid-cols-3 lg:grid-cols-5 gap-3 sm:gap-6">
           {games.map((game) => {
-            const Icon = game.icon;
+            const style = gameStyles[game.id] ?? { icon: Gamepad2, color: 'from-gray-500 to-gray-600' };
+            const Icon = style.icon;
+            const hasLevel = selectedLevel < game.level_count;
             return (
               <button
                 key={game.id}
                 onClick={() => navigate(`/games/${game.id}?level=${selectedLevel}`)}
-                className="card p-3 sm:p-6 hover:scale-105 transition-transform duration-300 group"
+                disabled={!hasLevel}
+                className="card p-3 sm:p-6 hover:scale-105 transition-transform duration-300 group disabled:opacity-50 disabled:hover:scale-100"
               >
-                <div className={`w-10 h-10 sm:w-16 sm:h-16 rounded-xl bg-gradient-to-br ${game.color} flex items-center justify-center mb-2 sm:mb-4 group-hover:scale-110 transition-transform mx-auto`}>
+                <div className={`w-10 h-10 sm:w-16 sm:h-16 rounded-xl bg-gradient-to-br ${style.color} flex items-center justify-center mb-2 sm:mb-4 group-hover:scale-110 transition-transform mx-auto`}>
                   <Icon className="w-5 h-5 sm:w-8 sm:h-8 text-white" />
                 </div>
                 <h3 className="text-xs sm:text-lg font-bold text-gray-900 dark:text-white mb-1 sm:mb-2 text-center leading-tight">
-                  {t(`games.list.${game.id}.title`, { defaultValue: game.title })}
+                  {t(`games.list.${game.id}.title`, { defaultValue: game.name })}
                 </h3>
                 <p className="text-xs text-gray-600 dark:text-gray-400 text-center hidden sm:block">
-                  {t(`games.list.${game.id}.description`, { defaultValue: game.description })}
+                  {hasLevel
+                    ? t(`games.list.${game.id}.description`, { defaultValue: game.description })
+                    : t('games.levelUnavailable', { count: game.level_count })}
                 </p>
               </button>
             );
@@ -2,7 +2,7 @@ import React, { useState, useEffect } from 'react';
 import { useNavigate } from 'react-router-dom';
 import Layout from '../../components/Layout';
 import { gamesAPI, gameNames, levelNames } from '../../api/games';
-import type { GameResult, GameSummary } from '../../api/games';
+import type { GameDefinition, GameResult, GameSummary } from '../../api/games';
 import { Trophy, ArrowLeft, Medal, Gamepad2, Loader2, Star, Clock, Zap, Users } from 'lucide-react';
 import { useAuth } from '../../context/AuthContext';
 import { useTranslation } from 'react-i18next';
@@ -36,9 +36,11 @@ const GameLeaderboard: React.FC = () => {
   // Фильтры для рейтинга
   const [selectedGame, setSelectedGame] = useState<string>('grammar-detective');
   const [selectedLevel, setSelectedLevel] = useState<number>(0);
+  const [games, setGames] = useState<GameDefinition[]>([]);
 
   useEffect(() => {
     loadData();
+    gamesAPI.getGames().then(setGames).catch(console.error);
   }, []);
 
   useEffect(() => {
@@ -214,7 +216,11 @@ const GameLeaderboard: React.FC = () => {
                 <label className="block text-xs font-semibold text-gray-600 dark:text-gray-400 mb-1">{t('games.filters.game')}</label>
                 <select
                   value={selectedGame}
-                  onChange={(e) => setSelectedGame(e.target.value)}
+                  onChange={(e) => {
+                    setSelectedGame(e.target.value);
+                    const count = games.find((game) => game.id === e.target.value)?.level_count ?? 1;
+                    if (selectedLevel >= count) setSelectedLevel(0);
+                  }}
                   className="input-field text-sm"
                 >
                   {Object.entries(gameNames).map(([key, name]) => (
@@ -229,7 +235,7 @@ const GameLeaderboard: React.FC = () => {
                   onChange={(e) => setSelectedLevel(Number(e.target.value))}
                   className="input-field text-sm"
                 >
-                  {[0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10].map((lvl) => (
+                  {Array.from({ length: games.find((game) => game.id === selectedGame)?.level_count ?? 1 }, (_, lvl) => lvl).map((lvl) => (
                     <option key={lvl} value={lvl}>
                       {lvl}. {t(`games.levels.${lvl}.label`, { defaultValue: levelNames[lvl] || `Level ${lvl}` })}
                     </option>
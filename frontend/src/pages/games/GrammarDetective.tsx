@@ -1,15 +1,13 @@
-import React, { useState, useEffect, useRef } from 'react';
+import React, { useState } from 'react';
 import { useNavigate, useSearchParams } from 'react-router-dom';
 import Layout from '../../components/Layout';
 import { Target, ArrowLeft, CheckCircle, XCircle } from 'lucide-react';
-import { gamesAPI } from '../../api/games';
+import { useGameSession } from '../../hooks/useGameSession';
 import { useTranslation } from 'react-i18next';
 
-interface Question {
-  sentence: string;
-  errorIndex: number;
-  correctWord: string;
-  explanation: string;
+// Раунд Grammar Detective с сервера: предложения без номера слова с ошибкой
+interface Round {
+  items: { sentence: string }[];
 }
 
 const GrammarDetective: React.FC = () => {
@@ -19,147 +17,43 @@ const GrammarDetective: React.FC = () => {
   const levelParam = searchParams.get('level');
   const level = levelParam !== null ? Number(levelParam) : 0;
 
-  const [questions, setQuestions] = useState<Question[]>([]);
+  const { round, result, error, answer, finish, restart } = useGameSession<Round>('grammar-detective', level);
+  const questions = round?.items ?? [];
+
   const [currentQuestion, setCurrentQuestion] = useState(0);
   const [selectedWordIndex, setSelectedWordIndex] = useState<number | null>(null);
   const [score, setScore] = useState(0);
-  const [showResult, setShowResult] = useState(false);
-  const [gameFinished, setGameFinished] = useState(false);
-  const [resultSaved, setResultSaved] = useState(false);
-  const startTimeRef = useRef<number>(Date.now());
-
-  useEffect(() => {
-    const generatedQuestions = generateQuestions(level);
-    setQuestions(generatedQuestions);
-    startTimeRef.current = Date.now();
-  }, [level]);
-
-  // Отправка результатов при завершении игры
-  useEffect(() => {
-    if (gameFinished && !resultSaved && questions.length > 0) {
-      const timeSpent = Math.round((Date.now() - startTimeRef.current) / 1000);
-      gamesAPI.submitResult({
-        game_type: 'grammar-detective',
-        level,
-        score,
-        max_score: questions.length,
-        time_spent: timeSpent,
-        correct_count: score,
-        total_count: questions.length,
-      }).then(() => {
-        setResultSaved(true);
-      }).catch((err) => {
-        console.error('Failed to save game result:', err);
-      });
-    }
-  }, [gameFinished, resultSaved, questions.length, score, level]);
+  const [isCorrect, setIsCorrect] = useState<boolean | null>(null);
+  const [checking, setChecking] = useState(false);
 
-  const generateQuestions = (level: number): Question[] => {
-    const questionsByLevel: { [key: number]: Question[] } = {
-      0: [ // Beginner - Базовые артикли, множественное число
-        { sentence: "I have a apple", errorIndex: 2, correctWord: "an", explanation: "Перед гласным звуком: an apple" },
-        { sentence: "She has two cat", errorIndex: 3, correctWord: "cats", explanation: "Множественное число: cats" },
-        { sentence: "This is a umbrella", errorIndex: 2, correctWord: "an", explanation: "Umbrella начинается с гласного: an" },
-        { sentence: "I see three dog", errorIndex: 3, correctWord: "dogs", explanation: "Множественное число: dogs" },
-        { sentence: "She have a book", errorIndex: 1, correctWord: "has", explanation: "С she: has (не have)" },
-      ],
-      1: [ // Beginner+ - Исчисляемые/неисчисляемые
-        { sentence: "I need an advice", errorIndex: 2, correctWord: "some", explanation: "Advice неисчисляемое: some advice" },
-        { sentence: "How many water do you need", errorIndex: 1, correctWord: "much", explanation: "Water - неисчисляемое: much" },
-        { sentence: "There is many furniture", errorIndex: 2, correctWord: "much", explanation: "Furniture - неисчисляемое: much" },
-        { sentence: "She has a few money", errorIndex: 3, correctWord: "little", explanation: "Money - неисчисляемое: a little" },
-        { sentence: "I saw three childs", errorIndex: 3, correctWord: "children", explanation: "Child → children (irregular)" },
-      ],
-      2: [ // Elementary - Present Simple
-        { sentence: "She go to school", errorIndex: 1, correctWord: "goes", explanation: "С she: goes" },
-        { sentence: "He don't like coffee", errorIndex: 1, correctWord: "doesn't", explanation: "С he: doesn't" },
-        { sentence: "They plays football", errorIndex: 1, correctWord: "play", explanation: "С they: play (без -s)" },
-        { sentence: "Does she likes music", errorIndex: 2, correctWord: "like", explanation: "После does: base form" },
-        { sentence: "I doesn't understand", errorIndex: 1, correctWord: "don't", explanation: "С I: don't" },
-      ],
-      3: [ // Elementary+ - Present Continuous, притяжательные
-        { sentence: "She is cook dinner", errorIndex: 2, correctWord: "cooking", explanation: "Present Continuous: is cooking" },
-        { sentence: "This is my sister car", errorIndex: 3, correctWord: "sister's", explanation: "Притяжательная форма: sister's" },
-        { sentence: "They are play football", errorIndex: 2, correctWord: "playing", explanation: "Are playing" },
-        { sentence: "I am run right now", errorIndex: 2, correctWord: "running", explanation: "Am running (удвоение n)" },
-        { sentence: "The boys toys are here", errorIndex: 1, correctWord: "boys'", explanation: "Множественное: boys'" },
-      ],
-      4: [ // Pre-Intermediate - Past Simple
-        { sentence: "I go to London yesterday", errorIndex: 1, correctWord: "went", explanation: "Past Simple: went" },
-        { sentence: "She didn't went home", errorIndex: 2, correctWord: "go", explanation: "После didn't: base form" },
-        { sentence: "They was at home", errorIndex: 1, correctWord: "were", explanation: "С they: were" },
-        { sentence: "Did you saw him", errorIndex: 2, correctWord: "see", explanation: "После did: base form" },
-        { sentence: "We buyed a car", errorIndex: 1, correctWord: "bought", explanation: "Buy → bought (irregular)" },
-      ],
-      5: [ // Pre-Intermediate+ - Present Perfect, сравнения
-        { sentence: "I have went to Paris", errorIndex: 2, correctWord: "been/gone", explanation: "Have + V3: been/gone" },
-        { sentence: "She is more tall", errorIndex: 3, correctWord: "taller", explanation: "Короткие: -er (taller)" },
-        { sentence: "He has already finish", errorIndex: 3, correctWord: "finished", explanation: "Has + V3: finished" },
-        { sentence: "This is the most big", errorIndex: 3, correctWord: "biggest", explanation: "Короткие: -est (biggest)" },
-        { sentence: "They have just arrive", errorIndex: 3, correctWord: "arrived", explanation: "Have + V3: arrived" },
-      ],
-      6: [ // Intermediate - Past Perfect, Future
-        { sentence: "Before I arrived they have left", errorIndex: 4, correctWord: "had", explanation: "Более раннее: had left" },
-        { sentence: "I will to go tomorrow", errorIndex: 2, correctWord: "go", explanation: "Will + base (без to)" },
-        { sentence: "She had already eat", errorIndex: 3, correctWord: "eaten", explanation: "Had + V3: eaten" },
-        { sentence: "He is go to leave", errorIndex: 2, correctWord: "going", explanation: "Be going to: is going" },
-        { sentence: "They will going tomorrow", errorIndex: 2, correctWord: "go", explanation: "Will + go (не going)" },
-      ],
-      7: [ // Intermediate+ - Модальные, условные
-        { sentence: "She cans speak English", errorIndex: 1, correctWord: "can", explanation: "Can (не cans)" },
-        { sentence: "You must to study", errorIndex: 2, correctWord: "study", explanation: "Must + base (без to)" },
-        { sentence: "If it will rain tomorrow", errorIndex: 2, correctWord: "rains", explanation: "Условие: Present Simple" },
-        { sentence: "If I was rich", errorIndex: 2, correctWord: "were", explanation: "Second Conditional: were" },
-        { sentence: "He shoulds go home", errorIndex: 1, correctWord: "should", explanation: "Should (не shoulds)" },
-      ],
-      8: [ // Upper-Intermediate - Пассив, фразовые
-        { sentence: "The book was wrote", errorIndex: 3, correctWord: "written", explanation: "Was + V3: written" },
-        { sentence: "English is spoke here", errorIndex: 3, correctWord: "spoken", explanation: "Is + V3: spoken" },
-        { sentence: "It will be finish", errorIndex: 3, correctWord: "finished", explanation: "Will be + V3" },
-        { sentence: "The house is building", errorIndex: 3, correctWord: "being built", explanation: "Continuous Passive: being built" },
-        { sentence: "I look forward for it", errorIndex: 3, correctWord: "to", explanation: "Look forward to (не for)" },
-      ],
-      9: [ // Advanced - Косвенная речь
-        { sentence: "She said she is tired", errorIndex: 3, correctWord: "was", explanation: "Согласование времен: was" },
-        { sentence: "He told he will come", errorIndex: 2, correctWord: "me he would", explanation: "Told + объект; will → would" },
-        { sentence: "She asked if can I", errorIndex: 3, correctWord: "I could", explanation: "Can → could (порядок слов)" },
-        { sentence: "He said me that", errorIndex: 1, correctWord: "told", explanation: "Told me (не said me)" },
-        { sentence: "She asked where is he", errorIndex: 3, correctWord: "he was", explanation: "Порядок: where he was" },
-      ],
-      10: [ // Proficiency - Сложные структуры
-        { sentence: "Because I was tired so left", errorIndex: 4, correctWord: "(убрать so)", explanation: "Because... (без so)" },
-        { sentence: "Although it rained but went", errorIndex: 3, correctWord: "(убрать but)", explanation: "Although... (без but)" },
-        { sentence: "It will have complete", errorIndex: 3, correctWord: "been completed", explanation: "Will have been + V3" },
-        { sentence: "She suggested me to go", errorIndex: 2, correctWord: "that I go", explanation: "Suggest + (that) + subject + base" },
-        { sentence: "Not only he came but also", errorIndex: 2, correctWord: "did he come", explanation: "Инверсия: did he come" },
-      ],
-    };
-    
-    return questionsByLevel[level] || questionsByLevel[1];
-  };
+  const showResult = isCorrect !== null;
 
   const handleWordClick = (index: number) => {
     if (showResult) return;
     setSelectedWordIndex(index);
   };
 
-  const handleCheck = () => {
-    if (selectedWordIndex === null) return;
+  const handleCheck = async () => {
+    if (selectedWordIndex === null || checking) return;
+
+    setChecking(true);
+    const checked = await answer({ word_index: selectedWordIndex });
+    setChecking(false);
+    if (!checked) return;
 
-    const isCorrect = selectedWordIndex === questions[currentQuestion].errorIndex;
-    if (isCorrect) {
+    if (checked.correct) {
       setScore(score + 1);
     }
-    setShowResult(true);
+    setIsCorrect(checked.correct);
   };
 
   const handleNext = () => {
     if (currentQuestion < questions.length - 1) {
       setCurrentQuestion(currentQuestion + 1);
       setSelectedWordIndex(null);
-      setShowResult(false);
+      setIsCorrect(null);
     } else {
-      setGameFinished(true);
+      finish();
     }
   };
 
@@ -167,12 +61,23 @@ const GrammarDetective: React.FC = () => {
     setCurrentQuestion(0);
     setScore(0);
     setSelectedWordIndex(null);
-    setShowResult(false);
-    setGameFinished(false);
-    setResultSaved(false);
-    startTimeRef.current = Date.now();
+    setIsCorrect(null);
+    restart();
   };
 
+  if (error) {
+    return (
+      <Layout>
+        <div className="max-w-4xl mx-auto px-3 sm:px-4 py-4 sm:py-8 text-center">
+          <p className="text-red-600 mb-4">{error}</p>
+          <button onClick={() => navigate('/games')} className="btn-secondary text-sm sm:text-base">
+            {t('games.grammarDetective.chooseAnother')}
+          </button>
+        </div>
+      </Layout>
+    );
+  }
+
   if (questions.length === 0) {
     return (
       <Layout>
@@ -183,8 +88,8 @@ const GrammarDetective: React.FC = () => {
     );
   }
 
-  if (gameFinished) {
-    const percentage = Math.round((score / questions.length) * 100);
+  if (result) {
+    const percentage = Math.round(result.percentage);
     return (
       <Layout>
         <div className="max-w-4xl mx-auto px-3 sm:px-4 py-4 sm:py-8">
@@ -204,7 +109,7 @@ const GrammarDetective: React.FC = () => {
               {t('games.grammarDetective.resultTitle')}
             </h2>
             <div className="text-3xl sm:text-5xl font-bold bg-gradient-to-r from-blue-600 to-purple-600 bg-clip-text text-transparent mb-4 sm:mb-6">
-              {score} / {questions.length}
+              {result.score} / {result.max_score}
             </div>
             <p className="text-base sm:text-xl text-gray-600 dark:text-gray-400 mb-6 sm:mb-8">
               {t('games.grammarDetective.correctPercentage', { percentage })}
@@ -224,8 +129,7 @@ const GrammarDetective: React.FC = () => {
   }
 
   const question = questions[currentQuestion];
-  const words = question.sentence.split(' ');
-  const isCorrect = selectedWordIndex === question.errorIndex;
+  const words = question.sentence.trim().split(/\s+/);
 
   return (
     <Layout>
@@ -284,8 +188,6 @@ const GrammarDetective: React.FC = () => {
                         ? 'bg-green-500 text-white'
                         : 'bg-red-500 text-white'
                       : 'bg-blue-500 text-white'
-                    : showResult && index === question.errorIndex
-                    ? 'bg-green-500 text-white'
                     : 'bg-gray-100 dark:bg-gray-700 hover:bg-gray-200 dark:hover:bg-gray-600'
                 }`}
               >
@@ -301,7 +203,7 @@ const GrammarDetective: React.FC = () => {
                 ? 'bg-green-50 dark:bg-green-900/20 border-2 border-green-200 dark:border-green-800'
                 : 'bg-red-50 dark:bg-red-900/20 border-2 border-red-200 dark:border-red-800'
             }`}>
-              <div className="flex items-center gap-2 sm:gap-3 mb-2 sm:mb-3">
+              <div className="flex items-center gap-2 sm:gap-3">
                 {isCorrect ? (
                   <>
                     <CheckCircle className="w-5 h-5 sm:w-6 sm:h-6 text-green-600" />
@@ -318,12 +220,6 @@ const GrammarDetective: React.FC = () => {
                   </>
                 )}
               </div>
-              <p className="text-sm sm:text-base text-gray-700 dark:text-gray-300 mb-1 sm:mb-2">
-                <strong>{t('games.grammarDetective.correctAnswerLabel')}</strong> {question.correctWord}
-              </p>
-              <p className="text-sm sm:text-base text-gray-600 dark:text-gray-400">
-                {question.explanation}
-              </p>
             </div>
           )}
 
@@ -332,7 +228,7 @@ const GrammarDetective: React.FC = () => {
             {!showResult ? (
               <button
                 onClick={handleCheck}
-                disabled={selectedWordIndex === null}
+                disabled={selectedWordIndex === null || checking}
                 className="btn-primary disabled:opacity-50 text-sm sm:text-base"
               >
                 {t('games.grammarDetective.checkBtn')}
@@ -1,14 +1,18 @@
-import React, { useState, useEffect, useRef } from 'react';
+import React, { useState, useEffect } from 'react';
 import { useNavigate, useSearchParams } from 'react-router-dom';
 import Layout from '../../components/Layout';
 import { Brain, ArrowLeft } from 'lucide-react';
-import { gamesAPI } from '../../api/games';
+import { useGameSession } from '../../hooks/useGameSession';
 import { useTranslation } from 'react-i18next';
 
+// Раунд Memory Cards с сервера: перемешанные карточки без номера пары
+interface Round {
+  cards: { id: number; text: string }[];
+}
+
 interface Card {
   id: number;
   content: string;
-  pairId: number;
   isFlipped: boolean;
   isMatched: boolean;
 }
@@ -20,132 +24,30 @@ const MemoryCards: React.FC = () => {
   const { t } = useTranslation();
   const level = levelParam !== null ? Number(levelParam) : 0;
 
+  const { round, result, error, answer, finish, restart } = useGameSession<Round>('memory-cards', level);
+
   const [cards, setCards] = useState<Card[]>([]);
   const [flippedCards, setFlippedCards] = useState<number[]>([]);
   const [moves, setMoves] = useState(0);
   const [matches, setMatches] = useState(0);
-  const [gameFinished, setGameFinished] = useState(false);
-  const [startTime, setStartTime] = useState<number>(Date.now());
-  const [endTime, setEndTime] = useState<number>(0);
-  const [resultSaved, setResultSaved] = useState(false);
-  const startTimeRef = useRef<number>(Date.now());
 
-  // Сохраняем результат игры
-  useEffect(() => {
-    if (gameFinished && !resultSaved) {
-      const timeSpent = Math.round((Date.now() - startTimeRef.current) / 1000);
-      const maxPairs = 6;
-      // Эффективность - сколько ходов на все пары (минимум 6 ходов)
-      const efficiency = Math.max(0, Math.round((maxPairs / moves) * 100));
-      
-      gamesAPI.submitResult({
-        game_type: 'memory-cards',
-        level,
-        score: efficiency,
-        max_score: 100,
-        time_spent: timeSpent,
-        correct_count: matches,
-        total_count: maxPairs,
-      }).then(() => {
-        setResultSaved(true);
-      }).catch(console.error);
-    }
-  }, [gameFinished, resultSaved, matches, moves, level]);
+  const pairCount = cards.length / 2;
 
   useEffect(() => {
-    initializeGame();
-  }, [level]);
-
-  const initializeGame = () => {
-    let pairs: { english: string; russian: string }[] = [];
-    
-    if (level === 1) { // Countable/Uncountable
-      pairs = [
-        { english: 'many', russian: 'много (исчисл.)' },
-        { english: 'much', russian: 'много (неисчисл.)' },
-        { english: 'a few', russian: 'несколько' },
-        { english: 'a little', russian: 'немного' },
-        { english: 'countable', russian: 'исчисляемое' },
-        { english: 'uncountable', russian: 'неисчисляемое' },
-      ];
-    } else if (level === 2) { // Singular/Plural
-      pairs = [
-        { english: 'child', russian: 'children' },
-        { english: 'man', russian: 'men' },
-        { english: 'woman', russian: 'women' },
-        { english: 'tooth', russian: 'teeth' },
-        { english: 'mouse', russian: 'mice' },
-        { english: 'foot', russian: 'feet' },
-      ];
-    } else if (level === 3) { // Articles
-      pairs = [
-        { english: 'a/an', russian: 'неопр. артикль' },
-        { english: 'the', russian: 'опр. артикль' },
-        { english: 'a cat', russian: 'кот (любой)' },
-        { english: 'the cat', russian: 'кот (конкретный)' },
-        { english: 'an apple', russian: 'яблоко (гласная)' },
-        { english: 'the sun', russian: 'солнце (уник.)' },
-      ];
-    } else if (level === 4) { // Present Simple
-      pairs = [
-        { english: 'I go', russian: 'Я хожу' },
-        { english: 'he goes', russian: 'он ходит' },
-        { english: 'do', russian: 'вспом. глагол' },
-        { english: 'does', russian: 'для he/she/it' },
-        { english: 'always', russian: 'всегда' },
-        { english: 'usually', russian: 'обычно' },
-      ];
-    } else if (level === 5) { // Present Continuous
-      pairs = [
-        { english: 'am doing', russian: 'я делаю (сейчас)' },
-        { english: 'is doing', russian: 'он/она делает' },
-        { english: 'are doing', russian: 'мы/они делают' },
-        { english: 'now', russian: 'сейчас' },
-        { english: 'at the moment', russian: 'в данный момент' },
-        { english: 'right now', russian: 'прямо сейчас' },
-      ];
-    } else {
-      pairs = [
-        { english: 'Hello', russian: 'Привет' },
-        { english: 'Good', russian: 'Хороший' },
-        { english: 'Big', russian: 'Большой' },
-        { english: 'Small', russian: 'Маленький' },
-        { english: 'Happy', russian: 'Счастливый' },
-        { english: 'Beautiful', russian: 'Красивый' },
-      ];
-    }
-
-    const cardArray: Card[] = [];
-    pairs.forEach((pair, index) => {
-      cardArray.push({
-        id: index * 2,
-        content: pair.english,
-        pairId: index,
-        isFlipped: false,
-        isMatched: false,
-      });
-      cardArray.push({
-        id: index * 2 + 1,
-        content: pair.russian,
-        pairId: index,
-        isFlipped: false,
-        isMatched: false,
-      });
-    });
-
-    // Shuffle cards
-    for (let i = cardArray.length - 1; i > 0; i--) {
-      const j = Math.floor(Math.random() * (i + 1));
-      [cardArray[i], cardArray[j]] = [cardArray[j], cardArray[i]];
-    }
-
-    setCards(cardArray);
-    setStartTime(Date.now());
-  };
+    setCards((round?.cards ?? []).map((card) => ({
+      id: card.id,
+      content: card.text,
+      isFlipped: false,
+      isMatched: false,
+    })));
+    setFlippedCards([]);
+    setMoves(0);
+    setMatches(0);
+  }, [round]);
 
   const handleCardClick = (cardId: number) => {
     if (flippedCards.length === 2) return;
-    
+
     const card = cards.find((c) => c.id === cardId);
     if (!card || card.isFlipped || card.isMatched) return;
 
@@ -163,61 +65,63 @@ const MemoryCards: React.FC = () => {
     }
   };
 
-  const checkMatch = (flipped: number[], currentCards: Card[]) => {
-    const [first, second] = flipped.map((id) =>
-      currentCards.find((c) => c.id === id)
-    );
+  // Пару проверяет сервер: номера пар карточек есть только у него
+  const checkMatch = async (flipped: number[], currentCards: Card[]) => {
+    const checked = await answer({ cards: flipped });
+    if (!checked) return;
 
-    if (first && second && first.pairId === second.pairId) {
-      // Match found
+    if (checked.correct) {
       setTimeout(() => {
-        const matchedCards = currentCards.map((c) =>
-          c.id === first.id || c.id === second.id
-            ? { ...c, isMatched: true }
-            : c
-        );
-        setCards(matchedCards);
+        setCards(currentCards.map((c) =>
+          flipped.includes(c.id) ? { ...c, isMatched: true } : c
+        ));
         setFlippedCards([]);
-        
+
         const newMatches = matches + 1;
         setMatches(newMatches);
-        
-        if (newMatches === 6) {
-          setEndTime(Date.now());
-          setGameFinished(true);
+
+        if (newMatches === pairCount) {
+          finish();
         }
       }, 500);
     } else {
-      // No match
       setTimeout(() => {
-        const unflippedCards = currentCards.map((c) =>
-          c.id === first?.id || c.id === second?.id
-            ? { ...c, isFlipped: false }
-            : c
-        );
-        setCards(unflippedCards);
+        setCards(currentCards.map((c) =>
+          flipped.includes(c.id) ? { ...c, isFlipped: false } : c
+        ));
         setFlippedCards([]);
       }, 1000);
     }
   };
 
-  const restartGame = () => {
-    setMoves(0);
-    setMatches(0);
-    setFlippedCards([]);
-    setGameFinished(false);
-    setEndTime(0);
-    setResultSaved(false);
-    startTimeRef.current = Date.now();
-    initializeGame();
-  };
-
-  const getTimeTaken = () => {
-    const seconds = Math.floor((endTime - startTime) / 1000);
+  const getTimeTaken = (seconds: number) => {
     return `${Math.floor(seconds / 60)}:${(seconds % 60).toString().padStart(2, '0')}`;
   };
 
-  if (gameFinished) {
+  if (error) {
+    return (
+      <Layout>
+        <div className="max-w-4xl mx-auto px-3 sm:px-4 py-4 sm:py-8 text-center">
+          <p className="text-red-600 mb-4">{error}</p>
+          <button onClick={() => navigate('/games')} className="btn-secondary text-sm sm:text-base">
+            {t('games.memoryCards.chooseAnother')}
+          </button>
+        </div>
+      </Layout>
+    );
+  }
+
+  if (cards.length === 0) {
+    return (
+      <Layout>
+        <div className="max-w-4xl mx-auto px-3 sm:px-4 py-4 sm:py-8 text-center">
+          <p>{t('games.memoryCards.loading')}</p>
+        </div>
+      </Layout>
+    );
+  }
+
+  if (result) {
     return (
       <Layout>
         <div className="max-w-4xl mx-auto px-3 sm:px-4 py-4 sm:py-8">
@@ -240,12 +144,12 @@ const MemoryCards: React.FC = () => {
                 <div className="text-sm sm:text-base text-gray-600 dark:text-gray-400">{t('games.memoryCards.movesSimple')}</div>
               </div>
               <div className="card p-3 sm:p-4">
-                <div className="text-xl sm:text-3xl font-bold text-purple-600 mb-1">{getTimeTaken()}</div>
+                <div className="text-xl sm:text-3xl font-bold text-purple-600 mb-1">{getTimeTaken(result.time_spent)}</div>
                 <div className="text-sm sm:text-base text-gray-600 dark:text-gray-400">{t('games.memoryCards.timeSimple')}</div>
               </div>
             </div>
             <div className="flex flex-col sm:flex-row gap-3 sm:gap-4 justify-center">
-              <button onClick={restartGame} className="btn-primary text-sm sm:text-base">
+              <button onClick={restart} className="btn-primary text-sm sm:text-base">
                 {t('games.memoryCards.playAgain')}
               </button>
               <button onClick={() => navigate('/games')} className="btn-secondary text-sm sm:text-base">
@@ -282,7 +186,7 @@ const MemoryCards: React.FC = () => {
                 {t('games.memoryCards.movesHeader')} <strong>{moves}</strong>
               </span>
               <span className="text-gray-600 dark:text-gray-400">
-                {t('games.memoryCards.pairsHeader')} <strong>{matches} / 6</strong>
+                {t('games.memoryCards.pairsHeader')} <strong>{matches} / {pairCount}</strong>
               </span>
             </div>
           </div>
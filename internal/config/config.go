@@ -3,12 +3,43 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"time"
 )
 
 type Config struct {
 	DatabaseURL string
 	JWTSecret   string
 	Port        string
+	// Location часовой пояс школы: по нему определяются границы учебного дня
+	Location *time.Location
+	XP       XPWeights
+	Media    MediaConfig
+	// GradeScale минимальный процент для оценок 5, 4, 3 и 2 через запятую;
+	// ниже последнего порога ставится 1
+	GradeScale string
+}
+
+// MediaConfig хранилище загруженных файлов: локальный каталог или S3
+type MediaConfig struct {
+	Driver       string // local или s3
+	LocalDir     string
+	MaxAudioSize int64 // байт
+	S3Endpoint   string
+	S3Region     string
+	S3Bucket     string
+	S3AccessKey  string
+	S3SecretKey  string
+	S3PathStyle  bool
+}
+
+// XPWeights сколько опыта начисляется за каждый вид активности
+type XPWeights struct {
+	TestPass       int
+	PerfectScore   int
+	GameCompletion int
+	StreakDay      int
+	Achievement    int
 }
 
 func Load() *Config {
@@ -39,10 +70,62 @@ func Load() *Config {
 		port = "8080"
 	}
 
+	timezone := os.Getenv("SCHOOL_TIMEZONE")
+	if timezone == "" {
+		timezone = "Asia/Tashkent"
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		log.Printf("Warning: unknown SCHOOL_TIMEZONE %q, falling back to UTC: %v", timezone, err)
+		location = time.UTC
+	}
+
 	return &Config{
 		DatabaseURL: dbURL,
 		JWTSecret:   jwtSecret,
 		Port:        port,
+		Location:    location,
+		XP: XPWeights{
+			TestPass:       envInt("XP_TEST_PASS", 50),
+			PerfectScore:   envInt("XP_PERFECT_SCORE", 25),
+			GameCompletion: envInt("XP_GAME_COMPLETION", 20),
+			StreakDay:      envInt("XP_STREAK_DAY", 5),
+			Achievement:    envInt("XP_ACHIEVEMENT", 30),
+		},
+		Media: MediaConfig{
+			Driver:       envString("MEDIA_STORAGE", "local"),
+			LocalDir:     envString("MEDIA_DIR", "media"),
+			MaxAudioSize: int64(envInt("MEDIA_MAX_AUDIO_MB", 10)) << 20,
+			S3Endpoint:   os.Getenv("S3_ENDPOINT"),
+			S3Region:     os.Getenv("S3_REGION"),
+			S3Bucket:     os.Getenv("S3_BUCKET"),
+			S3AccessKey:  os.Getenv("S3_ACCESS_KEY"),
+			S3SecretKey:  os.Getenv("S3_SECRET_KEY"),
+			S3PathStyle:  os.Getenv("S3_PATH_STYLE") == "true",
+		},
+		GradeScale: envString("GRADE_SCALE", "90,75,60,40"),
+	}
+}
+
+// envString читает строку из окружения или возвращает значение по умолчанию
+func envString(name string, defaultValue string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// envInt читает неотрицательное целое из окружения или возвращает значение по умолчанию
+func envInt(name string, defaultValue int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		log.Printf("Warning: invalid %s=%q, using %d", name, value, defaultValue)
+		return defaultValue
 	}
+	return parsed
 }
 
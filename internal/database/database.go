@@ -24,12 +24,38 @@ func Connect(databaseURL string) (*gorm.DB, error) {
 func Migrate(db *gorm.DB) error {
 	return db.AutoMigrate(
 		&models.User{},
+		&models.MediaFile{},
 		&models.Lesson{},
+		&models.LessonBlock{},
 		&models.Question{},
 		&models.AnswerOption{},
 		&models.TestAttempt{},
 		&models.LessonProgress{},
 		&models.Achievement{},
 		&models.GameResult{},
+		&models.DailyActivity{},
+		&models.UserStreak{},
+		&models.AchievementDefinition{},
+		&models.AchievementEvent{},
+		&models.XPEntry{},
+		&models.Season{},
+		&models.SeasonStanding{},
+		&models.UserStats{},
+		&models.ClassStats{},
+		&models.ClassPrivacySettings{},
+		&models.GameItem{},
+		&models.GameSession{},
+		&models.Challenge{},
+		&models.ReviewItem{},
+		&models.SkillMastery{},
+		&models.ItemDifficulty{},
+		&models.Tag{},
+		&models.AnswerRecord{},
+		&models.PlacementTest{},
+		&models.PlacementAnswer{},
+		&models.Comment{},
+		&models.Notification{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
 	)
 }
@@ -0,0 +1,132 @@
+package database
+
+import (
+	"englishlessons.back/internal/models"
+	"gorm.io/gorm"
+)
+
+// defaultAchievementDefinitions встроенные достижения платформы
+var defaultAchievementDefinitions = []models.AchievementDefinition{
+	{
+		Type:          models.AchievementTypeFirstLesson,
+		TitleRu:       "Первый шаг",
+		TitleUz:       "Birinchi qadam",
+		DescriptionRu: "Пройден первый урок",
+		DescriptionUz: "Birinchi dars o'tildi",
+		Icon:          "🎯",
+		Event:         models.EventTestSubmitted,
+		Rule:          "lesson_order == 1 && is_passed == true",
+	},
+	{
+		Type:          models.AchievementTypePerfectScore,
+		TitleRu:       "Идеально!",
+		TitleUz:       "Mukammal!",
+		DescriptionRu: "100% правильных ответов в тесте",
+		DescriptionUz: "Testda 100% to'g'ri javob",
+		Icon:          "⭐",
+		Event:         models.EventTestSubmitted,
+		Rule:          "percentage >= 100",
+	},
+	{
+		Type:          models.AchievementTypeStreak,
+		TitleRu:       "Серия побед",
+		TitleUz:       "G'alabalar seriyasi",
+		DescriptionRu: "3 урока подряд на 90%+",
+		DescriptionUz: "Ketma-ket 3 ta dars 90%+ natija bilan",
+		Icon:          "🔥",
+		Event:         models.EventTestSubmitted,
+		Rule:          "high_score_streak >= 3",
+	},
+	{
+		Type:          models.AchievementTypeAllLessons,
+		TitleRu:       "Мастер",
+		TitleUz:       "Usta",
+		DescriptionRu: "Пройдены все уроки",
+		DescriptionUz: "Barcha darslar o'tildi",
+		Icon:          "👑",
+		Event:         models.EventTestSubmitted,
+		Rule:          "total_lessons > 0 && completed_lessons >= total_lessons",
+	},
+	{
+		Type:          models.AchievementTypeFastLearner,
+		TitleRu:       "Быстрый ученик",
+		TitleUz:       "Tez o'rganuvchi",
+		DescriptionRu: "Пройден урок с первой попытки на 90%+",
+		DescriptionUz: "Dars birinchi urinishda 90%+ natija bilan o'tildi",
+		Icon:          "⚡",
+		Event:         models.EventTestSubmitted,
+		Rule:          "is_first_attempt == true && percentage >= 90",
+	},
+	{
+		Type:          models.AchievementTypePersistent,
+		TitleRu:       "Упорство",
+		TitleUz:       "Qat'iyat",
+		DescriptionRu: "10+ попыток прохождения теста",
+		DescriptionUz: "Testdan o'tish uchun 10+ urinish",
+		Icon:          "💪",
+		Event:         models.EventTestSubmitted,
+		Rule:          "attempts_on_lesson >= 10",
+	},
+	{
+		Type:          models.AchievementTypeStreakWeek,
+		TitleRu:       "Неделя без пропусков",
+		TitleUz:       "Bir hafta uzluksiz",
+		DescriptionRu: "7 дней подряд с выполненной дневной целью",
+		DescriptionUz: "Kunlik maqsad ketma-ket 7 kun bajarildi",
+		Icon:          "📅",
+		Event:         models.EventStreakUpdated,
+		Rule:          "current_streak >= 7",
+	},
+	{
+		Type:          models.AchievementTypeStreakMonth,
+		TitleRu:       "Месяц без пропусков",
+		TitleUz:       "Bir oy uzluksiz",
+		DescriptionRu: "30 дней подряд с выполненной дневной целью",
+		DescriptionUz: "Kunlik maqsad ketma-ket 30 kun bajarildi",
+		Icon:          "🗓️",
+		Event:         models.EventStreakUpdated,
+		Rule:          "current_streak >= 30",
+	},
+	{
+		Type:          models.AchievementTypeFirstGame,
+		TitleRu:       "Игрок",
+		TitleUz:       "O'yinchi",
+		DescriptionRu: "Сыграна первая игра",
+		DescriptionUz: "Birinchi o'yin o'ynaldi",
+		Icon:          "🎮",
+		Event:         models.EventGameFinished,
+		Rule:          "games_played >= 1",
+	},
+	{
+		Type:          models.AchievementTypeGamePerfect,
+		TitleRu:       "Без ошибок",
+		TitleUz:       "Xatosiz",
+		DescriptionRu: "Игра пройдена на 100%",
+		DescriptionUz: "O'yin 100% natija bilan yakunlandi",
+		Icon:          "🏅",
+		Event:         models.EventGameFinished,
+		Rule:          "percentage >= 100",
+	},
+}
+
+// SeedAchievementDefinitions добавляет недостающие встроенные достижения.
+// Уже существующие записи не изменяются, чтобы не затирать правки в БД.
+func SeedAchievementDefinitions(db *gorm.DB) error {
+	for _, definition := range defaultAchievementDefinitions {
+		var count int64
+		if err := db.Model(&models.AchievementDefinition{}).
+			Where("type = ?", definition.Type).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+
+		definition.IsActive = true
+		if err := db.Create(&definition).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
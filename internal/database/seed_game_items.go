@@ -0,0 +1,41 @@
+package database
+
+import (
+	_ "embed"
+	"encoding/json"
+
+	"englishlessons.back/internal/models"
+	"gorm.io/gorm"
+)
+
+// defaultGameItems задания, которые раньше были зашиты в страницы игр
+//
+//go:embed seed/game_items.json
+var defaultGameItems []byte
+
+// SeedGameItems загружает встроенный набор заданий, если банк заданий пуст
+func SeedGameItems(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&models.GameItem{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	var file models.GameContentFile
+	if err := json.Unmarshal(defaultGameItems, &file); err != nil {
+		return err
+	}
+
+	items := make([]models.GameItem, len(file.Items))
+	for i, entry := range file.Items {
+		items[i] = models.GameItem{
+			GameType: entry.GameType,
+			Level:    entry.Level,
+			Payload:  entry.Payload,
+			IsActive: true,
+		}
+	}
+	return db.CreateInBatches(items, 100).Error
+}
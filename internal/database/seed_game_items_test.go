@@ -0,0 +1,43 @@
+package database
+
+import (
+	"encoding/json"
+	"testing"
+
+	"englishlessons.back/internal/models"
+)
+
+// Каждый уровень из реестра должен быть заполнен встроенными заданиями на целый раунд,
+// а заданий для уровней вне реестра быть не должно
+func TestDefaultGameItemsCoverRegistryLevels(t *testing.T) {
+	var file models.GameContentFile
+	if err := json.Unmarshal(defaultGameItems, &file); err != nil {
+		t.Fatalf("seed/game_items.json: %v", err)
+	}
+
+	counts := make(map[models.GameType]map[int]int)
+	for _, entry := range file.Items {
+		if counts[entry.GameType] == nil {
+			counts[entry.GameType] = make(map[int]int)
+		}
+		counts[entry.GameType][entry.Level]++
+	}
+
+	for _, definition := range models.GameRegistry {
+		levels := counts[definition.ID]
+		for level := 0; level < definition.LevelCount; level++ {
+			if levels[level] < definition.RoundSize {
+				t.Errorf("%s level %d: %d items, want at least %d", definition.ID, level, levels[level], definition.RoundSize)
+			}
+		}
+		for level := range levels {
+			if !definition.HasLevel(level) {
+				t.Errorf("%s level %d is outside the registry (%d levels)", definition.ID, level, definition.LevelCount)
+			}
+		}
+		delete(counts, definition.ID)
+	}
+	for gameType := range counts {
+		t.Errorf("items for unknown game %s", gameType)
+	}
+}
@@ -0,0 +1,20 @@
+package database
+
+import (
+	"englishlessons.back/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SeedTags добавляет встроенные теги уровней CEFR и навыков.
+// Грамматические темы учителя заводят сами.
+func SeedTags(db *gorm.DB) error {
+	var tags []models.Tag
+	for _, level := range models.CEFRLevels {
+		tags = append(tags, models.Tag{Kind: models.TagKindCEFR, Name: level})
+	}
+	for _, skill := range models.SkillTags {
+		tags = append(tags, models.Tag{Kind: models.TagKindSkill, Name: skill})
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error
+}
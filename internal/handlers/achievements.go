@@ -1,7 +1,13 @@
 package handlers
 
 import (
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -9,7 +15,7 @@ import (
 func (h *Handlers) GetMyAchievements(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 
-	achievements, err := h.achievementService.GetUserAchievements(userID.(uint))
+	achievements, err := h.achievementService.GetUserAchievements(userID.(uint), c.DefaultQuery("lang", "ru"))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get achievements"})
 		return
@@ -18,3 +24,150 @@ func (h *Handlers) GetMyAchievements(c *gin.Context) {
 	c.JSON(http.StatusOK, achievements)
 }
 
+// formatNewAchievements готовит только что полученные достижения для ответа,
+// чтобы интерфейс мог их показать
+func (h *Handlers) formatNewAchievements(achievements []models.Achievement, lang string) []map[string]interface{} {
+	result, err := h.achievementService.FormatAchievements(achievements, lang)
+	if err != nil {
+		log.Printf("Failed to format achievements: %v", err)
+		return []map[string]interface{}{}
+	}
+	return result
+}
+
+// GetAchievementRuleMetrics возвращает события и метрики, доступные в правилах
+func (h *Handlers) GetAchievementRuleMetrics(c *gin.Context) {
+	c.JSON(http.StatusOK, services.RuleMetrics)
+}
+
+func (h *Handlers) GetAchievementDefinitions(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+
+	definitions, err := h.achievementService.GetDefinitions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get achievement definitions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, definitions)
+}
+
+func (h *Handlers) CreateAchievementDefinition(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только учителя могут создавать значки"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	var req services.AchievementDefinitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	definition, awarded, err := h.achievementService.CreateDefinition(userID.(uint), req)
+	if err != nil {
+		respondAchievementDefinitionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"definition":            definition,
+		"awarded_retroactively": awarded,
+	})
+}
+
+func (h *Handlers) UpdateAchievementDefinition(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только учителя могут изменять значки"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID значка"})
+		return
+	}
+
+	var req services.AchievementDefinitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	definition, awarded, err := h.achievementService.UpdateDefinition(uint(id), req)
+	if err != nil {
+		respondAchievementDefinitionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"definition":            definition,
+		"awarded_retroactively": awarded,
+	})
+}
+
+func (h *Handlers) DeleteAchievementDefinition(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только учителя могут удалять значки"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID значка"})
+		return
+	}
+
+	if err := h.achievementService.DeactivateDefinition(uint(id)); err != nil {
+		respondAchievementDefinitionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Значок отключен"})
+}
+
+func (h *Handlers) ReevaluateAchievementDefinition(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID значка"})
+		return
+	}
+
+	awarded, err := h.achievementService.ReevaluateDefinition(uint(id))
+	if err != nil {
+		respondAchievementDefinitionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"awarded_retroactively": awarded})
+}
+
+func respondAchievementDefinitionError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "не найден"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "уже существует"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "нельзя"):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "Неверн") || strings.Contains(err.Error(), "Необходимо"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save achievement definition"})
+	}
+}
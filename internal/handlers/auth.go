@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"englishlessons.back/internal/models"
 	"englishlessons.back/internal/services"
 	"net/http"
 
@@ -123,6 +124,8 @@ func (h *Handlers) GetMe(c *gin.Context) {
 		"level":         user.Level,
 		"level_letter":  user.LevelLetter,
 		"class_display": user.GetClassDisplay(),
+		"avatar":        user.Avatar,
+		"avatars":       models.AvatarVariants(user.Avatar),
 	})
 }
 
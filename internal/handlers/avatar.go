@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"englishlessons.back/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UploadAvatar загружает аватар (поле формы file: JPEG, PNG или WebP)
+func (h *Handlers) UploadAvatar(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, services.MaxAvatarSize+1<<20)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Файл слишком большой: максимум 5 МБ"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Необходимо приложить файл"})
+		return
+	}
+	if fileHeader.Size > services.MaxAvatarSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Файл слишком большой: максимум 5 МБ"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Не удалось прочитать файл"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, services.MaxAvatarSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Не удалось прочитать файл"})
+		return
+	}
+
+	variants, err := h.avatarService.UploadAvatar(userID.(uint), data)
+	if err != nil {
+		message := err.Error()
+		switch {
+		case strings.Contains(message, "не найден"):
+			c.JSON(http.StatusNotFound, gin.H{"error": message})
+		case strings.Contains(message, "слишком большой"):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": message})
+		case strings.Contains(message, "Неподдерживаем") || strings.Contains(message, "Неверн") ||
+			strings.Contains(message, "пустой") || strings.Contains(message, "слишком большое"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save avatar"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"avatar":  variants["256"],
+		"avatars": variants,
+	})
+}
+
+// DeleteAvatar убирает аватар текущего пользователя
+func (h *Handlers) DeleteAvatar(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	if err := h.avatarService.DeleteAvatar(userID.(uint)); err != nil {
+		if strings.Contains(err.Error(), "не найден") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete avatar"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Аватар удален"})
+}
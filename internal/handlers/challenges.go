@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateChallenge вызывает одноклассника и выдает вызывающему раунд игры
+func (h *Handlers) CreateChallenge(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleStudent) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только ученики могут вызывать соперников"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	var req services.CreateChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	start, err := h.challengeService.CreateChallenge(userID.(uint), req)
+	if err != nil {
+		respondChallengeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, start)
+}
+
+// GetMyChallenges входящие и исходящие вызовы, фильтр status
+func (h *Handlers) GetMyChallenges(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	challenges, total, err := h.challengeService.GetChallenges(userID.(uint), c.Query("status"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get challenges"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": challenges,
+		"total": total,
+	})
+}
+
+func (h *Handlers) GetChallenge(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID вызова"})
+		return
+	}
+
+	challenge, err := h.challengeService.GetChallenge(userID.(uint), uint(id))
+	if err != nil {
+		respondChallengeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, challenge)
+}
+
+// AcceptChallenge выдает сопернику тот же раунд, что сыграл вызывающий
+func (h *Handlers) AcceptChallenge(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID вызова"})
+		return
+	}
+
+	start, err := h.challengeService.AcceptChallenge(userID.(uint), uint(id))
+	if err != nil {
+		respondChallengeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, start)
+}
+
+func (h *Handlers) DeclineChallenge(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID вызова"})
+		return
+	}
+
+	challenge, err := h.challengeService.DeclineChallenge(userID.(uint), uint(id))
+	if err != nil {
+		respondChallengeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, challenge)
+}
+
+// GetHeadToHead счет личных встреч текущего пользователя с учеником :id (для профиля)
+func (h *Handlers) GetHeadToHead(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	opponentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || opponentID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID ученика"})
+		return
+	}
+
+	record, err := h.challengeService.GetHeadToHead(userID.(uint), uint(opponentID))
+	if err != nil {
+		respondChallengeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
+
+func respondChallengeError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "Нельзя") || strings.Contains(err.Error(), "только ученика своего класса"):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "уже"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		respondGameError(c, err, "Failed to process challenge")
+	}
+}
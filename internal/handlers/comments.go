@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCommentThread обсуждение работы: ?target_type=test_attempt|game_result|student&target_id=
+func (h *Handlers) GetCommentThread(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	targetID, err := strconv.ParseUint(c.Query("target_id"), 10, 32)
+	if err != nil || targetID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID работы"})
+		return
+	}
+
+	thread, err := h.commentService.GetThread(userID.(uint), models.Role(c.GetString("role")),
+		models.CommentTarget(c.Query("target_type")), uint(targetID))
+	if err != nil {
+		respondCommentError(c, err, "Failed to get comments")
+		return
+	}
+
+	c.JSON(http.StatusOK, thread)
+}
+
+// AddComment сообщение в обсуждении: отзыв учителя или ответ ученика
+func (h *Handlers) AddComment(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req services.CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	comment, err := h.commentService.AddComment(userID.(uint), models.Role(c.GetString("role")), req)
+	if err != nil {
+		respondCommentError(c, err, "Failed to add comment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// GetCommentThreads ученику - отзывы на его работы, учителю - обсуждения
+// учеников (?student_id=, ?unread=true)
+func (h *Handlers) GetCommentThreads(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	req := services.ThreadListRequest{UnreadOnly: c.Query("unread") == "true"}
+	req.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "20"))
+	req.Offset, _ = strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if studentID := c.Query("student_id"); studentID != "" {
+		id, err := strconv.ParseUint(studentID, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID студента"})
+			return
+		}
+		req.StudentID = uint(id)
+	}
+
+	threads, total, err := h.commentService.ListThreads(userID.(uint), models.Role(c.GetString("role")), req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get comment threads"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": threads,
+		"total": total,
+	})
+}
+
+// GetUnreadComments число непрочитанных сообщений
+func (h *Handlers) GetUnreadComments(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	count, err := h.commentService.UnreadCount(userID.(uint), models.Role(c.GetString("role")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count comments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unread": count})
+}
+
+func respondCommentError(c *gin.Context, err error, fallback string) {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "не найден"):
+		c.JSON(http.StatusNotFound, gin.H{"error": message})
+	case strings.Contains(message, "доступно только"):
+		c.JSON(http.StatusForbidden, gin.H{"error": message})
+	case strings.Contains(message, "Неверн") || strings.Contains(message, "Необходимо") ||
+		strings.Contains(message, "слишком длинный"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -40,6 +40,17 @@ func (h *Handlers) ExportStats(c *gin.Context) {
 		return
 	}
 
+	// Статистика всех студентов из кэша одним запросом
+	studentIDs := make([]uint, len(students))
+	for i, student := range students {
+		studentIDs[i] = student.ID
+	}
+	studentStats, err := h.statsService.GetUsersStats(studentIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get stats"})
+		return
+	}
+
 	if format == "excel" {
 		f := excelize.NewFile()
 		defer func() {
@@ -64,27 +75,14 @@ func (h *Handlers) ExportStats(c *gin.Context) {
 
 		// Данные
 		for rowIdx, student := range students {
-			stats, err := h.userService.GetStudentStats(student.ID)
-			if err != nil {
-				continue
-			}
-
-			totalPoints := 0
-			if tp, ok := stats["total_points"].(int); ok {
-				totalPoints = tp
-			}
-			completedLessons := 0
-			if cl, ok := stats["completed_lessons"].(int); ok {
-				completedLessons = cl
-			}
-			totalAttempts := 0
-			if ta, ok := stats["total_attempts"].(int); ok {
-				totalAttempts = ta
-			}
-			avgPercentage := 0.0
-			if ap, ok := stats["average_percentage"].(float64); ok {
-				avgPercentage = ap
+			var stats models.StatsCounters
+			if cached, ok := studentStats[student.ID]; ok {
+				stats = cached.StatsCounters
 			}
+			totalPoints := stats.TotalPoints
+			completedLessons := stats.CompletedLessons
+			totalAttempts := stats.TotalAttempts
+			avgPercentage := stats.AveragePercentage()
 
 			levelStr := ""
 			if student.Level != nil {
@@ -140,27 +138,14 @@ func (h *Handlers) ExportStats(c *gin.Context) {
 
 	// Данные
 	for _, student := range students {
-		stats, err := h.userService.GetStudentStats(student.ID)
-		if err != nil {
-			continue // Пропускаем студента если не удалось получить статистику
-		}
-
-		totalPoints := 0
-		if tp, ok := stats["total_points"].(int); ok {
-			totalPoints = tp
-		}
-		completedLessons := 0
-		if cl, ok := stats["completed_lessons"].(int); ok {
-			completedLessons = cl
-		}
-		totalAttempts := 0
-		if ta, ok := stats["total_attempts"].(int); ok {
-			totalAttempts = ta
-		}
-		avgPercentage := 0.0
-		if ap, ok := stats["average_percentage"].(float64); ok {
-			avgPercentage = ap
+		var stats models.StatsCounters
+		if cached, ok := studentStats[student.ID]; ok {
+			stats = cached.StatsCounters
 		}
+		totalPoints := stats.TotalPoints
+		completedLessons := stats.CompletedLessons
+		totalAttempts := stats.TotalAttempts
+		avgPercentage := stats.AveragePercentage()
 
 		levelStr := ""
 		if student.Level != nil {
@@ -181,6 +166,12 @@ func (h *Handlers) ExportStats(c *gin.Context) {
 	}
 }
 
+// classLetter буква класса из запроса в том виде, в котором она хранится у
+// учеников и в кэше статистики: заглавная, без пробелов
+func classLetter(c *gin.Context) string {
+	return strings.ToUpper(strings.TrimSpace(c.Query("level_letter")))
+}
+
 func (h *Handlers) GetClassAnalytics(c *gin.Context) {
 	role, _ := c.Get("role")
 	if role != string(models.RoleTeacher) {
@@ -189,8 +180,6 @@ func (h *Handlers) GetClassAnalytics(c *gin.Context) {
 	}
 
 	level := c.Query("level")
-	levelLetter := c.Query("level_letter")
-
 	if level == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Необходим параметр level"})
 		return
@@ -202,145 +191,17 @@ func (h *Handlers) GetClassAnalytics(c *gin.Context) {
 		return
 	}
 
-	// Валидация уровня
-	if levelInt < 1 || levelInt > 11 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Класс должен быть от 1 до 11"})
-		return
-	}
-
-	// Санитизация буквы класса
-	if levelLetter != "" {
-		levelLetter = strings.TrimSpace(strings.ToUpper(levelLetter))
-		// Используем подсчет рун для правильной работы с кириллицей
-		if len([]rune(levelLetter)) > 1 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат буквы класса"})
-			return
-		}
-	}
-
-	// Получаем студентов класса
-	filters := make(map[string]string)
-	filters["level"] = strconv.Itoa(levelInt)
-	if levelLetter != "" {
-		filters["level_letter"] = levelLetter
-	}
-
-	students, err := h.userService.GetStudents(filters)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get students"})
-		return
-	}
-
-	// Статистика по урокам
-	lessons, err := h.lessonRepo.FindAll(true)
+	analytics, err := h.analyticsService.GetClassAnalytics(levelInt, classLetter(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get lessons"})
-		return
-	}
-
-	lessonStats := make([]gin.H, len(lessons))
-	for i, lesson := range lessons {
-		// Получаем прогресс по уроку
-		allProgress, err := h.progressRepo.FindByLessonID(lesson.ID, nil)
-		if err != nil {
-			continue
-		}
-
-		// Фильтруем по классу
-		var progress []models.LessonProgress
-		for _, p := range allProgress {
-			user, err := h.userRepo.FindByID(p.UserID)
-			if err != nil {
-				continue
-			}
-			if user.Level != nil && *user.Level == levelInt {
-				if levelLetter == "" || user.LevelLetter == levelLetter {
-					progress = append(progress, p)
-				}
-			}
-		}
-
-		completedCount := 0
-		totalAttempts := 0
-		var percentages []float64
-
-		for _, p := range progress {
-			if p.IsCompleted {
-				completedCount++
-			}
-			totalAttempts += p.AttemptsCount
-			if p.BestPercentage > 0 {
-				percentages = append(percentages, p.BestPercentage)
-			}
-		}
-
-		avgPercentage := 0.0
-		if len(percentages) > 0 {
-			sum := 0.0
-			for _, p := range percentages {
-				sum += p
-			}
-			avgPercentage = sum / float64(len(percentages))
-		}
-
-		lessonStats[i] = gin.H{
-			"lesson_id":          lesson.ID,
-			"lesson_title":       lesson.Title,
-			"lesson_order":       lesson.Order,
-			"total_students":     len(students),
-			"completed_count":    completedCount,
-			"completion_rate":    float64(completedCount) / float64(len(students)) * 100,
-			"average_percentage": avgPercentage,
-			"total_attempts":     totalAttempts,
+		if strings.Contains(err.Error(), "Класс должен") || strings.Contains(err.Error(), "Неверный") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get class analytics"})
 		}
+		return
 	}
 
-	// Общая статистика класса
-	var allProgress []models.LessonProgress
-	for _, student := range students {
-		studentProgress, err := h.progressRepo.FindByUserID(student.ID)
-		if err != nil {
-			continue
-		}
-		allProgress = append(allProgress, studentProgress...)
-	}
-
-	totalPoints := 0
-	completedLessons := 0
-	var allPercentages []float64
-
-	for _, p := range allProgress {
-		totalPoints += p.BestScore
-		if p.IsCompleted {
-			completedLessons++
-		}
-		if p.BestPercentage > 0 {
-			allPercentages = append(allPercentages, p.BestPercentage)
-		}
-	}
-
-	classAvgPercentage := 0.0
-	if len(allPercentages) > 0 {
-		sum := 0.0
-		for _, p := range allPercentages {
-			sum += p
-		}
-		classAvgPercentage = sum / float64(len(allPercentages))
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"class_info": gin.H{
-			"level":          levelInt,
-			"level_letter":   levelLetter,
-			"total_students": len(students),
-		},
-		"overall_stats": gin.H{
-			"total_points":       totalPoints,
-			"completed_lessons":  completedLessons,
-			"average_percentage": classAvgPercentage,
-		},
-		"lessons_stats": lessonStats,
-	})
+	c.JSON(http.StatusOK, analytics)
 }
 
 func (h *Handlers) GetClassActivityStats(c *gin.Context) {
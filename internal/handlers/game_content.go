@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxGameImportSize предельный размер файла импорта заданий
+const maxGameImportSize = 1 << 20
+
+// GetGameItems список заданий игр с фильтрами game_type, level, tag_id, include_inactive
+func (h *Handlers) GetGameItems(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+
+	req := services.GameItemListRequest{
+		GameType:        c.Query("game_type"),
+		IncludeInactive: c.Query("include_inactive") == "true",
+	}
+	if levelStr := c.Query("level"); levelStr != "" {
+		if l, err := strconv.Atoi(levelStr); err == nil {
+			req.Level = &l
+		}
+	}
+	if tagID, err := strconv.ParseUint(c.Query("tag_id"), 10, 32); err == nil {
+		req.TagID = uint(tagID)
+	}
+	req.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "50"))
+	req.Offset, _ = strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	items, total, err := h.gameContentService.ListItems(req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get game items"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": items,
+		"total": total,
+	})
+}
+
+func (h *Handlers) CreateGameItem(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только учителя могут добавлять задания"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	var req services.GameItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	item, err := h.gameContentService.CreateItem(userID.(uint), req)
+	if err != nil {
+		respondGameError(c, err, "Failed to save game item")
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+func (h *Handlers) UpdateGameItem(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только учителя могут изменять задания"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID задания"})
+		return
+	}
+
+	var req services.GameItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	item, err := h.gameContentService.UpdateItem(uint(id), req)
+	if err != nil {
+		respondGameError(c, err, "Failed to save game item")
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *Handlers) DeleteGameItem(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только учителя могут удалять задания"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID задания"})
+		return
+	}
+
+	if err := h.gameContentService.DeactivateItem(uint(id)); err != nil {
+		respondGameError(c, err, "Failed to save game item")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Задание отключено"})
+}
+
+// ImportGameItems загружает задания из JSON-файла (поле формы file)
+func (h *Handlers) ImportGameItems(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только учителя могут добавлять задания"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Необходимо приложить файл"})
+		return
+	}
+	if fileHeader.Size > maxGameImportSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Файл слишком большой: максимум 1 МБ"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Не удалось прочитать файл"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxGameImportSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Не удалось прочитать файл"})
+		return
+	}
+
+	imported, err := h.gameContentService.ImportItems(userID.(uint), data)
+	if err != nil {
+		respondGameError(c, err, "Failed to save game item")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"imported": imported})
+}
+
+// PreviewGameRound случайный раунд с ответами, чтобы учитель мог проверить задания уровня
+func (h *Handlers) PreviewGameRound(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+
+	level, err := strconv.Atoi(c.Query("level"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный уровень"})
+		return
+	}
+
+	preview, err := h.gameSessionService.PreviewRound(c.Query("game_type"), level)
+	if err != nil {
+		respondGameError(c, err, "Failed to build game round")
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
@@ -1,34 +1,159 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"englishlessons.back/internal/models"
 	"englishlessons.back/internal/services"
 	"github.com/gin-gonic/gin"
 )
 
-// SubmitGameResult сохраняет результат игры
-func (h *Handlers) SubmitGameResult(c *gin.Context) {
+// gameResultResponse результат игры вместе с полученными за него достижениями и опытом
+type gameResultResponse struct {
+	*models.GameResult
+	NewAchievements []map[string]interface{}         `json:"new_achievements"`
+	XPAwarded       int                              `json:"xp_awarded"`
+	Feedback        map[int]*services.SpellingResult `json:"feedback,omitempty"`
+}
+
+// GetGames возвращает реестр игр: уровни, размер раунда, подсчет очков и лимит времени
+func (h *Handlers) GetGames(c *gin.Context) {
+	c.JSON(http.StatusOK, models.GameRegistry)
+}
+
+// StartGameSession выдает новый раунд игры без правильных ответов
+func (h *Handlers) StartGameSession(c *gin.Context) {
 	userID := c.GetUint("user_id")
 	if userID == 0 {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	var req services.SubmitGameResultRequest
+	var req services.StartGameSessionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	session, err := h.gameSessionService.StartSession(userID, req)
+	if err != nil {
+		respondGameError(c, err, "Failed to start game session")
+		return
+	}
+
+	c.JSON(http.StatusCreated, session)
+}
+
+// AnswerGameSessionRequest один ответ раунда; формат зависит от игры
+type AnswerGameSessionRequest struct {
+	Answer models.JSONMap `json:"answer" binding:"required"`
+}
+
+// AnswerGameSession записывает один ответ раунда и сразу сообщает, верен ли он
+func (h *Handlers) AnswerGameSession(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || sessionID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID сессии"})
+		return
+	}
+
+	var req AnswerGameSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	result, err := h.gameSessionService.AnswerSession(userID, uint(sessionID), req.Answer)
+	if err != nil {
+		respondGameError(c, err, "Failed to save answer")
 		return
 	}
 
-	result, err := h.gameResultService.SubmitResult(userID, req)
+	c.JSON(http.StatusOK, result)
+}
+
+// SubmitGameSessionRequest ответы на раунд; формат зависит от игры.
+// Если ответы уже отправлялись по одному, проверяются они, а не эти.
+type SubmitGameSessionRequest struct {
+	Answers models.JSONMap `json:"answers" binding:"required"`
+}
+
+// SubmitGameSession проверяет ответы на сервере и сохраняет результат игры
+func (h *Handlers) SubmitGameSession(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || sessionID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID сессии"})
+		return
+	}
+
+	var req SubmitGameSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	result, err := h.gameSessionService.SubmitSession(userID, uint(sessionID), req.Answers)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save game result"})
+		respondGameError(c, err, "Failed to save game result")
 		return
 	}
 
-	c.JSON(http.StatusCreated, result)
+	c.JSON(http.StatusCreated, gameResultResponse{
+		GameResult:      result.GameResult,
+		NewAchievements: h.formatNewAchievements(result.Outcome.Achievements, c.DefaultQuery("lang", "ru")),
+		XPAwarded:       result.Outcome.XPAwarded,
+		Feedback:        result.Feedback,
+	})
+}
+
+// SubmitGameResult старый способ сохранить результат с очками, посчитанными
+// клиентом. Оставлен, чтобы старые клиенты получали понятную ошибку вместо 404.
+//
+// Deprecated: раунд начинается через POST /api/games/sessions, ответы
+// отправляются в POST /api/games/sessions/:id/submit.
+func (h *Handlers) SubmitGameResult(c *gin.Context) {
+	c.JSON(http.StatusGone, &services.GameValidationError{
+		Code:    services.GameErrorEndpointRemoved,
+		Message: "Результаты игр больше не принимаются с клиента: начните раунд через POST /api/games/sessions и отправьте ответы в POST /api/games/sessions/:id/submit",
+	})
+}
+
+// respondGameError отвечает на ошибки игр. Ошибки проверки отдаются с кодом
+// и полем запроса, чтобы фронтенд мог показать причину отказа.
+func respondGameError(c *gin.Context, err error, fallback string) {
+	var gameErr *services.GameValidationError
+	if errors.As(err, &gameErr) {
+		statusCode := http.StatusBadRequest
+		if gameErr.Code == services.GameErrorSessionSubmitted || gameErr.Code == services.GameErrorSessionExpired {
+			statusCode = http.StatusConflict
+		}
+		c.JSON(statusCode, gameErr)
+		return
+	}
+
+	switch {
+	case strings.Contains(err.Error(), "не найден"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "Неверн") || strings.Contains(err.Error(), "Необходимо"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
 }
 
 // GetMyGameResults получает результаты текущего пользователя
@@ -147,7 +272,7 @@ func (h *Handlers) GetGameLeaderboard(c *gin.Context) {
 		}
 	}
 
-	results, err := h.gameResultService.GetLeaderboard(gameType, level, limit)
+	results, err := h.gameResultService.GetLeaderboard(c.GetUint("user_id"), c.GetString("role"), gameType, level, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get leaderboard"})
 		return
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+)
+
+// gradebookRequest фильтры выгрузки из запроса:
+// ?level=&level_letter=&from=2025-09-01&to=2025-12-31&scale=90,75,60,40
+func gradebookRequest(c *gin.Context) services.GradebookRequest {
+	return services.GradebookRequest{
+		Level:       c.Query("level"),
+		LevelLetter: c.Query("level_letter"),
+		From:        c.Query("from"),
+		To:          c.Query("to"),
+		Scale:       c.Query("scale"),
+	}
+}
+
+func respondGradebookError(c *gin.Context, err error) {
+	if strings.Contains(err.Error(), "Неверн") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build gradebook"})
+}
+
+// ExportGradebook журнал оценок: ученик в строке, урок в графе
+// (?format=csv|excel, ?value=grade|percent - что писать в CSV; в Excel оба листа)
+func (h *Handlers) ExportGradebook(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+
+	format := c.Query("format")
+	if format != "csv" && format != "excel" {
+		format = "csv"
+	}
+	usePercent := c.Query("value") == "percent"
+
+	book, err := h.gradebookService.Build(gradebookRequest(c))
+	if err != nil {
+		respondGradebookError(c, err)
+		return
+	}
+
+	headers := []string{"ID", "Фамилия", "Имя", "Класс"}
+	for _, lesson := range book.Lessons {
+		headers = append(headers, fmt.Sprintf("%d. %s", lesson.Order, lesson.Title))
+	}
+	headers = append(headers, "Средняя оценка")
+
+	filename := fmt.Sprintf("gradebook_%s", time.Now().Format("20060102"))
+
+	if format == "excel" {
+		f := excelize.NewFile()
+		defer func() {
+			if err := f.Close(); err != nil {
+				fmt.Println(err)
+			}
+		}()
+
+		sheets := []struct {
+			name    string
+			percent bool
+		}{
+			{"Оценки", false},
+			{"Проценты", true},
+		}
+		for _, sheet := range sheets {
+			f.NewSheet(sheet.name)
+			for i, header := range headers {
+				cell, _ := excelize.CoordinatesToCellName(i+1, 1)
+				f.SetCellValue(sheet.name, cell, header)
+			}
+			for rowIdx, student := range book.Students {
+				for colIdx, val := range gradebookRow(book, &student, sheet.percent) {
+					cell, _ := excelize.CoordinatesToCellName(colIdx+1, rowIdx+2)
+					f.SetCellValue(sheet.name, cell, val)
+				}
+			}
+		}
+		f.DeleteSheet("Sheet1")
+		f.SetActiveSheet(0)
+
+		c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.xlsx", filename))
+		c.Header("Access-Control-Expose-Headers", "Content-Disposition")
+
+		if err := f.Write(c.Writer); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate excel"})
+		}
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.csv", filename))
+	c.Header("Access-Control-Expose-Headers", "Content-Disposition")
+
+	// Добавляем BOM для корректного отображения кириллицы в Excel
+	c.Writer.Write([]byte{0xEF, 0xBB, 0xBF})
+
+	writer := csv.NewWriter(c.Writer)
+	defer writer.Flush()
+
+	writer.Write(headers)
+	for _, student := range book.Students {
+		values := gradebookRow(book, &student, usePercent)
+		row := make([]string, len(values))
+		for i, val := range values {
+			row[i] = fmt.Sprint(val)
+		}
+		writer.Write(row)
+	}
+}
+
+// gradebookRow строка журнала: данные ученика, оценка или процент по каждому
+// уроку (пусто, если попыток не было) и средняя оценка
+func gradebookRow(book *services.Gradebook, student *models.User, percent bool) []interface{} {
+	levelStr := ""
+	if student.Level != nil {
+		levelStr = fmt.Sprintf("%d-%s", *student.Level, student.LevelLetter)
+	}
+	row := []interface{}{student.ID, student.LastName, student.FirstName, levelStr}
+
+	for _, lesson := range book.Lessons {
+		cell, ok := book.Cells[student.ID][lesson.ID]
+		switch {
+		case !ok:
+			row = append(row, "")
+		case percent:
+			row = append(row, strconv.FormatFloat(cell.Percentage, 'f', 2, 64))
+		default:
+			row = append(row, cell.Grade)
+		}
+	}
+
+	if average, ok := book.AverageGrade(student.ID); ok {
+		row = append(row, average)
+	} else {
+		row = append(row, "")
+	}
+	return row
+}
+
+// ExportOneRoster пакет OneRoster 1.2 CSV (zip) для школьной информационной системы
+func (h *Handlers) ExportOneRoster(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+
+	book, err := h.gradebookService.Build(gradebookRequest(c))
+	if err != nil {
+		respondGradebookError(c, err)
+		return
+	}
+
+	// Архив собирается в памяти, чтобы ошибка не оборвала уже начатый ответ
+	var buf bytes.Buffer
+	if err := services.WriteOneRoster(&buf, book); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate OneRoster export"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=oneroster_%s.zip", time.Now().Format("20060102")))
+	c.Header("Access-Control-Expose-Headers", "Content-Disposition")
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
@@ -1,21 +1,45 @@
 package handlers
 
 import (
+	"log"
+
 	"englishlessons.back/internal/config"
+	"englishlessons.back/internal/models"
 	"englishlessons.back/internal/repositories"
 	"englishlessons.back/internal/services"
+	"englishlessons.back/internal/storage"
 	"gorm.io/gorm"
 )
 
 type Handlers struct {
 	// Services
-	authService        *services.AuthService
-	userService        *services.UserService
-	lessonService      *services.LessonService
-	testService        *services.TestService
-	achievementService *services.AchievementService
-	leaderboardService *services.LeaderboardService
-	gameResultService  *services.GameResultService
+	authService         *services.AuthService
+	userService         *services.UserService
+	lessonService       *services.LessonService
+	testService         *services.TestService
+	achievementService  *services.AchievementService
+	leaderboardService  *services.LeaderboardService
+	gameResultService   *services.GameResultService
+	gameSessionService  *services.GameSessionService
+	gameContentService  *services.GameContentService
+	liveQuizService     *services.LiveQuizService
+	challengeService    *services.ChallengeService
+	reviewService       *services.ReviewService
+	masteryService      *services.MasteryService
+	tagService          *services.TagService
+	placementService    *services.PlacementService
+	mediaService        *services.MediaService
+	avatarService       *services.AvatarService
+	commentService      *services.CommentService
+	notificationService *services.NotificationService
+	webhookService      *services.WebhookService
+	gradebookService    *services.GradebookService
+	streakService       *services.StreakService
+	xpService           *services.XPService
+	seasonService       *services.SeasonService
+	statsService        *services.StatsService
+	analyticsService    *services.AnalyticsService
+	privacyService      *services.PrivacyService
 
 	// Repositories (для временного доступа, пока не все перенесено в сервисы)
 	progressRepo *repositories.ProgressRepository
@@ -35,28 +59,142 @@ func New(db *gorm.DB, cfg *config.Config) *Handlers {
 	testRepo := repositories.NewTestRepository(db)
 	achievementRepo := repositories.NewAchievementRepository(db)
 	gameResultRepo := repositories.NewGameResultRepository(db)
+	activityRepo := repositories.NewActivityRepository(db)
+	xpRepo := repositories.NewXPRepository(db)
+	leaderboardRepo := repositories.NewLeaderboardRepository(db)
+	seasonRepo := repositories.NewSeasonRepository(db)
+	statsRepo := repositories.NewStatsRepository(db)
+	analyticsRepo := repositories.NewAnalyticsRepository(db)
+	privacyRepo := repositories.NewPrivacyRepository(db)
+	gameItemRepo := repositories.NewGameItemRepository(db)
+	gameSessionRepo := repositories.NewGameSessionRepository(db)
+	challengeRepo := repositories.NewChallengeRepository(db)
+	reviewRepo := repositories.NewReviewRepository(db)
+	masteryRepo := repositories.NewMasteryRepository(db)
+	tagRepo := repositories.NewTagRepository(db)
+	placementRepo := repositories.NewPlacementRepository(db)
+	mediaRepo := repositories.NewMediaRepository(db)
+	commentRepo := repositories.NewCommentRepository(db)
+	notificationRepo := repositories.NewNotificationRepository(db)
+	webhookRepo := repositories.NewWebhookRepository(db)
+
+	// Хранилище медиафайлов
+	store, err := storage.New(storage.Config{
+		Driver:   cfg.Media.Driver,
+		LocalDir: cfg.Media.LocalDir,
+		S3: storage.S3Config{
+			Endpoint:  cfg.Media.S3Endpoint,
+			Region:    cfg.Media.S3Region,
+			Bucket:    cfg.Media.S3Bucket,
+			AccessKey: cfg.Media.S3AccessKey,
+			SecretKey: cfg.Media.S3SecretKey,
+			PathStyle: cfg.Media.S3PathStyle,
+		},
+	})
+	if err != nil {
+		log.Fatalf("Failed to configure media storage: %v", err)
+	}
 
 	// Services
-	authService := services.NewAuthService(userRepo, cfg.JWTSecret)
-	userService := services.NewUserService(userRepo, progressRepo)
+	eventBus := services.NewEventBus()
+	notificationService := services.NewNotificationService(notificationRepo, gameResultRepo)
+	privacyService := services.NewPrivacyService(privacyRepo, userRepo)
+	authService := services.NewAuthService(userRepo, cfg.JWTSecret, eventBus)
+	statsService := services.NewStatsService(statsRepo, userRepo)
+	reviewService := services.NewReviewService(reviewRepo, lessonRepo, gameItemRepo, cfg.Location)
+	userService := services.NewUserService(userRepo, progressRepo, statsService, reviewService, notificationService)
+	analyticsService := services.NewAnalyticsService(analyticsRepo, statsService)
 	lessonService := services.NewLessonService(lessonRepo, progressRepo)
-	testService := services.NewTestService(testRepo, lessonRepo, progressRepo, achievementRepo)
-	achievementService := services.NewAchievementService(achievementRepo, progressRepo, lessonRepo)
-	leaderboardService := services.NewLeaderboardService(userRepo, progressRepo)
-	gameResultService := services.NewGameResultService(gameResultRepo)
+	masteryService := services.NewMasteryService(masteryRepo, lessonRepo, userRepo, lessonService)
+	testService := services.NewTestService(testRepo, lessonRepo, progressRepo, eventBus)
+	achievementService := services.NewAchievementService(achievementRepo, progressRepo, lessonRepo, userRepo, gameResultRepo, testRepo, activityRepo, eventBus)
+	leaderboardService := services.NewLeaderboardService(userRepo, leaderboardRepo, seasonRepo, privacyService, cfg.Location)
+	seasonService := services.NewSeasonService(seasonRepo, leaderboardService, cfg.Location)
+	gameResultService := services.NewGameResultService(gameResultRepo, privacyService)
+	gameSessionService := services.NewGameSessionService(gameItemRepo, gameSessionRepo, gameResultRepo, eventBus)
+	gameContentService := services.NewGameContentService(gameItemRepo)
+	tagService := services.NewTagService(tagRepo, lessonRepo, gameItemRepo)
+	placementService := services.NewPlacementService(placementRepo, tagRepo, lessonRepo, progressRepo, userRepo, notificationService)
+	mediaService := services.NewMediaService(mediaRepo, lessonRepo, store, cfg.Media.MaxAudioSize)
+	avatarService := services.NewAvatarService(userRepo, store)
+	commentService := services.NewCommentService(commentRepo, notificationService)
+	liveQuizService := services.NewLiveQuizService(gameItemRepo, gameResultRepo, userRepo, privacyService, eventBus)
+	challengeService := services.NewChallengeService(challengeRepo, userRepo, gameResultRepo, gameSessionService, notificationService)
+	streakService := services.NewStreakService(activityRepo, cfg.Location, eventBus)
+	xpService := services.NewXPService(xpRepo, cfg.XP)
+	webhookService := services.NewWebhookService(webhookRepo, userRepo, lessonRepo)
+	gradeScale, err := services.ParseGradeScale(cfg.GradeScale)
+	if err != nil {
+		log.Fatalf("Invalid GRADE_SCALE=%q: %v", cfg.GradeScale, err)
+	}
+	gradebookService := services.NewGradebookService(progressRepo, userRepo, lessonRepo, gradeScale, cfg.Location)
+
+	// Подписчики событий (порядок важен: серия считает XP дня по опыту,
+	// уже начисленному XPService, и затем публикует streak_updated)
+	eventBus.Subscribe(models.EventTestSubmitted, statsService)
+	eventBus.Subscribe(models.EventGameFinished, statsService)
+	eventBus.Subscribe(models.EventGameFinished, challengeService)
+	eventBus.Subscribe(models.EventTestSubmitted, reviewService)
+	eventBus.Subscribe(models.EventGameFinished, reviewService)
+	eventBus.Subscribe(models.EventTestSubmitted, masteryService)
+	eventBus.Subscribe(models.EventGameFinished, masteryService)
+	eventBus.Subscribe(models.EventTestSubmitted, tagService)
+	eventBus.Subscribe(models.EventGameFinished, tagService)
+	eventBus.Subscribe(models.EventTestSubmitted, achievementService)
+	eventBus.Subscribe(models.EventGameFinished, achievementService)
+	eventBus.Subscribe(models.EventStreakUpdated, achievementService)
+	eventBus.Subscribe(models.EventTestSubmitted, xpService)
+	eventBus.Subscribe(models.EventGameFinished, xpService)
+	eventBus.Subscribe(models.EventStreakUpdated, xpService)
+	eventBus.Subscribe(models.EventAchievementEarned, xpService)
+	eventBus.Subscribe(models.EventTestSubmitted, streakService)
+	eventBus.Subscribe(models.EventGameFinished, streakService)
+	eventBus.Subscribe(models.EventTestSubmitted, notificationService)
+	eventBus.Subscribe(models.EventGameFinished, notificationService)
+	eventBus.Subscribe(models.EventAchievementEarned, notificationService)
+	eventBus.Subscribe(models.EventTestSubmitted, webhookService)
+	eventBus.Subscribe(models.EventGameFinished, webhookService)
+	eventBus.Subscribe(models.EventAchievementEarned, webhookService)
+	eventBus.Subscribe(models.EventUserRegistered, webhookService)
+
+	// Фоновые задачи: отправка очереди вебхуков, очистка брошенных комнат игры
+	// и закрытие завершившихся сезонов
+	webhookService.Start()
+	liveQuizService.Start()
+	seasonService.Start()
 
 	return &Handlers{
-		authService:        authService,
-		userService:        userService,
-		lessonService:      lessonService,
-		testService:        testService,
-		achievementService: achievementService,
-		leaderboardService: leaderboardService,
-		gameResultService:  gameResultService,
-		progressRepo:       progressRepo,
-		lessonRepo:         lessonRepo,
-		userRepo:           userRepo,
-		testRepo:           testRepo,
-		cfg:                cfg,
+		authService:         authService,
+		userService:         userService,
+		lessonService:       lessonService,
+		testService:         testService,
+		achievementService:  achievementService,
+		leaderboardService:  leaderboardService,
+		gameResultService:   gameResultService,
+		gameSessionService:  gameSessionService,
+		gameContentService:  gameContentService,
+		liveQuizService:     liveQuizService,
+		challengeService:    challengeService,
+		reviewService:       reviewService,
+		masteryService:      masteryService,
+		tagService:          tagService,
+		placementService:    placementService,
+		mediaService:        mediaService,
+		avatarService:       avatarService,
+		commentService:      commentService,
+		notificationService: notificationService,
+		webhookService:      webhookService,
+		gradebookService:    gradebookService,
+		streakService:       streakService,
+		xpService:           xpService,
+		seasonService:       seasonService,
+		statsService:        statsService,
+		analyticsService:    analyticsService,
+		privacyService:      privacyService,
+		progressRepo:        progressRepo,
+		lessonRepo:          lessonRepo,
+		userRepo:            userRepo,
+		testRepo:            testRepo,
+		cfg:                 cfg,
 	}
 }
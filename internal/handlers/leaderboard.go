@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,10 +18,31 @@ func (h *Handlers) GetLeaderboard(c *gin.Context) {
 	if levelLetter := c.Query("level_letter"); levelLetter != "" {
 		filters["level_letter"] = levelLetter
 	}
+	if sortBy := c.Query("sort"); sortBy != "" {
+		filters["sort"] = sortBy
+	}
+	if limit := c.Query("limit"); limit != "" {
+		filters["limit"] = limit
+	}
+	if offset := c.Query("offset"); offset != "" {
+		filters["offset"] = offset
+	}
+	if period := c.Query("period"); period != "" {
+		filters["period"] = period
+	}
+	if seasonID := c.Query("season_id"); seasonID != "" {
+		filters["season_id"] = seasonID
+	}
 
 	leaderboard, err := h.leaderboardService.GetLeaderboard(userID.(uint), role.(string), filters)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get leaderboard"})
+		if strings.HasPrefix(err.Error(), "Неверный") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else if err.Error() == "Сезон не найден" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get leaderboard"})
+		}
 		return
 	}
 
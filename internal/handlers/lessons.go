@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -62,25 +63,14 @@ func (h *Handlers) GetLesson(c *gin.Context) {
 
 	questions := make([]gin.H, len(lesson.Questions))
 	for i, q := range lesson.Questions {
-		answerOptions := make([]gin.H, len(q.AnswerOptions))
-		for j, ao := range q.AnswerOptions {
-			aoData := gin.H{
-				"id":    ao.ID,
-				"text":  ao.Text,
-				"order": ao.Order,
-			}
-			// Для учителей показываем правильность ответа
-			if role == string(models.RoleTeacher) {
-				aoData["is_correct"] = ao.IsCorrect
-			}
-			answerOptions[j] = aoData
-		}
-
 		questions[i] = gin.H{
 			"id":             q.ID,
 			"text":           q.Text,
+			"type":           q.Type,
+			"audio":          q.Audio,
 			"order":          q.Order,
-			"answer_options": answerOptions,
+			"answer_options": answerOptionsView(&q, role == string(models.RoleTeacher)),
+			"tags":           q.Tags,
 		}
 	}
 
@@ -90,7 +80,9 @@ func (h *Handlers) GetLesson(c *gin.Context) {
 		"description": lesson.Description,
 		"order":       lesson.Order,
 		"is_active":   lesson.IsActive,
+		"blocks":      lesson.Blocks,
 		"questions":   questions,
+		"tags":        lesson.Tags,
 		"created_at":  lesson.CreatedAt,
 	}
 
@@ -125,30 +117,84 @@ func (h *Handlers) GetLessonQuestions(c *gin.Context) {
 	role, _ := c.Get("role")
 	result := make([]gin.H, len(questions))
 	for i, q := range questions {
-		answerOptions := make([]gin.H, len(q.AnswerOptions))
-		for j, ao := range q.AnswerOptions {
-			aoData := gin.H{
-				"id":    ao.ID,
-				"text":  ao.Text,
-				"order": ao.Order,
-			}
-			if role == string(models.RoleTeacher) {
-				aoData["is_correct"] = ao.IsCorrect
-			}
-			answerOptions[j] = aoData
-		}
-
 		result[i] = gin.H{
 			"id":             q.ID,
 			"text":           q.Text,
+			"type":           q.Type,
+			"audio":          q.Audio,
 			"order":          q.Order,
-			"answer_options": answerOptions,
+			"answer_options": answerOptionsView(&q, role == string(models.RoleTeacher)),
 		}
 	}
 
 	c.JSON(http.StatusOK, result)
 }
 
+// CreateLessonQuestion добавляет вопрос в урок (для учителей)
+func (h *Handlers) CreateLessonQuestion(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+
+	lessonID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || lessonID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID урока"})
+		return
+	}
+
+	var req services.CreateQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	question, err := h.lessonService.CreateQuestion(uint(lessonID), req)
+	if err != nil {
+		switch {
+		case err.Error() == "Lesson not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "Необходимо") || strings.Contains(err.Error(), "Неверн"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create question"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"id":             question.ID,
+		"lesson_id":      question.LessonID,
+		"text":           question.Text,
+		"type":           question.Type,
+		"order":          question.Order,
+		"answer_options": answerOptionsView(question, true),
+	})
+}
+
+// answerOptionsView варианты ответа для вывода. Правильность видят только
+// учителя; у вопросов с вводом ответа варианты - это допустимые написания,
+// поэтому ученикам они не показываются.
+func answerOptionsView(q *models.Question, isTeacher bool) []gin.H {
+	if q.Type == models.QuestionTyped && !isTeacher {
+		return []gin.H{}
+	}
+	answerOptions := make([]gin.H, len(q.AnswerOptions))
+	for j, ao := range q.AnswerOptions {
+		aoData := gin.H{
+			"id":    ao.ID,
+			"text":  ao.Text,
+			"order": ao.Order,
+		}
+		if isTeacher {
+			aoData["is_correct"] = ao.IsCorrect
+		}
+		answerOptions[j] = aoData
+	}
+	return answerOptions
+}
+
 func (h *Handlers) GetMyProgress(c *gin.Context) {
 	role, _ := c.Get("role")
 	if role != string(models.RoleStudent) {
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// liveQuizHeartbeat интервал пустых событий, чтобы прокси не закрывал поток
+const liveQuizHeartbeat = 25 * time.Second
+
+// CreateLiveQuiz открывает комнату Quiz Show для игры в классе
+func (h *Handlers) CreateLiveQuiz(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только учителя могут проводить игру"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	var req services.CreateLiveQuizRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	state, err := h.liveQuizService.CreateRoom(userID.(uint), req)
+	if err != nil {
+		respondLiveQuizError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, state)
+}
+
+// JoinLiveQuiz вход ученика в комнату по коду
+func (h *Handlers) JoinLiveQuiz(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleStudent) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только ученики могут участвовать в игре"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	state, err := h.liveQuizService.Join(liveQuizCode(c), userID.(uint))
+	if err != nil {
+		respondLiveQuizError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+// StreamLiveQuiz поток событий комнаты (Server-Sent Events): вопросы,
+// таблица участников после каждого ответа, правильные ответы и итоги
+func (h *Handlers) StreamLiveQuiz(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	events, unsubscribe, err := h.liveQuizService.Subscribe(liveQuizCode(c), userID.(uint))
+	if err != nil {
+		respondLiveQuizError(c, err)
+		return
+	}
+	defer unsubscribe()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+
+	heartbeat := time.NewTicker(liveQuizHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Type, event.Data)
+			return true
+		case <-heartbeat.C:
+			c.SSEvent("ping", gin.H{})
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// NextLiveQuizQuestion показывает следующий вопрос
+func (h *Handlers) NextLiveQuizQuestion(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	state, err := h.liveQuizService.NextQuestion(liveQuizCode(c), userID.(uint))
+	if err != nil {
+		respondLiveQuizError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+type LiveQuizAnswerRequest struct {
+	QuestionIndex int  `json:"question_index"`
+	Option        *int `json:"option" binding:"required"`
+}
+
+// AnswerLiveQuiz ответ ученика на текущий вопрос
+func (h *Handlers) AnswerLiveQuiz(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req LiveQuizAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	standing, err := h.liveQuizService.Answer(liveQuizCode(c), userID.(uint), req.QuestionIndex, *req.Option)
+	if err != nil {
+		respondLiveQuizError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, standing)
+}
+
+// FinishLiveQuiz завершает игру и сохраняет результаты участников
+func (h *Handlers) FinishLiveQuiz(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	standings, err := h.liveQuizService.Finish(liveQuizCode(c), userID.(uint))
+	if err != nil {
+		respondLiveQuizError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"standings": standings})
+}
+
+func liveQuizCode(c *gin.Context) string {
+	return strings.ToUpper(strings.TrimSpace(c.Param("code")))
+}
+
+func respondLiveQuizError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "не найдена"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "только ее учитель") || strings.Contains(err.Error(), "Сначала необходимо"):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "уже") || strings.Contains(err.Error(), "закончились") ||
+		strings.Contains(err.Error(), "заполнена"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		respondGameError(c, err, "Failed to process live quiz")
+	}
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/services"
+	"englishlessons.back/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UploadAudio загружает аудиофайл в библиотеку (поле формы file, для учителей)
+func (h *Handlers) UploadAudio(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+	userID, _ := c.Get("user_id")
+
+	maxSize := h.mediaService.MaxAudioSize()
+	// Запас на заголовки multipart; сам файл проверяется отдельно
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+1<<20)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Файл слишком большой"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Необходимо приложить файл"})
+		return
+	}
+	if fileHeader.Size > maxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Файл слишком большой"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Не удалось прочитать файл"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Не удалось прочитать файл"})
+		return
+	}
+
+	media, err := h.mediaService.UploadAudio(userID.(uint), fileHeader.Filename, data)
+	if err != nil {
+		respondMediaError(c, err, "Failed to upload audio")
+		return
+	}
+
+	c.JSON(http.StatusCreated, media)
+}
+
+// GetAudioLibrary загруженные аудиофайлы (для учителей)
+func (h *Handlers) GetAudioLibrary(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	files, total, err := h.mediaService.ListAudio(limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get audio files"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": files,
+		"total": total,
+	})
+}
+
+// ServeMedia отдает файл хранилища с поддержкой Range, чтобы плеер мог
+// перематывать запись. Ключи содержат хэш содержимого, поэтому файл
+// можно кэшировать бессрочно.
+func (h *Handlers) ServeMedia(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("filepath"), "/")
+	object, err := h.mediaService.Open(key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Файл не найден"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open file"})
+		return
+	}
+	defer object.Close()
+
+	if object.ContentType != "" {
+		c.Header("Content-Type", object.ContentType)
+	}
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	http.ServeContent(c.Writer, c.Request, path.Base(key), object.ModTime, object)
+}
+
+// SetQuestionAudio привязывает аудио к вопросу или отвязывает его (для учителей)
+func (h *Handlers) SetQuestionAudio(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID вопроса"})
+		return
+	}
+
+	var req services.SetAudioRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	question, err := h.mediaService.SetQuestionAudio(uint(id), req)
+	if err != nil {
+		respondMediaError(c, err, "Failed to set question audio")
+		return
+	}
+
+	c.JSON(http.StatusOK, question)
+}
+
+// CreateLessonBlock добавляет блок текста или аудио в урок (для учителей)
+func (h *Handlers) CreateLessonBlock(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+
+	lessonID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || lessonID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID урока"})
+		return
+	}
+
+	var req services.LessonBlockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	block, err := h.mediaService.CreateLessonBlock(uint(lessonID), req)
+	if err != nil {
+		respondMediaError(c, err, "Failed to create lesson block")
+		return
+	}
+
+	c.JSON(http.StatusCreated, block)
+}
+
+// DeleteLessonBlock удаляет блок урока (для учителей)
+func (h *Handlers) DeleteLessonBlock(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+
+	lessonID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || lessonID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID урока"})
+		return
+	}
+	blockID, err := strconv.ParseUint(c.Param("block_id"), 10, 32)
+	if err != nil || blockID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID блока"})
+		return
+	}
+
+	if err := h.mediaService.DeleteLessonBlock(uint(lessonID), uint(blockID)); err != nil {
+		respondMediaError(c, err, "Failed to delete lesson block")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Блок удален"})
+}
+
+func respondMediaError(c *gin.Context, err error, fallback string) {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "не найден"):
+		c.JSON(http.StatusNotFound, gin.H{"error": message})
+	case strings.Contains(message, "слишком большой"):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": message})
+	case strings.Contains(message, "Неподдерживаем") || strings.Contains(message, "Неверн") ||
+		strings.Contains(message, "Необходимо") || strings.Contains(message, "пустой"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"englishlessons.back/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// notificationHeartbeat период комментариев-пингов в потоке, чтобы прокси
+// и балансировщики не закрывали простаивающее соединение
+const notificationHeartbeat = 25 * time.Second
+
+// GetNotifications уведомления пользователя (?unread=true, ?limit=, ?offset=)
+func (h *Handlers) GetNotifications(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	req := services.NotificationListRequest{UnreadOnly: c.Query("unread") == "true"}
+	req.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "20"))
+	req.Offset, _ = strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	notifications, total, unread, err := h.notificationService.List(userID.(uint), req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items":  notifications,
+		"total":  total,
+		"unread": unread,
+	})
+}
+
+// MarkNotificationsRead отмечает прочитанными уведомления из списка ids
+func (h *Handlers) MarkNotificationsRead(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req services.MarkReadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	unread, err := h.notificationService.MarkRead(userID.(uint), req)
+	if err != nil {
+		message := err.Error()
+		if strings.Contains(message, "Неверн") || strings.Contains(message, "Необходимо") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": message})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notifications read"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unread": unread})
+}
+
+// MarkAllNotificationsRead отмечает прочитанными все уведомления пользователя
+func (h *Handlers) MarkAllNotificationsRead(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	if err := h.notificationService.MarkAllRead(userID.(uint)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notifications read"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unread": 0})
+}
+
+// StreamNotifications поток Server-Sent Events: сразу после подключения
+// событие unread с числом непрочитанных, затем notification на каждое новое
+// уведомление. Пропущенное за время разрыва клиент берет из GET /notifications.
+func (h *Handlers) StreamNotifications(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	unread, err := h.notificationService.UnreadCount(userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notifications"})
+		return
+	}
+
+	notifications, unsubscribe := h.notificationService.Subscribe(userID.(uint))
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// nginx иначе буферизует ответ и события приходят пачками
+	c.Header("X-Accel-Buffering", "no")
+
+	heartbeat := time.NewTicker(notificationHeartbeat)
+	defer heartbeat.Stop()
+
+	c.SSEvent("unread", gin.H{"unread": unread})
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case notification := <-notifications:
+			c.SSEvent("notification", notification)
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		}
+	})
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StartPlacement начинает тест на уровень или возвращает незавершенный
+func (h *Handlers) StartPlacement(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleStudent) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учеников"})
+		return
+	}
+	userID, _ := c.Get("user_id")
+
+	state, err := h.placementService.StartPlacement(userID.(uint))
+	if err != nil {
+		respondPlacementError(c, err, "Failed to start placement test")
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+// GetMyPlacement последний тест на уровень текущего ученика
+func (h *Handlers) GetMyPlacement(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	state, err := h.placementService.GetMyPlacement(userID.(uint))
+	if err != nil {
+		respondPlacementError(c, err, "Failed to get placement test")
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+// AnswerPlacement ответ на текущий вопрос; возвращает следующий вопрос или итог
+func (h *Handlers) AnswerPlacement(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleStudent) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учеников"})
+		return
+	}
+	userID, _ := c.Get("user_id")
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID теста"})
+		return
+	}
+
+	var req services.PlacementAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	state, err := h.placementService.AnswerPlacement(userID.(uint), uint(id), req)
+	if err != nil {
+		respondPlacementError(c, err, "Failed to save placement answer")
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+// GetClassPlacements тесты на уровень учеников класса (для учителей)
+func (h *Handlers) GetClassPlacements(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+
+	level, err := strconv.Atoi(c.Query("level"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Необходим параметр level"})
+		return
+	}
+
+	tests, err := h.placementService.GetClassPlacements(level, c.Query("level_letter"))
+	if err != nil {
+		respondPlacementError(c, err, "Failed to get placement tests")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": tests,
+		"total": len(tests),
+	})
+}
+
+// GetPlacement тест на уровень со всеми ответами (для учителей)
+func (h *Handlers) GetPlacement(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID теста"})
+		return
+	}
+
+	state, err := h.placementService.GetPlacement(uint(id))
+	if err != nil {
+		respondPlacementError(c, err, "Failed to get placement test")
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+// OverridePlacement учитель заменяет найденный уровень; открытые уроки пересчитываются
+func (h *Handlers) OverridePlacement(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+	userID, _ := c.Get("user_id")
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID теста"})
+		return
+	}
+
+	var req services.PlacementOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	state, err := h.placementService.OverridePlacement(userID.(uint), uint(id), req)
+	if err != nil {
+		respondPlacementError(c, err, "Failed to override placement level")
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+func respondPlacementError(c *gin.Context, err error, fallback string) {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "не найден"):
+		c.JSON(http.StatusNotFound, gin.H{"error": message})
+	case strings.Contains(message, "уже пройден") || strings.Contains(message, "уже завершен") ||
+		strings.Contains(message, "еще не завершен"):
+		c.JSON(http.StatusConflict, gin.H{"error": message})
+	case strings.Contains(message, "доступен ученикам"):
+		c.JSON(http.StatusForbidden, gin.H{"error": message})
+	case strings.Contains(message, "Неверн") || strings.Contains(message, "Класс должен") ||
+		strings.Contains(message, "Нет вопросов"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
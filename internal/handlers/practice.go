@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetNextPracticeQuestion следующий вопрос адаптивной практики по уроку
+func (h *Handlers) GetNextPracticeQuestion(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleStudent) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учеников"})
+		return
+	}
+	userID, _ := c.Get("user_id")
+
+	lessonID, err := strconv.ParseUint(c.Query("lesson_id"), 10, 32)
+	if err != nil || lessonID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Необходим параметр lesson_id"})
+		return
+	}
+
+	question, err := h.masteryService.NextPracticeQuestion(userID.(uint), uint(lessonID))
+	if err != nil {
+		respondPracticeError(c, err, "Failed to get practice question")
+		return
+	}
+
+	c.JSON(http.StatusOK, question)
+}
+
+// SubmitPracticeAnswer ответ на вопрос практики; возвращает новую оценку и следующий вопрос
+func (h *Handlers) SubmitPracticeAnswer(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleStudent) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учеников"})
+		return
+	}
+	userID, _ := c.Get("user_id")
+
+	var req services.PracticeAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	result, err := h.masteryService.SubmitPracticeAnswer(userID.(uint), req)
+	if err != nil {
+		respondPracticeError(c, err, "Failed to save practice answer")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetMasteryHeatmap освоение навыков учениками класса (для учителей)
+func (h *Handlers) GetMasteryHeatmap(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+
+	level, err := strconv.Atoi(c.Query("level"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Необходим параметр level"})
+		return
+	}
+
+	heatmap, err := h.masteryService.GetClassHeatmap(level, c.Query("level_letter"))
+	if err != nil {
+		if strings.Contains(err.Error(), "Класс должен") || strings.Contains(err.Error(), "Неверный") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get mastery heatmap"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, heatmap)
+}
+
+func respondPracticeError(c *gin.Context, err error, fallback string) {
+	message := err.Error()
+	switch {
+	case message == "Lesson not found" || strings.Contains(message, "не найден"):
+		c.JSON(http.StatusNotFound, gin.H{"error": message})
+	case strings.Contains(message, "предыдущий урок"):
+		c.JSON(http.StatusForbidden, gin.H{"error": message})
+	case strings.Contains(message, "Неверный") || strings.Contains(message, "нет вопросов"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
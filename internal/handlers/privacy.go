@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMyPrivacy настройки отображения текущего ученика в рейтингах
+func (h *Handlers) GetMyPrivacy(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	user, err := h.privacyService.GetUserPrivacy(userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get privacy settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"nickname":         user.Nickname,
+		"leaderboard_mode": user.LeaderboardMode,
+	})
+}
+
+func (h *Handlers) UpdateMyPrivacy(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req services.UserPrivacyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	user, err := h.privacyService.UpdateUserPrivacy(userID.(uint), req)
+	if err != nil {
+		if strings.Contains(err.Error(), "Неверный") || strings.Contains(err.Error(), "Необходимо") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update privacy settings"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"nickname":         user.Nickname,
+		"leaderboard_mode": user.LeaderboardMode,
+	})
+}
+
+func (h *Handlers) GetClassPrivacySettings(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+
+	settings, err := h.privacyService.GetClassSettings()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get class privacy settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func (h *Handlers) SaveClassPrivacySettings(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+	userID, _ := c.Get("user_id")
+
+	var req services.ClassPrivacyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	settings, err := h.privacyService.SaveClassSettings(userID.(uint), req)
+	if err != nil {
+		if strings.Contains(err.Error(), "Неверный") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save class privacy settings"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
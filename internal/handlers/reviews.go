@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"englishlessons.back/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetDueReviews элементы, которые текущему ученику пора повторить сегодня
+func (h *Handlers) GetDueReviews(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	items, total, err := h.reviewService.GetDue(userID.(uint), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get reviews"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": items,
+		"total": total,
+	})
+}
+
+// SubmitReview ответ на элемент повторения; возвращает правильный ответ и дату следующего повторения
+func (h *Handlers) SubmitReview(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID элемента повторения"})
+		return
+	}
+
+	var req services.SubmitReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	result, err := h.reviewService.SubmitReview(userID.(uint), uint(id), req)
+	if err != nil {
+		if strings.Contains(err.Error(), "еще не наступило") {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		respondGameError(c, err, "Failed to save review")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) GetSeasons(c *gin.Context) {
+	seasons, err := h.seasonService.GetSeasons()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get seasons"})
+		return
+	}
+
+	c.JSON(http.StatusOK, seasons)
+}
+
+func (h *Handlers) CreateSeason(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только учителя могут создавать сезоны"})
+		return
+	}
+	userID, _ := c.Get("user_id")
+
+	var req services.CreateSeasonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	season, err := h.seasonService.CreateSeason(userID.(uint), req)
+	if err != nil {
+		if strings.Contains(err.Error(), "Неверн") || strings.Contains(err.Error(), "Необходимо") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create season"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, season)
+}
+
+// CloseSeason сохраняет итоговые места сезона (в том числе досрочно)
+func (h *Handlers) CloseSeason(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только учителя могут закрывать сезоны"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID сезона"})
+		return
+	}
+
+	season, err := h.seasonService.CloseSeason(uint(id))
+	if err != nil {
+		if err.Error() == "Сезон не найден" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to close season"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, season)
+}
+
+// GetMySeasonHistory места текущего ученика по прошедшим сезонам
+func (h *Handlers) GetMySeasonHistory(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	history, err := h.seasonService.GetHistory(userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get season history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+func (h *Handlers) GetStudentSeasonHistory(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только учителя могут просматривать историю других пользователей"})
+		return
+	}
+
+	studentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || studentID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID студента"})
+		return
+	}
+
+	history, err := h.seasonService.GetHistory(uint(studentID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get season history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
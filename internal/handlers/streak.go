@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SetDailyGoalRequest struct {
+	DailyGoalXP int `json:"daily_goal_xp" binding:"required"`
+}
+
+// GetMyStreak возвращает серию, дневную цель и календарь активности для профиля
+func (h *Handlers) GetMyStreak(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	info, err := h.streakService.GetStreakInfo(userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get streak"})
+		return
+	}
+
+	c.JSON(http.StatusOK, info)
+}
+
+// SetDailyGoal изменяет дневную цель XP текущего пользователя
+func (h *Handlers) SetDailyGoal(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req SetDailyGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	streak, newAchievements, err := h.streakService.SetDailyGoal(userID.(uint), req.DailyGoalXP)
+	if err != nil {
+		if strings.Contains(err.Error(), "Неверная") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update daily goal"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"daily_goal_xp":    streak.DailyGoalXP,
+		"current_streak":   streak.CurrentStreak,
+		"new_achievements": h.formatNewAchievements(newAchievements, c.DefaultQuery("lang", "ru")),
+	})
+}
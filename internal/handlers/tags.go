@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTags теги с фильтром kind (topic, cefr, skill)
+func (h *Handlers) GetTags(c *gin.Context) {
+	tags, err := h.tagService.ListTags(c.Query("kind"))
+	if err != nil {
+		respondTagError(c, err, "Failed to get tags")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": tags,
+		"total": len(tags),
+	})
+}
+
+// CreateTag новая грамматическая тема (для учителей)
+func (h *Handlers) CreateTag(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+
+	var req services.CreateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	tag, err := h.tagService.CreateTag(req)
+	if err != nil {
+		respondTagError(c, err, "Failed to create tag")
+		return
+	}
+
+	c.JSON(http.StatusCreated, tag)
+}
+
+func (h *Handlers) DeleteTag(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID тега"})
+		return
+	}
+
+	if err := h.tagService.DeleteTag(uint(id)); err != nil {
+		respondTagError(c, err, "Failed to delete tag")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Тег удален"})
+}
+
+func (h *Handlers) SetLessonTags(c *gin.Context) {
+	h.setTags(c, "Неверный ID урока", h.tagService.SetLessonTags)
+}
+
+func (h *Handlers) SetQuestionTags(c *gin.Context) {
+	h.setTags(c, "Неверный ID вопроса", h.tagService.SetQuestionTags)
+}
+
+func (h *Handlers) SetGameItemTags(c *gin.Context) {
+	h.setTags(c, "Неверный ID задания", h.tagService.SetGameItemTags)
+}
+
+// setTags заменяет теги урока, вопроса или задания игры (для учителей)
+func (h *Handlers) setTags(c *gin.Context, invalidID string, set func(uint, services.SetTagsRequest) ([]models.Tag, error)) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalidID})
+		return
+	}
+
+	var req services.SetTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	tags, err := set(uint(id), req)
+	if err != nil {
+		respondTagError(c, err, "Failed to save tags")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+// GetMyTagStats результаты текущего ученика по тегам
+func (h *Handlers) GetMyTagStats(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	stats, err := h.analyticsService.GetStudentTagStats(userID.(uint), c.Query("kind"))
+	if err != nil {
+		respondTagError(c, err, "Failed to get tag stats")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": stats})
+}
+
+// GetStudentTagStats результаты ученика по тегам (для учителей)
+func (h *Handlers) GetStudentTagStats(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+
+	studentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || studentID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID студента"})
+		return
+	}
+	student, err := h.userService.GetUserByID(uint(studentID))
+	if err != nil || student.Role != models.RoleStudent {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Student not found"})
+		return
+	}
+
+	stats, err := h.analyticsService.GetStudentTagStats(student.ID, c.Query("kind"))
+	if err != nil {
+		respondTagError(c, err, "Failed to get tag stats")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": stats})
+}
+
+// GetClassTagStats результаты класса по тегам (для учителей)
+func (h *Handlers) GetClassTagStats(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+
+	level, err := strconv.Atoi(c.Query("level"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Необходим параметр level"})
+		return
+	}
+
+	stats, err := h.analyticsService.GetClassTagStats(level, classLetter(c), c.Query("kind"))
+	if err != nil {
+		respondTagError(c, err, "Failed to get tag stats")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": stats})
+}
+
+func respondTagError(c *gin.Context, err error, fallback string) {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "не найден"):
+		c.JSON(http.StatusNotFound, gin.H{"error": message})
+	case strings.Contains(message, "Неверн") || strings.Contains(message, "Класс должен") ||
+		strings.Contains(message, "Название тега"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -13,8 +13,9 @@ import (
 )
 
 type SubmitTestRequest struct {
-	LessonID int            `json:"lesson_id" binding:"required"`
-	Answers  map[string]int `json:"answers" binding:"required"`
+	LessonID    int               `json:"lesson_id" binding:"required"`
+	Answers     map[string]int    `json:"answers"`
+	TextAnswers map[string]string `json:"text_answers"`
 }
 
 func (h *Handlers) SubmitTest(c *gin.Context) {
@@ -39,15 +40,16 @@ func (h *Handlers) SubmitTest(c *gin.Context) {
 	}
 
 	// Валидация answers
-	if len(req.Answers) == 0 {
+	if len(req.Answers) == 0 && len(req.TextAnswers) == 0 {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Необходимо предоставить ответы"})
 		return
 	}
 
 	serviceReq := services.SubmitTestRequest{
-		LessonID: req.LessonID,
-		Answers:  req.Answers,
-		UserID:   userID.(uint),
+		LessonID:    req.LessonID,
+		Answers:     req.Answers,
+		TextAnswers: req.TextAnswers,
+		UserID:      userID.(uint),
 	}
 
 	result, err := h.testService.SubmitTest(serviceReq)
@@ -65,22 +67,21 @@ func (h *Handlers) SubmitTest(c *gin.Context) {
 		return
 	}
 
-	// Проверяем достижения
-	isFirstAttempt := result.IsNewProgress
-	if err := h.achievementService.CheckAchievements(userID.(uint), uint(req.LessonID), result.TestAttempt.Percentage, isFirstAttempt); err != nil {
-		// Логируем ошибку, но не прерываем выполнение
-	}
+	newAchievements := h.formatNewAchievements(result.NewAchievements, c.DefaultQuery("lang", "ru"))
 
 	c.JSON(http.StatusCreated, gin.H{
-		"id":              result.TestAttempt.ID,
-		"user_id":         result.TestAttempt.UserID,
-		"lesson_id":       result.TestAttempt.LessonID,
-		"score":           result.TestAttempt.Score,
-		"percentage":      result.TestAttempt.Percentage,
-		"total_questions": result.TestAttempt.TotalQuestions,
-		"correct_answers": result.TestAttempt.CorrectAnswers,
-		"is_passed":       result.TestAttempt.IsPassed,
-		"created_at":      result.TestAttempt.CreatedAt,
+		"id":               result.TestAttempt.ID,
+		"user_id":          result.TestAttempt.UserID,
+		"lesson_id":        result.TestAttempt.LessonID,
+		"score":            result.TestAttempt.Score,
+		"percentage":       result.TestAttempt.Percentage,
+		"total_questions":  result.TestAttempt.TotalQuestions,
+		"correct_answers":  result.TestAttempt.CorrectAnswers,
+		"is_passed":        result.TestAttempt.IsPassed,
+		"created_at":       result.TestAttempt.CreatedAt,
+		"new_achievements": newAchievements,
+		"xp_awarded":       result.XPAwarded,
+		"feedback":         result.Feedback,
 	})
 }
 
@@ -3,6 +3,7 @@ package handlers
 import (
 	"englishlessons.back/internal/models"
 	"englishlessons.back/internal/services"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
@@ -43,6 +44,7 @@ func (h *Handlers) GetStudents(c *gin.Context) {
 			"class_display": u.GetClassDisplay(),
 			"level":         u.Level,
 			"level_letter":  u.LevelLetter,
+			"avatar":        u.Avatar,
 		}
 	}
 
@@ -156,6 +158,11 @@ func (h *Handlers) UpdateProfile(c *gin.Context) {
 		return
 	}
 
+	// Класс мог измениться: переносим показатели ученика в агрегат нового класса
+	if _, err := h.statsService.RefreshUser(userID.(uint)); err != nil {
+		log.Printf("Failed to refresh stats for user %d: %v", userID.(uint), err)
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Профиль успешно обновлен"})
 }
 
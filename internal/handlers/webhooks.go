@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetWebhooks подписки внешних систем на события (для учителей)
+func (h *Handlers) GetWebhooks(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+
+	webhooks, err := h.webhookService.ListWebhooks()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items":  webhooks,
+		"events": models.WebhookEvents,
+	})
+}
+
+// CreateWebhook создает подписку; секрет подписи возвращается только здесь
+func (h *Handlers) CreateWebhook(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+	userID, _ := c.Get("user_id")
+
+	var req services.WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	webhook, err := h.webhookService.CreateWebhook(userID.(uint), req)
+	if err != nil {
+		respondWebhookError(c, err, "Failed to create webhook")
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// UpdateWebhook меняет адрес, события или включает/выключает подписку
+func (h *Handlers) UpdateWebhook(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID вебхука"})
+		return
+	}
+
+	var req services.WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	webhook, err := h.webhookService.UpdateWebhook(uint(id), req)
+	if err != nil {
+		respondWebhookError(c, err, "Failed to update webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// RotateWebhookSecret выдает новый секрет подписи
+func (h *Handlers) RotateWebhookSecret(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID вебхука"})
+		return
+	}
+
+	webhook, err := h.webhookService.RotateSecret(uint(id))
+	if err != nil {
+		respondWebhookError(c, err, "Failed to rotate webhook secret")
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhook удаляет подписку вместе с журналом доставок
+func (h *Handlers) DeleteWebhook(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID вебхука"})
+		return
+	}
+
+	if err := h.webhookService.DeleteWebhook(uint(id)); err != nil {
+		respondWebhookError(c, err, "Failed to delete webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Вебхук удален"})
+}
+
+// GetWebhookDeliveries журнал доставок (?status=pending|delivered|failed)
+func (h *Handlers) GetWebhookDeliveries(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID вебхука"})
+		return
+	}
+
+	req := services.DeliveryListRequest{Status: models.WebhookDeliveryStatus(c.Query("status"))}
+	req.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "50"))
+	req.Offset, _ = strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	deliveries, total, err := h.webhookService.ListDeliveries(uint(id), req)
+	if err != nil {
+		respondWebhookError(c, err, "Failed to get webhook deliveries")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": deliveries,
+		"total": total,
+	})
+}
+
+// RetryWebhookDelivery ставит неудавшуюся доставку в очередь заново
+func (h *Handlers) RetryWebhookDelivery(c *gin.Context) {
+	role, _ := c.Get("role")
+	if role != string(models.RoleTeacher) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Только для учителей"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID доставки"})
+		return
+	}
+
+	delivery, err := h.webhookService.RetryDelivery(uint(id))
+	if err != nil {
+		respondWebhookError(c, err, "Failed to retry webhook delivery")
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+func respondWebhookError(c *gin.Context, err error, fallback string) {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "не найден"):
+		c.JSON(http.StatusNotFound, gin.H{"error": message})
+	case strings.Contains(message, "Повторить можно"):
+		c.JSON(http.StatusConflict, gin.H{"error": message})
+	case strings.Contains(message, "Неверн") || strings.Contains(message, "Необходимо"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMyXPHistory возвращает журнал начисления опыта текущего пользователя
+func (h *Handlers) GetMyXPHistory(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	entries, total, err := h.xpService.GetHistory(userID.(uint), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get XP history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": entries,
+		"total": total,
+	})
+}
+
+// GetMyLevel возвращает уровень и прогресс до следующего уровня
+func (h *Handlers) GetMyLevel(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	level, err := h.xpService.GetLevel(userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get level"})
+		return
+	}
+
+	c.JSON(http.StatusOK, level)
+}
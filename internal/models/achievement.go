@@ -13,12 +13,27 @@ const (
 	AchievementTypeAllLessons   AchievementType = "all_lessons"
 	AchievementTypeFastLearner  AchievementType = "fast_learner"
 	AchievementTypePersistent   AchievementType = "persistent"
+	AchievementTypeStreakWeek   AchievementType = "streak_week"
+	AchievementTypeStreakMonth  AchievementType = "streak_month"
+	AchievementTypeFirstGame    AchievementType = "first_game"
+	AchievementTypeGamePerfect  AchievementType = "game_perfect"
+)
+
+// События, по которым оцениваются правила достижений
+const (
+	EventTestSubmitted = "test_submitted"
+	EventGameFinished  = "game_finished"
+	EventStreakUpdated = "streak_updated"
+	// EventAchievementEarned и EventUserRegistered не используются в правилах,
+	// только в шине событий
+	EventAchievementEarned = "achievement_earned"
+	EventUserRegistered    = "user_registered"
 )
 
 type Achievement struct {
 	ID          uint            `gorm:"primaryKey" json:"id"`
-	UserID      uint            `gorm:"not null;index" json:"user_id"`
-	Type        AchievementType `gorm:"type:varchar(50);not null" json:"type"`
+	UserID      uint            `gorm:"not null;index;uniqueIndex:idx_achievement_user_type" json:"user_id"`
+	Type        AchievementType `gorm:"type:varchar(50);not null;uniqueIndex:idx_achievement_user_type" json:"type"`
 	Title       string          `gorm:"not null" json:"title"`
 	Description string          `json:"description"`
 	Icon        string          `json:"icon"`
@@ -27,21 +42,52 @@ type Achievement struct {
 	User User `gorm:"foreignKey:UserID" json:"user,omitempty"`
 }
 
-func GetAchievementInfo(achievementType AchievementType) (title, description, icon string) {
-	switch achievementType {
-	case AchievementTypeFirstLesson:
-		return "Первый шаг", "Пройден первый урок", "🎯"
-	case AchievementTypePerfectScore:
-		return "Идеально!", "100% правильных ответов в тесте", "⭐"
-	case AchievementTypeStreak:
-		return "Серия побед", "3 урока подряд на 90%+", "🔥"
-	case AchievementTypeAllLessons:
-		return "Мастер", "Пройдены все уроки", "👑"
-	case AchievementTypeFastLearner:
-		return "Быстрый ученик", "Пройден урок с первой попытки на 90%+", "⚡"
-	case AchievementTypePersistent:
-		return "Упорство", "10+ попыток прохождения теста", "💪"
-	default:
-		return "Достижение", "Новое достижение", "🏆"
+// AchievementDefinition описывает достижение как данные: тексты на всех языках,
+// иконку и правило выдачи, которое оценивается на событии Event.
+// Level/LevelLetter ограничивают значок одним классом (значки учителей).
+type AchievementDefinition struct {
+	ID            uint            `gorm:"primaryKey" json:"id"`
+	Type          AchievementType `gorm:"type:varchar(50);not null;uniqueIndex" json:"type"`
+	TitleRu       string          `gorm:"not null" json:"title_ru"`
+	TitleUz       string          `json:"title_uz"`
+	DescriptionRu string          `json:"description_ru"`
+	DescriptionUz string          `json:"description_uz"`
+	Icon          string          `json:"icon"`
+	Event         string          `gorm:"type:varchar(50);not null;index" json:"event"`
+	Rule          string          `gorm:"type:text;not null" json:"rule"`
+	Level         *int            `json:"level"`
+	LevelLetter   string          `json:"level_letter"`
+	CreatedBy     *uint           `json:"created_by"`
+	IsActive      bool            `gorm:"default:true" json:"is_active"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// Localized возвращает название и описание на языке lang (по умолчанию русский)
+func (d *AchievementDefinition) Localized(lang string) (title, description string) {
+	if lang == "uz" && d.TitleUz != "" {
+		return d.TitleUz, d.DescriptionUz
 	}
+	return d.TitleRu, d.DescriptionRu
+}
+
+// AppliesTo проверяет, относится ли значок к классу пользователя
+func (d *AchievementDefinition) AppliesTo(user *User) bool {
+	if d.Level == nil {
+		return true
+	}
+	if user.Level == nil || *user.Level != *d.Level {
+		return false
+	}
+	return d.LevelLetter == "" || d.LevelLetter == user.LevelLetter
+}
+
+// AchievementEvent журнал событий с метриками на момент события.
+// Нужен, чтобы новые правила можно было применить к прошлой активности.
+type AchievementEvent struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     uint      `gorm:"not null;index" json:"user_id"`
+	Event      string    `gorm:"type:varchar(50);not null;index" json:"event"`
+	Metrics    JSONMap   `gorm:"type:jsonb" json:"metrics"`
+	OccurredAt time.Time `gorm:"index" json:"occurred_at"`
 }
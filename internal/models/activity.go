@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	// DefaultDailyGoalXP дневная цель по умолчанию
+	DefaultDailyGoalXP = 30
+	// MaxFreezeTokens максимальное количество заморозок серии на руках
+	MaxFreezeTokens = 2
+	// FreezeTokenStreakStep каждые N дней серии начисляется заморозка
+	FreezeTokenStreakStep = 7
+)
+
+// DailyActivity агрегирует активность пользователя за один учебный день.
+// День определяется в часовом поясе школы и хранится в формате YYYY-MM-DD.
+type DailyActivity struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	UserID      uint      `gorm:"not null;uniqueIndex:idx_user_day" json:"user_id"`
+	Day         string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_user_day" json:"day"`
+	XP          int       `gorm:"default:0" json:"xp"`
+	TestsCount  int       `gorm:"default:0" json:"tests_count"`
+	GamesCount  int       `gorm:"default:0" json:"games_count"`
+	GoalReached bool      `gorm:"default:false" json:"goal_reached"`
+	FreezeUsed  bool      `gorm:"default:false" json:"freeze_used"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// UserStreak хранит текущую и лучшую серию дней с выполненной дневной целью
+type UserStreak struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	UserID        uint      `gorm:"not null;uniqueIndex" json:"user_id"`
+	CurrentStreak int       `gorm:"default:0" json:"current_streak"`
+	LongestStreak int       `gorm:"default:0" json:"longest_streak"`
+	LastGoalDay   string    `gorm:"type:varchar(10)" json:"last_goal_day"`
+	DailyGoalXP   int       `gorm:"default:30" json:"daily_goal_xp"`
+	FreezeTokens  int       `gorm:"default:0" json:"freeze_tokens"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+)
+
+// ChallengeStatus состояние вызова
+type ChallengeStatus string
+
+const (
+	// ChallengeOpen ждет результатов одного или обоих игроков
+	ChallengeOpen      ChallengeStatus = "open"
+	ChallengeCompleted ChallengeStatus = "completed"
+	ChallengeDeclined  ChallengeStatus = "declined"
+	ChallengeExpired   ChallengeStatus = "expired"
+)
+
+// Challenge вызов одноклассника на игру. Раунд собирается один раз при
+// создании и выдается обоим игрокам: те же задания в том же порядке.
+// WinnerID пустой у завершенного вызова означает ничью.
+type Challenge struct {
+	ID                 uint            `gorm:"primaryKey" json:"id"`
+	ChallengerID       uint            `gorm:"not null;index" json:"challenger_id"`
+	OpponentID         uint            `gorm:"not null;index" json:"opponent_id"`
+	GameType           GameType        `gorm:"type:varchar(50);not null" json:"game_type"`
+	Level              int             `gorm:"not null" json:"level"`
+	Round              JSONMap         `gorm:"type:jsonb" json:"-"`
+	AnswerKey          JSONMap         `gorm:"type:jsonb" json:"-"`
+	Status             ChallengeStatus `gorm:"type:varchar(20);not null;default:'open';index" json:"status"`
+	ChallengerResultID *uint           `json:"challenger_result_id"`
+	OpponentResultID   *uint           `json:"opponent_result_id"`
+	WinnerID           *uint           `json:"winner_id"`
+	ExpiresAt          time.Time       `gorm:"not null" json:"expires_at"`
+	CompletedAt        *time.Time      `json:"completed_at"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+
+	Challenger       User        `gorm:"foreignKey:ChallengerID" json:"challenger,omitempty"`
+	Opponent         User        `gorm:"foreignKey:OpponentID" json:"opponent,omitempty"`
+	ChallengerResult *GameResult `gorm:"foreignKey:ChallengerResultID" json:"challenger_result,omitempty"`
+	OpponentResult   *GameResult `gorm:"foreignKey:OpponentResultID" json:"opponent_result,omitempty"`
+}
+
+// HeadToHead счет личных встреч с точки зрения UserID
+type HeadToHead struct {
+	UserID     uint  `json:"user_id"`
+	OpponentID uint  `json:"opponent_id"`
+	Wins       int64 `json:"wins"`
+	Losses     int64 `json:"losses"`
+	Draws      int64 `json:"draws"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// CommentTarget к чему относится обсуждение
+type CommentTarget string
+
+const (
+	CommentTestAttempt CommentTarget = "test_attempt"
+	CommentGameResult  CommentTarget = "game_result"
+	CommentStudent     CommentTarget = "student" // ученик в целом, TargetID = StudentID
+)
+
+// Comment сообщение в обсуждении работы ученика между учителем и учеником.
+// Обсуждение определяется парой TargetType+TargetID. ReadAt ставит
+// получатель: ученик для сообщений учителя, любой учитель для сообщений ученика.
+type Comment struct {
+	ID         uint          `gorm:"primaryKey" json:"id"`
+	StudentID  uint          `gorm:"not null;index" json:"student_id"`
+	TargetType CommentTarget `gorm:"type:varchar(20);not null;index:idx_comment_target,priority:1" json:"target_type"`
+	TargetID   uint          `gorm:"not null;index:idx_comment_target,priority:2" json:"target_id"`
+	AuthorID   uint          `gorm:"not null;index" json:"author_id"`
+	AuthorRole Role          `gorm:"type:varchar(20);not null" json:"author_role"`
+	Body       string        `gorm:"type:text;not null" json:"body"`
+	ReadAt     *time.Time    `json:"read_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+
+	Author User `gorm:"foreignKey:AuthorID" json:"-"`
+}
+
+// CommentThread сводка обсуждения для списка
+type CommentThread struct {
+	StudentID      uint          `json:"student_id"`
+	StudentName    string        `json:"student_name"`
+	TargetType     CommentTarget `json:"target_type"`
+	TargetID       uint          `json:"target_id"`
+	CommentsCount  int           `json:"comments_count"`
+	UnreadCount    int           `json:"unread_count"`
+	LastBody       string        `json:"last_body"`
+	LastAuthorRole Role          `json:"last_author_role"`
+	LastCommentAt  time.Time     `json:"last_comment_at"`
+}
@@ -0,0 +1,212 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// GameItem одно задание игры. Содержимое зависит от типа игры
+// и хранится в Payload (см. структуры *Item ниже).
+type GameItem struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	GameType  GameType  `gorm:"type:varchar(50);not null;index:idx_game_item_type_level" json:"game_type"`
+	Level     int       `gorm:"not null;index:idx_game_item_type_level" json:"level"`
+	Payload   JSONMap   `gorm:"type:jsonb;not null" json:"payload"`
+	IsActive  bool      `gorm:"default:true" json:"is_active"`
+	CreatedBy *uint     `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Tags []Tag `gorm:"many2many:game_item_tags" json:"tags,omitempty"`
+}
+
+// DecodePayload разбирает Payload в структуру задания нужного типа
+func (i *GameItem) DecodePayload(v interface{}) error {
+	return DecodeJSONMap(i.Payload, v)
+}
+
+// GameItemPayload содержимое задания конкретной игры
+type GameItemPayload interface {
+	Validate() error
+}
+
+// GameContentFile формат файла импорта заданий (и встроенного набора)
+type GameContentFile struct {
+	Items []GameContentEntry `json:"items"`
+}
+
+// GameContentEntry одно задание в файле импорта
+type GameContentEntry struct {
+	GameType GameType `json:"game_type"`
+	Level    int      `json:"level"`
+	Payload  JSONMap  `json:"payload"`
+}
+
+// GrammarDetectiveItem предложение с одной ошибкой; ErrorIndex - номер слова с ошибкой
+type GrammarDetectiveItem struct {
+	Sentence    string `json:"sentence"`
+	ErrorIndex  int    `json:"error_index"`
+	CorrectWord string `json:"correct_word"`
+	Explanation string `json:"explanation"`
+}
+
+func (i *GrammarDetectiveItem) Validate() error {
+	if strings.TrimSpace(i.Sentence) == "" || strings.TrimSpace(i.CorrectWord) == "" {
+		return errors.New("Необходимо указать предложение и правильное слово")
+	}
+	if i.ErrorIndex < 0 || i.ErrorIndex >= len(strings.Fields(i.Sentence)) {
+		return errors.New("Неверный номер слова с ошибкой")
+	}
+	return nil
+}
+
+// SentenceBuilderItem перемешанные слова и правильный порядок их индексов
+type SentenceBuilderItem struct {
+	Words        []string `json:"words"`
+	CorrectOrder []int    `json:"correct_order"`
+	Translation  string   `json:"translation"`
+}
+
+func (i *SentenceBuilderItem) Validate() error {
+	if len(i.Words) < 2 {
+		return errors.New("Необходимо указать хотя бы два слова")
+	}
+	if len(i.CorrectOrder) != len(i.Words) {
+		return errors.New("Неверный порядок слов: нужен номер для каждого слова")
+	}
+	seen := make([]bool, len(i.Words))
+	for _, index := range i.CorrectOrder {
+		if index < 0 || index >= len(i.Words) || seen[index] {
+			return errors.New("Неверный порядок слов: номера должны быть перестановкой слов")
+		}
+		seen[index] = true
+	}
+	return nil
+}
+
+// MemoryCardItem пара карточек: слово и перевод
+type MemoryCardItem struct {
+	English string `json:"english"`
+	Russian string `json:"russian"`
+}
+
+func (i *MemoryCardItem) Validate() error {
+	if strings.TrimSpace(i.English) == "" || strings.TrimSpace(i.Russian) == "" {
+		return errors.New("Необходимо указать обе карточки пары")
+	}
+	return nil
+}
+
+// FillGapItem предложение с пропуском "___" и вариантами ответа. Пропуск
+// можно заполнить и вводом слова; Accepted - другие допустимые написания
+// (например, британское и американское).
+type FillGapItem struct {
+	Sentence      string   `json:"sentence"`
+	CorrectAnswer string   `json:"correct_answer"`
+	Options       []string `json:"options"`
+	Accepted      []string `json:"accepted,omitempty"`
+}
+
+// AcceptedAnswers правильный ответ и допустимые написания для ввода слова
+func (i *FillGapItem) AcceptedAnswers() []string {
+	return append([]string{i.CorrectAnswer}, i.Accepted...)
+}
+
+func (i *FillGapItem) Validate() error {
+	if !strings.Contains(i.Sentence, "___") {
+		return errors.New("Необходимо отметить пропуск в предложении как ___")
+	}
+	if err := validateOptions(i.Options); err != nil {
+		return err
+	}
+	for _, spelling := range i.Accepted {
+		if strings.TrimSpace(spelling) == "" {
+			return errors.New("Неверные варианты написания: пустая строка")
+		}
+	}
+	for _, option := range i.Options {
+		if option == i.CorrectAnswer {
+			return nil
+		}
+	}
+	return errors.New("Неверный ответ: его нет среди вариантов")
+}
+
+// QuizItem вопрос викторины; CorrectAnswer - индекс правильного варианта
+type QuizItem struct {
+	Question      string   `json:"question"`
+	Options       []string `json:"options"`
+	CorrectAnswer int      `json:"correct_answer"`
+	Explanation   string   `json:"explanation"`
+}
+
+func (i *QuizItem) Validate() error {
+	if strings.TrimSpace(i.Question) == "" {
+		return errors.New("Необходимо указать вопрос")
+	}
+	if err := validateOptions(i.Options); err != nil {
+		return err
+	}
+	if i.CorrectAnswer < 0 || i.CorrectAnswer >= len(i.Options) {
+		return errors.New("Неверный номер правильного варианта")
+	}
+	return nil
+}
+
+// validateOptions варианты ответа: не меньше двух, непустые и без повторов
+func validateOptions(options []string) error {
+	if len(options) < 2 {
+		return errors.New("Необходимо указать хотя бы два варианта ответа")
+	}
+	seen := make(map[string]bool, len(options))
+	for _, option := range options {
+		if strings.TrimSpace(option) == "" || seen[option] {
+			return errors.New("Неверные варианты ответа: пустые или повторяющиеся")
+		}
+		seen[option] = true
+	}
+	return nil
+}
+
+// GameSession раунд игры, выданный сервером. Ключ ответов хранится только
+// на сервере; результат считается по ответам ученика и времени сервера.
+// Answers - ответы, отправленные по одному во время игры, в формате сдачи раунда.
+// Раунд вызова выдается каждому игроку не больше одного раза.
+type GameSession struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	UserID       uint       `gorm:"not null;index;uniqueIndex:idx_game_session_challenge_user,priority:2" json:"user_id"`
+	GameType     GameType   `gorm:"type:varchar(50);not null" json:"game_type"`
+	Level        int        `gorm:"not null" json:"level"`
+	Round        JSONMap    `gorm:"type:jsonb" json:"round"`
+	AnswerKey    JSONMap    `gorm:"type:jsonb" json:"-"`
+	Answers      JSONMap    `gorm:"type:jsonb" json:"-"`
+	StartedAt    time.Time  `gorm:"not null" json:"started_at"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`
+	SubmittedAt  *time.Time `json:"submitted_at"`
+	GameResultID *uint      `json:"game_result_id"`
+	ChallengeID  *uint      `gorm:"index;uniqueIndex:idx_game_session_challenge_user,priority:1" json:"challenge_id"`
+}
+
+// DecodeJSONMap переводит JSONMap в структуру через JSON
+func DecodeJSONMap(m JSONMap, v interface{}) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// EncodeJSONMap переводит структуру в JSONMap через JSON
+func EncodeJSONMap(v interface{}) (JSONMap, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	result := JSONMap{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,96 @@
+package models
+
+// GameScoring способ подсчета очков в игре
+type GameScoring string
+
+const (
+	// GameScoringCorrectAnswers одно очко за каждое верное задание
+	GameScoringCorrectAnswers GameScoring = "correct_answers"
+	// GameScoringEfficiency найденные пары к числу попыток, в процентах
+	GameScoringEfficiency GameScoring = "efficiency"
+)
+
+// GameDefinition описание игры: по нему сервер проверяет раунды и результаты,
+// а фронтенд строит список игр
+type GameDefinition struct {
+	ID          GameType    `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	LevelCount  int         `json:"level_count"`
+	RoundSize   int         `json:"round_size"`
+	Scoring     GameScoring `json:"scoring"`
+	// MaxScore для GameScoringCorrectAnswers равен числу заданий в раунде
+	MaxScore int `json:"max_score"`
+	// MaxTimeSeconds время, за которое раунд точно можно пройти; после него сессия истекает
+	MaxTimeSeconds int `json:"max_time_seconds"`
+}
+
+// HasLevel проверяет, есть ли у игры уровень level
+func (d *GameDefinition) HasLevel(level int) bool {
+	return level >= 0 && level < d.LevelCount
+}
+
+// GameRegistry все игры платформы. LevelCount совпадает с числом уровней
+// во встроенном банке заданий (database/seed/game_items.json).
+var GameRegistry = []GameDefinition{
+	{
+		ID:             GameGrammarDetective,
+		Name:           "Grammar Detective",
+		Description:    "Найди ошибку в предложении",
+		LevelCount:     11,
+		RoundSize:      5,
+		Scoring:        GameScoringCorrectAnswers,
+		MaxScore:       5,
+		MaxTimeSeconds: 600,
+	},
+	{
+		ID:             GameSentenceBuilder,
+		Name:           "Sentence Builder",
+		Description:    "Собери предложение из слов",
+		LevelCount:     11,
+		RoundSize:      5,
+		Scoring:        GameScoringCorrectAnswers,
+		MaxScore:       5,
+		MaxTimeSeconds: 600,
+	},
+	{
+		ID:             GameMemoryCards,
+		Name:           "Memory Cards",
+		Description:    "Найди пары слово-перевод",
+		LevelCount:     6,
+		RoundSize:      6,
+		Scoring:        GameScoringEfficiency,
+		MaxScore:       100,
+		MaxTimeSeconds: 600,
+	},
+	{
+		ID:             GameFillGapRace,
+		Name:           "Fill the Gap Race",
+		Description:    "Вставь пропущенное слово",
+		LevelCount:     6,
+		RoundSize:      8,
+		Scoring:        GameScoringCorrectAnswers,
+		MaxScore:       8,
+		MaxTimeSeconds: 300,
+	},
+	{
+		ID:             GameQuizShow,
+		Name:           "Quiz Show",
+		Description:    "Ответь на вопросы викторины",
+		LevelCount:     6,
+		RoundSize:      8,
+		Scoring:        GameScoringCorrectAnswers,
+		MaxScore:       8,
+		MaxTimeSeconds: 400,
+	},
+}
+
+// FindGameDefinition ищет игру в реестре
+func FindGameDefinition(gameType GameType) (*GameDefinition, bool) {
+	for i := range GameRegistry {
+		if GameRegistry[i].ID == gameType {
+			return &GameRegistry[i], true
+		}
+	}
+	return nil, false
+}
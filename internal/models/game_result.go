@@ -28,6 +28,8 @@ type GameResult struct {
 	TimeSpent    int       `gorm:"not null" json:"time_spent"` // в секундах
 	CorrectCount int       `gorm:"not null" json:"correct_count"`
 	TotalCount   int       `gorm:"not null" json:"total_count"`
+	SessionID    *uint     `gorm:"uniqueIndex" json:"session_id"`
+	ChallengeID  *uint     `gorm:"index" json:"challenge_id"`
 	CreatedAt    time.Time `json:"created_at"`
 }
 
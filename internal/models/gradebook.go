@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// GradebookEntry лучший результат ученика по уроку для журнала оценок
+type GradebookEntry struct {
+	UserID         uint      `json:"user_id"`
+	LessonID       uint      `json:"lesson_id"`
+	BestPercentage float64   `json:"best_percentage"`
+	AttemptsCount  int       `json:"attempts_count"`
+	LastAttemptAt  time.Time `json:"last_attempt_at"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// JSONMap произвольный JSON-объект, хранимый в колонке jsonb
+type JSONMap map[string]interface{}
+
+func (m JSONMap) Value() (driver.Value, error) {
+	if m == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (m *JSONMap) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*m = JSONMap{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.New("unsupported type for JSONMap")
+	}
+	result := JSONMap{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	*m = result
+	return nil
+}
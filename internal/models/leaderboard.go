@@ -0,0 +1,40 @@
+package models
+
+// LeaderboardRow строка рейтинга, посчитанная в БД
+type LeaderboardRow struct {
+	UserID            uint
+	Username          string
+	FirstName         string
+	LastName          string
+	Level             *int
+	LevelLetter       string
+	Nickname          string
+	Avatar            string
+	LeaderboardMode   LeaderboardNameMode
+	TotalPoints       int
+	CompletedLessons  int
+	AveragePercentage float64
+	GamePoints        int
+	TotalXP           int
+	AllTimeXP         int
+	Rank              int
+	ClassRank         int
+	RowNumber         int
+	TotalCount        int64
+}
+
+// Student восстанавливает пользователя из строки рейтинга для отображения имени и класса
+func (r *LeaderboardRow) Student() *User {
+	return &User{
+		ID:              r.UserID,
+		Username:        r.Username,
+		FirstName:       r.FirstName,
+		LastName:        r.LastName,
+		Role:            RoleStudent,
+		Level:           r.Level,
+		LevelLetter:     r.LevelLetter,
+		Avatar:          r.Avatar,
+		Nickname:        r.Nickname,
+		LeaderboardMode: r.LeaderboardMode,
+	}
+}
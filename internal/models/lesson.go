@@ -16,18 +16,43 @@ type Lesson struct {
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
 
-	Questions []Question `gorm:"foreignKey:LessonID" json:"questions,omitempty"`
+	Questions []Question    `gorm:"foreignKey:LessonID" json:"questions,omitempty"`
+	Blocks    []LessonBlock `gorm:"foreignKey:LessonID" json:"blocks,omitempty"`
+	Tags      []Tag         `gorm:"many2many:lesson_tags" json:"tags,omitempty"`
 }
 
+// QuestionType способ ответа на вопрос
+type QuestionType string
+
+const (
+	QuestionChoice QuestionType = "choice" // выбор варианта
+	QuestionTyped  QuestionType = "typed"  // ученик вводит слово или предложение (диктант)
+)
+
+// Question вопрос урока. У вопроса с вводом ответа варианты - это допустимые
+// написания (например, британское и американское), первый - основной.
 type Question struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	LessonID  uint      `gorm:"not null;index" json:"lesson_id"`
-	Text      string    `gorm:"type:text;not null" json:"text"`
-	Order     int       `gorm:"not null" json:"order"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        uint         `gorm:"primaryKey" json:"id"`
+	LessonID  uint         `gorm:"not null;index" json:"lesson_id"`
+	Text      string       `gorm:"type:text;not null" json:"text"`
+	Type      QuestionType `gorm:"type:varchar(20);not null;default:'choice'" json:"type"`
+	AudioID   *uint        `json:"audio_id"`
+	Order     int          `gorm:"not null" json:"order"`
+	CreatedAt time.Time    `json:"created_at"`
 
 	Lesson        Lesson         `gorm:"foreignKey:LessonID" json:"-"`
+	Audio         *MediaFile     `gorm:"foreignKey:AudioID" json:"audio,omitempty"`
 	AnswerOptions []AnswerOption `gorm:"foreignKey:QuestionID" json:"answer_options,omitempty"`
+	Tags          []Tag          `gorm:"many2many:question_tags" json:"tags,omitempty"`
+}
+
+// AcceptedAnswers допустимые ответы на вопрос с вводом ответа
+func (q *Question) AcceptedAnswers() []string {
+	answers := make([]string, 0, len(q.AnswerOptions))
+	for _, option := range q.AnswerOptions {
+		answers = append(answers, option.Text)
+	}
+	return answers
 }
 
 type AnswerOption struct {
@@ -63,6 +88,7 @@ type LessonProgress struct {
 	BestPercentage float64    `gorm:"default:0" json:"best_percentage"`
 	AttemptsCount  int        `gorm:"default:0" json:"attempts_count"`
 	IsCompleted    bool       `gorm:"default:false" json:"is_completed"`
+	IsUnlocked     bool       `gorm:"default:false" json:"is_unlocked"` // открыт по итогам теста на уровень
 	CompletedAt    *time.Time `json:"completed_at"`
 	LastAttemptAt  time.Time  `json:"last_attempt_at"`
 	CreatedAt      time.Time  `json:"created_at"`
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Навык, по которому оценивается освоение: урок для вопросов тестов,
+// игра и уровень для заданий игр
+const (
+	SkillKindLesson = "lesson"
+	SkillKindGame   = "game"
+)
+
+// LessonSkill ключ навыка урока
+func LessonSkill(lessonID uint) string {
+	return fmt.Sprintf("%s:%d", SkillKindLesson, lessonID)
+}
+
+// GameSkill ключ навыка игры на заданном уровне
+func GameSkill(gameType GameType, level int) string {
+	return fmt.Sprintf("%s:%s:%d", SkillKindGame, gameType, level)
+}
+
+// SkillMastery рейтинг ученика по навыку в модели Эло. Рейтинг 0 означает
+// 50% шанс верно ответить на вопрос средней сложности.
+type SkillMastery struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	UserID       uint      `gorm:"not null;uniqueIndex:idx_mastery_user_skill" json:"user_id"`
+	Skill        string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_mastery_user_skill;index" json:"skill"`
+	Rating       float64   `gorm:"not null;default:0" json:"rating"`
+	Attempts     int       `gorm:"not null;default:0" json:"attempts"`
+	CorrectCount int       `gorm:"not null;default:0" json:"correct_count"`
+	LastItemID   uint      `json:"-"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// ItemDifficulty сложность вопроса теста или задания игры в той же шкале,
+// что и рейтинг ученика. Уточняется по ответам всех учеников.
+type ItemDifficulty struct {
+	ID         uint         `gorm:"primaryKey" json:"id"`
+	SourceType ReviewSource `gorm:"type:varchar(20);not null;uniqueIndex:idx_difficulty_source" json:"source_type"`
+	SourceID   uint         `gorm:"not null;uniqueIndex:idx_difficulty_source" json:"source_id"`
+	Skill      string       `gorm:"type:varchar(100);not null;index" json:"skill"`
+	Difficulty float64      `gorm:"not null;default:0" json:"difficulty"`
+	Attempts   int          `gorm:"not null;default:0" json:"attempts"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MediaKind назначение медиафайла
+type MediaKind string
+
+const (
+	MediaAudio MediaKind = "audio"
+)
+
+// MediaFile загруженный файл. Key - путь в хранилище, образованный от
+// sha256 содержимого, поэтому одинаковые файлы хранятся один раз.
+type MediaFile struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Key          string    `gorm:"type:varchar(255);not null;uniqueIndex" json:"key"`
+	Kind         MediaKind `gorm:"type:varchar(20);not null;index" json:"kind"`
+	ContentType  string    `gorm:"type:varchar(100);not null" json:"content_type"`
+	Size         int64     `gorm:"not null" json:"size"`
+	OriginalName string    `gorm:"type:varchar(255)" json:"original_name"`
+	UploadedBy   uint      `gorm:"not null;index" json:"uploaded_by"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	URL string `gorm:"-" json:"url"`
+}
+
+// MediaURL путь, по которому сервер отдает файл хранилища
+func MediaURL(key string) string {
+	return "/media/" + key
+}
+
+// AfterFind заполняет URL загруженного файла
+func (m *MediaFile) AfterFind(tx *gorm.DB) error {
+	m.URL = MediaURL(m.Key)
+	return nil
+}
+
+// AvatarSizes стандартные размеры аватара в пикселях, по возрастанию
+var AvatarSizes = []int{64, 128, 256}
+
+// AvatarKey ключ файла аватара: hash - sha256 загруженной картинки
+func AvatarKey(hash string, size int) string {
+	return "avatars/" + hash + "_" + strconv.Itoa(size) + ".jpg"
+}
+
+// AvatarHash достает хэш из ссылки на аватар; false для аватаров старого формата
+func AvatarHash(avatar string) (string, bool) {
+	largest := strconv.Itoa(AvatarSizes[len(AvatarSizes)-1])
+	name, ok := strings.CutPrefix(avatar, MediaURL("avatars/"))
+	if !ok {
+		return "", false
+	}
+	hash, ok := strings.CutSuffix(name, "_"+largest+".jpg")
+	if !ok || len(hash) != 64 || strings.Trim(hash, "0123456789abcdef") != "" {
+		return "", false
+	}
+	return hash, true
+}
+
+// AvatarVariants ссылки на все размеры аватара по ширине в пикселях.
+// Для аватаров старого формата есть только исходная ссылка.
+func AvatarVariants(avatar string) map[string]string {
+	if avatar == "" {
+		return nil
+	}
+	hash, ok := AvatarHash(avatar)
+	if !ok {
+		return map[string]string{"original": avatar}
+	}
+	variants := make(map[string]string, len(AvatarSizes))
+	for _, size := range AvatarSizes {
+		variants[strconv.Itoa(size)] = MediaURL(AvatarKey(hash, size))
+	}
+	return variants
+}
+
+// LessonBlockKind вид блока содержимого урока
+type LessonBlockKind string
+
+const (
+	LessonBlockText  LessonBlockKind = "text"
+	LessonBlockAudio LessonBlockKind = "audio"
+)
+
+// LessonBlock блок теории урока: текст или аудио (например, текст для
+// аудирования с записью), показывается перед вопросами
+type LessonBlock struct {
+	ID        uint            `gorm:"primaryKey" json:"id"`
+	LessonID  uint            `gorm:"not null;index" json:"lesson_id"`
+	Kind      LessonBlockKind `gorm:"type:varchar(20);not null" json:"kind"`
+	Text      string          `gorm:"type:text" json:"text"`
+	MediaID   *uint           `json:"media_id"`
+	Order     int             `gorm:"not null" json:"order"`
+	CreatedAt time.Time       `json:"created_at"`
+
+	Media *MediaFile `gorm:"foreignKey:MediaID" json:"media,omitempty"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// NotificationType вид уведомления; по нему клиент выбирает иконку и ссылку
+type NotificationType string
+
+const (
+	NotificationTestPassed           NotificationType = "test_passed"
+	NotificationAchievementEarned    NotificationType = "achievement_earned"
+	NotificationLeaderboardOvertaken NotificationType = "leaderboard_overtaken"
+	NotificationPasswordReset        NotificationType = "password_reset"
+	NotificationCommentReceived      NotificationType = "comment_received"
+	NotificationChallengeReceived    NotificationType = "challenge_received"
+	NotificationPlacementOverridden  NotificationType = "placement_overridden"
+)
+
+// Notification уведомление пользователя. Payload содержит идентификаторы
+// связанных объектов (урок, попытка, вызов), чтобы клиент мог открыть их.
+type Notification struct {
+	ID        uint             `gorm:"primaryKey" json:"id"`
+	UserID    uint             `gorm:"not null;index:idx_notification_user,priority:1" json:"user_id"`
+	Type      NotificationType `gorm:"type:varchar(50);not null" json:"type"`
+	Title     string           `gorm:"not null" json:"title"`
+	Body      string           `gorm:"type:text" json:"body"`
+	Payload   JSONMap          `gorm:"type:jsonb" json:"payload"`
+	ReadAt    *time.Time       `json:"read_at"`
+	CreatedAt time.Time        `gorm:"index:idx_notification_user,priority:2" json:"created_at"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+)
+
+type PlacementStatus string
+
+const (
+	PlacementInProgress PlacementStatus = "in_progress"
+	PlacementCompleted  PlacementStatus = "completed"
+)
+
+// PlacementTest тест на уровень CEFR для нового ученика. Вопросы выдаются
+// по одному; по итогам ученику открываются уроки ниже найденного уровня.
+// Учитель может заменить найденный уровень своим (OverrideLevel).
+type PlacementTest struct {
+	ID                uint            `gorm:"primaryKey" json:"id"`
+	UserID            uint            `gorm:"not null;index" json:"user_id"`
+	Status            PlacementStatus `gorm:"type:varchar(20);not null" json:"status"`
+	CurrentLevel      string          `gorm:"type:varchar(2)" json:"current_level"`
+	CurrentQuestionID *uint           `json:"-"`
+	EstimatedLevel    string          `gorm:"type:varchar(2)" json:"estimated_level"`
+	OverrideLevel     string          `gorm:"type:varchar(2)" json:"override_level"`
+	OverriddenBy      *uint           `json:"overridden_by"`
+	OverrideNote      string          `gorm:"type:varchar(500)" json:"override_note"`
+	CompletedAt       *time.Time      `json:"completed_at"`
+	CreatedAt         time.Time       `json:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+
+	User    *User             `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Answers []PlacementAnswer `gorm:"foreignKey:PlacementTestID" json:"answers,omitempty"`
+}
+
+// Level итоговый уровень: решение учителя важнее результата теста
+func (p *PlacementTest) Level() string {
+	if p.OverrideLevel != "" {
+		return p.OverrideLevel
+	}
+	return p.EstimatedLevel
+}
+
+// PlacementAnswer ответ на вопрос теста на уровень
+type PlacementAnswer struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	PlacementTestID uint      `gorm:"not null;index" json:"placement_test_id"`
+	QuestionID      uint      `gorm:"not null" json:"question_id"`
+	Level           string    `gorm:"type:varchar(2);not null" json:"level"`
+	AnswerOptionID  uint      `json:"answer_option_id"`
+	IsCorrect       bool      `gorm:"not null" json:"is_correct"`
+	CreatedAt       time.Time `json:"created_at"`
+
+	Question *Question `gorm:"foreignKey:QuestionID" json:"question,omitempty"`
+}
+
+// CEFRIndex номер уровня CEFR от 0 (A1); -1 для неизвестного уровня
+func CEFRIndex(level string) int {
+	for i, l := range CEFRLevels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
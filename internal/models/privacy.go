@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+)
+
+// LeaderboardNameMode как ученик отображается в рейтингах для одноклассников
+type LeaderboardNameMode string
+
+const (
+	NameModeFull     LeaderboardNameMode = "full"
+	NameModeNickname LeaderboardNameMode = "nickname"
+	NameModeAvatar   LeaderboardNameMode = "avatar"
+	// NameModeHidden ученик не участвует в рейтингах (только настройка ученика)
+	NameModeHidden LeaderboardNameMode = "hidden"
+)
+
+// strictness чем больше, тем меньше данных видно другим
+func (m LeaderboardNameMode) strictness() int {
+	switch m {
+	case NameModeNickname:
+		return 1
+	case NameModeAvatar:
+		return 2
+	case NameModeHidden:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// IsValid проверяет режим; allowHidden разрешает отказ от участия
+func (m LeaderboardNameMode) IsValid(allowHidden bool) bool {
+	switch m {
+	case NameModeFull, NameModeNickname, NameModeAvatar:
+		return true
+	case NameModeHidden:
+		return allowHidden
+	}
+	return false
+}
+
+// StricterNameMode из двух режимов выбирает более закрытый
+func StricterNameMode(a, b LeaderboardNameMode) LeaderboardNameMode {
+	if b.strictness() > a.strictness() {
+		return b
+	}
+	return a
+}
+
+// ClassPrivacySettings настройки рейтингов класса, задаются учителем
+type ClassPrivacySettings struct {
+	ID             uint                `gorm:"primaryKey" json:"id"`
+	Level          int                 `gorm:"not null;uniqueIndex:idx_class_privacy" json:"level"`
+	LevelLetter    string              `gorm:"not null;default:'';uniqueIndex:idx_class_privacy" json:"level_letter"`
+	NameMode       LeaderboardNameMode `gorm:"type:varchar(20);default:'full'" json:"name_mode"`
+	HideBottomHalf bool                `gorm:"default:false" json:"hide_bottom_half"`
+	UpdatedBy      uint                `json:"updated_by"`
+	CreatedAt      time.Time           `json:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at"`
+}
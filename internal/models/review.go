@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+)
+
+// ReviewSource откуда взят элемент повторения
+type ReviewSource string
+
+const (
+	ReviewSourceQuestion ReviewSource = "question"
+	ReviewSourceGameItem ReviewSource = "game_item"
+)
+
+// Параметры SM-2
+const (
+	DefaultEaseFactor = 2.5
+	MinEaseFactor     = 1.3
+)
+
+// ReviewItem вопрос теста или задание игры, которое ученик решил неверно
+// и которое возвращается ему на повторение по алгоритму SM-2.
+// Prompt - то, что видит ученик; ключ ответа хранится только на сервере.
+type ReviewItem struct {
+	ID             uint         `gorm:"primaryKey" json:"id"`
+	UserID         uint         `gorm:"not null;uniqueIndex:idx_review_user_source;index:idx_review_user_due" json:"user_id"`
+	SourceType     ReviewSource `gorm:"type:varchar(20);not null;uniqueIndex:idx_review_user_source" json:"source_type"`
+	SourceID       uint         `gorm:"not null;uniqueIndex:idx_review_user_source" json:"source_id"`
+	GameType       GameType     `gorm:"type:varchar(50)" json:"game_type,omitempty"`
+	Prompt         JSONMap      `gorm:"type:jsonb" json:"prompt"`
+	AnswerKey      JSONMap      `gorm:"type:jsonb" json:"-"`
+	EaseFactor     float64      `gorm:"not null;default:2.5" json:"ease_factor"`
+	IntervalDays   int          `gorm:"not null;default:0" json:"interval_days"`
+	Repetitions    int          `gorm:"not null;default:0" json:"repetitions"`
+	Lapses         int          `gorm:"not null;default:0" json:"lapses"`
+	DueDay         string       `gorm:"type:varchar(10);not null;index:idx_review_user_due" json:"due_day"`
+	LastReviewedAt *time.Time   `json:"last_reviewed_at"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
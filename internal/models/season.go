@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+)
+
+// Season соревновательный период, задаваемый учителем.
+// Границы хранятся как полуинтервал [StartsAt, EndsAt).
+type Season struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	Name      string     `gorm:"not null" json:"name"`
+	StartsAt  time.Time  `gorm:"not null;index" json:"starts_at"`
+	EndsAt    time.Time  `gorm:"not null;index" json:"ends_at"`
+	ClosedAt  *time.Time `json:"closed_at"`
+	CreatedBy uint       `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// IsClosed итоги сезона уже сохранены
+func (s *Season) IsClosed() bool {
+	return s.ClosedAt != nil
+}
+
+// SeasonStanding итоговое место ученика в закрытом сезоне.
+// Класс сохраняется на момент закрытия, потому что ученик может перейти в другой.
+type SeasonStanding struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	SeasonID         uint      `gorm:"not null;uniqueIndex:idx_season_user" json:"season_id"`
+	UserID           uint      `gorm:"not null;uniqueIndex:idx_season_user;index" json:"user_id"`
+	Rank             int       `gorm:"not null" json:"rank"`
+	ClassRank        int       `gorm:"not null" json:"class_rank"`
+	Level            *int      `json:"level"`
+	LevelLetter      string    `json:"level_letter"`
+	TotalPoints      int       `json:"total_points"`
+	GamePoints       int       `json:"game_points"`
+	TotalXP          int       `json:"total_xp"`
+	CompletedLessons int       `json:"completed_lessons"`
+	CreatedAt        time.Time `json:"created_at"`
+
+	Season Season `gorm:"foreignKey:SeasonID" json:"season,omitempty"`
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"time"
+)
+
+// StatsCounters накопленные показатели ученика или класса.
+// Средние хранятся как сумма и количество, чтобы их можно было обновлять приращениями.
+type StatsCounters struct {
+	TotalPoints            int     `gorm:"not null;default:0" json:"total_points"`
+	LessonsStarted         int     `gorm:"not null;default:0" json:"lessons_started"`
+	CompletedLessons       int     `gorm:"not null;default:0" json:"completed_lessons"`
+	CompletedPercentageSum float64 `gorm:"not null;default:0" json:"-"`
+	ScoredLessons          int     `gorm:"not null;default:0" json:"-"`
+	ScoredPercentageSum    float64 `gorm:"not null;default:0" json:"-"`
+	TotalAttempts          int     `gorm:"not null;default:0" json:"total_attempts"`
+	GamesPlayed            int     `gorm:"not null;default:0" json:"games_played"`
+	GameScoreSum           int     `gorm:"not null;default:0" json:"game_score_sum"`
+}
+
+// Sub разность показателей (приращение для агрегата класса)
+func (c StatsCounters) Sub(other StatsCounters) StatsCounters {
+	return StatsCounters{
+		TotalPoints:            c.TotalPoints - other.TotalPoints,
+		LessonsStarted:         c.LessonsStarted - other.LessonsStarted,
+		CompletedLessons:       c.CompletedLessons - other.CompletedLessons,
+		CompletedPercentageSum: c.CompletedPercentageSum - other.CompletedPercentageSum,
+		ScoredLessons:          c.ScoredLessons - other.ScoredLessons,
+		ScoredPercentageSum:    c.ScoredPercentageSum - other.ScoredPercentageSum,
+		TotalAttempts:          c.TotalAttempts - other.TotalAttempts,
+		GamesPlayed:            c.GamesPlayed - other.GamesPlayed,
+		GameScoreSum:           c.GameScoreSum - other.GameScoreSum,
+	}
+}
+
+// Add сумма показателей
+func (c StatsCounters) Add(other StatsCounters) StatsCounters {
+	return StatsCounters{
+		TotalPoints:            c.TotalPoints + other.TotalPoints,
+		LessonsStarted:         c.LessonsStarted + other.LessonsStarted,
+		CompletedLessons:       c.CompletedLessons + other.CompletedLessons,
+		CompletedPercentageSum: c.CompletedPercentageSum + other.CompletedPercentageSum,
+		ScoredLessons:          c.ScoredLessons + other.ScoredLessons,
+		ScoredPercentageSum:    c.ScoredPercentageSum + other.ScoredPercentageSum,
+		TotalAttempts:          c.TotalAttempts + other.TotalAttempts,
+		GamesPlayed:            c.GamesPlayed + other.GamesPlayed,
+		GameScoreSum:           c.GameScoreSum + other.GameScoreSum,
+	}
+}
+
+// AveragePercentage средний лучший процент по пройденным урокам
+func (c StatsCounters) AveragePercentage() float64 {
+	if c.CompletedLessons == 0 {
+		return 0
+	}
+	return c.CompletedPercentageSum / float64(c.CompletedLessons)
+}
+
+// AverageScoredPercentage средний лучший процент по урокам с ненулевым результатом
+func (c StatsCounters) AverageScoredPercentage() float64 {
+	if c.ScoredLessons == 0 {
+		return 0
+	}
+	return c.ScoredPercentageSum / float64(c.ScoredLessons)
+}
+
+// UserStats кэш статистики ученика. Level/LevelLetter - класс, в агрегат
+// которого сейчас учтены показатели ученика.
+type UserStats struct {
+	UserID      uint   `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	Level       *int   `gorm:"index:idx_user_stats_class" json:"level"`
+	LevelLetter string `gorm:"index:idx_user_stats_class" json:"level_letter"`
+	StatsCounters
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ClassStats кэш статистики класса: сумма показателей его учеников
+type ClassStats struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	Level       int    `gorm:"not null;uniqueIndex:idx_class_stats_class" json:"level"`
+	LevelLetter string `gorm:"not null;default:'';uniqueIndex:idx_class_stats_class" json:"level_letter"`
+	StatsCounters
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (UserStats) TableName() string {
+	return "user_stats"
+}
+
+func (ClassStats) TableName() string {
+	return "class_stats"
+}
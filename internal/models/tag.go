@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+// TagKind раздел таксономии тегов
+type TagKind string
+
+const (
+	TagKindTopic TagKind = "topic" // грамматическая тема: Present Perfect, артикли...
+	TagKindCEFR  TagKind = "cefr"  // уровень CEFR
+	TagKindSkill TagKind = "skill" // навык: чтение, лексика, грамматика
+)
+
+// CEFRLevels допустимые теги уровня CEFR
+var CEFRLevels = []string{"A1", "A2", "B1", "B2", "C1", "C2"}
+
+// SkillTags встроенные теги навыков
+var SkillTags = []string{"reading", "vocabulary", "grammar"}
+
+// Tag метка урока, вопроса или задания игры. Вопрос наследует теги своего урока.
+type Tag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Kind      TagKind   `gorm:"type:varchar(20);not null;uniqueIndex:idx_tag_kind_name" json:"kind"`
+	Name      string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_tag_kind_name" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AnswerRecord ответ ученика на отдельный вопрос теста или задание игры.
+// Нужен для статистики по тегам: попытка теста хранит только итог.
+type AnswerRecord struct {
+	ID         uint         `gorm:"primaryKey" json:"id"`
+	UserID     uint         `gorm:"not null;index" json:"user_id"`
+	SourceType ReviewSource `gorm:"type:varchar(20);not null;index:idx_answer_source" json:"source_type"`
+	SourceID   uint         `gorm:"not null;index:idx_answer_source" json:"source_id"`
+	IsCorrect  bool         `gorm:"not null" json:"is_correct"`
+	CreatedAt  time.Time    `gorm:"index" json:"created_at"`
+}
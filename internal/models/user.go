@@ -25,6 +25,8 @@ type User struct {
 	Level      *int      `json:"level"`
 	LevelLetter string   `json:"level_letter"`
 	Avatar     string    `json:"avatar"`
+	Nickname   string    `json:"nickname"`
+	LeaderboardMode LeaderboardNameMode `gorm:"type:varchar(20);default:'full'" json:"leaderboard_mode"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
 	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
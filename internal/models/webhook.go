@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+// WebhookEvent событие, о котором внешняя система (например, электронный
+// журнал школы) получает уведомление
+type WebhookEvent string
+
+const (
+	WebhookTestSubmitted     WebhookEvent = "test.submitted"
+	WebhookLessonCompleted   WebhookEvent = "lesson.completed"
+	WebhookAchievementEarned WebhookEvent = "achievement.earned"
+	WebhookGameFinished      WebhookEvent = "game.finished"
+	WebhookUserRegistered    WebhookEvent = "user.registered"
+)
+
+// WebhookEvents все события, на которые можно подписаться
+var WebhookEvents = []WebhookEvent{
+	WebhookTestSubmitted,
+	WebhookLessonCompleted,
+	WebhookAchievementEarned,
+	WebhookGameFinished,
+	WebhookUserRegistered,
+}
+
+// IsValid событие из списка WebhookEvents
+func (e WebhookEvent) IsValid() bool {
+	for _, event := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhook подписка внешней системы на события. Тело каждой доставки
+// подписывается HMAC-SHA256 секретом, который показывается только при создании.
+type Webhook struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	URL         string         `gorm:"not null" json:"url"`
+	Description string         `json:"description"`
+	Secret      string         `gorm:"not null" json:"-"`
+	Events      []WebhookEvent `gorm:"serializer:json;type:jsonb;not null" json:"events"`
+	IsActive    bool           `gorm:"not null" json:"is_active"`
+	CreatedBy   uint           `gorm:"not null" json:"created_by"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// Subscribes подписан ли вебхук на событие
+func (w *Webhook) Subscribes(event WebhookEvent) bool {
+	for _, subscribed := range w.Events {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDeliveryStatus состояние доставки
+type WebhookDeliveryStatus string
+
+const (
+	WebhookPending   WebhookDeliveryStatus = "pending"
+	WebhookDelivered WebhookDeliveryStatus = "delivered"
+	WebhookFailed    WebhookDeliveryStatus = "failed" // попытки исчерпаны
+)
+
+// WebhookDelivery запись исходящей очереди и журнала доставок. Создается в
+// момент события и отправляется фоновым обработчиком; неудачные попытки
+// повторяются с растущей паузой до NextAttemptAt. Payload хранится готовым
+// JSON, чтобы повторные попытки отправляли те же байты.
+type WebhookDelivery struct {
+	ID             uint                  `gorm:"primaryKey" json:"id"`
+	WebhookID      uint                  `gorm:"not null;index" json:"webhook_id"`
+	Event          WebhookEvent          `gorm:"type:varchar(50);not null" json:"event"`
+	Payload        string                `gorm:"type:text;not null" json:"payload"`
+	Status         WebhookDeliveryStatus `gorm:"type:varchar(20);not null;index:idx_webhook_delivery_due,priority:1" json:"status"`
+	Attempts       int                   `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time             `gorm:"not null;index:idx_webhook_delivery_due,priority:2" json:"next_attempt_at"`
+	LastAttemptAt  *time.Time            `json:"last_attempt_at"`
+	ResponseStatus int                   `json:"response_status"`
+	LastError      string                `gorm:"type:text" json:"last_error"`
+	DeliveredAt    *time.Time            `json:"delivered_at"`
+	CreatedAt      time.Time             `json:"created_at"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// XPSource за что начислен опыт
+type XPSource string
+
+const (
+	XPSourceTestPass     XPSource = "test_pass"
+	XPSourcePerfectScore XPSource = "perfect_score"
+	XPSourceGame         XPSource = "game"
+	XPSourceStreak       XPSource = "streak"
+	XPSourceAchievement  XPSource = "achievement"
+)
+
+// XPEntry запись журнала опыта. Журнал только дополняется; пара
+// (Source, SourceID) уникальна для пользователя, поэтому повторная
+// обработка события не начисляет опыт дважды.
+type XPEntry struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index;uniqueIndex:idx_xp_user_source" json:"user_id"`
+	Source    XPSource  `gorm:"type:varchar(30);not null;uniqueIndex:idx_xp_user_source" json:"source"`
+	SourceID  uint      `gorm:"not null;uniqueIndex:idx_xp_user_source" json:"source_id"`
+	Amount    int       `gorm:"not null" json:"amount"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
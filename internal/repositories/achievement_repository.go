@@ -3,6 +3,7 @@ package repositories
 import (
 	"englishlessons.back/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type AchievementRepository struct {
@@ -32,7 +33,79 @@ func (r *AchievementRepository) FindByUserIDAndType(userID uint, achievementType
 	return &achievement, nil
 }
 
-func (r *AchievementRepository) Create(achievement *models.Achievement) error {
-	return r.db.Create(achievement).Error
+// Create выдает достижение. Возвращает false, если оно у пользователя уже есть.
+func (r *AchievementRepository) Create(achievement *models.Achievement) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(achievement)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *AchievementRepository) FindDefinitions(activeOnly bool) ([]models.AchievementDefinition, error) {
+	var definitions []models.AchievementDefinition
+	query := r.db
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+	err := query.Order("id").Find(&definitions).Error
+	return definitions, err
+}
+
+func (r *AchievementRepository) FindDefinitionsByEvent(event string) ([]models.AchievementDefinition, error) {
+	var definitions []models.AchievementDefinition
+	err := r.db.Where("event = ? AND is_active = ?", event, true).
+		Order("id").
+		Find(&definitions).Error
+	return definitions, err
+}
+
+func (r *AchievementRepository) FindDefinitionByID(id uint) (*models.AchievementDefinition, error) {
+	var definition models.AchievementDefinition
+	if err := r.db.First(&definition, id).Error; err != nil {
+		return nil, err
+	}
+	return &definition, nil
+}
+
+func (r *AchievementRepository) FindDefinitionByType(achievementType models.AchievementType) (*models.AchievementDefinition, error) {
+	var definition models.AchievementDefinition
+	if err := r.db.Where("type = ?", achievementType).First(&definition).Error; err != nil {
+		return nil, err
+	}
+	return &definition, nil
+}
+
+func (r *AchievementRepository) CreateDefinition(definition *models.AchievementDefinition) error {
+	return r.db.Create(definition).Error
+}
+
+func (r *AchievementRepository) UpdateDefinition(definition *models.AchievementDefinition) error {
+	return r.db.Save(definition).Error
 }
 
+func (r *AchievementRepository) CreateEvent(event *models.AchievementEvent) error {
+	return r.db.Create(event).Error
+}
+
+// FindEvents возвращает события учеников userIDs в хронологическом порядке
+func (r *AchievementRepository) FindEvents(event string, userIDs []uint) ([]models.AchievementEvent, error) {
+	var events []models.AchievementEvent
+	if len(userIDs) == 0 {
+		return events, nil
+	}
+	err := r.db.Where("event = ? AND user_id IN ?", event, userIDs).
+		Order("occurred_at, id").
+		Find(&events).Error
+	return events, err
+}
+
+// FindUserIDsByType возвращает пользователей, уже получивших достижение
+func (r *AchievementRepository) FindUserIDsByType(achievementType models.AchievementType) ([]uint, error) {
+	var userIDs []uint
+	err := r.db.Model(&models.Achievement{}).
+		Where("type = ?", achievementType).
+		Distinct().
+		Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/testdb"
+)
+
+// Повторная выдача того же достижения не создает строку и сообщает об этом
+func TestAchievementCreateOncePerType(t *testing.T) {
+	db := testdb.Open(t)
+	student := testdb.Seed(t, db, 1, 0).Students[0]
+	repo := NewAchievementRepository(db)
+
+	for i, want := range []bool{true, false} {
+		achievement := &models.Achievement{UserID: student.ID, Type: models.AchievementTypeFirstLesson, Title: "Первый урок", EarnedAt: time.Now()}
+		created, err := repo.Create(achievement)
+		if err != nil {
+			t.Fatalf("Create #%d: %v", i+1, err)
+		}
+		if created != want {
+			t.Fatalf("Create #%d = %v, want %v", i+1, created, want)
+		}
+	}
+
+	var count int64
+	if err := db.Model(&models.Achievement{}).Where("user_id = ?", student.ID).Count(&count).Error; err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("user has %d achievements, want 1", count)
+	}
+}
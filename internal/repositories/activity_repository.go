@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"englishlessons.back/internal/models"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ActivityRepository struct {
+	db *gorm.DB
+}
+
+func NewActivityRepository(db *gorm.DB) *ActivityRepository {
+	return &ActivityRepository{db: db}
+}
+
+// AddActivity атомарно добавляет XP и счетчики к записи дня (создает ее при необходимости)
+func (r *ActivityRepository) AddActivity(userID uint, day string, xp, tests, games int) (*models.DailyActivity, error) {
+	activity := models.DailyActivity{
+		UserID:     userID,
+		Day:        day,
+		XP:         xp,
+		TestsCount: tests,
+		GamesCount: games,
+	}
+
+	err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"xp":          gorm.Expr("daily_activities.xp + ?", xp),
+			"tests_count": gorm.Expr("daily_activities.tests_count + ?", tests),
+			"games_count": gorm.Expr("daily_activities.games_count + ?", games),
+			"updated_at":  gorm.Expr("NOW()"),
+		}),
+	}).Create(&activity).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return r.FindByUserAndDay(userID, day)
+}
+
+func (r *ActivityRepository) FindByUserAndDay(userID uint, day string) (*models.DailyActivity, error) {
+	var activity models.DailyActivity
+	err := r.db.Where("user_id = ? AND day = ?", userID, day).First(&activity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &activity, nil
+}
+
+// FindRange возвращает записи за период [fromDay, toDay] в порядке возрастания дня
+func (r *ActivityRepository) FindRange(userID uint, fromDay, toDay string) ([]models.DailyActivity, error) {
+	var activities []models.DailyActivity
+	err := r.db.Where("user_id = ? AND day >= ? AND day <= ?", userID, fromDay, toDay).
+		Order("day").
+		Find(&activities).Error
+	return activities, err
+}
+
+func (r *ActivityRepository) MarkGoalReached(activityID uint) error {
+	return r.db.Model(&models.DailyActivity{}).
+		Where("id = ?", activityID).
+		Update("goal_reached", true).Error
+}
+
+// MarkFrozen отмечает пропущенные дни, закрытые заморозкой серии
+func (r *ActivityRepository) MarkFrozen(userID uint, days []string) error {
+	for _, day := range days {
+		activity := models.DailyActivity{UserID: userID, Day: day, FreezeUsed: true}
+		err := r.db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "day"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"freeze_used": true}),
+		}).Create(&activity).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindOrCreateStreak возвращает серию пользователя, создавая запись по умолчанию
+func (r *ActivityRepository) FindOrCreateStreak(userID uint) (*models.UserStreak, error) {
+	var streak models.UserStreak
+	err := r.db.Where("user_id = ?", userID).First(&streak).Error
+	if err == nil {
+		return &streak, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	streak = models.UserStreak{
+		UserID:      userID,
+		DailyGoalXP: models.DefaultDailyGoalXP,
+	}
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&streak).Error; err != nil {
+		return nil, err
+	}
+	if streak.ID == 0 {
+		// Запись создана параллельным запросом
+		if err := r.db.Where("user_id = ?", userID).First(&streak).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &streak, nil
+}
+
+// FindStreaks серии учеников userIDs; у кого серии еще не было, в ответе нет
+func (r *ActivityRepository) FindStreaks(userIDs []uint) ([]models.UserStreak, error) {
+	var streaks []models.UserStreak
+	if len(userIDs) == 0 {
+		return streaks, nil
+	}
+	err := r.db.Where("user_id IN ?", userIDs).Order("user_id").Find(&streaks).Error
+	return streaks, err
+}
+
+func (r *ActivityRepository) SaveStreak(streak *models.UserStreak) error {
+	return r.db.Save(streak).Error
+}
@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"englishlessons.back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type AnalyticsRepository struct {
+	db *gorm.DB
+}
+
+func NewAnalyticsRepository(db *gorm.DB) *AnalyticsRepository {
+	return &AnalyticsRepository{db: db}
+}
+
+// LessonClassStats показатели класса по одному уроку
+type LessonClassStats struct {
+	LessonID          uint
+	LessonTitle       string
+	LessonOrder       int
+	CompletedCount    int
+	TotalAttempts     int
+	AveragePercentage float64
+}
+
+// classStudents подзапрос ID учеников класса; пустая буква - вся параллель
+func (r *AnalyticsRepository) classStudents(level int, levelLetter string) *gorm.DB {
+	query := r.db.Model(&models.User{}).
+		Select("id").
+		Where("role = ? AND level = ?", models.RoleStudent, level)
+	if levelLetter != "" {
+		query = query.Where("level_letter = ?", levelLetter)
+	}
+	return query
+}
+
+func (r *AnalyticsRepository) CountClassStudents(level int, levelLetter string) (int64, error) {
+	var count int64
+	err := r.classStudents(level, levelLetter).Count(&count).Error
+	return count, err
+}
+
+// LessonStatsByClass показатели класса по всем активным урокам одним запросом.
+// Средний процент считается по урокам с ненулевым результатом.
+func (r *AnalyticsRepository) LessonStatsByClass(level int, levelLetter string) ([]LessonClassStats, error) {
+	var stats []LessonClassStats
+	err := r.db.Model(&models.Lesson{}).
+		Select(`lessons.id AS lesson_id,
+			lessons.title AS lesson_title,
+			lessons."order" AS lesson_order,
+			COUNT(lp.id) FILTER (WHERE lp.is_completed) AS completed_count,
+			COALESCE(SUM(lp.attempts_count), 0) AS total_attempts,
+			COALESCE(AVG(lp.best_percentage) FILTER (WHERE lp.best_percentage > 0), 0) AS average_percentage`).
+		Joins("LEFT JOIN lesson_progresses lp ON lp.lesson_id = lessons.id AND lp.user_id IN (?)", r.classStudents(level, levelLetter)).
+		Where("lessons.is_active = ?", true).
+		Group("lessons.id, lessons.title, lessons.\"order\"").
+		Order("lessons.\"order\"").
+		Scan(&stats).Error
+	return stats, err
+}
+
+// TagStats результаты по одному тегу
+type TagStats struct {
+	TagID        uint
+	TagKind      models.TagKind
+	TagName      string
+	Answers      int
+	CorrectCount int
+	Students     int
+}
+
+// sourceTags связь вопросов и заданий игр с тегами. Вопрос получает
+// и собственные теги, и теги своего урока.
+const sourceTags = `(
+	SELECT 'question' AS source_type, qt.question_id AS source_id, qt.tag_id FROM question_tags qt
+	UNION
+	SELECT 'question', q.id, lt.tag_id FROM questions q JOIN lesson_tags lt ON lt.lesson_id = q.lesson_id
+	UNION
+	SELECT 'game_item', gt.game_item_id, gt.tag_id FROM game_item_tags gt
+) st`
+
+// tagStats результаты учеников из подзапроса users по тегам раздела kind
+func (r *AnalyticsRepository) tagStats(users interface{}, kind models.TagKind) ([]TagStats, error) {
+	var stats []TagStats
+	query := r.db.Table("answer_records ar").
+		Select(`t.id AS tag_id,
+			t.kind AS tag_kind,
+			t.name AS tag_name,
+			COUNT(*) AS answers,
+			COUNT(*) FILTER (WHERE ar.is_correct) AS correct_count,
+			COUNT(DISTINCT ar.user_id) AS students`).
+		Joins("JOIN "+sourceTags+" ON st.source_type = ar.source_type AND st.source_id = ar.source_id").
+		Joins("JOIN tags t ON t.id = st.tag_id").
+		Where("ar.user_id IN (?)", users)
+	if kind != "" {
+		query = query.Where("t.kind = ?", kind)
+	}
+	err := query.Group("t.id, t.kind, t.name").
+		Order("t.kind, t.name").
+		Scan(&stats).Error
+	return stats, err
+}
+
+// TagStatsByClass результаты класса по тегам
+func (r *AnalyticsRepository) TagStatsByClass(level int, levelLetter string, kind models.TagKind) ([]TagStats, error) {
+	return r.tagStats(r.classStudents(level, levelLetter), kind)
+}
+
+// TagStatsByUser результаты ученика по тегам
+func (r *AnalyticsRepository) TagStatsByUser(userID uint, kind models.TagKind) ([]TagStats, error) {
+	return r.tagStats([]uint{userID}, kind)
+}
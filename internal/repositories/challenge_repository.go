@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"time"
+
+	"englishlessons.back/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ChallengeRepository struct {
+	db *gorm.DB
+}
+
+func NewChallengeRepository(db *gorm.DB) *ChallengeRepository {
+	return &ChallengeRepository{db: db}
+}
+
+// DB возвращает *gorm.DB для запуска транзакций в сервисе
+func (r *ChallengeRepository) DB() *gorm.DB {
+	return r.db
+}
+
+// WithTx возвращает репозиторий, работающий внутри транзакции tx
+func (r *ChallengeRepository) WithTx(tx *gorm.DB) *ChallengeRepository {
+	return &ChallengeRepository{db: tx}
+}
+
+func (r *ChallengeRepository) Create(challenge *models.Challenge) error {
+	return r.db.Create(challenge).Error
+}
+
+func (r *ChallengeRepository) Update(challenge *models.Challenge) error {
+	return r.db.Omit(clause.Associations).Save(challenge).Error
+}
+
+// FindByID вызов вместе с игроками и их результатами
+func (r *ChallengeRepository) FindByID(id uint) (*models.Challenge, error) {
+	var challenge models.Challenge
+	err := r.db.Preload("Challenger").
+		Preload("Opponent").
+		Preload("ChallengerResult").
+		Preload("OpponentResult").
+		First(&challenge, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+// FindByIDForUpdate блокирует вызов до конца транзакции
+func (r *ChallengeRepository) FindByIDForUpdate(id uint) (*models.Challenge, error) {
+	var challenge models.Challenge
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&challenge, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+// FindByUser вызовы, где пользователь участвует с любой стороны
+func (r *ChallengeRepository) FindByUser(userID uint, status models.ChallengeStatus, limit, offset int) ([]models.Challenge, int64, error) {
+	var challenges []models.Challenge
+	var total int64
+
+	query := r.db.Model(&models.Challenge{}).
+		Where("challenger_id = ? OR opponent_id = ?", userID, userID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Challenger").
+		Preload("Opponent").
+		Preload("ChallengerResult").
+		Preload("OpponentResult").
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&challenges).Error
+	return challenges, total, err
+}
+
+// ExpireOverdue закрывает открытые вызовы, срок которых прошел
+func (r *ChallengeRepository) ExpireOverdue(now time.Time) error {
+	return r.db.Model(&models.Challenge{}).
+		Where("status = ? AND expires_at < ?", models.ChallengeOpen, now).
+		Update("status", models.ChallengeExpired).Error
+}
+
+// HeadToHead считает победы, поражения и ничьи userID в завершенных вызовах против opponentID
+func (r *ChallengeRepository) HeadToHead(userID, opponentID uint) (*models.HeadToHead, error) {
+	record := &models.HeadToHead{UserID: userID, OpponentID: opponentID}
+	err := r.db.Model(&models.Challenge{}).
+		Select(`COUNT(*) FILTER (WHERE winner_id = ?) AS wins,
+			COUNT(*) FILTER (WHERE winner_id = ?) AS losses,
+			COUNT(*) FILTER (WHERE winner_id IS NULL) AS draws`, userID, opponentID).
+		Where("status = ?", models.ChallengeCompleted).
+		Where("(challenger_id = ? AND opponent_id = ?) OR (challenger_id = ? AND opponent_id = ?)",
+			userID, opponentID, opponentID, userID).
+		Scan(record).Error
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"time"
+
+	"englishlessons.back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type CommentRepository struct {
+	db *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) *CommentRepository {
+	return &CommentRepository{db: db}
+}
+
+// ThreadFilter выборка обсуждений; ViewerRole определяет, какие сообщения
+// считаются непрочитанными (написанные другой стороной)
+type ThreadFilter struct {
+	ViewerRole models.Role
+	StudentID  uint
+	UnreadOnly bool
+	Limit      int
+	Offset     int
+}
+
+// FindTargetOwner ученик, которому принадлежит работа
+func (r *CommentRepository) FindTargetOwner(targetType models.CommentTarget, targetID uint) (uint, error) {
+	var owners []uint
+	var err error
+	switch targetType {
+	case models.CommentTestAttempt:
+		err = r.db.Model(&models.TestAttempt{}).Where("id = ?", targetID).Pluck("user_id", &owners).Error
+	case models.CommentGameResult:
+		err = r.db.Model(&models.GameResult{}).Where("id = ?", targetID).Pluck("user_id", &owners).Error
+	case models.CommentStudent:
+		err = r.db.Model(&models.User{}).Where("id = ? AND role = ?", targetID, models.RoleStudent).Pluck("id", &owners).Error
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(owners) == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return owners[0], nil
+}
+
+func (r *CommentRepository) Create(comment *models.Comment) error {
+	return r.db.Create(comment).Error
+}
+
+// FindByID сообщение вместе с автором
+func (r *CommentRepository) FindByID(id uint) (*models.Comment, error) {
+	var comment models.Comment
+	if err := r.db.Preload("Author").First(&comment, id).Error; err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// FindThread сообщения обсуждения по порядку вместе с авторами
+func (r *CommentRepository) FindThread(targetType models.CommentTarget, targetID uint) ([]models.Comment, error) {
+	var comments []models.Comment
+	err := r.db.Preload("Author").
+		Where("target_type = ? AND target_id = ?", targetType, targetID).
+		Order("created_at, id").
+		Find(&comments).Error
+	return comments, err
+}
+
+// MarkRead отмечает прочитанными сообщения другой стороны
+func (r *CommentRepository) MarkRead(targetType models.CommentTarget, targetID uint, viewerRole models.Role) error {
+	return r.db.Model(&models.Comment{}).
+		Where("target_type = ? AND target_id = ? AND author_role <> ? AND read_at IS NULL", targetType, targetID, viewerRole).
+		Update("read_at", time.Now()).Error
+}
+
+// CountUnread непрочитанные сообщения другой стороны; studentID = 0 - по всем ученикам
+func (r *CommentRepository) CountUnread(viewerRole models.Role, studentID uint) (int64, error) {
+	var count int64
+	query := r.db.Model(&models.Comment{}).Where("author_role <> ? AND read_at IS NULL", viewerRole)
+	if studentID != 0 {
+		query = query.Where("student_id = ?", studentID)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// FindThreads сводки обсуждений, последние активные первыми
+func (r *CommentRepository) FindThreads(filter ThreadFilter) ([]models.CommentThread, int64, error) {
+	threads := r.db.Table("comments c").
+		Select(`c.student_id,
+			COALESCE(NULLIF(TRIM(u.first_name || ' ' || u.last_name), ''), u.username) AS student_name,
+			c.target_type, c.target_id,
+			COUNT(*) AS comments_count,
+			COUNT(*) FILTER (WHERE c.author_role <> ? AND c.read_at IS NULL) AS unread_count,
+			(ARRAY_AGG(c.body ORDER BY c.created_at DESC, c.id DESC))[1] AS last_body,
+			(ARRAY_AGG(c.author_role ORDER BY c.created_at DESC, c.id DESC))[1] AS last_author_role,
+			MAX(c.created_at) AS last_comment_at`, filter.ViewerRole).
+		Joins("JOIN users u ON u.id = c.student_id").
+		Group("c.student_id, u.first_name, u.last_name, u.username, c.target_type, c.target_id")
+	if filter.StudentID != 0 {
+		threads = threads.Where("c.student_id = ?", filter.StudentID)
+	}
+	if filter.UnreadOnly {
+		threads = threads.Having("COUNT(*) FILTER (WHERE c.author_role <> ? AND c.read_at IS NULL) > 0", filter.ViewerRole)
+	}
+
+	var total int64
+	if err := r.db.Table("(?) AS threads", threads).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var result []models.CommentThread
+	err := threads.Order("last_comment_at DESC").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Scan(&result).Error
+	return result, total, err
+}
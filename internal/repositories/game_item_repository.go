@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"englishlessons.back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type GameItemRepository struct {
+	db *gorm.DB
+}
+
+func NewGameItemRepository(db *gorm.DB) *GameItemRepository {
+	return &GameItemRepository{db: db}
+}
+
+// FindActive возвращает активные задания игры для уровня
+func (r *GameItemRepository) FindActive(gameType models.GameType, level int) ([]models.GameItem, error) {
+	var items []models.GameItem
+	err := r.db.Where("game_type = ? AND level = ? AND is_active = ?", gameType, level, true).
+		Order("id").
+		Find(&items).Error
+	return items, err
+}
+
+// GameItemFilter фильтр списка заданий для учителя
+type GameItemFilter struct {
+	GameType        models.GameType
+	Level           *int
+	TagID           uint
+	IncludeInactive bool
+	Limit           int
+	Offset          int
+}
+
+func (r *GameItemRepository) FindAll(filter GameItemFilter) ([]models.GameItem, int64, error) {
+	var items []models.GameItem
+	var total int64
+
+	query := r.db.Model(&models.GameItem{})
+	if filter.GameType != "" {
+		query = query.Where("game_type = ?", filter.GameType)
+	}
+	if filter.Level != nil {
+		query = query.Where("level = ?", *filter.Level)
+	}
+	if filter.TagID > 0 {
+		query = query.Where("id IN (SELECT game_item_id FROM game_item_tags WHERE tag_id = ?)", filter.TagID)
+	}
+	if !filter.IncludeInactive {
+		query = query.Where("is_active = ?", true)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Tags").
+		Order("game_type, level, id").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&items).Error
+	return items, total, err
+}
+
+func (r *GameItemRepository) FindByID(id uint) (*models.GameItem, error) {
+	var item models.GameItem
+	if err := r.db.Preload("Tags").First(&item, id).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *GameItemRepository) Create(item *models.GameItem) error {
+	return r.db.Create(item).Error
+}
+
+// CreateBatch сохраняет задания одной транзакцией: либо все, либо ни одного
+func (r *GameItemRepository) CreateBatch(items []models.GameItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(items, 100).Error
+	})
+}
+
+func (r *GameItemRepository) Update(item *models.GameItem) error {
+	return r.db.Save(item).Error
+}
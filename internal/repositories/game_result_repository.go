@@ -13,11 +13,45 @@ func NewGameResultRepository(db *gorm.DB) *GameResultRepository {
 	return &GameResultRepository{db: db}
 }
 
+// WithTx возвращает репозиторий, работающий внутри транзакции tx
+func (r *GameResultRepository) WithTx(tx *gorm.DB) *GameResultRepository {
+	return &GameResultRepository{db: tx}
+}
+
 // Create сохраняет новый результат игры
 func (r *GameResultRepository) Create(result *models.GameResult) error {
 	return r.db.Create(result).Error
 }
 
+// CreateBatch сохраняет несколько результатов одной транзакцией
+func (r *GameResultRepository) CreateBatch(results []models.GameResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return tx.Create(&results).Error
+	})
+}
+
+func (r *GameResultRepository) FindByID(id uint) (*models.GameResult, error) {
+	var result models.GameResult
+	if err := r.db.First(&result, id).Error; err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CountByUser считает игры пользователя (по всем играм, если gameType пустой)
+func (r *GameResultRepository) CountByUser(userID uint, gameType models.GameType) (int64, error) {
+	var count int64
+	query := r.db.Model(&models.GameResult{}).Where("user_id = ?", userID)
+	if gameType != "" {
+		query = query.Where("game_type = ?", gameType)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
+
 // GetByUserID получает все результаты пользователя
 func (r *GameResultRepository) GetByUserID(userID uint) ([]models.GameResult, error) {
 	var results []models.GameResult
@@ -25,6 +59,16 @@ func (r *GameResultRepository) GetByUserID(userID uint) ([]models.GameResult, er
 	return results, err
 }
 
+// FindByUserIDs результаты учеников по порядку: по ученику, затем по времени
+func (r *GameResultRepository) FindByUserIDs(userIDs []uint) ([]models.GameResult, error) {
+	var results []models.GameResult
+	if len(userIDs) == 0 {
+		return results, nil
+	}
+	err := r.db.Where("user_id IN ?", userIDs).Order("user_id, created_at, id").Find(&results).Error
+	return results, err
+}
+
 // GetByUserAndGame получает результаты пользователя по конкретной игре
 func (r *GameResultRepository) GetByUserAndGame(userID uint, gameType models.GameType) ([]models.GameResult, error) {
 	var results []models.GameResult
@@ -179,27 +223,65 @@ func (r *GameResultRepository) GetClassStats(level *int, levelLetter string) ([]
 	return stats, err
 }
 
-// GetLeaderboard получает рейтинг по игре и уровню
-func (r *GameResultRepository) GetLeaderboard(gameType models.GameType, level int, limit int) ([]models.GameResult, error) {
+// GetLeaderboard лучшие результаты по игре. excludeHidden убирает учеников,
+// отказавшихся от участия в рейтингах.
+func (r *GameResultRepository) GetLeaderboard(gameType models.GameType, level int, limit int, excludeHidden bool) ([]models.GameResult, error) {
 	var results []models.GameResult
 
-	// Подзапрос для получения лучших результатов каждого пользователя
-	subQuery := r.db.Model(&models.GameResult{}).
-		Select("user_id, MAX(percentage) as max_percentage").
-		Where("game_type = ? AND level = ?", gameType, level).
-		Group("user_id")
+	// Лучший результат каждого пользователя: при равном проценте - более быстрый,
+	// затем более ранний, чтобы у пользователя была ровно одна строка
+	best := r.db.Model(&models.GameResult{}).
+		Select("DISTINCT ON (game_results.user_id) game_results.*").
+		Where("game_results.game_type = ? AND game_results.level = ?", gameType, level)
+	if excludeHidden {
+		best = best.Joins("JOIN users ON users.id = game_results.user_id").
+			Where("COALESCE(users.leaderboard_mode, '') <> ?", models.NameModeHidden)
+	}
+	best = best.Order("game_results.user_id, game_results.percentage DESC, game_results.time_spent ASC, game_results.id ASC")
 
-	err := r.db.Model(&models.GameResult{}).
-		Joins("JOIN (?) as best ON game_results.user_id = best.user_id AND game_results.percentage = best.max_percentage", subQuery).
-		Where("game_results.game_type = ? AND game_results.level = ?", gameType, level).
+	err := r.db.Table("(?) AS game_results", best).
 		Preload("User").
-		Order("game_results.percentage DESC, game_results.time_spent ASC").
+		Order("game_results.percentage DESC, game_results.time_spent ASC, game_results.id ASC").
 		Limit(limit).
 		Find(&results).Error
 
 	return results, err
 }
 
+// CountLeaderboard количество учеников в рейтинге игры — всех, а не только
+// попавших в первые limit строк GetLeaderboard
+func (r *GameResultRepository) CountLeaderboard(gameType models.GameType, level int, excludeHidden bool) (int64, error) {
+	var count int64
+	query := r.db.Model(&models.GameResult{}).
+		Where("game_results.game_type = ? AND game_results.level = ?", gameType, level)
+	if excludeHidden {
+		query = query.Joins("JOIN users ON users.id = game_results.user_id").
+			Where("COALESCE(users.leaderboard_mode, '') <> ?", models.NameModeHidden)
+	}
+	err := query.Distinct("game_results.user_id").Count(&count).Error
+	return count, err
+}
+
+// FindOvertakenUsers ученики, которых result обогнал в рейтинге игры: их лучший
+// процент не ниже прежнего лучшего у автора результата, но ниже нового.
+// Ближайшие к новому результату идут первыми.
+func (r *GameResultRepository) FindOvertakenUsers(result *models.GameResult, limit int) ([]uint, error) {
+	previous := r.db.Model(&models.GameResult{}).
+		Select("COALESCE(MAX(percentage), -1)").
+		Where("user_id = ? AND game_type = ? AND level = ? AND id <> ?", result.UserID, result.GameType, result.Level, result.ID)
+
+	var userIDs []uint
+	err := r.db.Model(&models.GameResult{}).
+		Select("user_id").
+		Where("game_type = ? AND level = ? AND user_id <> ?", result.GameType, result.Level, result.UserID).
+		Group("user_id").
+		Having("MAX(percentage) >= (?) AND MAX(percentage) < ?", previous, result.Percentage).
+		Order("MAX(percentage) DESC").
+		Limit(limit).
+		Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
 // GetRecentResults получает последние результаты (для учителя)
 func (r *GameResultRepository) GetRecentResults(limit int, level *int, levelLetter string) ([]models.GameResult, error) {
 	var results []models.GameResult
@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"testing"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/testdb"
+)
+
+// При равных лучших результатах у ученика в рейтинге остается одна строка
+func TestGameResultLeaderboardOneRowPerUser(t *testing.T) {
+	db := testdb.Open(t)
+	students := testdb.Seed(t, db, 2, 0).Students
+	repo := NewGameResultRepository(db)
+	const level = 9
+
+	for _, result := range []models.GameResult{
+		{UserID: students[0].ID, Percentage: 100, TimeSpent: 40},
+		{UserID: students[0].ID, Percentage: 100, TimeSpent: 40},
+		{UserID: students[0].ID, Percentage: 100, TimeSpent: 30},
+		{UserID: students[1].ID, Percentage: 80, TimeSpent: 20},
+		{UserID: students[1].ID, Percentage: 80, TimeSpent: 20},
+	} {
+		result.GameType, result.Level, result.MaxScore, result.TotalCount = models.GameQuizShow, level, 8, 8
+		testdb.Create(t, db, &result)
+	}
+
+	results, err := repo.GetLeaderboard(models.GameQuizShow, level, 10, false)
+	if err != nil {
+		t.Fatalf("GetLeaderboard: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("leaderboard has %d rows, want one per user", len(results))
+	}
+	if results[0].UserID != students[0].ID || results[0].TimeSpent != 30 || results[1].UserID != students[1].ID {
+		t.Errorf("leaderboard = %+v", results)
+	}
+	if results[0].User.ID != students[0].ID {
+		t.Errorf("user is not preloaded: %+v", results[0].User)
+	}
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"englishlessons.back/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GameSessionRepository struct {
+	db *gorm.DB
+}
+
+func NewGameSessionRepository(db *gorm.DB) *GameSessionRepository {
+	return &GameSessionRepository{db: db}
+}
+
+// DB возвращает *gorm.DB для запуска транзакций в сервисе
+func (r *GameSessionRepository) DB() *gorm.DB {
+	return r.db
+}
+
+// WithTx возвращает репозиторий, работающий внутри транзакции tx
+func (r *GameSessionRepository) WithTx(tx *gorm.DB) *GameSessionRepository {
+	return &GameSessionRepository{db: tx}
+}
+
+func (r *GameSessionRepository) Create(session *models.GameSession) error {
+	return r.db.Create(session).Error
+}
+
+// FindByIDForUpdate блокирует сессию до конца транзакции, чтобы ее нельзя было сдать дважды
+func (r *GameSessionRepository) FindByIDForUpdate(id uint) (*models.GameSession, error) {
+	var session models.GameSession
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&session, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *GameSessionRepository) Update(session *models.GameSession) error {
+	return r.db.Save(session).Error
+}
+
+// CountByChallengeAndUser сколько раундов вызова уже выдано игроку
+func (r *GameSessionRepository) CountByChallengeAndUser(challengeID, userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.GameSession{}).
+		Where("challenge_id = ? AND user_id = ?", challengeID, userID).
+		Count(&count).Error
+	return count, err
+}
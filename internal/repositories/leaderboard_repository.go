@@ -0,0 +1,242 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"englishlessons.back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type LeaderboardRepository struct {
+	db *gorm.DB
+}
+
+func NewLeaderboardRepository(db *gorm.DB) *LeaderboardRepository {
+	return &LeaderboardRepository{db: db}
+}
+
+// LeaderboardQuery параметры рейтинга. Нулевые From/To - без ограничения по времени,
+// Limit == 0 - все строки. Строка IncludeUserID возвращается, даже если она вне страницы.
+// ExcludeHidden убирает из рейтинга учеников, отказавшихся от участия.
+// HideBottomHalf убирает скрытые от зрителя строки нижней половины до пагинации.
+type LeaderboardQuery struct {
+	Level          int
+	LevelLetter    string
+	From           time.Time
+	To             time.Time
+	SortByXP       bool
+	ExcludeHidden  bool
+	Limit          int
+	Offset         int
+	IncludeUserID  uint
+	HideBottomHalf *LeaderboardHiding
+}
+
+// LeaderboardClass класс учеников: параллель и буква
+type LeaderboardClass struct {
+	Level       int
+	LevelLetter string
+}
+
+// LeaderboardHiding классы, в которых нижняя половина рейтинга скрыта от зрителя.
+// Параллель из Levels скрывается целиком, кроме классов из OwnSettings - у них
+// свои настройки, и скрываются они, только если перечислены в Classes.
+type LeaderboardHiding struct {
+	ViewerID    uint
+	Classes     []LeaderboardClass
+	Levels      []int
+	OwnSettings []LeaderboardClass
+}
+
+// leaderboardSQL считает показатели и места всех учеников одним запросом.
+// По каждому уроку (и по каждой паре игра+уровень) учитывается лучший результат за период.
+// Места считаются по всему рейтингу, а номер строки и total_count - по строкам,
+// которые видит зритель.
+const leaderboardSQL = `
+WITH students AS (
+	SELECT id, username, first_name, last_name, level, level_letter, nickname, avatar, leaderboard_mode
+	FROM users
+	WHERE role = @role AND deleted_at IS NULL %s
+),
+best_tests AS (
+	SELECT ta.user_id, ta.lesson_id,
+		MAX(ta.score) AS best_score,
+		MAX(ta.percentage) AS best_percentage,
+		BOOL_OR(ta.is_passed) AS passed
+	FROM test_attempts ta
+	JOIN students s ON s.id = ta.user_id
+	WHERE %s
+	GROUP BY ta.user_id, ta.lesson_id
+),
+tests AS (
+	SELECT user_id,
+		SUM(best_score) AS total_points,
+		COUNT(*) FILTER (WHERE passed) AS completed_lessons,
+		COALESCE(AVG(best_percentage) FILTER (WHERE passed), 0) AS average_percentage
+	FROM best_tests
+	GROUP BY user_id
+),
+best_games AS (
+	SELECT gr.user_id, MAX(gr.score) AS best_score
+	FROM game_results gr
+	JOIN students s ON s.id = gr.user_id
+	WHERE %s
+	GROUP BY gr.user_id, gr.game_type, gr.level
+),
+games AS (
+	SELECT user_id, SUM(best_score) AS game_points
+	FROM best_games
+	GROUP BY user_id
+),
+xp AS (
+	SELECT xe.user_id,
+		SUM(xe.amount) FILTER (WHERE %s) AS total_xp,
+		SUM(xe.amount) AS all_time_xp
+	FROM xp_entries xe
+	JOIN students s ON s.id = xe.user_id
+	GROUP BY xe.user_id
+),
+ranked AS (
+	SELECT s.id AS user_id, s.username, s.first_name, s.last_name, s.level, s.level_letter,
+		s.nickname, s.avatar, s.leaderboard_mode,
+		COALESCE(t.total_points, 0) AS total_points,
+		COALESCE(t.completed_lessons, 0) AS completed_lessons,
+		COALESCE(t.average_percentage, 0) AS average_percentage,
+		COALESCE(g.game_points, 0) AS game_points,
+		COALESCE(x.total_xp, 0) AS total_xp,
+		COALESCE(x.all_time_xp, 0) AS all_time_xp
+	FROM students s
+	LEFT JOIN tests t ON t.user_id = s.id
+	LEFT JOIN games g ON g.user_id = s.id
+	LEFT JOIN xp x ON x.user_id = s.id
+),
+positioned AS (
+	SELECT ranked.*,
+		RANK() OVER (ORDER BY %[5]s) AS rank,
+		RANK() OVER (PARTITION BY level, level_letter ORDER BY %[5]s) AS class_rank,
+		COUNT(*) OVER () AS board_count
+	FROM ranked
+),
+visible AS (
+	SELECT positioned.*,
+		ROW_NUMBER() OVER (ORDER BY %[5]s, last_name, first_name, user_id) AS row_number,
+		COUNT(*) OVER () AS total_count
+	FROM positioned
+	WHERE %[6]s
+)
+SELECT * FROM visible
+WHERE %[7]s
+ORDER BY row_number`
+
+const (
+	leaderboardOrderByPoints = "total_points DESC, average_percentage DESC"
+	leaderboardOrderByXP     = "total_xp DESC, total_points DESC, average_percentage DESC"
+)
+
+// Rank возвращает страницу рейтинга с местами в школе и в классе
+func (r *LeaderboardRepository) Rank(q LeaderboardQuery) ([]models.LeaderboardRow, error) {
+	args := map[string]interface{}{
+		"role": models.RoleStudent,
+	}
+
+	var studentFilters []string
+	if q.Level > 0 {
+		studentFilters = append(studentFilters, "level = @level")
+		args["level"] = q.Level
+	}
+	if q.LevelLetter != "" {
+		studentFilters = append(studentFilters, "level_letter ILIKE @level_letter")
+		args["level_letter"] = q.LevelLetter
+	}
+	if q.ExcludeHidden {
+		studentFilters = append(studentFilters, "COALESCE(leaderboard_mode, '') <> @hidden_mode")
+		args["hidden_mode"] = models.NameModeHidden
+	}
+	studentWhere := ""
+	if len(studentFilters) > 0 {
+		studentWhere = "AND " + strings.Join(studentFilters, " AND ")
+	}
+
+	if !q.From.IsZero() {
+		args["from"] = q.From
+	}
+	if !q.To.IsZero() {
+		args["to"] = q.To
+	}
+
+	orderBy := leaderboardOrderByPoints
+	if q.SortByXP {
+		orderBy = leaderboardOrderByXP
+	}
+
+	pageWhere := "TRUE"
+	if q.Limit > 0 {
+		pageWhere = "(row_number > @offset AND row_number <= @offset + @limit)"
+		args["offset"] = q.Offset
+		args["limit"] = q.Limit
+		if q.IncludeUserID != 0 {
+			pageWhere += " OR user_id = @include_user_id"
+			args["include_user_id"] = q.IncludeUserID
+		}
+	}
+
+	sql := fmt.Sprintf(leaderboardSQL,
+		studentWhere,
+		windowCondition("ta.created_at", q.From, q.To),
+		windowCondition("gr.created_at", q.From, q.To),
+		windowCondition("xe.created_at", q.From, q.To),
+		orderBy,
+		hidingCondition(q.HideBottomHalf, args),
+		pageWhere,
+	)
+
+	var rows []models.LeaderboardRow
+	err := r.db.Raw(sql, args).Scan(&rows).Error
+	return rows, err
+}
+
+// hidingCondition условие на строки, которые видит зритель: ниже середины
+// рейтинга (место больше половины от всех учеников) не видно учеников из
+// классов со скрытой нижней половиной. Себя зритель видит всегда.
+func hidingCondition(hiding *LeaderboardHiding, args map[string]interface{}) string {
+	if hiding == nil || (len(hiding.Classes) == 0 && len(hiding.Levels) == 0) {
+		return "TRUE"
+	}
+
+	var hidden []string
+	for i, class := range hiding.Classes {
+		hidden = append(hidden, fmt.Sprintf("(level = @hidden_level_%[1]d AND level_letter = @hidden_letter_%[1]d)", i))
+		args[fmt.Sprintf("hidden_level_%d", i)] = class.Level
+		args[fmt.Sprintf("hidden_letter_%d", i)] = class.LevelLetter
+	}
+	if len(hiding.Levels) > 0 {
+		parallel := "level IN @hidden_levels"
+		args["hidden_levels"] = hiding.Levels
+		for i, class := range hiding.OwnSettings {
+			parallel += fmt.Sprintf(" AND NOT (level = @own_level_%[1]d AND level_letter = @own_letter_%[1]d)", i)
+			args[fmt.Sprintf("own_level_%d", i)] = class.Level
+			args[fmt.Sprintf("own_letter_%d", i)] = class.LevelLetter
+		}
+		hidden = append(hidden, "("+parallel+")")
+	}
+	args["hiding_viewer_id"] = hiding.ViewerID
+
+	// COALESCE: у ученика без класса level равен NULL, и его строка не скрывается
+	return fmt.Sprintf("user_id = @hiding_viewer_id OR rank <= (board_count + 1) / 2 OR NOT COALESCE(%s, FALSE)",
+		strings.Join(hidden, " OR "))
+}
+
+// windowCondition условие на полуинтервал [@from, @to) для колонки column
+func windowCondition(column string, from, to time.Time) string {
+	conditions := []string{"TRUE"}
+	if !from.IsZero() {
+		conditions = append(conditions, column+" >= @from")
+	}
+	if !to.IsZero() {
+		conditions = append(conditions, column+" < @to")
+	}
+	return strings.Join(conditions, " AND ")
+}
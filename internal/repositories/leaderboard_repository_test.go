@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"englishlessons.back/internal/testdb"
+)
+
+func TestLeaderboardRankPage(t *testing.T) {
+	db := testdb.Open(t)
+	testdb.Seed(t, db, 30, 5)
+	repo := NewLeaderboardRepository(db)
+
+	all, err := repo.Rank(LeaderboardQuery{})
+	if err != nil {
+		t.Fatalf("Rank: %v", err)
+	}
+	page, err := repo.Rank(LeaderboardQuery{Limit: 10, Offset: 10})
+	if err != nil {
+		t.Fatalf("Rank page: %v", err)
+	}
+	if len(page) != 10 {
+		t.Fatalf("page size = %d, want 10", len(page))
+	}
+	for i, row := range page {
+		if row.UserID != all[10+i].UserID || row.Rank != all[10+i].Rank {
+			t.Fatalf("page row %d = user %d rank %d, want user %d rank %d",
+				i, row.UserID, row.Rank, all[10+i].UserID, all[10+i].Rank)
+		}
+		if row.TotalCount != int64(len(all)) {
+			t.Fatalf("total count = %d, want %d", row.TotalCount, len(all))
+		}
+	}
+}
+
+// Скрытые строки нижней половины убираются до пагинации: страницы полные,
+// а total_count считается по видимым строкам
+func TestLeaderboardRankHidesBottomHalfBeforePaging(t *testing.T) {
+	db := testdb.Open(t)
+	seeded := testdb.Seed(t, db, 30, 5)
+	repo := NewLeaderboardRepository(db)
+	viewer := seeded.Students[0]
+	hiding := &LeaderboardHiding{
+		ViewerID:    viewer.ID,
+		Classes:     []LeaderboardClass{{Level: 5, LevelLetter: "Б"}},
+		Levels:      []int{6, 7},
+		OwnSettings: []LeaderboardClass{{Level: 5, LevelLetter: "Б"}, {Level: 7, LevelLetter: "А"}},
+	}
+
+	all, err := repo.Rank(LeaderboardQuery{})
+	if err != nil {
+		t.Fatalf("Rank: %v", err)
+	}
+	var visible []uint
+	for _, row := range all {
+		hiddenClass := row.Level != nil && ((*row.Level == 5 && row.LevelLetter == "Б") ||
+			(*row.Level == 6) || (*row.Level == 7 && row.LevelLetter != "А"))
+		if row.UserID == viewer.ID || !hiddenClass || row.Rank <= (int(row.TotalCount)+1)/2 {
+			visible = append(visible, row.UserID)
+		}
+	}
+	if len(visible) < 10 || len(visible) == len(all) {
+		t.Fatalf("seed gives %d visible rows of %d, test needs a hidden row and two pages", len(visible), len(all))
+	}
+
+	page, err := repo.Rank(LeaderboardQuery{Limit: 5, Offset: 5, HideBottomHalf: hiding})
+	if err != nil {
+		t.Fatalf("Rank page: %v", err)
+	}
+	if len(page) != 5 {
+		t.Fatalf("page size = %d, want 5", len(page))
+	}
+	for i, row := range page {
+		if row.UserID != visible[5+i] {
+			t.Fatalf("page row %d = user %d, want %d", i, row.UserID, visible[5+i])
+		}
+		if row.TotalCount != int64(len(visible)) {
+			t.Fatalf("total count = %d, want %d", row.TotalCount, len(visible))
+		}
+	}
+}
+
+// BenchmarkLeaderboardRank рейтинг школы на 600 учениках по 20 урокам
+// (DATABASE_URL=... go test -run '^$' -bench LeaderboardRank ./internal/repositories)
+func BenchmarkLeaderboardRank(b *testing.B) {
+	db := testdb.Open(b)
+	testdb.Seed(b, db, 600, 20)
+	repo := NewLeaderboardRepository(db)
+	weekAgo := time.Now().AddDate(0, 0, -7)
+
+	benchmarks := []struct {
+		name  string
+		query LeaderboardQuery
+	}{
+		{"AllTime", LeaderboardQuery{Limit: 50}},
+		{"Class", LeaderboardQuery{Level: 7, LevelLetter: "А", Limit: 50}},
+		{"Week", LeaderboardQuery{From: weekAgo, To: time.Now(), Limit: 50}},
+		{"ByXPWithMyRow", LeaderboardQuery{SortByXP: true, Limit: 50, Offset: 100, IncludeUserID: 1}},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.Rank(bm.query); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -23,6 +23,16 @@ func (r *LessonRepository) FindAll(activeOnly bool) ([]models.Lesson, error) {
 	return lessons, err
 }
 
+// FindAllWithTags активные уроки по порядку вместе с тегами
+func (r *LessonRepository) FindAllWithTags() ([]models.Lesson, error) {
+	var lessons []models.Lesson
+	err := r.db.Preload("Tags").
+		Where("is_active = ?", true).
+		Order("\"order\"").
+		Find(&lessons).Error
+	return lessons, err
+}
+
 func (r *LessonRepository) FindByID(id uint) (*models.Lesson, error) {
 	var lesson models.Lesson
 	err := r.db.Where("id = ?", id).First(&lesson).Error
@@ -34,7 +44,12 @@ func (r *LessonRepository) FindByID(id uint) (*models.Lesson, error) {
 
 func (r *LessonRepository) FindByIDWithQuestions(id uint, activeOnly bool) (*models.Lesson, error) {
 	var lesson models.Lesson
-	query := r.db.Preload("Questions.AnswerOptions")
+	query := r.db.Preload("Questions.AnswerOptions").Preload("Questions.Tags").Preload("Questions.Audio").
+		Preload("Blocks", func(db *gorm.DB) *gorm.DB {
+			return db.Order("\"order\", id")
+		}).
+		Preload("Blocks.Media").
+		Preload("Tags")
 	if activeOnly {
 		query = query.Where("id = ? AND is_active = ?", id, true)
 	} else {
@@ -66,8 +81,36 @@ func (r *LessonRepository) FindQuestionsByLessonID(lessonID uint) ([]models.Ques
 	var questions []models.Question
 	err := r.db.Where("lesson_id = ?", lessonID).
 		Preload("AnswerOptions").
+		Preload("Audio").
 		Order("id").
 		Find(&questions).Error
 	return questions, err
 }
 
+// FindQuestionByID вопрос с вариантами ответа и аудио
+func (r *LessonRepository) FindQuestionByID(id uint) (*models.Question, error) {
+	var question models.Question
+	err := r.db.Preload("AnswerOptions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("\"order\", id")
+	}).Preload("Audio").First(&question, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &question, nil
+}
+
+// CreateQuestion добавляет вопрос в конец урока вместе с вариантами ответа
+func (r *LessonRepository) CreateQuestion(question *models.Question) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var maxOrder int
+		err := tx.Model(&models.Question{}).
+			Where("lesson_id = ?", question.LessonID).
+			Select("COALESCE(MAX(\"order\"), 0)").
+			Scan(&maxOrder).Error
+		if err != nil {
+			return err
+		}
+		question.Order = maxOrder + 1
+		return tx.Create(question).Error
+	})
+}
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"englishlessons.back/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MasteryRepository struct {
+	db *gorm.DB
+}
+
+func NewMasteryRepository(db *gorm.DB) *MasteryRepository {
+	return &MasteryRepository{db: db}
+}
+
+// DB возвращает *gorm.DB для запуска транзакций в сервисе
+func (r *MasteryRepository) DB() *gorm.DB {
+	return r.db
+}
+
+// WithTx возвращает репозиторий, работающий внутри транзакции tx
+func (r *MasteryRepository) WithTx(tx *gorm.DB) *MasteryRepository {
+	return &MasteryRepository{db: tx}
+}
+
+func (r *MasteryRepository) FindMastery(userID uint, skill string) (*models.SkillMastery, error) {
+	var mastery models.SkillMastery
+	err := r.db.Where("user_id = ? AND skill = ?", userID, skill).First(&mastery).Error
+	if err != nil {
+		return nil, err
+	}
+	return &mastery, nil
+}
+
+// LockMastery создает запись навыка при первом обращении и блокирует ее до
+// конца транзакции, чтобы параллельные ответы не затирали рейтинг друг друга
+func (r *MasteryRepository) LockMastery(userID uint, skill string) (*models.SkillMastery, error) {
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.SkillMastery{UserID: userID, Skill: skill}).Error; err != nil {
+		return nil, err
+	}
+
+	var mastery models.SkillMastery
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND skill = ?", userID, skill).
+		First(&mastery).Error
+	if err != nil {
+		return nil, err
+	}
+	return &mastery, nil
+}
+
+func (r *MasteryRepository) SaveMastery(mastery *models.SkillMastery) error {
+	return r.db.Save(mastery).Error
+}
+
+// SetLastItem запоминает последний вопрос практики, чтобы не задавать его подряд
+func (r *MasteryRepository) SetLastItem(userID uint, skill string, itemID uint) error {
+	return r.db.Model(&models.SkillMastery{}).
+		Where("user_id = ? AND skill = ?", userID, skill).
+		UpdateColumn("last_item_id", itemID).Error
+}
+
+// FindDifficulties известные сложности заданий; у новых заданий записи еще нет
+func (r *MasteryRepository) FindDifficulties(sourceType models.ReviewSource, sourceIDs []uint) ([]models.ItemDifficulty, error) {
+	var difficulties []models.ItemDifficulty
+	if len(sourceIDs) == 0 {
+		return difficulties, nil
+	}
+	err := r.db.Where("source_type = ? AND source_id IN ?", sourceType, sourceIDs).
+		Find(&difficulties).Error
+	return difficulties, err
+}
+
+// LockDifficulties создает недостающие записи сложности и блокирует все
+// записи заданий в порядке ID, чтобы избежать взаимных блокировок
+func (r *MasteryRepository) LockDifficulties(sourceType models.ReviewSource, skill string, sourceIDs []uint) ([]models.ItemDifficulty, error) {
+	var difficulties []models.ItemDifficulty
+	if len(sourceIDs) == 0 {
+		return difficulties, nil
+	}
+
+	rows := make([]models.ItemDifficulty, len(sourceIDs))
+	for i, id := range sourceIDs {
+		rows[i] = models.ItemDifficulty{SourceType: sourceType, SourceID: id, Skill: skill}
+	}
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("source_type = ? AND source_id IN ?", sourceType, sourceIDs).
+		Order("id").
+		Find(&difficulties).Error
+	return difficulties, err
+}
+
+func (r *MasteryRepository) SaveDifficulties(difficulties []models.ItemDifficulty) error {
+	if len(difficulties) == 0 {
+		return nil
+	}
+	return r.db.Save(&difficulties).Error
+}
+
+// FindByUsers рейтинги учеников по всем навыкам (для тепловой карты класса)
+func (r *MasteryRepository) FindByUsers(userIDs []uint) ([]models.SkillMastery, error) {
+	var masteries []models.SkillMastery
+	if len(userIDs) == 0 {
+		return masteries, nil
+	}
+	err := r.db.Where("user_id IN ?", userIDs).
+		Order("user_id, skill").
+		Find(&masteries).Error
+	return masteries, err
+}
@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"englishlessons.back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type MediaRepository struct {
+	db *gorm.DB
+}
+
+func NewMediaRepository(db *gorm.DB) *MediaRepository {
+	return &MediaRepository{db: db}
+}
+
+func (r *MediaRepository) Create(file *models.MediaFile) error {
+	return r.db.Create(file).Error
+}
+
+func (r *MediaRepository) FindByID(id uint) (*models.MediaFile, error) {
+	var file models.MediaFile
+	if err := r.db.First(&file, id).Error; err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+func (r *MediaRepository) FindByKey(key string) (*models.MediaFile, error) {
+	var file models.MediaFile
+	if err := r.db.Where("key = ?", key).First(&file).Error; err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// FindAll файлы одного вида, новые первыми
+func (r *MediaRepository) FindAll(kind models.MediaKind, limit, offset int) ([]models.MediaFile, int64, error) {
+	var files []models.MediaFile
+	var total int64
+	query := r.db.Model(&models.MediaFile{}).Where("kind = ?", kind)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&files).Error
+	return files, total, err
+}
+
+// SetQuestionAudio привязывает аудио к вопросу; nil отвязывает
+func (r *MediaRepository) SetQuestionAudio(questionID uint, mediaID *uint) error {
+	return r.db.Model(&models.Question{}).Where("id = ?", questionID).Update("audio_id", mediaID).Error
+}
+
+// CreateBlock добавляет блок в конец урока
+func (r *MediaRepository) CreateBlock(block *models.LessonBlock) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var maxOrder int
+		err := tx.Model(&models.LessonBlock{}).
+			Where("lesson_id = ?", block.LessonID).
+			Select("COALESCE(MAX(\"order\"), 0)").
+			Scan(&maxOrder).Error
+		if err != nil {
+			return err
+		}
+		block.Order = maxOrder + 1
+		return tx.Create(block).Error
+	})
+}
+
+func (r *MediaRepository) FindBlock(id uint) (*models.LessonBlock, error) {
+	var block models.LessonBlock
+	if err := r.db.First(&block, id).Error; err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+func (r *MediaRepository) DeleteBlock(id uint) error {
+	return r.db.Delete(&models.LessonBlock{}, id).Error
+}
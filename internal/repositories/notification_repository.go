@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"time"
+
+	"englishlessons.back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+func (r *NotificationRepository) Create(notification *models.Notification) error {
+	return r.db.Create(notification).Error
+}
+
+// CreateBatch сохраняет одинаковые уведомления нескольким пользователям
+func (r *NotificationRepository) CreateBatch(notifications []models.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.db.Create(&notifications).Error
+}
+
+// FindByUser уведомления пользователя, новые первыми
+func (r *NotificationRepository) FindByUser(userID uint, unreadOnly bool, limit, offset int) ([]models.Notification, int64, error) {
+	query := r.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var notifications []models.Notification
+	err := query.Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&notifications).Error
+	return notifications, total, err
+}
+
+func (r *NotificationRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead отмечает прочитанными уведомления пользователя; чужие id игнорируются
+func (r *NotificationRepository) MarkRead(userID uint, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND id IN ? AND read_at IS NULL", userID, ids).
+		Update("read_at", time.Now()).Error
+}
+
+func (r *NotificationRepository) MarkAllRead(userID uint) error {
+	return r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now()).Error
+}
@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"englishlessons.back/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PlacementRepository struct {
+	db *gorm.DB
+}
+
+func NewPlacementRepository(db *gorm.DB) *PlacementRepository {
+	return &PlacementRepository{db: db}
+}
+
+// DB возвращает *gorm.DB для запуска транзакций в сервисе
+func (r *PlacementRepository) DB() *gorm.DB {
+	return r.db
+}
+
+// WithTx возвращает репозиторий, работающий внутри транзакции tx
+func (r *PlacementRepository) WithTx(tx *gorm.DB) *PlacementRepository {
+	return &PlacementRepository{db: tx}
+}
+
+func (r *PlacementRepository) Create(test *models.PlacementTest) error {
+	return r.db.Create(test).Error
+}
+
+func (r *PlacementRepository) Update(test *models.PlacementTest) error {
+	return r.db.Omit(clause.Associations).Save(test).Error
+}
+
+// FindByID тест с учеником и ответами (для учителя)
+func (r *PlacementRepository) FindByID(id uint) (*models.PlacementTest, error) {
+	var test models.PlacementTest
+	err := r.db.Preload("User").
+		Preload("Answers", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).
+		Preload("Answers.Question").
+		First(&test, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &test, nil
+}
+
+// FindByIDForUpdate блокирует тест, чтобы на один вопрос нельзя было ответить дважды
+func (r *PlacementRepository) FindByIDForUpdate(id uint) (*models.PlacementTest, error) {
+	var test models.PlacementTest
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&test, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &test, nil
+}
+
+// FindLatestByUser последний тест ученика
+func (r *PlacementRepository) FindLatestByUser(userID uint) (*models.PlacementTest, error) {
+	var test models.PlacementTest
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC, id DESC").First(&test).Error
+	if err != nil {
+		return nil, err
+	}
+	return &test, nil
+}
+
+// FindByClass тесты учеников класса, новые первыми; пустая буква - вся параллель
+func (r *PlacementRepository) FindByClass(level int, levelLetter string) ([]models.PlacementTest, error) {
+	students := r.db.Model(&models.User{}).
+		Select("id").
+		Where("role = ? AND level = ?", models.RoleStudent, level)
+	if levelLetter != "" {
+		students = students.Where("level_letter ILIKE ?", levelLetter)
+	}
+
+	var tests []models.PlacementTest
+	err := r.db.Preload("User").
+		Where("user_id IN (?)", students).
+		Order("created_at DESC, id DESC").
+		Find(&tests).Error
+	return tests, err
+}
+
+func (r *PlacementRepository) CreateAnswer(answer *models.PlacementAnswer) error {
+	return r.db.Create(answer).Error
+}
+
+func (r *PlacementRepository) FindAnswers(testID uint) ([]models.PlacementAnswer, error) {
+	var answers []models.PlacementAnswer
+	err := r.db.Where("placement_test_id = ?", testID).Order("id").Find(&answers).Error
+	return answers, err
+}
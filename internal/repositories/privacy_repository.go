@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"englishlessons.back/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PrivacyRepository struct {
+	db *gorm.DB
+}
+
+func NewPrivacyRepository(db *gorm.DB) *PrivacyRepository {
+	return &PrivacyRepository{db: db}
+}
+
+func (r *PrivacyRepository) FindAllClassSettings() ([]models.ClassPrivacySettings, error) {
+	var settings []models.ClassPrivacySettings
+	err := r.db.Order("level, level_letter").Find(&settings).Error
+	return settings, err
+}
+
+// SaveClassSettings создает или заменяет настройки класса
+func (r *PrivacyRepository) SaveClassSettings(settings *models.ClassPrivacySettings) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "level"}, {Name: "level_letter"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name_mode", "hide_bottom_half", "updated_by", "updated_at"}),
+	}).Create(settings).Error
+}
@@ -1,8 +1,11 @@
 package repositories
 
 import (
+	"time"
+
 	"englishlessons.back/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProgressRepository struct {
@@ -18,6 +21,23 @@ func (r *ProgressRepository) DB() *gorm.DB {
 	return r.db
 }
 
+// WithTx возвращает репозиторий, работающий внутри транзакции tx
+func (r *ProgressRepository) WithTx(tx *gorm.DB) *ProgressRepository {
+	return &ProgressRepository{db: tx}
+}
+
+// FindByUserAndLessonForUpdate блокирует строку прогресса до конца транзакции
+func (r *ProgressRepository) FindByUserAndLessonForUpdate(userID, lessonID uint) (*models.LessonProgress, error) {
+	var progress models.LessonProgress
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND lesson_id = ?", userID, lessonID).
+		First(&progress).Error
+	if err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
 func (r *ProgressRepository) FindByUserAndLesson(userID, lessonID uint) (*models.LessonProgress, error) {
 	var progress models.LessonProgress
 	err := r.db.Where("user_id = ? AND lesson_id = ?", userID, lessonID).First(&progress).Error
@@ -70,3 +90,59 @@ func (r *ProgressRepository) Update(progress *models.LessonProgress) error {
 	return r.db.Save(progress).Error
 }
 
+// SetUnlocked открывает ученику уроки lessonIDs и снимает отметку с остальных.
+// Для еще не начатых уроков создается пустая строка прогресса.
+func (r *ProgressRepository) SetUnlocked(userID uint, lessonIDs []uint) error {
+	query := r.db.Model(&models.LessonProgress{}).Where("user_id = ? AND is_unlocked = ?", userID, true)
+	if len(lessonIDs) > 0 {
+		query = query.Where("lesson_id NOT IN ?", lessonIDs)
+	}
+	if err := query.Update("is_unlocked", false).Error; err != nil {
+		return err
+	}
+	if len(lessonIDs) == 0 {
+		return nil
+	}
+
+	rows := make([]models.LessonProgress, len(lessonIDs))
+	for i, lessonID := range lessonIDs {
+		rows[i] = models.LessonProgress{UserID: userID, LessonID: lessonID, IsUnlocked: true}
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "lesson_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"is_unlocked": true}),
+	}).Create(&rows).Error
+}
+
+// FindGradebookEntries лучшие результаты учеников по урокам. Без периода
+// берется LessonProgress.BestPercentage, с периодом - лучшая попытка внутри
+// [from, to). Уроки без попыток (открытые тестом на уровень) не попадают.
+func (r *ProgressRepository) FindGradebookEntries(userIDs []uint, from, to *time.Time) ([]models.GradebookEntry, error) {
+	var entries []models.GradebookEntry
+	if len(userIDs) == 0 {
+		return entries, nil
+	}
+
+	if from == nil && to == nil {
+		err := r.db.Model(&models.LessonProgress{}).
+			Select("user_id, lesson_id, best_percentage, attempts_count, last_attempt_at").
+			Where("user_id IN ? AND attempts_count > 0", userIDs).
+			Scan(&entries).Error
+		return entries, err
+	}
+
+	query := r.db.Model(&models.TestAttempt{}).
+		Select(`user_id, lesson_id,
+			MAX(percentage) AS best_percentage,
+			COUNT(*) AS attempts_count,
+			MAX(created_at) AS last_attempt_at`).
+		Where("user_id IN ?", userIDs)
+	if from != nil {
+		query = query.Where("created_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("created_at < ?", *to)
+	}
+	err := query.Group("user_id, lesson_id").Scan(&entries).Error
+	return entries, err
+}
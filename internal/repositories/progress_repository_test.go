@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"testing"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/testdb"
+)
+
+func TestSetUnlockedMovesBoundary(t *testing.T) {
+	db := testdb.Open(t)
+	seeded := testdb.Seed(t, db, 1, 4)
+	repo := NewProgressRepository(db)
+	userID, lessonIDs := seeded.Students[0].ID, seeded.LessonIDs
+
+	// Урок с попытками: после сдвига границы результат должен сохраниться
+	if err := repo.Create(&models.LessonProgress{
+		UserID: userID, LessonID: lessonIDs[2], BestScore: 8, BestPercentage: 80, AttemptsCount: 2, IsCompleted: true,
+	}); err != nil {
+		t.Fatalf("create progress: %v", err)
+	}
+
+	unlocked := func() map[uint]bool {
+		t.Helper()
+		var rows []models.LessonProgress
+		if err := db.Where("user_id = ?", userID).Find(&rows).Error; err != nil {
+			t.Fatalf("progress: %v", err)
+		}
+		result := make(map[uint]bool, len(rows))
+		for _, row := range rows {
+			result[row.LessonID] = row.IsUnlocked
+		}
+		return result
+	}
+
+	if err := repo.SetUnlocked(userID, lessonIDs[:3]); err != nil {
+		t.Fatalf("SetUnlocked: %v", err)
+	}
+	got := unlocked()
+	for i, lessonID := range lessonIDs {
+		if got[lessonID] != (i < 3) {
+			t.Fatalf("after unlocking 3 lessons: lesson %d unlocked = %v", i, got[lessonID])
+		}
+	}
+
+	// Учитель понизил уровень: лишние уроки снова закрыты, попытки не тронуты
+	if err := repo.SetUnlocked(userID, lessonIDs[:1]); err != nil {
+		t.Fatalf("SetUnlocked: %v", err)
+	}
+	got = unlocked()
+	for i, lessonID := range lessonIDs {
+		if got[lessonID] != (i < 1) {
+			t.Fatalf("after lowering: lesson %d unlocked = %v", i, got[lessonID])
+		}
+	}
+	progress, err := repo.FindByUserAndLesson(userID, lessonIDs[2])
+	if err != nil {
+		t.Fatalf("FindByUserAndLesson: %v", err)
+	}
+	if progress.AttemptsCount != 2 || progress.BestScore != 8 || !progress.IsCompleted {
+		t.Fatalf("progress overwritten: %+v", progress)
+	}
+
+	if err := repo.SetUnlocked(userID, nil); err != nil {
+		t.Fatalf("SetUnlocked(nil): %v", err)
+	}
+	for lessonID, isUnlocked := range unlocked() {
+		if isUnlocked {
+			t.Fatalf("lesson %d still unlocked", lessonID)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"englishlessons.back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ReviewRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+func (r *ReviewRepository) FindByID(id uint) (*models.ReviewItem, error) {
+	var item models.ReviewItem
+	if err := r.db.First(&item, id).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *ReviewRepository) FindBySource(userID uint, sourceType models.ReviewSource, sourceID uint) (*models.ReviewItem, error) {
+	var item models.ReviewItem
+	err := r.db.Where("user_id = ? AND source_type = ? AND source_id = ?", userID, sourceType, sourceID).
+		First(&item).Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *ReviewRepository) Save(item *models.ReviewItem) error {
+	return r.db.Save(item).Error
+}
+
+// FindDue элементы, срок повторения которых наступил к дню today (YYYY-MM-DD)
+func (r *ReviewRepository) FindDue(userID uint, today string, limit int) ([]models.ReviewItem, error) {
+	var items []models.ReviewItem
+	err := r.db.Where("user_id = ? AND due_day <= ?", userID, today).
+		Order("due_day, id").
+		Limit(limit).
+		Find(&items).Error
+	return items, err
+}
+
+func (r *ReviewRepository) CountDue(userID uint, today string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.ReviewItem{}).
+		Where("user_id = ? AND due_day <= ?", userID, today).
+		Count(&count).Error
+	return count, err
+}
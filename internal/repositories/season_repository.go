@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"time"
+
+	"englishlessons.back/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SeasonRepository struct {
+	db *gorm.DB
+}
+
+func NewSeasonRepository(db *gorm.DB) *SeasonRepository {
+	return &SeasonRepository{db: db}
+}
+
+func (r *SeasonRepository) Create(season *models.Season) error {
+	return r.db.Create(season).Error
+}
+
+func (r *SeasonRepository) FindAll() ([]models.Season, error) {
+	var seasons []models.Season
+	err := r.db.Order("starts_at DESC").Find(&seasons).Error
+	return seasons, err
+}
+
+func (r *SeasonRepository) FindByID(id uint) (*models.Season, error) {
+	var season models.Season
+	err := r.db.First(&season, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &season, nil
+}
+
+// FindCurrent сезон, идущий в момент at (при пересечении - начавшийся позже)
+func (r *SeasonRepository) FindCurrent(at time.Time) (*models.Season, error) {
+	var season models.Season
+	err := r.db.Where("starts_at <= ? AND ends_at > ?", at, at).
+		Order("starts_at DESC").
+		First(&season).Error
+	if err != nil {
+		return nil, err
+	}
+	return &season, nil
+}
+
+// FindExpiredOpen завершившиеся сезоны, итоги которых еще не сохранены
+func (r *SeasonRepository) FindExpiredOpen(at time.Time) ([]models.Season, error) {
+	var seasons []models.Season
+	err := r.db.Where("ends_at <= ? AND closed_at IS NULL", at).
+		Order("ends_at").
+		Find(&seasons).Error
+	return seasons, err
+}
+
+// SaveStandings сохраняет итоги сезона и отмечает его закрытым в одной транзакции
+func (r *SeasonRepository) SaveStandings(season *models.Season, standings []models.SeasonStanding) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Блокировка строки сезона: одновременное закрытие из фоновой задачи
+		// и учителем иначе вставит места дважды и упадет на idx_season_user
+		var locked models.Season
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, season.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("season_id = ?", season.ID).Delete(&models.SeasonStanding{}).Error; err != nil {
+			return err
+		}
+		if len(standings) > 0 {
+			if err := tx.CreateInBatches(standings, 200).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(season).Update("closed_at", season.ClosedAt).Error
+	})
+}
+
+// FindStandingsByUserID история мест ученика по сезонам в хронологическом порядке
+func (r *SeasonRepository) FindStandingsByUserID(userID uint) ([]models.SeasonStanding, error) {
+	var standings []models.SeasonStanding
+	err := r.db.Preload("Season").
+		Joins("JOIN seasons ON seasons.id = season_standings.season_id").
+		Where("season_standings.user_id = ?", userID).
+		Order("seasons.starts_at").
+		Find(&standings).Error
+	return standings, err
+}
+
+// CountStandings количество участников каждого сезона
+func (r *SeasonRepository) CountStandings(seasonIDs []uint) (map[uint]int, error) {
+	counts := make(map[uint]int, len(seasonIDs))
+	if len(seasonIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		SeasonID uint
+		Count    int
+	}
+	err := r.db.Model(&models.SeasonStanding{}).
+		Select("season_id, COUNT(*) as count").
+		Where("season_id IN ?", seasonIDs).
+		Group("season_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.SeasonID] = row.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/testdb"
+)
+
+func TestSaveStandingsReplacesPreviousResults(t *testing.T) {
+	db := testdb.Open(t)
+	students := testdb.Seed(t, db, 3, 1).Students
+	repo := NewSeasonRepository(db)
+
+	now := time.Now()
+	season := &models.Season{Name: "Осень", StartsAt: now.AddDate(0, -3, 0), EndsAt: now.AddDate(0, 0, -1)}
+	if err := repo.Create(season); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	expired, err := repo.FindExpiredOpen(now)
+	if err != nil || !containsSeason(expired, season.ID) {
+		t.Fatalf("FindExpiredOpen = %v, %v; want the new season", expired, err)
+	}
+
+	standings := func(n int) []models.SeasonStanding {
+		result := make([]models.SeasonStanding, n)
+		for i := range result {
+			result[i] = models.SeasonStanding{SeasonID: season.ID, UserID: students[i].ID, Rank: i + 1, ClassRank: 1}
+		}
+		return result
+	}
+
+	// Повторное закрытие заменяет места, а не падает на idx_season_user
+	for _, n := range []int{3, 2} {
+		closedAt := time.Now()
+		season.ClosedAt = &closedAt
+		if err := repo.SaveStandings(season, standings(n)); err != nil {
+			t.Fatalf("SaveStandings(%d): %v", n, err)
+		}
+		counts, err := repo.CountStandings([]uint{season.ID})
+		if err != nil {
+			t.Fatalf("CountStandings: %v", err)
+		}
+		if counts[season.ID] != n {
+			t.Fatalf("standings = %d, want %d", counts[season.ID], n)
+		}
+	}
+
+	expired, err = repo.FindExpiredOpen(now)
+	if err != nil || containsSeason(expired, season.ID) {
+		t.Fatalf("closed season still open: %v, %v", expired, err)
+	}
+}
+
+func containsSeason(seasons []models.Season, id uint) bool {
+	for _, season := range seasons {
+		if season.ID == id {
+			return true
+		}
+	}
+	return false
+}
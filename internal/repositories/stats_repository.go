@@ -0,0 +1,269 @@
+package repositories
+
+import (
+	"errors"
+
+	"englishlessons.back/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StatsRepository struct {
+	db *gorm.DB
+}
+
+func NewStatsRepository(db *gorm.DB) *StatsRepository {
+	return &StatsRepository{db: db}
+}
+
+// progressCountersSelect агрегаты прогресса уроков в терминах StatsCounters
+const progressCountersSelect = `user_id,
+	COALESCE(SUM(best_score), 0) AS total_points,
+	COUNT(*) FILTER (WHERE attempts_count > 0) AS lessons_started,
+	COUNT(*) FILTER (WHERE is_completed) AS completed_lessons,
+	COALESCE(SUM(best_percentage) FILTER (WHERE is_completed), 0) AS completed_percentage_sum,
+	COUNT(*) FILTER (WHERE best_percentage > 0) AS scored_lessons,
+	COALESCE(SUM(best_percentage) FILTER (WHERE best_percentage > 0), 0) AS scored_percentage_sum,
+	COALESCE(SUM(attempts_count), 0) AS total_attempts`
+
+const gameCountersSelect = `user_id,
+	COUNT(*) AS games_played,
+	COALESCE(SUM(score), 0) AS game_score_sum`
+
+type countersRow struct {
+	UserID uint
+	models.StatsCounters
+}
+
+// Compute считает показатели по исходным данным. Если userIDs пуст - для всех учеников.
+func (r *StatsRepository) Compute(userIDs []uint) (map[uint]models.StatsCounters, error) {
+	return r.compute(r.db, userIDs)
+}
+
+func (r *StatsRepository) compute(db *gorm.DB, userIDs []uint) (map[uint]models.StatsCounters, error) {
+	progressQuery := db.Model(&models.LessonProgress{}).Select(progressCountersSelect).Group("user_id")
+	gamesQuery := db.Model(&models.GameResult{}).Select(gameCountersSelect).Group("user_id")
+	if len(userIDs) > 0 {
+		progressQuery = progressQuery.Where("user_id IN ?", userIDs)
+		gamesQuery = gamesQuery.Where("user_id IN ?", userIDs)
+	}
+
+	var progressRows []countersRow
+	if err := progressQuery.Scan(&progressRows).Error; err != nil {
+		return nil, err
+	}
+	var gameRows []countersRow
+	if err := gamesQuery.Scan(&gameRows).Error; err != nil {
+		return nil, err
+	}
+
+	counters := make(map[uint]models.StatsCounters, len(progressRows))
+	for _, row := range progressRows {
+		counters[row.UserID] = row.StatsCounters
+	}
+	for _, row := range gameRows {
+		c := counters[row.UserID]
+		c.GamesPlayed = row.GamesPlayed
+		c.GameScoreSum = row.GameScoreSum
+		counters[row.UserID] = c
+	}
+	return counters, nil
+}
+
+func (r *StatsRepository) FindUser(userID uint) (*models.UserStats, error) {
+	var stats models.UserStats
+	if err := r.db.First(&stats, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+func (r *StatsRepository) FindUsers(userIDs []uint) (map[uint]*models.UserStats, error) {
+	result := make(map[uint]*models.UserStats, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	var stats []models.UserStats
+	if err := r.db.Where("user_id IN ?", userIDs).Find(&stats).Error; err != nil {
+		return nil, err
+	}
+	for i := range stats {
+		result[stats[i].UserID] = &stats[i]
+	}
+	return result, nil
+}
+
+func (r *StatsRepository) FindAllUsers() ([]models.UserStats, error) {
+	var stats []models.UserStats
+	err := r.db.Order("user_id").Find(&stats).Error
+	return stats, err
+}
+
+// FindClass агрегат класса; пустая буква - сумма по всем параллелям уровня
+func (r *StatsRepository) FindClass(level int, levelLetter string) (*models.ClassStats, error) {
+	if levelLetter != "" {
+		var stats models.ClassStats
+		err := r.db.Where("level = ? AND level_letter = ?", level, levelLetter).First(&stats).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.ClassStats{Level: level, LevelLetter: levelLetter}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &stats, nil
+	}
+
+	var classes []models.ClassStats
+	if err := r.db.Where("level = ?", level).Find(&classes).Error; err != nil {
+		return nil, err
+	}
+	total := &models.ClassStats{Level: level}
+	for _, class := range classes {
+		total.StatsCounters = total.StatsCounters.Add(class.StatsCounters)
+	}
+	return total, nil
+}
+
+func (r *StatsRepository) FindAllClasses() ([]models.ClassStats, error) {
+	var stats []models.ClassStats
+	err := r.db.Order("level, level_letter").Find(&stats).Error
+	return stats, err
+}
+
+// statsLockNamespace первый ключ рекомендательной блокировки пересчета
+// статистики ученика (второй ключ - ID ученика)
+const statsLockNamespace = 32032
+
+// Refresh пересчитывает строку ученика и переносит разницу в агрегат класса.
+// Если ученик сменил класс, его показатели вычитаются из прежнего класса
+// и добавляются в новый. Строка ученика блокируется до пересчета, поэтому
+// параллельные пересчеты одного ученика идут по очереди и каждый видит
+// результат предыдущего.
+func (r *StatsRepository) Refresh(user *models.User) (*models.UserStats, error) {
+	var stats models.UserStats
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		previous, exists, err := lockUserStats(tx, user.ID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			// Строки еще нет, и блокировать нечего: первые пересчеты ученика
+			// упорядочиваются рекомендательной блокировкой, после нее строку
+			// мог создать пересчет, который ждал раньше
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?, ?::int)", statsLockNamespace, user.ID).Error; err != nil {
+				return err
+			}
+			if previous, exists, err = lockUserStats(tx, user.ID); err != nil {
+				return err
+			}
+		}
+
+		fresh, err := r.compute(tx, []uint{user.ID})
+		if err != nil {
+			return err
+		}
+
+		stats = models.UserStats{
+			UserID:        user.ID,
+			Level:         user.Level,
+			LevelLetter:   user.LevelLetter,
+			StatsCounters: fresh[user.ID],
+		}
+
+		sameClass := exists && sameLevel(previous.Level, user.Level) && previous.LevelLetter == user.LevelLetter
+		if sameClass {
+			if err := addToClass(tx, user.Level, user.LevelLetter, stats.StatsCounters.Sub(previous.StatsCounters)); err != nil {
+				return err
+			}
+		} else {
+			if exists {
+				if err := addToClass(tx, previous.Level, previous.LevelLetter, models.StatsCounters{}.Sub(previous.StatsCounters)); err != nil {
+					return err
+				}
+			}
+			if err := addToClass(tx, user.Level, user.LevelLetter, stats.StatsCounters); err != nil {
+				return err
+			}
+		}
+
+		return tx.Save(&stats).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// lockUserStats читает строку ученика с блокировкой до конца транзакции
+func lockUserStats(tx *gorm.DB, userID uint) (models.UserStats, bool, error) {
+	var stats models.UserStats
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&stats, "user_id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return stats, false, nil
+	}
+	return stats, err == nil, err
+}
+
+// addToClass атомарно прибавляет delta к агрегату класса, создавая его при необходимости
+func addToClass(tx *gorm.DB, level *int, levelLetter string, delta models.StatsCounters) error {
+	if level == nil || delta == (models.StatsCounters{}) {
+		return nil
+	}
+
+	columns := []string{
+		"total_points", "lessons_started", "completed_lessons", "completed_percentage_sum",
+		"scored_lessons", "scored_percentage_sum", "total_attempts", "games_played", "game_score_sum",
+	}
+	assignments := make(map[string]interface{}, len(columns)+1)
+	for _, column := range columns {
+		assignments[column] = gorm.Expr("class_stats." + column + " + EXCLUDED." + column)
+	}
+	assignments["updated_at"] = gorm.Expr("EXCLUDED.updated_at")
+
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "level"}, {Name: "level_letter"}},
+		DoUpdates: clause.Assignments(assignments),
+	}).Create(&models.ClassStats{
+		Level:         *level,
+		LevelLetter:   levelLetter,
+		StatsCounters: delta,
+	}).Error
+}
+
+func sameLevel(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// ReplaceAll полностью заменяет содержимое кэша
+func (r *StatsRepository) ReplaceAll(users []models.UserStats, classes []models.ClassStats) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.UserStats{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("1 = 1").Delete(&models.ClassStats{}).Error; err != nil {
+			return err
+		}
+		if len(users) > 0 {
+			if err := tx.CreateInBatches(users, 500).Error; err != nil {
+				return err
+			}
+		}
+		if len(classes) > 0 {
+			if err := tx.CreateInBatches(classes, 500).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *StatsRepository) CountUsers() (int64, error) {
+	var count int64
+	err := r.db.Model(&models.UserStats{}).Count(&count).Error
+	return count, err
+}
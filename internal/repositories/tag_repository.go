@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"englishlessons.back/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type TagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+// FindAll теги раздела kind; пустой kind - все теги
+func (r *TagRepository) FindAll(kind models.TagKind) ([]models.Tag, error) {
+	var tags []models.Tag
+	query := r.db
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	err := query.Order("kind, name").Find(&tags).Error
+	return tags, err
+}
+
+func (r *TagRepository) FindByID(id uint) (*models.Tag, error) {
+	var tag models.Tag
+	if err := r.db.First(&tag, id).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *TagRepository) FindByIDs(ids []uint) ([]models.Tag, error) {
+	var tags []models.Tag
+	if len(ids) == 0 {
+		return tags, nil
+	}
+	err := r.db.Where("id IN ?", ids).Order("kind, name").Find(&tags).Error
+	return tags, err
+}
+
+func (r *TagRepository) FindByKindAndName(kind models.TagKind, name string) (*models.Tag, error) {
+	var tag models.Tag
+	if err := r.db.Where("kind = ? AND name ILIKE ?", kind, name).First(&tag).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *TagRepository) Create(tag *models.Tag) error {
+	return r.db.Create(tag).Error
+}
+
+// Delete удаляет тег вместе с его привязками
+func (r *TagRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, table := range []string{"lesson_tags", "question_tags", "game_item_tags"} {
+			if err := tx.Exec("DELETE FROM "+table+" WHERE tag_id = ?", id).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&models.Tag{}, id).Error
+	})
+}
+
+// ReplaceTags заменяет теги урока, вопроса или задания игры
+func (r *TagRepository) ReplaceTags(owner interface{}, tags []models.Tag) error {
+	return r.db.Model(owner).Association("Tags").Replace(tags)
+}
+
+// FindChoiceQuestionIDsByTag вопросы с выбором ответа из активных уроков с
+// тегом: собственным или унаследованным от урока, если у вопроса нет своего
+// тега того же раздела
+func (r *TagRepository) FindChoiceQuestionIDsByTag(tag *models.Tag) ([]uint, error) {
+	var ids []uint
+	err := r.db.Table("questions q").
+		Joins("JOIN lessons l ON l.id = q.lesson_id AND l.is_active AND l.deleted_at IS NULL").
+		Where("q.type = ?", models.QuestionChoice).
+		Where(`(q.id IN (SELECT question_id FROM question_tags WHERE tag_id = ?)
+			OR (q.lesson_id IN (SELECT lesson_id FROM lesson_tags WHERE tag_id = ?)
+				AND NOT EXISTS (SELECT 1 FROM question_tags qt JOIN tags t ON t.id = qt.tag_id
+					WHERE qt.question_id = q.id AND t.kind = ?)))`, tag.ID, tag.ID, tag.Kind).
+		Order("q.id").
+		Pluck("q.id", &ids).Error
+	return ids, err
+}
+
+// CreateAnswerRecords сохраняет ответы на отдельные вопросы и задания
+func (r *TagRepository) CreateAnswerRecords(records []models.AnswerRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(records, 100).Error
+}
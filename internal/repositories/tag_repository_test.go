@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"reflect"
+	"testing"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/testdb"
+
+	"gorm.io/gorm"
+)
+
+// taggedContent урок с темой past и вопросами: q1 наследует тему урока,
+// q2 задает свою тему present и навык reading, q3 — ввод ответа.
+// Задание игры отмечено темой present.
+type taggedContent struct {
+	past, present, reading models.Tag
+	lesson                 models.Lesson
+	q1, q2, q3             models.Question
+	item                   models.GameItem
+}
+
+func seedTagged(t *testing.T, db *gorm.DB) *taggedContent {
+	t.Helper()
+	c := &taggedContent{
+		past:    models.Tag{Kind: models.TagKindTopic, Name: "test Past Simple"},
+		present: models.Tag{Kind: models.TagKindTopic, Name: "test Present Perfect"},
+		reading: models.Tag{Kind: models.TagKindSkill, Name: "test reading"},
+	}
+	testdb.Create(t, db, &c.past, &c.present, &c.reading)
+
+	c.lesson = models.Lesson{Title: "tagged lesson", Order: 2000000, IsActive: true, Tags: []models.Tag{c.past}}
+	testdb.Create(t, db, &c.lesson)
+	c.q1 = models.Question{LessonID: c.lesson.ID, Text: "q1", Order: 1}
+	c.q2 = models.Question{LessonID: c.lesson.ID, Text: "q2", Order: 2, Tags: []models.Tag{c.present, c.reading}}
+	c.q3 = models.Question{LessonID: c.lesson.ID, Text: "q3", Order: 3, Type: models.QuestionTyped}
+	c.item = models.GameItem{GameType: models.GameQuizShow, Level: 1, Payload: models.JSONMap{}, IsActive: true,
+		Tags: []models.Tag{c.present}}
+	testdb.Create(t, db, &c.q1, &c.q2, &c.q3, &c.item)
+	return c
+}
+
+func TestFindChoiceQuestionIDsByTag(t *testing.T) {
+	db := testdb.Open(t)
+	c := seedTagged(t, db)
+	repo := NewTagRepository(db)
+
+	tests := []struct {
+		tag  *models.Tag
+		want []uint
+	}{
+		// q2 задает свою тему, поэтому тему урока не наследует; q3 без выбора ответа
+		{&c.past, []uint{c.q1.ID}},
+		{&c.present, []uint{c.q2.ID}},
+		// Навык задан только у q2, у урока навыка нет
+		{&c.reading, []uint{c.q2.ID}},
+	}
+	for _, tt := range tests {
+		got, err := repo.FindChoiceQuestionIDsByTag(tt.tag)
+		if err != nil {
+			t.Fatalf("FindChoiceQuestionIDsByTag(%s): %v", tt.tag.Name, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FindChoiceQuestionIDsByTag(%s) = %v, want %v", tt.tag.Name, got, tt.want)
+		}
+	}
+}
+
+func TestTagStatsByUserAndClass(t *testing.T) {
+	db := testdb.Open(t)
+	c := seedTagged(t, db)
+	repo := NewAnalyticsRepository(db)
+
+	// Два ученика в отдельном классе 9Я, чтобы в статистику класса не попали чужие ответы
+	students := testdb.Seed(t, db, 2, 0).Students
+	level := 9
+	if err := db.Model(&models.User{}).Where("id IN ?", []uint{students[0].ID, students[1].ID}).
+		Updates(map[string]any{"level": level, "level_letter": "Я"}).Error; err != nil {
+		t.Fatalf("class: %v", err)
+	}
+
+	records := []models.AnswerRecord{
+		{UserID: students[0].ID, SourceType: models.ReviewSourceQuestion, SourceID: c.q1.ID, IsCorrect: true},
+		{UserID: students[0].ID, SourceType: models.ReviewSourceQuestion, SourceID: c.q2.ID, IsCorrect: false},
+		{UserID: students[0].ID, SourceType: models.ReviewSourceGameItem, SourceID: c.item.ID, IsCorrect: true},
+		{UserID: students[1].ID, SourceType: models.ReviewSourceQuestion, SourceID: c.q1.ID, IsCorrect: false},
+	}
+	if err := NewTagRepository(db).CreateAnswerRecords(records); err != nil {
+		t.Fatalf("CreateAnswerRecords: %v", err)
+	}
+
+	byName := func(stats []TagStats) map[string]TagStats {
+		result := make(map[string]TagStats, len(stats))
+		for _, stat := range stats {
+			result[stat.TagName] = stat
+		}
+		return result
+	}
+
+	stats, err := repo.TagStatsByUser(students[0].ID, models.TagKindTopic)
+	if err != nil {
+		t.Fatalf("TagStatsByUser: %v", err)
+	}
+	got := byName(stats)
+	// Вопрос q2 получает и свою тему, и тему урока (статистика учитывает оба тега)
+	if s := got[c.past.Name]; s.Answers != 2 || s.CorrectCount != 1 || s.Students != 1 {
+		t.Errorf("past = %+v, want 2 answers, 1 correct", s)
+	}
+	if s := got[c.present.Name]; s.Answers != 2 || s.CorrectCount != 1 {
+		t.Errorf("present = %+v, want question and game item", s)
+	}
+	if _, ok := got[c.reading.Name]; ok {
+		t.Error("skill tag returned for kind topic")
+	}
+
+	stats, err = repo.TagStatsByClass(level, "Я", "")
+	if err != nil {
+		t.Fatalf("TagStatsByClass: %v", err)
+	}
+	got = byName(stats)
+	if s := got[c.past.Name]; s.Answers != 3 || s.CorrectCount != 1 || s.Students != 2 {
+		t.Errorf("class past = %+v, want 3 answers from 2 students", s)
+	}
+	if s := got[c.reading.Name]; s.Answers != 1 || s.CorrectCount != 0 {
+		t.Errorf("class reading = %+v", s)
+	}
+}
@@ -16,6 +16,11 @@ func NewTestRepository(db *gorm.DB) *TestRepository {
 	return &TestRepository{db: db}
 }
 
+// WithTx возвращает репозиторий, работающий внутри транзакции tx
+func (r *TestRepository) WithTx(tx *gorm.DB) *TestRepository {
+	return &TestRepository{db: tx}
+}
+
 func (r *TestRepository) Create(attempt *models.TestAttempt) error {
 	return r.db.Create(attempt).Error
 }
@@ -32,6 +37,16 @@ func (r *TestRepository) FindByUserID(userID uint, lessonID *uint) ([]models.Tes
 	return attempts, nil
 }
 
+// FindByUserIDs попытки учеников по порядку: по ученику, затем по времени
+func (r *TestRepository) FindByUserIDs(userIDs []uint) ([]models.TestAttempt, error) {
+	var attempts []models.TestAttempt
+	if len(userIDs) == 0 {
+		return attempts, nil
+	}
+	err := r.db.Where("user_id IN ?", userIDs).Order("user_id, created_at, id").Find(&attempts).Error
+	return attempts, err
+}
+
 func (r *TestRepository) FindByLessonID(lessonID uint, userID *uint) ([]models.TestAttempt, error) {
 	var attempts []models.TestAttempt
 	query := r.db.Preload("User").Preload("Lesson").Where("lesson_id = ?", lessonID)
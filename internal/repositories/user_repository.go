@@ -51,6 +51,28 @@ func (r *UserRepository) FindStudents(filters map[string]interface{}) ([]models.
 	return users, err
 }
 
+// FindStudentIDsAfter страница ID учеников по возрастанию после afterID;
+// level и levelLetter ограничивают выборку классом
+func (r *UserRepository) FindStudentIDsAfter(afterID uint, limit int, level *int, levelLetter string) ([]uint, error) {
+	var ids []uint
+	query := r.db.Model(&models.User{}).Where("role = ? AND id > ?", models.RoleStudent, afterID)
+	if level != nil {
+		query = query.Where("level = ?", *level)
+		if levelLetter != "" {
+			query = query.Where("level_letter = ?", levelLetter)
+		}
+	}
+	err := query.Order("id").Limit(limit).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// FindTeachers все учителя по фамилии
+func (r *UserRepository) FindTeachers() ([]models.User, error) {
+	var users []models.User
+	err := r.db.Where("role = ?", models.RoleTeacher).Order("last_name, first_name").Find(&users).Error
+	return users, err
+}
+
 func (r *UserRepository) UpdatePassword(userID uint, hashedPassword string) error {
 	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("password", hashedPassword).Error
 }
@@ -59,3 +81,10 @@ func (r *UserRepository) UpdateProfile(userID uint, updates map[string]interface
 	return r.db.Model(&models.User{}).Where("id = ?", userID).Updates(updates).Error
 }
 
+// CountByAvatar сколько пользователей используют аватар
+func (r *UserRepository) CountByAvatar(avatar string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Where("avatar = ?", avatar).Count(&count).Error
+	return count, err
+}
+
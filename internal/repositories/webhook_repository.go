@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"time"
+
+	"englishlessons.back/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) Create(webhook *models.Webhook) error {
+	return r.db.Create(webhook).Error
+}
+
+func (r *WebhookRepository) Update(webhook *models.Webhook) error {
+	return r.db.Save(webhook).Error
+}
+
+func (r *WebhookRepository) FindByID(id uint) (*models.Webhook, error) {
+	var webhook models.Webhook
+	if err := r.db.First(&webhook, id).Error; err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *WebhookRepository) FindAll() ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	err := r.db.Order("id").Find(&webhooks).Error
+	return webhooks, err
+}
+
+// FindActive включенные вебхуки; их немного, подписки фильтруются в сервисе
+func (r *WebhookRepository) FindActive() ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	err := r.db.Where("is_active = ?", true).Order("id").Find(&webhooks).Error
+	return webhooks, err
+}
+
+// Delete удаляет вебхук вместе с журналом доставок
+func (r *WebhookRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", id).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.Webhook{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (r *WebhookRepository) CreateDeliveries(deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.Create(&deliveries).Error
+}
+
+func (r *WebhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	return r.db.Save(delivery).Error
+}
+
+func (r *WebhookRepository) FindDelivery(id uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := r.db.First(&delivery, id).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// FindDeliveries журнал доставок вебхука, новые первыми; status пустой - все
+func (r *WebhookRepository) FindDeliveries(webhookID uint, status models.WebhookDeliveryStatus, limit, offset int) ([]models.WebhookDelivery, int64, error) {
+	query := r.db.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", webhookID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deliveries []models.WebhookDelivery
+	err := query.Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&deliveries).Error
+	return deliveries, total, err
+}
+
+// ClaimDue забирает доставки, которым пора отправляться, и откладывает их на
+// lease: если обработчик упадет посреди отправки, доставка вернется в очередь.
+// SKIP LOCKED не дает нескольким экземплярам сервера взять одну доставку.
+func (r *WebhookRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.WebhookPending, now).
+			Order("next_attempt_at, id").
+			Limit(limit).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(deliveries))
+		for i := range deliveries {
+			ids[i] = deliveries[i].ID
+		}
+		return tx.Model(&models.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	return deliveries, err
+}
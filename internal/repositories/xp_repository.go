@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"englishlessons.back/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type XPRepository struct {
+	db *gorm.DB
+}
+
+func NewXPRepository(db *gorm.DB) *XPRepository {
+	return &XPRepository{db: db}
+}
+
+// Create добавляет запись в журнал. Возвращает false, если опыт за этот
+// источник уже начислялся.
+func (r *XPRepository) Create(entry *models.XPEntry) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(entry)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *XPRepository) FindByUserID(userID uint, limit, offset int) ([]models.XPEntry, int64, error) {
+	var entries []models.XPEntry
+	var total int64
+
+	query := r.db.Model(&models.XPEntry{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&entries).Error
+	return entries, total, err
+}
+
+func (r *XPRepository) TotalByUserID(userID uint) (int, error) {
+	var total int
+	err := r.db.Model(&models.XPEntry{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("user_id = ?", userID).
+		Scan(&total).Error
+	return total, err
+}
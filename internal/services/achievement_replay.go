@@ -0,0 +1,164 @@
+package services
+
+import (
+	"englishlessons.back/internal/models"
+	"time"
+)
+
+// Значок, созданный или измененный учителем, выдается и за прошлые успехи.
+// Метрики прошлых событий восстанавливаются по исходным таблицам
+// (test_attempts, game_results) так же, как их считают OnTestSubmitted и
+// OnGameFinished в момент события; журнал achievement_events для этого не
+// нужен, его могло и не быть на момент попытки.
+
+// testReplay проигрывает попытки тестов одного ученика по порядку,
+// обновляя прогресс по урокам как applyAttemptToProgress
+type testReplay struct {
+	lessons     []models.Lesson
+	lessonOrder map[uint]int
+	progress    map[uint]*models.LessonProgress
+	completed   int64
+}
+
+// newTestReplay lessons — активные уроки по Order, lessonOrder — порядок
+// всех уроков, включая отключенные
+func newTestReplay(lessons []models.Lesson, lessonOrder map[uint]int) *testReplay {
+	return &testReplay{
+		lessons:     lessons,
+		lessonOrder: lessonOrder,
+		progress:    make(map[uint]*models.LessonProgress),
+	}
+}
+
+// apply учитывает попытку и возвращает метрики события test_submitted
+func (r *testReplay) apply(attempt *models.TestAttempt) map[string]interface{} {
+	progress, ok := r.progress[attempt.LessonID]
+	if !ok {
+		progress = &models.LessonProgress{UserID: attempt.UserID, LessonID: attempt.LessonID}
+		r.progress[attempt.LessonID] = progress
+	}
+
+	isFirstAttempt := progress.AttemptsCount == 0
+	progress.AttemptsCount++
+	if isFirstAttempt || attempt.Score > progress.BestScore {
+		progress.BestScore = attempt.Score
+		progress.BestPercentage = attempt.Percentage
+	}
+	if attempt.IsPassed && !progress.IsCompleted {
+		progress.IsCompleted = true
+		r.completed++
+	}
+
+	progressList := make([]models.LessonProgress, 0, len(r.progress))
+	for _, p := range r.progress {
+		progressList = append(progressList, *p)
+	}
+
+	return map[string]interface{}{
+		"lesson_id":          attempt.LessonID,
+		"percentage":         attempt.Percentage,
+		"score":              attempt.Score,
+		"is_passed":          attempt.IsPassed,
+		"is_first_attempt":   isFirstAttempt,
+		"lesson_order":       r.lessonOrder[attempt.LessonID],
+		"attempts_on_lesson": progress.AttemptsCount,
+		"total_lessons":      int64(len(r.lessons)),
+		"completed_lessons":  r.completed,
+		"high_score_streak":  highScoreStreak(r.lessons, progressList),
+	}
+}
+
+// gameReplay проигрывает результаты игр одного ученика по порядку
+type gameReplay struct {
+	played       int64
+	playedByType map[models.GameType]int64
+}
+
+func newGameReplay() *gameReplay {
+	return &gameReplay{playedByType: make(map[models.GameType]int64)}
+}
+
+// apply учитывает результат и возвращает метрики события game_finished
+func (r *gameReplay) apply(result *models.GameResult) map[string]interface{} {
+	r.played++
+	r.playedByType[result.GameType]++
+	return gameFinishedMetrics(result, r.played, r.playedByType[result.GameType])
+}
+
+// gameFinishedMetrics метрики game_finished; счетчики игр включают эту игру
+func gameFinishedMetrics(result *models.GameResult, gamesPlayed, gameTypePlayed int64) map[string]interface{} {
+	return map[string]interface{}{
+		"game_type":        string(result.GameType),
+		"level":            result.Level,
+		"percentage":       result.Percentage,
+		"score":            result.Score,
+		"correct_count":    result.CorrectCount,
+		"total_count":      result.TotalCount,
+		"time_spent":       result.TimeSpent,
+		"games_played":     gamesPlayed,
+		"game_type_played": gameTypePlayed,
+	}
+}
+
+func streakMetrics(streak *models.UserStreak) map[string]interface{} {
+	return map[string]interface{}{
+		"current_streak": streak.CurrentStreak,
+		"longest_streak": streak.LongestStreak,
+		"freeze_tokens":  streak.FreezeTokens,
+	}
+}
+
+// firstTestMatches время первой попытки каждого ученика, после которой
+// выполнялось правило; attempts отсортированы по ученику и времени
+func firstTestMatches(rule ruleNode, attempts []models.TestAttempt, lessons []models.Lesson, lessonOrder map[uint]int) map[uint]time.Time {
+	matches := make(map[uint]time.Time)
+	var replay *testReplay
+	var userID uint
+	for i := range attempts {
+		attempt := &attempts[i]
+		if replay == nil || attempt.UserID != userID {
+			replay = newTestReplay(lessons, lessonOrder)
+			userID = attempt.UserID
+		}
+		if _, ok := matches[userID]; ok {
+			continue
+		}
+		if rule.eval(replay.apply(attempt)) {
+			matches[userID] = attempt.CreatedAt
+		}
+	}
+	return matches
+}
+
+// firstGameMatches время первой игры каждого ученика, после которой
+// выполнялось правило; results отсортированы по ученику и времени
+func firstGameMatches(rule ruleNode, results []models.GameResult) map[uint]time.Time {
+	matches := make(map[uint]time.Time)
+	var replay *gameReplay
+	var userID uint
+	for i := range results {
+		result := &results[i]
+		if replay == nil || result.UserID != userID {
+			replay = newGameReplay()
+			userID = result.UserID
+		}
+		if _, ok := matches[userID]; ok {
+			continue
+		}
+		if rule.eval(replay.apply(result)) {
+			matches[userID] = result.CreatedAt
+		}
+	}
+	return matches
+}
+
+// streakMatches проверяет текущую серию ученика. Серия росла по одному дню,
+// поэтому правило проверяется и на самой длинной серии как на текущей.
+func streakMatches(rule ruleNode, streak *models.UserStreak) bool {
+	if rule.eval(streakMetrics(streak)) {
+		return true
+	}
+	longest := *streak
+	longest.CurrentStreak = streak.LongestStreak
+	return rule.eval(streakMetrics(&longest))
+}
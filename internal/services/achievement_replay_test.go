@@ -0,0 +1,152 @@
+package services
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"englishlessons.back/internal/models"
+)
+
+func mustParseRule(t *testing.T, event, src string) ruleNode {
+	t.Helper()
+	rule, err := ParseRule(event, src)
+	if err != nil {
+		t.Fatalf("ParseRule(%q): %v", src, err)
+	}
+	return rule
+}
+
+func metricKeys(metrics map[string]interface{}) []string {
+	keys := make([]string, 0, len(metrics))
+	for key := range metrics {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Восстановленные метрики должны содержать все, что доступно в правилах
+func TestReplayMetricsCoverRuleMetrics(t *testing.T) {
+	tests := []struct {
+		event   string
+		metrics map[string]interface{}
+	}{
+		{models.EventTestSubmitted, newTestReplay(nil, nil).apply(&models.TestAttempt{LessonID: 1})},
+		{models.EventGameFinished, newGameReplay().apply(&models.GameResult{GameType: models.GameQuizShow})},
+		{models.EventStreakUpdated, streakMetrics(&models.UserStreak{})},
+	}
+	for _, tt := range tests {
+		want := append([]string(nil), RuleMetrics[tt.event]...)
+		sort.Strings(want)
+		got := metricKeys(tt.metrics)
+		if len(got) != len(want) {
+			t.Fatalf("%s: metrics %v, want %v", tt.event, got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("%s: metrics %v, want %v", tt.event, got, want)
+			}
+		}
+	}
+}
+
+func TestTestReplayProgress(t *testing.T) {
+	lessons := []models.Lesson{{ID: 1, Order: 1}, {ID: 2, Order: 2}, {ID: 3, Order: 3}}
+	order := map[uint]int{1: 1, 2: 2, 3: 3, 9: 9}
+	replay := newTestReplay(lessons, order)
+
+	steps := []struct {
+		attempt         models.TestAttempt
+		firstAttempt    bool
+		attempts        int
+		completed       int64
+		highScoreStreak int
+	}{
+		{models.TestAttempt{LessonID: 1, Score: 5, Percentage: 50}, true, 1, 0, 0},
+		{models.TestAttempt{LessonID: 1, Score: 10, Percentage: 100, IsPassed: true}, false, 2, 1, 1},
+		{models.TestAttempt{LessonID: 1, Score: 7, Percentage: 70, IsPassed: true}, false, 3, 1, 1},
+		{models.TestAttempt{LessonID: 3, Score: 9, Percentage: 90, IsPassed: true}, true, 1, 2, 1},
+		{models.TestAttempt{LessonID: 2, Score: 10, Percentage: 95, IsPassed: true}, true, 1, 3, 3},
+		{models.TestAttempt{LessonID: 9, Score: 1, Percentage: 10}, true, 1, 3, 3},
+	}
+	for i, step := range steps {
+		metrics := replay.apply(&step.attempt)
+		if metrics["is_first_attempt"] != step.firstAttempt {
+			t.Errorf("step %d: is_first_attempt = %v", i, metrics["is_first_attempt"])
+		}
+		if metrics["attempts_on_lesson"] != step.attempts {
+			t.Errorf("step %d: attempts_on_lesson = %v, want %d", i, metrics["attempts_on_lesson"], step.attempts)
+		}
+		if metrics["completed_lessons"] != step.completed {
+			t.Errorf("step %d: completed_lessons = %v, want %d", i, metrics["completed_lessons"], step.completed)
+		}
+		if metrics["high_score_streak"] != step.highScoreStreak {
+			t.Errorf("step %d: high_score_streak = %v, want %d", i, metrics["high_score_streak"], step.highScoreStreak)
+		}
+		if metrics["lesson_order"] != order[step.attempt.LessonID] {
+			t.Errorf("step %d: lesson_order = %v", i, metrics["lesson_order"])
+		}
+		if metrics["total_lessons"] != int64(3) {
+			t.Errorf("step %d: total_lessons = %v", i, metrics["total_lessons"])
+		}
+	}
+
+	// Худшая попытка не снижает лучший результат
+	if best := replay.progress[1].BestPercentage; best != 100 {
+		t.Errorf("best percentage = %v, want 100", best)
+	}
+}
+
+func TestFirstTestMatches(t *testing.T) {
+	lessons := []models.Lesson{{ID: 1, Order: 1}, {ID: 2, Order: 2}}
+	order := map[uint]int{1: 1, 2: 2}
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 10, 0, 0, 0, time.UTC) }
+	attempts := []models.TestAttempt{
+		{UserID: 1, LessonID: 1, IsPassed: true, CreatedAt: day(1)},
+		{UserID: 1, LessonID: 2, IsPassed: true, CreatedAt: day(2)},
+		{UserID: 1, LessonID: 2, IsPassed: true, CreatedAt: day(3)},
+		{UserID: 2, LessonID: 1, IsPassed: true, CreatedAt: day(1)},
+		{UserID: 2, LessonID: 1, IsPassed: true, CreatedAt: day(4)},
+		{UserID: 3, LessonID: 2, IsPassed: false, CreatedAt: day(5)},
+	}
+
+	rule := mustParseRule(t, models.EventTestSubmitted, "completed_lessons >= 2")
+	matches := firstTestMatches(rule, attempts, lessons, order)
+	if len(matches) != 1 || !matches[1].Equal(day(2)) {
+		t.Fatalf("matches = %v, want only user 1 on day 2", matches)
+	}
+
+	// Прогресс считается отдельно для каждого ученика
+	rule = mustParseRule(t, models.EventTestSubmitted, "attempts_on_lesson >= 2")
+	matches = firstTestMatches(rule, attempts, lessons, order)
+	if len(matches) != 2 || !matches[1].Equal(day(3)) || !matches[2].Equal(day(4)) {
+		t.Fatalf("matches = %v", matches)
+	}
+}
+
+func TestFirstGameMatches(t *testing.T) {
+	at := func(m int) time.Time { return time.Date(2025, 3, 1, 10, m, 0, 0, time.UTC) }
+	results := []models.GameResult{
+		{UserID: 1, GameType: models.GameQuizShow, Percentage: 40, CreatedAt: at(1)},
+		{UserID: 1, GameType: models.GameQuizShow, Percentage: 100, CreatedAt: at(2)},
+		{UserID: 1, GameType: models.GameQuizShow, Percentage: 100, CreatedAt: at(3)},
+		{UserID: 2, GameType: models.GameQuizShow, Percentage: 100, CreatedAt: at(4)},
+	}
+
+	rule := mustParseRule(t, models.EventGameFinished, "games_played >= 2 && percentage == 100")
+	matches := firstGameMatches(rule, results)
+	if len(matches) != 1 || !matches[1].Equal(at(2)) {
+		t.Fatalf("matches = %v, want only user 1 at the second game", matches)
+	}
+}
+
+func TestStreakMatches(t *testing.T) {
+	rule := mustParseRule(t, models.EventStreakUpdated, "current_streak >= 7")
+	if !streakMatches(rule, &models.UserStreak{CurrentStreak: 2, LongestStreak: 9}) {
+		t.Error("longest streak of 9 days must satisfy current_streak >= 7")
+	}
+	if streakMatches(rule, &models.UserStreak{CurrentStreak: 3, LongestStreak: 6}) {
+		t.Error("streak of 6 days must not satisfy current_streak >= 7")
+	}
+}
@@ -0,0 +1,348 @@
+package services
+
+import (
+	"englishlessons.back/internal/models"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// RuleMetrics метрики, доступные в правилах достижений для каждого события
+var RuleMetrics = map[string][]string{
+	models.EventTestSubmitted: {
+		"lesson_id", "lesson_order", "percentage", "score", "is_passed", "is_first_attempt",
+		"attempts_on_lesson", "completed_lessons", "total_lessons", "high_score_streak",
+	},
+	models.EventGameFinished: {
+		"game_type", "level", "percentage", "score", "correct_count", "total_count",
+		"time_spent", "games_played", "game_type_played",
+	},
+	models.EventStreakUpdated: {
+		"current_streak", "longest_streak", "freeze_tokens",
+	},
+}
+
+// Правило — выражение вида
+//
+//	percentage >= 90 && (is_first_attempt == true || attempts_on_lesson >= 10)
+//
+// Поддерживаются сравнения ==, !=, >, >=, <, <=, логические && и ||, скобки,
+// числа, строки в кавычках, true/false и другие метрики события
+// (completed_lessons >= total_lessons).
+type ruleNode interface {
+	eval(metrics map[string]interface{}) bool
+}
+
+type ruleLogical struct {
+	op          string
+	left, right ruleNode
+}
+
+func (n *ruleLogical) eval(metrics map[string]interface{}) bool {
+	if n.op == "&&" {
+		return n.left.eval(metrics) && n.right.eval(metrics)
+	}
+	return n.left.eval(metrics) || n.right.eval(metrics)
+}
+
+type ruleComparison struct {
+	metric string
+	op     string
+	value  interface{}
+	// other метрика, с которой сравнивается metric, вместо value
+	other string
+}
+
+func (n *ruleComparison) eval(metrics map[string]interface{}) bool {
+	actual, ok := metrics[n.metric]
+	if !ok {
+		return false
+	}
+
+	value := n.value
+	if n.other != "" {
+		if value, ok = metrics[n.other]; !ok {
+			return false
+		}
+		switch value.(type) {
+		case string, bool:
+		default:
+			if value, ok = toFloat(value); !ok {
+				return false
+			}
+		}
+	}
+
+	switch expected := value.(type) {
+	case float64:
+		number, ok := toFloat(actual)
+		if !ok {
+			return false
+		}
+		switch n.op {
+		case "==":
+			return number == expected
+		case "!=":
+			return number != expected
+		case ">":
+			return number > expected
+		case ">=":
+			return number >= expected
+		case "<":
+			return number < expected
+		case "<=":
+			return number <= expected
+		}
+	case string:
+		str, ok := actual.(string)
+		if !ok {
+			return false
+		}
+		switch n.op {
+		case "==":
+			return str == expected
+		case "!=":
+			return str != expected
+		}
+	case bool:
+		flag, ok := actual.(bool)
+		if !ok {
+			return false
+		}
+		switch n.op {
+		case "==":
+			return flag == expected
+		case "!=":
+			return flag != expected
+		}
+	}
+	return false
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+type ruleToken struct {
+	kind  string // ident, number, string, op, lparen, rparen
+	value string
+}
+
+func tokenizeRule(src string) ([]ruleToken, error) {
+	var tokens []ruleToken
+	runes := []rune(src)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, ruleToken{kind: "lparen"})
+			i++
+		case r == ')':
+			tokens = append(tokens, ruleToken{kind: "rparen"})
+			i++
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, errors.New("незакрытая строка в правиле")
+			}
+			tokens = append(tokens, ruleToken{kind: "string", value: string(runes[i+1 : end])})
+			i = end + 1
+		case unicode.IsDigit(r) || r == '-' || r == '.':
+			end := i + 1
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || runes[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, ruleToken{kind: "number", value: string(runes[i:end])})
+			i = end
+		case unicode.IsLetter(r) || r == '_':
+			end := i + 1
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || runes[end] == '_') {
+				end++
+			}
+			tokens = append(tokens, ruleToken{kind: "ident", value: string(runes[i:end])})
+			i = end
+		default:
+			end := i + 1
+			if end < len(runes) && strings.ContainsRune("=&|", runes[end]) {
+				end++
+			}
+			op := string(runes[i:end])
+			switch op {
+			case "==", "!=", ">=", "<=", ">", "<", "&&", "||":
+				tokens = append(tokens, ruleToken{kind: "op", value: op})
+			default:
+				return nil, fmt.Errorf("неизвестный оператор %q в правиле", op)
+			}
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+type ruleParser struct {
+	tokens  []ruleToken
+	pos     int
+	allowed map[string]bool
+}
+
+// ParseRule разбирает правило и проверяет, что используются только метрики события
+func ParseRule(event, src string) (ruleNode, error) {
+	metrics, ok := RuleMetrics[event]
+	if !ok {
+		return nil, fmt.Errorf("неизвестное событие %q", event)
+	}
+
+	tokens, err := tokenizeRule(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("пустое правило")
+	}
+
+	p := &ruleParser{tokens: tokens, allowed: make(map[string]bool)}
+	for _, m := range metrics {
+		p.allowed[m] = true
+	}
+
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, errors.New("лишние символы в конце правила")
+	}
+	return node, nil
+}
+
+func (p *ruleParser) peek() *ruleToken {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos]
+}
+
+func (p *ruleParser) parseOr() (ruleNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t != nil && t.kind == "op" && t.value == "||"; t = p.peek() {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &ruleLogical{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *ruleParser) parseAnd() (ruleNode, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t != nil && t.kind == "op" && t.value == "&&"; t = p.peek() {
+		p.pos++
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &ruleLogical{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *ruleParser) parseTerm() (ruleNode, error) {
+	t := p.peek()
+	if t == nil {
+		return nil, errors.New("неожиданный конец правила")
+	}
+
+	if t.kind == "lparen" {
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.peek(); closing == nil || closing.kind != "rparen" {
+			return nil, errors.New("ожидалась закрывающая скобка")
+		}
+		p.pos++
+		return node, nil
+	}
+
+	if t.kind != "ident" {
+		return nil, fmt.Errorf("ожидалось имя метрики, получено %q", t.value)
+	}
+	if !p.allowed[t.value] {
+		return nil, fmt.Errorf("метрика %q недоступна для этого события", t.value)
+	}
+	metric := t.value
+	p.pos++
+
+	opToken := p.peek()
+	if opToken == nil || opToken.kind != "op" || opToken.value == "&&" || opToken.value == "||" {
+		return nil, fmt.Errorf("ожидался оператор сравнения после %q", metric)
+	}
+	p.pos++
+
+	valueToken := p.peek()
+	if valueToken == nil {
+		return nil, errors.New("неожиданный конец правила")
+	}
+	p.pos++
+
+	var value interface{}
+	switch valueToken.kind {
+	case "number":
+		number, err := strconv.ParseFloat(valueToken.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("неверное число %q", valueToken.value)
+		}
+		value = number
+	case "string":
+		value = valueToken.value
+	case "ident":
+		if valueToken.value == "true" || valueToken.value == "false" {
+			value = valueToken.value == "true"
+			break
+		}
+		if !p.allowed[valueToken.value] {
+			return nil, fmt.Errorf("неверное значение %q", valueToken.value)
+		}
+		// Тип другой метрики известен только при проверке
+		return &ruleComparison{metric: metric, op: opToken.value, other: valueToken.value}, nil
+	default:
+		return nil, errors.New("ожидалось значение для сравнения")
+	}
+
+	if _, isNumber := value.(float64); !isNumber && opToken.value != "==" && opToken.value != "!=" {
+		return nil, fmt.Errorf("оператор %s применим только к числам", opToken.value)
+	}
+
+	return &ruleComparison{metric: metric, op: opToken.value, value: value}, nil
+}
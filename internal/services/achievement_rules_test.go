@@ -0,0 +1,137 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"englishlessons.back/internal/models"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   string
+		rule    string
+		wantErr string
+	}{
+		{"comparison", models.EventTestSubmitted, "percentage >= 90", ""},
+		{"and or parens", models.EventTestSubmitted, "percentage >= 90 && (is_first_attempt == true || attempts_on_lesson >= 10)", ""},
+		{"string", models.EventGameFinished, "game_type == 'quiz_show'", ""},
+		{"double quotes", models.EventGameFinished, `game_type != "quiz_show"`, ""},
+		{"negative number", models.EventGameFinished, "score > -1", ""},
+		{"fraction", models.EventTestSubmitted, "percentage >= 99.5", ""},
+		{"streak", models.EventStreakUpdated, "current_streak >= 7 || longest_streak >= 30", ""},
+		{"metric against metric", models.EventTestSubmitted, "completed_lessons >= total_lessons", ""},
+
+		{"unknown event", "lesson_viewed", "percentage >= 90", "неизвестное событие"},
+		{"empty", models.EventTestSubmitted, "   ", "пустое правило"},
+		{"metric of another event", models.EventTestSubmitted, "games_played >= 1", "недоступна"},
+		{"unknown metric", models.EventStreakUpdated, "xp >= 100", "недоступна"},
+		{"unknown operator", models.EventTestSubmitted, "percentage => 90", "неизвестный оператор"},
+		{"single equals", models.EventTestSubmitted, "percentage = 90", "неизвестный оператор"},
+		{"unclosed string", models.EventGameFinished, "game_type == 'quiz", "незакрытая строка"},
+		{"unclosed paren", models.EventTestSubmitted, "(percentage >= 90", "закрывающая скобка"},
+		{"extra paren", models.EventTestSubmitted, "percentage >= 90)", "лишние символы"},
+		{"missing value", models.EventTestSubmitted, "percentage >=", "неожиданный конец"},
+		{"missing operator", models.EventTestSubmitted, "percentage 90", "оператор сравнения"},
+		{"dangling and", models.EventTestSubmitted, "percentage >= 90 &&", "неожиданный конец"},
+		{"bare identifier value", models.EventTestSubmitted, "is_passed == yes", "неверное значение"},
+		{"value from another event", models.EventTestSubmitted, "score >= games_played", "неверное значение"},
+		{"string with order", models.EventGameFinished, "game_type > 'a'", "только к числам"},
+		{"bool with order", models.EventTestSubmitted, "is_passed >= true", "только к числам"},
+		{"bad number", models.EventTestSubmitted, "percentage >= 1.2.3", "неверное число"},
+		{"value first", models.EventTestSubmitted, "90 <= percentage", "ожидалось имя метрики"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRule(tt.event, tt.rule)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ParseRule(%q): %v", tt.rule, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ParseRule(%q) error = %v, want %q", tt.rule, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRuleEval(t *testing.T) {
+	test := map[string]interface{}{
+		"lesson_id":          uint(3),
+		"lesson_order":       1,
+		"percentage":         92.5,
+		"score":              9,
+		"is_passed":          true,
+		"is_first_attempt":   false,
+		"attempts_on_lesson": 10,
+		"completed_lessons":  int64(12),
+		"total_lessons":      int64(12),
+		"high_score_streak":  3,
+	}
+	game := map[string]interface{}{
+		"game_type":        "quiz_show",
+		"percentage":       100.0,
+		"games_played":     int64(1),
+		"game_type_played": int64(1),
+	}
+
+	tests := []struct {
+		event   string
+		rule    string
+		metrics map[string]interface{}
+		want    bool
+	}{
+		{models.EventTestSubmitted, "percentage >= 90", test, true},
+		{models.EventTestSubmitted, "percentage > 92.5", test, false},
+		{models.EventTestSubmitted, "percentage < 93 && percentage <= 92.5", test, true},
+		{models.EventTestSubmitted, "score == 9 && score != 10", test, true},
+		{models.EventTestSubmitted, "lesson_id == 3", test, true},
+		{models.EventTestSubmitted, "is_passed == true", test, true},
+		{models.EventTestSubmitted, "is_first_attempt == true", test, false},
+		{models.EventTestSubmitted, "is_first_attempt != true", test, true},
+		{models.EventTestSubmitted, "is_first_attempt == true && percentage >= 90", test, false},
+		{models.EventTestSubmitted, "is_first_attempt == true || attempts_on_lesson >= 10", test, true},
+		// && связывает сильнее ||
+		{models.EventTestSubmitted, "is_passed == false && score == 9 || lesson_order == 1", test, true},
+		{models.EventTestSubmitted, "is_passed == false && (score == 9 || lesson_order == 1)", test, false},
+		// Встроенные правила
+		{models.EventTestSubmitted, "lesson_order == 1 && is_passed == true", test, true},
+		{models.EventTestSubmitted, "total_lessons > 0 && completed_lessons >= total_lessons", test, true},
+		{models.EventTestSubmitted, "total_lessons > 0 && completed_lessons >= total_lessons",
+			map[string]interface{}{"total_lessons": int64(0), "completed_lessons": int64(0)}, false},
+		{models.EventTestSubmitted, "high_score_streak >= 3", test, true},
+		// Сравнение двух метрик разных числовых типов
+		{models.EventTestSubmitted, "completed_lessons > total_lessons", test, false},
+		{models.EventTestSubmitted, "attempts_on_lesson > high_score_streak", test, true},
+		{models.EventTestSubmitted, "score == lesson_id", test, false},
+		{models.EventTestSubmitted, "is_passed != is_first_attempt", test, true},
+		{models.EventTestSubmitted, "is_passed > is_first_attempt", test, false},
+
+		{models.EventGameFinished, "game_type == 'quiz_show'", game, true},
+		{models.EventGameFinished, "game_type != 'quiz_show'", game, false},
+		{models.EventGameFinished, "games_played >= 1 && percentage >= 100", game, true},
+		// Тип значения не совпадает с правилом — правило не выполнено
+		{models.EventGameFinished, "game_type == 1", game, false},
+		{models.EventGameFinished, "percentage == 'full'", game, false},
+		// Отсутствующая метрика — правило не выполнено
+		{models.EventGameFinished, "time_spent < 60", game, false},
+		{models.EventGameFinished, "time_spent < 60 || games_played == 1", game, true},
+		{models.EventGameFinished, "games_played >= time_spent", game, false},
+		{models.EventGameFinished, "games_played == game_type_played", game, true},
+
+		{models.EventStreakUpdated, "current_streak >= 7", map[string]interface{}{"current_streak": 7}, true},
+		{models.EventStreakUpdated, "current_streak >= 30", map[string]interface{}{"current_streak": 29}, false},
+	}
+	for _, tt := range tests {
+		rule, err := ParseRule(tt.event, tt.rule)
+		if err != nil {
+			t.Fatalf("ParseRule(%q): %v", tt.rule, err)
+		}
+		if got := rule.eval(tt.metrics); got != tt.want {
+			t.Errorf("%q = %v, want %v", tt.rule, got, tt.want)
+		}
+	}
+}
@@ -4,38 +4,80 @@ import (
 	"englishlessons.back/internal/models"
 	"englishlessons.back/internal/repositories"
 	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+var achievementTypeRegex = regexp.MustCompile(`^[a-z0-9_]{3,50}$`)
+
 type AchievementService struct {
 	achievementRepo *repositories.AchievementRepository
 	progressRepo    *repositories.ProgressRepository
 	lessonRepo      *repositories.LessonRepository
+	userRepo        *repositories.UserRepository
+	gameResultRepo  *repositories.GameResultRepository
+	testRepo        *repositories.TestRepository
+	activityRepo    *repositories.ActivityRepository
+	eventBus        *EventBus
 }
 
+// reevaluatePageSize сколько учеников проверяется за один проход при выдаче значка задним числом
+const reevaluatePageSize = 200
+
 func NewAchievementService(
 	achievementRepo *repositories.AchievementRepository,
 	progressRepo *repositories.ProgressRepository,
 	lessonRepo *repositories.LessonRepository,
+	userRepo *repositories.UserRepository,
+	gameResultRepo *repositories.GameResultRepository,
+	testRepo *repositories.TestRepository,
+	activityRepo *repositories.ActivityRepository,
+	eventBus *EventBus,
 ) *AchievementService {
 	return &AchievementService{
 		achievementRepo: achievementRepo,
 		progressRepo:    progressRepo,
 		lessonRepo:      lessonRepo,
+		userRepo:        userRepo,
+		gameResultRepo:  gameResultRepo,
+		testRepo:        testRepo,
+		activityRepo:    activityRepo,
+		eventBus:        eventBus,
 	}
 }
 
-func (s *AchievementService) GetUserAchievements(userID uint) ([]map[string]interface{}, error) {
+func (s *AchievementService) GetUserAchievements(userID uint, lang string) ([]map[string]interface{}, error) {
 	achievements, err := s.achievementRepo.FindByUserID(userID)
 	if err != nil {
 		return nil, err
 	}
 
+	return s.FormatAchievements(achievements, lang)
+}
+
+// FormatAchievements подставляет тексты на языке lang из определений достижений
+func (s *AchievementService) FormatAchievements(achievements []models.Achievement, lang string) ([]map[string]interface{}, error) {
+	definitions, err := s.achievementRepo.FindDefinitions(false)
+	if err != nil {
+		return nil, err
+	}
+	definitionsByType := make(map[models.AchievementType]*models.AchievementDefinition, len(definitions))
+	for i := range definitions {
+		definitionsByType[definitions[i].Type] = &definitions[i]
+	}
+
 	result := make([]map[string]interface{}, len(achievements))
 	for i, a := range achievements {
-		title, description, icon := models.GetAchievementInfo(a.Type)
+		title, description, icon := a.Title, a.Description, a.Icon
+		if definition, ok := definitionsByType[a.Type]; ok {
+			title, description = definition.Localized(lang)
+			icon = definition.Icon
+		}
 		result[i] = map[string]interface{}{
 			"id":          a.ID,
 			"type":        a.Type,
@@ -49,154 +91,478 @@ func (s *AchievementService) GetUserAchievements(userID uint) ([]map[string]inte
 	return result, nil
 }
 
-func (s *AchievementService) CheckAchievements(userID uint, lessonID uint, percentage float64, isFirstAttempt bool) error {
-	// Первый урок
-	if lessonID == 1 {
-		_, err := s.achievementRepo.FindByUserIDAndType(userID, models.AchievementTypeFirstLesson)
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			title, description, icon := models.GetAchievementInfo(models.AchievementTypeFirstLesson)
-			achievement := &models.Achievement{
-				UserID:      userID,
-				Type:        models.AchievementTypeFirstLesson,
-				Title:       title,
-				Description: description,
-				Icon:        icon,
-				EarnedAt:    time.Now(),
-			}
-			if err := s.achievementRepo.Create(achievement); err != nil {
-				return err
-			}
-		}
+// HandleEvent оценивает правила достижений для события шины
+func (s *AchievementService) HandleEvent(event Event, outcome *EventOutcome) error {
+	var awarded []models.Achievement
+	var err error
+
+	switch event.Type {
+	case models.EventTestSubmitted:
+		awarded, err = s.OnTestSubmitted(event.TestAttempt, event.IsFirstAttempt)
+	case models.EventGameFinished:
+		awarded, err = s.OnGameFinished(event.GameResult)
+	case models.EventStreakUpdated:
+		awarded, err = s.OnStreakUpdated(event.Streak)
 	}
 
-	// Идеальный результат (100%)
-	if percentage >= 100 {
-		_, err := s.achievementRepo.FindByUserIDAndType(userID, models.AchievementTypePerfectScore)
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			title, description, icon := models.GetAchievementInfo(models.AchievementTypePerfectScore)
-			achievement := &models.Achievement{
-				UserID:      userID,
-				Type:        models.AchievementTypePerfectScore,
-				Title:       title,
-				Description: description,
-				Icon:        icon,
-				EarnedAt:    time.Now(),
-			}
-			if err := s.achievementRepo.Create(achievement); err != nil {
-				return err
-			}
-		}
+	outcome.Achievements = append(outcome.Achievements, awarded...)
+	for i := range awarded {
+		s.publishEarned(&awarded[i], outcome)
 	}
+	return err
+}
 
-	// Быстрый ученик (первая попытка 90%+)
-	if isFirstAttempt && percentage >= 90 {
-		_, err := s.achievementRepo.FindByUserIDAndType(userID, models.AchievementTypeFastLearner)
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			title, description, icon := models.GetAchievementInfo(models.AchievementTypeFastLearner)
-			achievement := &models.Achievement{
-				UserID:      userID,
-				Type:        models.AchievementTypeFastLearner,
-				Title:       title,
-				Description: description,
-				Icon:        icon,
-				EarnedAt:    time.Now(),
-			}
-			if err := s.achievementRepo.Create(achievement); err != nil {
-				return err
-			}
-		}
+// publishEarned сообщает подписчикам (например, журналу опыта) о новом достижении
+func (s *AchievementService) publishEarned(achievement *models.Achievement, outcome *EventOutcome) {
+	s.eventBus.PublishTo(Event{
+		Type:        models.EventAchievementEarned,
+		UserID:      achievement.UserID,
+		OccurredAt:  achievement.EarnedAt,
+		Achievement: achievement,
+	}, outcome)
+}
+
+// OnTestSubmitted оценивает правила после отправки теста
+func (s *AchievementService) OnTestSubmitted(attempt *models.TestAttempt, isFirstAttempt bool) ([]models.Achievement, error) {
+	metrics := map[string]interface{}{
+		"lesson_id":        attempt.LessonID,
+		"percentage":       attempt.Percentage,
+		"score":            attempt.Score,
+		"is_passed":        attempt.IsPassed,
+		"is_first_attempt": isFirstAttempt,
 	}
 
-	// Проверка серии побед (3 урока подряд 90%+)
-	progress, _ := s.progressRepo.FindByUserID(userID)
-	var recentProgress []models.LessonProgress
-	for _, p := range progress {
-		if p.IsCompleted && p.BestPercentage >= 90.0 {
-			recentProgress = append(recentProgress, p)
-		}
+	lesson, err := s.lessonRepo.FindByID(attempt.LessonID)
+	if err != nil {
+		return nil, err
 	}
+	metrics["lesson_order"] = lesson.Order
 
-	// Сортируем по lesson_id DESC и берем последние 3
-	if len(recentProgress) >= 3 {
-		// Проверяем что это последовательные уроки
-		isStreak := true
-		for i := 0; i < len(recentProgress)-1; i++ {
-			if recentProgress[i].LessonID != recentProgress[i+1].LessonID+1 {
-				isStreak = false
-				break
-			}
-		}
-		if isStreak {
-			_, err := s.achievementRepo.FindByUserIDAndType(userID, models.AchievementTypeStreak)
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				title, description, icon := models.GetAchievementInfo(models.AchievementTypeStreak)
-				achievement := &models.Achievement{
-					UserID:      userID,
-					Type:        models.AchievementTypeStreak,
-					Title:       title,
-					Description: description,
-					Icon:        icon,
-					EarnedAt:    time.Now(),
-				}
-				if err := s.achievementRepo.Create(achievement); err != nil {
-					return err
-				}
-			}
-		}
+	if progress, err := s.progressRepo.FindByUserAndLesson(attempt.UserID, attempt.LessonID); err == nil {
+		metrics["attempts_on_lesson"] = progress.AttemptsCount
 	}
 
-	// Все уроки пройдены
 	var totalLessons int64
 	if err := s.progressRepo.DB().Model(&models.Lesson{}).
 		Where("is_active = ?", true).Count(&totalLessons).Error; err != nil {
-		totalLessons = 0
+		return nil, err
 	}
+	metrics["total_lessons"] = totalLessons
 
 	var completedCount int64
 	if err := s.progressRepo.DB().Model(&models.LessonProgress{}).
-		Where("user_id = ? AND is_completed = ?", userID, true).
+		Where("user_id = ? AND is_completed = ?", attempt.UserID, true).
 		Count(&completedCount).Error; err != nil {
-		completedCount = 0
+		return nil, err
+	}
+	metrics["completed_lessons"] = completedCount
+
+	lessons, err := s.lessonRepo.FindAll(true)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := s.progressRepo.FindByUserID(attempt.UserID)
+	if err != nil {
+		return nil, err
+	}
+	metrics["high_score_streak"] = highScoreStreak(lessons, progress)
+
+	return s.ProcessEvent(attempt.UserID, models.EventTestSubmitted, metrics, attempt.CreatedAt)
+}
+
+// highScoreStreak длина самой длинной серии подряд идущих (по Order) уроков,
+// пройденных на 90%+. lessons должны быть отсортированы по Order.
+func highScoreStreak(lessons []models.Lesson, progress []models.LessonProgress) int {
+	highScore := make(map[uint]bool, len(progress))
+	for _, p := range progress {
+		if p.IsCompleted && p.BestPercentage >= 90.0 {
+			highScore[p.LessonID] = true
+		}
+	}
+
+	best, current := 0, 0
+	for _, lesson := range lessons {
+		if !highScore[lesson.ID] {
+			current = 0
+			continue
+		}
+		current++
+		if current > best {
+			best = current
+		}
+	}
+	return best
+}
+
+// OnGameFinished оценивает правила после сохранения результата игры
+func (s *AchievementService) OnGameFinished(result *models.GameResult) ([]models.Achievement, error) {
+	gamesPlayed, err := s.gameResultRepo.CountByUser(result.UserID, "")
+	if err != nil {
+		return nil, err
+	}
+	gameTypePlayed, err := s.gameResultRepo.CountByUser(result.UserID, result.GameType)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := gameFinishedMetrics(result, gamesPlayed, gameTypePlayed)
+
+	return s.ProcessEvent(result.UserID, models.EventGameFinished, metrics, result.CreatedAt)
+}
+
+// OnStreakUpdated оценивает правила после изменения серии
+func (s *AchievementService) OnStreakUpdated(streak *models.UserStreak) ([]models.Achievement, error) {
+	metrics := streakMetrics(streak)
+
+	return s.ProcessEvent(streak.UserID, models.EventStreakUpdated, metrics, time.Now())
+}
+
+// ProcessEvent сохраняет событие в журнал и выдает достижения, правила которых выполнены
+func (s *AchievementService) ProcessEvent(userID uint, event string, metrics map[string]interface{}, occurredAt time.Time) ([]models.Achievement, error) {
+	if occurredAt.IsZero() {
+		occurredAt = time.Now()
+	}
+
+	if err := s.achievementRepo.CreateEvent(&models.AchievementEvent{
+		UserID:     userID,
+		Event:      event,
+		Metrics:    models.JSONMap(metrics),
+		OccurredAt: occurredAt,
+	}); err != nil {
+		return nil, err
+	}
+
+	definitions, err := s.achievementRepo.FindDefinitionsByEvent(event)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var awarded []models.Achievement
+	for i := range definitions {
+		definition := &definitions[i]
+		if !definition.AppliesTo(user) {
+			continue
+		}
+
+		_, err := s.achievementRepo.FindByUserIDAndType(userID, definition.Type)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return awarded, err
+		}
+
+		rule, err := ParseRule(definition.Event, definition.Rule)
+		if err != nil {
+			// Некорректное правило не должно мешать остальным
+			log.Printf("Invalid rule for achievement %s: %v", definition.Type, err)
+			continue
+		}
+		if !rule.eval(metrics) {
+			continue
+		}
+
+		achievement, err := s.award(userID, definition, time.Now())
+		if err != nil {
+			return awarded, err
+		}
+		if achievement == nil {
+			continue
+		}
+		awarded = append(awarded, *achievement)
 	}
 
-	if completedCount >= totalLessons && totalLessons > 0 {
-		_, err := s.achievementRepo.FindByUserIDAndType(userID, models.AchievementTypeAllLessons)
+	return awarded, nil
+}
+
+// award выдает достижение; nil, если параллельный запрос уже выдал его,
+// чтобы опыт за достижение не начислялся дважды
+func (s *AchievementService) award(userID uint, definition *models.AchievementDefinition, earnedAt time.Time) (*models.Achievement, error) {
+	achievement := &models.Achievement{
+		UserID:      userID,
+		Type:        definition.Type,
+		Title:       definition.TitleRu,
+		Description: definition.DescriptionRu,
+		Icon:        definition.Icon,
+		EarnedAt:    earnedAt,
+	}
+	created, err := s.achievementRepo.Create(achievement)
+	if err != nil || !created {
+		return nil, err
+	}
+	return achievement, nil
+}
+
+// AchievementDefinitionRequest данные значка, задаваемые учителем
+type AchievementDefinitionRequest struct {
+	Type          string `json:"type"`
+	TitleRu       string `json:"title_ru" binding:"required"`
+	TitleUz       string `json:"title_uz"`
+	DescriptionRu string `json:"description_ru"`
+	DescriptionUz string `json:"description_uz"`
+	Icon          string `json:"icon"`
+	Event         string `json:"event" binding:"required"`
+	Rule          string `json:"rule" binding:"required"`
+	Level         *int   `json:"level" binding:"required"`
+	LevelLetter   string `json:"level_letter"`
+}
+
+func (req *AchievementDefinitionRequest) validate() error {
+	if strings.TrimSpace(req.TitleRu) == "" {
+		return errors.New("Необходимо указать название значка")
+	}
+	if req.Level == nil || *req.Level < 1 || *req.Level > 11 {
+		return errors.New("Неверный класс: допустимо от 1 до 11")
+	}
+	req.LevelLetter = strings.TrimSpace(strings.ToUpper(req.LevelLetter))
+	if len([]rune(req.LevelLetter)) > 1 {
+		return errors.New("Неверный формат буквы класса")
+	}
+	if _, err := ParseRule(req.Event, req.Rule); err != nil {
+		return fmt.Errorf("Неверное правило: %v", err)
+	}
+	return nil
+}
+
+func (req *AchievementDefinitionRequest) apply(definition *models.AchievementDefinition) {
+	definition.TitleRu = strings.TrimSpace(req.TitleRu)
+	definition.TitleUz = strings.TrimSpace(req.TitleUz)
+	definition.DescriptionRu = strings.TrimSpace(req.DescriptionRu)
+	definition.DescriptionUz = strings.TrimSpace(req.DescriptionUz)
+	definition.Icon = req.Icon
+	if definition.Icon == "" {
+		definition.Icon = "🏆"
+	}
+	definition.Event = req.Event
+	definition.Rule = req.Rule
+	definition.Level = req.Level
+	definition.LevelLetter = req.LevelLetter
+}
+
+func (s *AchievementService) GetDefinitions() ([]models.AchievementDefinition, error) {
+	return s.achievementRepo.FindDefinitions(false)
+}
+
+// CreateDefinition создает значок класса и сразу применяет его к прошлым событиям.
+// Возвращает количество учеников, получивших значок задним числом.
+func (s *AchievementService) CreateDefinition(teacherID uint, req AchievementDefinitionRequest) (*models.AchievementDefinition, int, error) {
+	req.Type = strings.TrimSpace(strings.ToLower(req.Type))
+	if !achievementTypeRegex.MatchString(req.Type) {
+		return nil, 0, errors.New("Неверный код значка: допустимы латинские буквы, цифры и _, от 3 до 50 символов")
+	}
+	if err := req.validate(); err != nil {
+		return nil, 0, err
+	}
+
+	if _, err := s.achievementRepo.FindDefinitionByType(models.AchievementType(req.Type)); err == nil {
+		return nil, 0, errors.New("Значок с таким кодом уже существует")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, 0, err
+	}
+
+	definition := &models.AchievementDefinition{
+		Type:      models.AchievementType(req.Type),
+		CreatedBy: &teacherID,
+		IsActive:  true,
+	}
+	req.apply(definition)
+
+	if err := s.achievementRepo.CreateDefinition(definition); err != nil {
+		return nil, 0, err
+	}
+
+	awarded, err := s.reevaluate(definition)
+	if err != nil {
+		return definition, awarded, err
+	}
+	return definition, awarded, nil
+}
+
+// UpdateDefinition изменяет значок, созданный учителем, и применяет новое правило к прошлым событиям
+func (s *AchievementService) UpdateDefinition(id uint, req AchievementDefinitionRequest) (*models.AchievementDefinition, int, error) {
+	definition, err := s.findCustomDefinition(id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := req.validate(); err != nil {
+		return nil, 0, err
+	}
+
+	req.apply(definition)
+	if err := s.achievementRepo.UpdateDefinition(definition); err != nil {
+		return nil, 0, err
+	}
+
+	awarded, err := s.reevaluate(definition)
+	return definition, awarded, err
+}
+
+// DeactivateDefinition отключает значок; уже выданные остаются у учеников
+func (s *AchievementService) DeactivateDefinition(id uint) error {
+	definition, err := s.findCustomDefinition(id)
+	if err != nil {
+		return err
+	}
+	definition.IsActive = false
+	return s.achievementRepo.UpdateDefinition(definition)
+}
+
+// ReevaluateDefinition повторно применяет правило значка ко всем прошлым событиям
+func (s *AchievementService) ReevaluateDefinition(id uint) (int, error) {
+	definition, err := s.achievementRepo.FindDefinitionByID(id)
+	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			title, description, icon := models.GetAchievementInfo(models.AchievementTypeAllLessons)
-			achievement := &models.Achievement{
-				UserID:      userID,
-				Type:        models.AchievementTypeAllLessons,
-				Title:       title,
-				Description: description,
-				Icon:        icon,
-				EarnedAt:    time.Now(),
-			}
-			if err := s.achievementRepo.Create(achievement); err != nil {
-				return err
-			}
+			return 0, errors.New("Значок не найден")
 		}
+		return 0, err
 	}
+	return s.reevaluate(definition)
+}
 
-	// Упорство (10+ попыток на одном уроке)
-	progressItem, err := s.progressRepo.FindByUserAndLesson(userID, lessonID)
-	if err == nil && progressItem.AttemptsCount >= 10 {
-		_, err := s.achievementRepo.FindByUserIDAndType(userID, models.AchievementTypePersistent)
+func (s *AchievementService) findCustomDefinition(id uint) (*models.AchievementDefinition, error) {
+	definition, err := s.achievementRepo.FindDefinitionByID(id)
+	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			title, description, icon := models.GetAchievementInfo(models.AchievementTypePersistent)
-			achievement := &models.Achievement{
-				UserID:      userID,
-				Type:        models.AchievementTypePersistent,
-				Title:       title,
-				Description: description,
-				Icon:        icon,
-				EarnedAt:    time.Now(),
+			return nil, errors.New("Значок не найден")
+		}
+		return nil, err
+	}
+	if definition.CreatedBy == nil {
+		return nil, errors.New("Встроенные достижения нельзя изменять")
+	}
+	return definition, nil
+}
+
+// reevaluate выдает значок ученикам, которые уже выполняли его правило.
+// Ученики обходятся страницами по ID, метрики восстанавливаются по их
+// попыткам тестов и результатам игр; для серий проверяется журнал событий
+// и текущая серия.
+func (s *AchievementService) reevaluate(definition *models.AchievementDefinition) (int, error) {
+	if !definition.IsActive {
+		return 0, nil
+	}
+
+	rule, err := ParseRule(definition.Event, definition.Rule)
+	if err != nil {
+		return 0, err
+	}
+
+	holders, err := s.achievementRepo.FindUserIDsByType(definition.Type)
+	if err != nil {
+		return 0, err
+	}
+	hasAchievement := make(map[uint]bool, len(holders))
+	for _, userID := range holders {
+		hasAchievement[userID] = true
+	}
+
+	var lessons []models.Lesson
+	var lessonOrder map[uint]int
+	if definition.Event == models.EventTestSubmitted {
+		if lessons, err = s.lessonRepo.FindAll(true); err != nil {
+			return 0, err
+		}
+		allLessons, err := s.lessonRepo.FindAll(false)
+		if err != nil {
+			return 0, err
+		}
+		lessonOrder = make(map[uint]int, len(allLessons))
+		for _, lesson := range allLessons {
+			lessonOrder[lesson.ID] = lesson.Order
+		}
+	}
+
+	awarded := 0
+	var afterID uint
+	for {
+		ids, err := s.userRepo.FindStudentIDsAfter(afterID, reevaluatePageSize, definition.Level, definition.LevelLetter)
+		if err != nil {
+			return awarded, err
+		}
+		if len(ids) == 0 {
+			break
+		}
+		afterID = ids[len(ids)-1]
+
+		candidates := make([]uint, 0, len(ids))
+		for _, userID := range ids {
+			if !hasAchievement[userID] {
+				candidates = append(candidates, userID)
 			}
-			if err := s.achievementRepo.Create(achievement); err != nil {
-				return err
+		}
+
+		var matches map[uint]time.Time
+		switch definition.Event {
+		case models.EventTestSubmitted:
+			attempts, err := s.testRepo.FindByUserIDs(candidates)
+			if err != nil {
+				return awarded, err
+			}
+			matches = firstTestMatches(rule, attempts, lessons, lessonOrder)
+		case models.EventGameFinished:
+			results, err := s.gameResultRepo.FindByUserIDs(candidates)
+			if err != nil {
+				return awarded, err
+			}
+			matches = firstGameMatches(rule, results)
+		default:
+			matches, err = s.journalMatches(definition.Event, rule, candidates)
+			if err != nil {
+				return awarded, err
+			}
+		}
+
+		for _, userID := range candidates {
+			earnedAt, ok := matches[userID]
+			if !ok {
+				continue
+			}
+			achievement, err := s.award(userID, definition, earnedAt)
+			if err != nil {
+				return awarded, err
+			}
+			hasAchievement[userID] = true
+			if achievement == nil {
+				continue
 			}
+			s.publishEarned(achievement, &EventOutcome{})
+			awarded++
 		}
 	}
 
-	return nil
+	return awarded, nil
 }
 
+// journalMatches время первого события из журнала, на котором выполнялось
+// правило; для серий дополнительно проверяется текущая серия ученика
+func (s *AchievementService) journalMatches(event string, rule ruleNode, userIDs []uint) (map[uint]time.Time, error) {
+	matches := make(map[uint]time.Time)
+	events, err := s.achievementRepo.FindEvents(event, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range events {
+		if _, ok := matches[e.UserID]; !ok && rule.eval(e.Metrics) {
+			matches[e.UserID] = e.OccurredAt
+		}
+	}
+
+	if event != models.EventStreakUpdated {
+		return matches, nil
+	}
+	streaks, err := s.activityRepo.FindStreaks(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range streaks {
+		if _, ok := matches[streaks[i].UserID]; !ok && streakMatches(rule, &streaks[i]) {
+			matches[streaks[i].UserID] = time.Now()
+		}
+	}
+	return matches, nil
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/repositories"
+	"errors"
+	"math"
+)
+
+type AnalyticsService struct {
+	analyticsRepo *repositories.AnalyticsRepository
+	statsService  *StatsService
+}
+
+func NewAnalyticsService(analyticsRepo *repositories.AnalyticsRepository, statsService *StatsService) *AnalyticsService {
+	return &AnalyticsService{
+		analyticsRepo: analyticsRepo,
+		statsService:  statsService,
+	}
+}
+
+type ClassInfo struct {
+	Level         int    `json:"level"`
+	LevelLetter   string `json:"level_letter"`
+	TotalStudents int    `json:"total_students"`
+}
+
+type ClassOverallStats struct {
+	TotalPoints       int     `json:"total_points"`
+	CompletedLessons  int     `json:"completed_lessons"`
+	AveragePercentage float64 `json:"average_percentage"`
+}
+
+type LessonAnalytics struct {
+	LessonID          uint    `json:"lesson_id"`
+	LessonTitle       string  `json:"lesson_title"`
+	LessonOrder       int     `json:"lesson_order"`
+	TotalStudents     int     `json:"total_students"`
+	CompletedCount    int     `json:"completed_count"`
+	CompletionRate    float64 `json:"completion_rate"`
+	AveragePercentage float64 `json:"average_percentage"`
+	TotalAttempts     int     `json:"total_attempts"`
+}
+
+// ClassAnalytics сводка по классу для учителя
+type ClassAnalytics struct {
+	ClassInfo    ClassInfo         `json:"class_info"`
+	OverallStats ClassOverallStats `json:"overall_stats"`
+	LessonsStats []LessonAnalytics `json:"lessons_stats"`
+}
+
+// GetClassAnalytics считает показатели класса по урокам. Для пустого класса
+// все доли и средние равны нулю. Буква класса приходит уже нормализованной.
+func (s *AnalyticsService) GetClassAnalytics(level int, levelLetter string) (*ClassAnalytics, error) {
+	if level < 1 || level > 11 {
+		return nil, errors.New("Класс должен быть от 1 до 11")
+	}
+	if len([]rune(levelLetter)) > 1 {
+		return nil, errors.New("Неверный формат буквы класса")
+	}
+
+	totalStudents, err := s.analyticsRepo.CountClassStudents(level, levelLetter)
+	if err != nil {
+		return nil, err
+	}
+
+	lessonStats, err := s.analyticsRepo.LessonStatsByClass(level, levelLetter)
+	if err != nil {
+		return nil, err
+	}
+
+	classStats, err := s.statsService.GetClassStats(level, levelLetter)
+	if err != nil {
+		return nil, err
+	}
+
+	analytics := &ClassAnalytics{
+		ClassInfo: ClassInfo{
+			Level:         level,
+			LevelLetter:   levelLetter,
+			TotalStudents: int(totalStudents),
+		},
+		OverallStats: ClassOverallStats{
+			TotalPoints:       classStats.TotalPoints,
+			CompletedLessons:  classStats.CompletedLessons,
+			AveragePercentage: classStats.AverageScoredPercentage(),
+		},
+		LessonsStats: make([]LessonAnalytics, len(lessonStats)),
+	}
+
+	for i, stats := range lessonStats {
+		completionRate := 0.0
+		if totalStudents > 0 {
+			completionRate = float64(stats.CompletedCount) / float64(totalStudents) * 100
+		}
+		analytics.LessonsStats[i] = LessonAnalytics{
+			LessonID:          stats.LessonID,
+			LessonTitle:       stats.LessonTitle,
+			LessonOrder:       stats.LessonOrder,
+			TotalStudents:     int(totalStudents),
+			CompletedCount:    stats.CompletedCount,
+			CompletionRate:    completionRate,
+			AveragePercentage: stats.AveragePercentage,
+			TotalAttempts:     stats.TotalAttempts,
+		}
+	}
+
+	return analytics, nil
+}
+
+// TagPerformance результаты по тегу: доля верных ответов на вопросы и задания с этим тегом
+type TagPerformance struct {
+	TagID        uint           `json:"tag_id"`
+	Kind         models.TagKind `json:"kind"`
+	Name         string         `json:"name"`
+	Answers      int            `json:"answers"`
+	CorrectCount int            `json:"correct_count"`
+	Accuracy     float64        `json:"accuracy"`
+	Students     int            `json:"students"`
+}
+
+// GetClassTagStats результаты класса по тегам раздела kind (пустой - все разделы)
+func (s *AnalyticsService) GetClassTagStats(level int, levelLetter string, kind string) ([]TagPerformance, error) {
+	if level < 1 || level > 11 {
+		return nil, errors.New("Класс должен быть от 1 до 11")
+	}
+	if len([]rune(levelLetter)) > 1 {
+		return nil, errors.New("Неверный формат буквы класса")
+	}
+	tagKind, err := parseTagKind(kind, true)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.analyticsRepo.TagStatsByClass(level, levelLetter, tagKind)
+	if err != nil {
+		return nil, err
+	}
+	return tagPerformance(stats), nil
+}
+
+// GetStudentTagStats результаты ученика по тегам: видно, какие темы даются хуже
+func (s *AnalyticsService) GetStudentTagStats(userID uint, kind string) ([]TagPerformance, error) {
+	tagKind, err := parseTagKind(kind, true)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.analyticsRepo.TagStatsByUser(userID, tagKind)
+	if err != nil {
+		return nil, err
+	}
+	return tagPerformance(stats), nil
+}
+
+func tagPerformance(stats []repositories.TagStats) []TagPerformance {
+	result := make([]TagPerformance, len(stats))
+	for i, stat := range stats {
+		accuracy := 0.0
+		if stat.Answers > 0 {
+			accuracy = math.Round(float64(stat.CorrectCount)/float64(stat.Answers)*1000) / 10
+		}
+		result[i] = TagPerformance{
+			TagID:        stat.TagID,
+			Kind:         stat.TagKind,
+			Name:         stat.TagName,
+			Answers:      stat.Answers,
+			CorrectCount: stat.CorrectCount,
+			Accuracy:     accuracy,
+			Students:     stat.Students,
+		}
+	}
+	return result
+}
@@ -16,12 +16,14 @@ import (
 type AuthService struct {
 	userRepo *repositories.UserRepository
 	jwtSecret string
+	eventBus  *EventBus
 }
 
-func NewAuthService(userRepo *repositories.UserRepository, jwtSecret string) *AuthService {
+func NewAuthService(userRepo *repositories.UserRepository, jwtSecret string, eventBus *EventBus) *AuthService {
 	return &AuthService{
 		userRepo:  userRepo,
 		jwtSecret: jwtSecret,
+		eventBus:  eventBus,
 	}
 }
 
@@ -106,6 +108,13 @@ func (s *AuthService) Register(req RegisterRequest) (*models.User, error) {
 		return nil, err
 	}
 
+	s.eventBus.Publish(Event{
+		Type:       models.EventUserRegistered,
+		UserID:     user.ID,
+		OccurredAt: user.CreatedAt,
+		User:       user,
+	})
+
 	return user, nil
 }
 
@@ -0,0 +1,281 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"log"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/repositories"
+	"englishlessons.back/internal/storage"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+const (
+	// MaxAvatarSize предельный размер загружаемого файла
+	MaxAvatarSize = 5 << 20
+	// maxAvatarPixels защита от "бомб": огромных картинок в маленьком файле
+	maxAvatarPixels = 40_000_000
+	avatarQuality   = 85
+)
+
+type AvatarService struct {
+	userRepo *repositories.UserRepository
+	store    storage.Storage
+}
+
+func NewAvatarService(userRepo *repositories.UserRepository, store storage.Storage) *AvatarService {
+	return &AvatarService{userRepo: userRepo, store: store}
+}
+
+// UploadAvatar проверяет картинку по содержимому, обрезает до квадрата,
+// сохраняет стандартные размеры в JPEG без метаданных и заменяет аватар
+// пользователя. Возвращает ссылки на все размеры.
+func (s *AvatarService) UploadAvatar(userID uint, data []byte) (map[string]string, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("Пользователь не найден")
+	}
+	if len(data) == 0 {
+		return nil, errors.New("Файл пустой")
+	}
+	if len(data) > MaxAvatarSize {
+		return nil, errors.New("Файл слишком большой: максимум 5 МБ")
+	}
+
+	source, err := decodeAvatar(data)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	for size, img := range renderAvatar(source, jpegOrientation(data)) {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: avatarQuality}); err != nil {
+			return nil, err
+		}
+		if err := s.store.Put(models.AvatarKey(hash, size), buf.Bytes(), "image/jpeg"); err != nil {
+			return nil, err
+		}
+	}
+
+	avatar := models.MediaURL(models.AvatarKey(hash, models.AvatarSizes[len(models.AvatarSizes)-1]))
+	if err := s.userRepo.UpdateProfile(userID, map[string]interface{}{"avatar": avatar}); err != nil {
+		return nil, err
+	}
+	if user.Avatar != avatar {
+		s.cleanup(user.Avatar)
+	}
+	return models.AvatarVariants(avatar), nil
+}
+
+// DeleteAvatar убирает аватар пользователя
+func (s *AvatarService) DeleteAvatar(userID uint) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("Пользователь не найден")
+	}
+	if user.Avatar == "" {
+		return nil
+	}
+	if err := s.userRepo.UpdateProfile(userID, map[string]interface{}{"avatar": ""}); err != nil {
+		return err
+	}
+	s.cleanup(user.Avatar)
+	return nil
+}
+
+// cleanup удаляет файлы старого аватара, если он больше ни у кого не стоит
+// (одинаковые картинки разных пользователей хранятся одним файлом).
+// Аватары старого формата не трогаем.
+func (s *AvatarService) cleanup(avatar string) {
+	hash, ok := models.AvatarHash(avatar)
+	if !ok {
+		return
+	}
+	count, err := s.userRepo.CountByAvatar(avatar)
+	if err != nil || count > 0 {
+		return
+	}
+	for _, size := range models.AvatarSizes {
+		if err := s.store.Delete(models.AvatarKey(hash, size)); err != nil {
+			log.Printf("Failed to delete old avatar %s: %v", models.AvatarKey(hash, size), err)
+		}
+	}
+}
+
+// decodeAvatar принимает только JPEG, PNG и WebP, определяя формат по сигнатуре
+func decodeAvatar(data []byte) (image.Image, error) {
+	var decodeConfig func([]byte) (image.Config, error)
+	var decode func([]byte) (image.Image, error)
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		decodeConfig = func(b []byte) (image.Config, error) { return jpeg.DecodeConfig(bytes.NewReader(b)) }
+		decode = func(b []byte) (image.Image, error) { return jpeg.Decode(bytes.NewReader(b)) }
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		decodeConfig = func(b []byte) (image.Config, error) { return png.DecodeConfig(bytes.NewReader(b)) }
+		decode = func(b []byte) (image.Image, error) { return png.Decode(bytes.NewReader(b)) }
+	case len(data) >= 12 && bytes.HasPrefix(data, []byte("RIFF")) && string(data[8:12]) == "WEBP":
+		decodeConfig = func(b []byte) (image.Config, error) { return webp.DecodeConfig(bytes.NewReader(b)) }
+		decode = func(b []byte) (image.Image, error) { return webp.Decode(bytes.NewReader(b)) }
+	default:
+		return nil, errors.New("Неподдерживаемый формат: нужен JPEG, PNG или WebP")
+	}
+
+	config, err := decodeConfig(data)
+	if err != nil {
+		return nil, errors.New("Неверное изображение: файл поврежден")
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxAvatarPixels {
+		return nil, errors.New("Изображение слишком большое по размеру в пикселях")
+	}
+	img, err := decode(data)
+	if err != nil {
+		return nil, errors.New("Неверное изображение: файл поврежден")
+	}
+	return img, nil
+}
+
+// renderAvatar вырезает центральный квадрат и масштабирует его до всех
+// стандартных размеров. Прозрачный фон заменяется белым.
+func renderAvatar(source image.Image, orientation int) map[int]image.Image {
+	bounds := source.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(
+		bounds.Min.X+(bounds.Dx()-side)/2,
+		bounds.Min.Y+(bounds.Dy()-side)/2,
+	))
+
+	largest := models.AvatarSizes[len(models.AvatarSizes)-1]
+	base := image.NewRGBA(image.Rect(0, 0, largest, largest))
+	draw.Draw(base, base.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(base, base.Bounds(), source, crop, draw.Over, nil)
+	oriented := orientImage(base, orientation)
+
+	result := make(map[int]image.Image, len(models.AvatarSizes))
+	for _, size := range models.AvatarSizes {
+		if size == largest {
+			result[size] = oriented
+			continue
+		}
+		scaled := image.NewRGBA(image.Rect(0, 0, size, size))
+		draw.CatmullRom.Scale(scaled, scaled.Bounds(), oriented, oriented.Bounds(), draw.Src, nil)
+		result[size] = scaled
+	}
+	return result
+}
+
+// orientImage поворачивает квадратную картинку по тегу EXIF Orientation (1-8)
+func orientImage(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	side := img.Bounds().Dx()
+	last := side - 1
+	result := image.NewRGBA(img.Bounds())
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // отражение по горизонтали
+				sx, sy = last-x, y
+			case 3: // поворот на 180
+				sx, sy = last-x, last-y
+			case 4: // отражение по вертикали
+				sx, sy = x, last-y
+			case 5: // транспонирование
+				sx, sy = y, x
+			case 6: // поворот на 90 по часовой
+				sx, sy = y, last-x
+			case 7: // транспонирование по второй диагонали
+				sx, sy = last-y, last-x
+			case 8: // поворот на 90 против часовой
+				sx, sy = last-y, x
+			}
+			result.SetRGBA(x, y, img.RGBAAt(sx, sy))
+		}
+	}
+	return result
+}
+
+// jpegOrientation читает тег Orientation из блока EXIF (APP1) файла JPEG.
+// Сами метаданные в сохраненный аватар не попадают: картинка кодируется заново.
+func jpegOrientation(data []byte) int {
+	if !bytes.HasPrefix(data, []byte{0xFF, 0xD8}) {
+		return 1
+	}
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return 1
+		}
+		marker := data[pos+1]
+		switch {
+		case marker == 0xFF:
+			pos++
+			continue
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			pos += 2
+			continue
+		case marker == 0xDA || marker == 0xD9:
+			// Дальше данные изображения, EXIF не встретился
+			return 1
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 || pos+2+length > len(data) {
+			return 1
+		}
+		segment := data[pos+4 : pos+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		pos += 2 + length
+	}
+	return 1
+}
+
+// exifOrientation ищет тег 0x0112 в первом каталоге (IFD0) заголовка TIFF
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if value := int(order.Uint16(tiff[entry+8:])); value >= 1 && value <= 8 {
+				return value
+			}
+			return 1
+		}
+	}
+	return 1
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+// exifTIFF заголовок TIFF с одним каталогом IFD0. Тег ориентации идет после
+// тега ImageWidth, чтобы проверить поиск, а не только первую запись.
+func exifTIFF(order binary.ByteOrder, orientation uint16) []byte {
+	var buf bytes.Buffer
+	if order == binary.LittleEndian {
+		buf.WriteString("II")
+	} else {
+		buf.WriteString("MM")
+	}
+	binary.Write(&buf, order, uint16(42))
+	binary.Write(&buf, order, uint32(8))
+	binary.Write(&buf, order, uint16(2))
+	for _, entry := range [][2]uint16{{0x0100, 640}, {0x0112, orientation}} {
+		// Тег, тип SHORT, одно значение и само значение с выравниванием до 4 байт
+		binary.Write(&buf, order, entry[0])
+		binary.Write(&buf, order, uint16(3))
+		binary.Write(&buf, order, uint32(1))
+		binary.Write(&buf, order, entry[1])
+		binary.Write(&buf, order, uint16(0))
+	}
+	binary.Write(&buf, order, uint32(0))
+	return buf.Bytes()
+}
+
+// jpegSegment маркер сегмента JPEG с длиной
+func jpegSegment(marker byte, payload []byte) []byte {
+	segment := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// jpegWith настоящий JPEG, в который после SOI вставлены сегменты
+func jpegWith(t *testing.T, segments ...[]byte) []byte {
+	t.Helper()
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, image.NewRGBA(image.Rect(0, 0, 4, 4)), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte{0xFF, 0xD8}
+	for _, segment := range segments {
+		data = append(data, segment...)
+	}
+	return append(data, encoded.Bytes()[2:]...)
+}
+
+func TestExifOrientation(t *testing.T) {
+	for orientation := uint16(1); orientation <= 8; orientation++ {
+		if got := exifOrientation(exifTIFF(binary.LittleEndian, orientation)); got != int(orientation) {
+			t.Errorf("II orientation %d: got %d", orientation, got)
+		}
+		if got := exifOrientation(exifTIFF(binary.BigEndian, orientation)); got != int(orientation) {
+			t.Errorf("MM orientation %d: got %d", orientation, got)
+		}
+	}
+
+	valid := exifTIFF(binary.BigEndian, 6)
+	outOfRange := exifTIFF(binary.BigEndian, 9)
+	badOrder := append([]byte("XX"), valid[2:]...)
+	badOffset := append([]byte{}, valid...)
+	binary.BigEndian.PutUint32(badOffset[4:], 4000)
+	// Каталог обещает две записи, но вторая обрезана
+	truncated := valid[:8+2+12+6]
+	noTag := exifTIFF(binary.BigEndian, 6)
+	binary.BigEndian.PutUint16(noTag[8+2+12:], 0x0110)
+
+	tests := map[string][]byte{
+		"value out of range": outOfRange,
+		"unknown byte order": badOrder,
+		"offset past end":    badOffset,
+		"truncated entry":    truncated,
+		"no orientation tag": noTag,
+		"too short":          valid[:6],
+		"empty":              nil,
+	}
+	for name, tiff := range tests {
+		if got := exifOrientation(tiff); got != 1 {
+			t.Errorf("%s: got %d, want 1", name, got)
+		}
+	}
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	exif := func(orientation uint16) []byte {
+		return jpegSegment(0xE1, append([]byte("Exif\x00\x00"), exifTIFF(binary.BigEndian, orientation)...))
+	}
+	jfif := jpegSegment(0xE0, []byte("JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00"))
+	xmp := jpegSegment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta/>"))
+
+	plain := jpegWith(t)
+	withExif := jpegWith(t, jfif, xmp, exif(6))
+	// Лишние байты 0xFF перед маркером допустимы
+	padded := jpegWith(t, []byte{0xFF, 0xFF}, exif(8))
+	brokenLength := jpegWith(t, exif(6))
+	binary.BigEndian.PutUint16(brokenLength[4:], 0xFFF0)
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"no exif", plain, 1},
+		{"exif after jfif and xmp", withExif, 6},
+		{"fill bytes", padded, 8},
+		{"exif orientation out of range", jpegWith(t, exif(0)), 1},
+		{"segment longer than file", brokenLength, 1},
+		{"cut inside exif", withExif[:len(jfif)+len(xmp)+12], 1},
+		{"not a jpeg", []byte("\x89PNG\r\n\x1a\n"), 1},
+		{"empty", nil, 1},
+	}
+	for _, tt := range tests {
+		if got := jpegOrientation(tt.data); got != tt.want {
+			t.Errorf("%s: jpegOrientation = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	// EXIF после начала данных изображения не ищется
+	scanFirst := append(append([]byte{}, plain...), exif(6)...)
+	if got := jpegOrientation(scanFirst); got != 1 {
+		t.Errorf("exif after SOS: got %d, want 1", got)
+	}
+
+	// Файл с EXIF остается корректным JPEG
+	if _, err := decodeAvatar(withExif); err != nil {
+		t.Errorf("decodeAvatar: %v", err)
+	}
+}
+
+func TestOrientImage(t *testing.T) {
+	// Исходная картинка 2x2:
+	//   a b
+	//   c d
+	a := color.RGBA{R: 1, A: 255}
+	b := color.RGBA{R: 2, A: 255}
+	c := color.RGBA{R: 3, A: 255}
+	d := color.RGBA{R: 4, A: 255}
+	source := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	source.SetRGBA(0, 0, a)
+	source.SetRGBA(1, 0, b)
+	source.SetRGBA(0, 1, c)
+	source.SetRGBA(1, 1, d)
+
+	// Как картинка должна выглядеть после исправления ориентации, по строкам
+	tests := []struct {
+		orientation int
+		want        [4]color.RGBA
+	}{
+		{1, [4]color.RGBA{a, b, c, d}},
+		{2, [4]color.RGBA{b, a, d, c}},
+		{3, [4]color.RGBA{d, c, b, a}},
+		{4, [4]color.RGBA{c, d, a, b}},
+		{5, [4]color.RGBA{a, c, b, d}},
+		{6, [4]color.RGBA{c, a, d, b}},
+		{7, [4]color.RGBA{d, b, c, a}},
+		{8, [4]color.RGBA{b, d, a, c}},
+		{0, [4]color.RGBA{a, b, c, d}},
+		{9, [4]color.RGBA{a, b, c, d}},
+	}
+	for _, tt := range tests {
+		got := orientImage(source, tt.orientation)
+		pixels := [4]color.RGBA{got.RGBAAt(0, 0), got.RGBAAt(1, 0), got.RGBAAt(0, 1), got.RGBAAt(1, 1)}
+		if pixels != tt.want {
+			t.Errorf("orientation %d: got %v, want %v", tt.orientation, pixels, tt.want)
+		}
+	}
+
+	// Поворот на 90 по часовой и обратно возвращает исходную картинку
+	if back := orientImage(orientImage(source, 6), 8); !bytes.Equal(back.Pix, source.Pix) {
+		t.Error("orientation 6 followed by 8 must be identity")
+	}
+}
@@ -0,0 +1,301 @@
+package services
+
+import (
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/repositories"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// challengeLifetime сколько времени у соперника, чтобы ответить на вызов
+	challengeLifetime = 7 * 24 * time.Hour
+)
+
+var errChallengeRoundIssued = errors.New("Раунд вызова уже выдан")
+
+type ChallengeService struct {
+	challengeRepo       *repositories.ChallengeRepository
+	userRepo            *repositories.UserRepository
+	gameResultRepo      *repositories.GameResultRepository
+	gameSessionService  *GameSessionService
+	notificationService *NotificationService
+}
+
+func NewChallengeService(
+	challengeRepo *repositories.ChallengeRepository,
+	userRepo *repositories.UserRepository,
+	gameResultRepo *repositories.GameResultRepository,
+	gameSessionService *GameSessionService,
+	notificationService *NotificationService,
+) *ChallengeService {
+	return &ChallengeService{
+		challengeRepo:       challengeRepo,
+		userRepo:            userRepo,
+		gameResultRepo:      gameResultRepo,
+		gameSessionService:  gameSessionService,
+		notificationService: notificationService,
+	}
+}
+
+// CreateChallengeRequest вызов одноклассника на игру
+type CreateChallengeRequest struct {
+	OpponentID uint   `json:"opponent_id" binding:"required"`
+	GameType   string `json:"game_type" binding:"required"`
+	Level      int    `json:"level"`
+}
+
+// ChallengeStart вызов и раунд, который игроку нужно сыграть
+type ChallengeStart struct {
+	Challenge *models.Challenge   `json:"challenge"`
+	Session   *models.GameSession `json:"session"`
+}
+
+// CreateChallenge собирает раунд для обоих игроков и сразу выдает его вызывающему
+func (s *ChallengeService) CreateChallenge(challengerID uint, req CreateChallengeRequest) (*ChallengeStart, error) {
+	if req.OpponentID == challengerID {
+		return nil, errors.New("Нельзя вызвать самого себя")
+	}
+
+	challenger, err := s.userRepo.FindByID(challengerID)
+	if err != nil {
+		return nil, err
+	}
+	opponent, err := s.userRepo.FindByID(req.OpponentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Соперник не найден")
+		}
+		return nil, err
+	}
+	if !areClassmates(challenger, opponent) {
+		return nil, errors.New("Вызвать можно только ученика своего класса")
+	}
+
+	round, err := s.gameSessionService.PreviewRound(req.GameType, req.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge := &models.Challenge{
+		ChallengerID: challengerID,
+		OpponentID:   req.OpponentID,
+		GameType:     round.GameType,
+		Level:        round.Level,
+		Round:        round.Round,
+		AnswerKey:    round.AnswerKey,
+		Status:       models.ChallengeOpen,
+		ExpiresAt:    time.Now().Add(challengeLifetime),
+	}
+	if err := s.challengeRepo.Create(challenge); err != nil {
+		return nil, err
+	}
+
+	session, err := s.gameSessionService.StartChallengeSession(challengerID, challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notificationService.Notify(opponent.ID, models.NotificationChallengeReceived,
+		"Новый вызов",
+		fmt.Sprintf("%s вызывает вас на игру %s, уровень %d", challenger.GetFullName(), challenge.GameType, challenge.Level),
+		models.JSONMap{"challenge_id": challenge.ID, "game_type": challenge.GameType, "level": challenge.Level})
+	return &ChallengeStart{Challenge: challenge, Session: session}, nil
+}
+
+// AcceptChallenge выдает сопернику тот же раунд. Сыграть его можно только один раз.
+func (s *ChallengeService) AcceptChallenge(userID, challengeID uint) (*ChallengeStart, error) {
+	challenge, err := s.openChallenge(challengeID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if challenge.OpponentID != userID {
+		return nil, errors.New("Вызов не найден")
+	}
+
+	played, err := s.gameSessionService.HasChallengeSession(challenge.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if played {
+		return nil, errChallengeRoundIssued
+	}
+
+	// Два одновременных запроса могут пройти проверку выше; второй раунд
+	// не даст создать уникальный индекс game_sessions(challenge_id, user_id)
+	session, err := s.gameSessionService.StartChallengeSession(userID, challenge)
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "duplicate") {
+			return nil, errChallengeRoundIssued
+		}
+		return nil, err
+	}
+	return &ChallengeStart{Challenge: challenge, Session: session}, nil
+}
+
+// DeclineChallenge отказ соперника от вызова, пока он его не сыграл
+func (s *ChallengeService) DeclineChallenge(userID, challengeID uint) (*models.Challenge, error) {
+	return s.openChallenge(challengeID, func(challengeRepo *repositories.ChallengeRepository, challenge *models.Challenge) error {
+		if challenge.OpponentID != userID {
+			return errors.New("Вызов не найден")
+		}
+		if challenge.OpponentResultID != nil {
+			return errors.New("Вызов уже сыгран")
+		}
+		challenge.Status = models.ChallengeDeclined
+		return challengeRepo.Update(challenge)
+	})
+}
+
+// HandleEvent засчитывает результат игры в вызов. Когда сыграли оба,
+// определяется победитель: больше очков - победа, поровну - ничья.
+func (s *ChallengeService) HandleEvent(event Event, outcome *EventOutcome) error {
+	if event.Type != models.EventGameFinished || event.GameResult.ChallengeID == nil {
+		return nil
+	}
+	result := event.GameResult
+
+	return s.challengeRepo.DB().Transaction(func(tx *gorm.DB) error {
+		challengeRepo := s.challengeRepo.WithTx(tx)
+
+		challenge, err := challengeRepo.FindByIDForUpdate(*result.ChallengeID)
+		if err != nil {
+			return err
+		}
+		if challenge.Status != models.ChallengeOpen {
+			return nil
+		}
+
+		switch {
+		case result.UserID == challenge.ChallengerID && challenge.ChallengerResultID == nil:
+			challenge.ChallengerResultID = &result.ID
+		case result.UserID == challenge.OpponentID && challenge.OpponentResultID == nil:
+			challenge.OpponentResultID = &result.ID
+		default:
+			return nil
+		}
+
+		if challenge.ChallengerResultID != nil && challenge.OpponentResultID != nil {
+			if err := s.resolve(s.gameResultRepo.WithTx(tx), challenge); err != nil {
+				return err
+			}
+		}
+		return challengeRepo.Update(challenge)
+	})
+}
+
+func (s *ChallengeService) resolve(gameResultRepo *repositories.GameResultRepository, challenge *models.Challenge) error {
+	challengerResult, err := gameResultRepo.FindByID(*challenge.ChallengerResultID)
+	if err != nil {
+		return err
+	}
+	opponentResult, err := gameResultRepo.FindByID(*challenge.OpponentResultID)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case challengerResult.Score > opponentResult.Score:
+		challenge.WinnerID = &challenge.ChallengerID
+	case opponentResult.Score > challengerResult.Score:
+		challenge.WinnerID = &challenge.OpponentID
+	}
+
+	now := time.Now()
+	challenge.Status = models.ChallengeCompleted
+	challenge.CompletedAt = &now
+	return nil
+}
+
+// GetChallenges вызовы пользователя, входящие и исходящие
+func (s *ChallengeService) GetChallenges(userID uint, status string, limit, offset int) ([]models.Challenge, int64, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if err := s.challengeRepo.ExpireOverdue(time.Now()); err != nil {
+		return nil, 0, err
+	}
+	return s.challengeRepo.FindByUser(userID, models.ChallengeStatus(status), limit, offset)
+}
+
+// GetChallenge вызов, доступный только его участникам
+func (s *ChallengeService) GetChallenge(userID, challengeID uint) (*models.Challenge, error) {
+	if err := s.challengeRepo.ExpireOverdue(time.Now()); err != nil {
+		return nil, err
+	}
+	challenge, err := s.challengeRepo.FindByID(challengeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Вызов не найден")
+		}
+		return nil, err
+	}
+	if challenge.ChallengerID != userID && challenge.OpponentID != userID {
+		return nil, errors.New("Вызов не найден")
+	}
+	return challenge, nil
+}
+
+// GetHeadToHead счет личных встреч пользователя с другим учеником
+func (s *ChallengeService) GetHeadToHead(userID, opponentID uint) (*models.HeadToHead, error) {
+	if _, err := s.userRepo.FindByID(opponentID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Ученик не найден")
+		}
+		return nil, err
+	}
+	return s.challengeRepo.HeadToHead(userID, opponentID)
+}
+
+// openChallenge блокирует открытый вызов до конца транзакции и вызывает update,
+// если он задан; просроченный вызов помечается истекшим. Возвращает вызов
+// с игроками и результатами после фиксации транзакции.
+func (s *ChallengeService) openChallenge(challengeID uint, update func(challengeRepo *repositories.ChallengeRepository, challenge *models.Challenge) error) (*models.Challenge, error) {
+	closed := false
+	err := s.challengeRepo.DB().Transaction(func(tx *gorm.DB) error {
+		challengeRepo := s.challengeRepo.WithTx(tx)
+
+		challenge, err := challengeRepo.FindByIDForUpdate(challengeID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("Вызов не найден")
+			}
+			return err
+		}
+		if challenge.Status == models.ChallengeOpen && time.Now().After(challenge.ExpiresAt) {
+			challenge.Status = models.ChallengeExpired
+			if err := challengeRepo.Update(challenge); err != nil {
+				return err
+			}
+		}
+		if challenge.Status != models.ChallengeOpen {
+			closed = true
+			return nil
+		}
+		if update == nil {
+			return nil
+		}
+		return update(challengeRepo, challenge)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if closed {
+		return nil, errors.New("Вызов уже закрыт")
+	}
+	return s.challengeRepo.FindByID(challengeID)
+}
+
+// areClassmates оба пользователя - ученики одного класса
+func areClassmates(a, b *models.User) bool {
+	return a.Role == models.RoleStudent && b.Role == models.RoleStudent &&
+		a.Level != nil && b.Level != nil && *a.Level == *b.Level &&
+		a.LevelLetter == b.LevelLetter
+}
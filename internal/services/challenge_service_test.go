@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/repositories"
+	"englishlessons.back/internal/testdb"
+	"gorm.io/gorm"
+)
+
+func newTestChallengeService(db *gorm.DB) *ChallengeService {
+	gameResultRepo := repositories.NewGameResultRepository(db)
+	return NewChallengeService(
+		repositories.NewChallengeRepository(db),
+		repositories.NewUserRepository(db),
+		gameResultRepo,
+		newTestGameSessionService(db),
+		NewNotificationService(repositories.NewNotificationRepository(db), gameResultRepo),
+	)
+}
+
+// createTestChallenge открытый вызов ученика 0 ученику 1 на Quiz Show
+func createTestChallenge(t *testing.T, db *gorm.DB, students []models.User, expiresAt time.Time) *models.Challenge {
+	t.Helper()
+	challenge := &models.Challenge{
+		ChallengerID: students[0].ID,
+		OpponentID:   students[1].ID,
+		GameType:     models.GameQuizShow,
+		Round:        models.JSONMap{"items": []interface{}{}},
+		AnswerKey:    models.JSONMap{"items": []interface{}{}},
+		Status:       models.ChallengeOpen,
+		ExpiresAt:    expiresAt,
+	}
+	testdb.Create(t, db, challenge)
+	return challenge
+}
+
+// finishChallengeGame сохраняет результат игрока и засчитывает его в вызов
+func finishChallengeGame(t *testing.T, db *gorm.DB, s *ChallengeService, challenge *models.Challenge, userID uint, score int) {
+	t.Helper()
+	result := &models.GameResult{
+		UserID:      userID,
+		GameType:    challenge.GameType,
+		Score:       score,
+		MaxScore:    8,
+		TotalCount:  8,
+		ChallengeID: &challenge.ID,
+	}
+	testdb.Create(t, db, result)
+	event := Event{Type: models.EventGameFinished, UserID: userID, GameResult: result}
+	if err := s.HandleEvent(event, &EventOutcome{}); err != nil {
+		t.Fatalf("HandleEvent: %v", err)
+	}
+}
+
+func TestChallengeResolution(t *testing.T) {
+	tests := []struct {
+		name                      string
+		challengerScore, oppScore int
+		wantWinner                int // номер ученика; -1 - ничья
+	}{
+		{"challenger wins", 6, 4, 0},
+		{"opponent wins", 3, 7, 1},
+		{"tie", 5, 5, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testdb.Open(t)
+			students := testdb.Seed(t, db, 2, 0).Students
+			s := newTestChallengeService(db)
+			challenge := createTestChallenge(t, db, students, time.Now().Add(challengeLifetime))
+
+			finishChallengeGame(t, db, s, challenge, students[0].ID, tt.challengerScore)
+			pending, err := s.GetChallenge(students[0].ID, challenge.ID)
+			if err != nil {
+				t.Fatalf("GetChallenge: %v", err)
+			}
+			if pending.Status != models.ChallengeOpen || pending.ChallengerResultID == nil {
+				t.Fatalf("after one game status = %s, challenger result %v", pending.Status, pending.ChallengerResultID)
+			}
+
+			finishChallengeGame(t, db, s, challenge, students[1].ID, tt.oppScore)
+			resolved, err := s.GetChallenge(students[0].ID, challenge.ID)
+			if err != nil {
+				t.Fatalf("GetChallenge: %v", err)
+			}
+			if resolved.Status != models.ChallengeCompleted || resolved.CompletedAt == nil {
+				t.Fatalf("status = %s, completed at %v", resolved.Status, resolved.CompletedAt)
+			}
+			switch {
+			case tt.wantWinner < 0 && resolved.WinnerID != nil:
+				t.Errorf("tie has winner %d", *resolved.WinnerID)
+			case tt.wantWinner >= 0 && (resolved.WinnerID == nil || *resolved.WinnerID != students[tt.wantWinner].ID):
+				t.Errorf("winner = %v, want %d", resolved.WinnerID, students[tt.wantWinner].ID)
+			}
+		})
+	}
+}
+
+func TestChallengeExpiry(t *testing.T) {
+	db := testdb.Open(t)
+	students := testdb.Seed(t, db, 2, 0).Students
+	s := newTestChallengeService(db)
+	challenge := createTestChallenge(t, db, students, time.Now().Add(-time.Minute))
+
+	if _, err := s.AcceptChallenge(students[1].ID, challenge.ID); err == nil || err.Error() != "Вызов уже закрыт" {
+		t.Fatalf("AcceptChallenge on expired challenge = %v", err)
+	}
+	if _, err := s.DeclineChallenge(students[1].ID, challenge.ID); err == nil || err.Error() != "Вызов уже закрыт" {
+		t.Fatalf("DeclineChallenge on expired challenge = %v", err)
+	}
+
+	// Истечение сохраняется, хотя запрос вернул ошибку
+	expired, err := s.GetChallenge(students[1].ID, challenge.ID)
+	if err != nil {
+		t.Fatalf("GetChallenge: %v", err)
+	}
+	if expired.Status != models.ChallengeExpired {
+		t.Errorf("status = %s, want %s", expired.Status, models.ChallengeExpired)
+	}
+}
+
+func TestAcceptChallengeIssuesRoundOnce(t *testing.T) {
+	db := testdb.Open(t)
+	students := testdb.Seed(t, db, 2, 0).Students
+	s := newTestChallengeService(db)
+	challenge := createTestChallenge(t, db, students, time.Now().Add(challengeLifetime))
+
+	if _, err := s.AcceptChallenge(students[0].ID, challenge.ID); err == nil {
+		t.Fatal("challenger accepted their own challenge")
+	}
+	start, err := s.AcceptChallenge(students[1].ID, challenge.ID)
+	if err != nil {
+		t.Fatalf("AcceptChallenge: %v", err)
+	}
+	if start.Session.ChallengeID == nil || *start.Session.ChallengeID != challenge.ID {
+		t.Fatalf("session challenge = %v, want %d", start.Session.ChallengeID, challenge.ID)
+	}
+	if _, err := s.AcceptChallenge(students[1].ID, challenge.ID); !errors.Is(err, errChallengeRoundIssued) {
+		t.Errorf("second AcceptChallenge = %v, want %v", err, errChallengeRoundIssued)
+	}
+
+	// Уникальный индекс не дает выдать второй раунд в обход проверки
+	_, err = s.gameSessionService.StartChallengeSession(students[1].ID, challenge)
+	if err == nil {
+		t.Error("second session for the same challenge and user was created")
+	}
+}
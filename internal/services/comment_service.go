@@ -0,0 +1,229 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxCommentLength  = 2000
+	defaultThreadPage = 20
+	maxThreadPage     = 100
+)
+
+type CommentService struct {
+	commentRepo         *repositories.CommentRepository
+	notificationService *NotificationService
+}
+
+func NewCommentService(commentRepo *repositories.CommentRepository, notificationService *NotificationService) *CommentService {
+	return &CommentService{commentRepo: commentRepo, notificationService: notificationService}
+}
+
+// CommentRequest новое сообщение в обсуждении работы
+type CommentRequest struct {
+	TargetType models.CommentTarget `json:"target_type" binding:"required"`
+	TargetID   uint                 `json:"target_id" binding:"required"`
+	Body       string               `json:"body" binding:"required"`
+}
+
+// CommentAuthor автор сообщения
+type CommentAuthor struct {
+	ID       uint        `json:"id"`
+	FullName string      `json:"full_name"`
+	Role     models.Role `json:"role"`
+	Avatar   string      `json:"avatar"`
+}
+
+// CommentView сообщение для вывода
+type CommentView struct {
+	ID        uint          `json:"id"`
+	Author    CommentAuthor `json:"author"`
+	Body      string        `json:"body"`
+	IsRead    bool          `json:"is_read"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// CommentThreadView обсуждение целиком
+type CommentThreadView struct {
+	StudentID  uint                 `json:"student_id"`
+	TargetType models.CommentTarget `json:"target_type"`
+	TargetID   uint                 `json:"target_id"`
+	Comments   []CommentView        `json:"comments"`
+}
+
+// ThreadListRequest список обсуждений; StudentID учитывается только для учителя
+type ThreadListRequest struct {
+	StudentID  uint
+	UnreadOnly bool
+	Limit      int
+	Offset     int
+}
+
+// AddComment добавляет сообщение. Учитель пишет к любой работе ученика,
+// ученик - только к своим.
+func (s *CommentService) AddComment(userID uint, role models.Role, req CommentRequest) (*CommentView, error) {
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return nil, errors.New("Необходимо написать комментарий")
+	}
+	if len([]rune(body)) > maxCommentLength {
+		return nil, errors.New("Комментарий слишком длинный: максимум 2000 символов")
+	}
+
+	studentID, err := s.resolveTarget(userID, role, req.TargetType, req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &models.Comment{
+		StudentID:  studentID,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		AuthorID:   userID,
+		AuthorRole: role,
+		Body:       body,
+	}
+	if err := s.commentRepo.Create(comment); err != nil {
+		return nil, err
+	}
+	// Отвечая, автор заодно прочитал сообщения другой стороны
+	if err := s.commentRepo.MarkRead(req.TargetType, req.TargetID, role); err != nil {
+		return nil, err
+	}
+
+	created, err := s.commentRepo.FindByID(comment.ID)
+	if err != nil {
+		return nil, err
+	}
+	view := commentView(created)
+	// Ученику сообщаем об отзыве учителя; учителя видят новые сообщения в списке обсуждений
+	if role == models.RoleTeacher {
+		s.notificationService.Notify(studentID, models.NotificationCommentReceived,
+			"Новый комментарий учителя",
+			commentPreview(body),
+			models.JSONMap{"comment_id": created.ID, "target_type": req.TargetType, "target_id": req.TargetID})
+	}
+	return &view, nil
+}
+
+// GetThread сообщения обсуждения; сообщения другой стороны отмечаются прочитанными
+func (s *CommentService) GetThread(userID uint, role models.Role, targetType models.CommentTarget, targetID uint) (*CommentThreadView, error) {
+	studentID, err := s.resolveTarget(userID, role, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := s.commentRepo.FindThread(targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.commentRepo.MarkRead(targetType, targetID, role); err != nil {
+		return nil, err
+	}
+
+	result := &CommentThreadView{
+		StudentID:  studentID,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Comments:   make([]CommentView, len(comments)),
+	}
+	for i := range comments {
+		// is_read показывает состояние до открытия, чтобы клиент мог выделить новые
+		result.Comments[i] = commentView(&comments[i])
+	}
+	return result, nil
+}
+
+// ListThreads для ученика - отзывы на его работы, для учителя - все
+// обсуждения (или одного ученика), последние активные первыми
+func (s *CommentService) ListThreads(userID uint, role models.Role, req ThreadListRequest) ([]models.CommentThread, int64, error) {
+	if req.Limit <= 0 {
+		req.Limit = defaultThreadPage
+	}
+	if req.Limit > maxThreadPage {
+		req.Limit = maxThreadPage
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	filter := repositories.ThreadFilter{
+		ViewerRole: role,
+		StudentID:  req.StudentID,
+		UnreadOnly: req.UnreadOnly,
+		Limit:      req.Limit,
+		Offset:     req.Offset,
+	}
+	if role == models.RoleStudent {
+		filter.StudentID = userID
+	}
+	threads, total, err := s.commentRepo.FindThreads(filter)
+	if threads == nil {
+		threads = []models.CommentThread{}
+	}
+	return threads, total, err
+}
+
+// UnreadCount непрочитанные сообщения: ученику - от учителей, учителю - от учеников
+func (s *CommentService) UnreadCount(userID uint, role models.Role) (int64, error) {
+	if role == models.RoleStudent {
+		return s.commentRepo.CountUnread(role, userID)
+	}
+	return s.commentRepo.CountUnread(role, 0)
+}
+
+// resolveTarget проверяет работу и доступ к ней, возвращает ученика-владельца
+func (s *CommentService) resolveTarget(userID uint, role models.Role, targetType models.CommentTarget, targetID uint) (uint, error) {
+	switch targetType {
+	case models.CommentTestAttempt, models.CommentGameResult, models.CommentStudent:
+	default:
+		return 0, errors.New("Неверный тип обсуждения")
+	}
+	if targetID == 0 {
+		return 0, errors.New("Неверный ID работы")
+	}
+
+	studentID, err := s.commentRepo.FindTargetOwner(targetType, targetID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, errors.New("Работа не найдена")
+		}
+		return 0, err
+	}
+	if role != models.RoleTeacher && studentID != userID {
+		return 0, errors.New("Обсуждение доступно только ученику и учителям")
+	}
+	return studentID, nil
+}
+
+func commentView(comment *models.Comment) CommentView {
+	return CommentView{
+		ID: comment.ID,
+		Author: CommentAuthor{
+			ID:       comment.AuthorID,
+			FullName: comment.Author.GetFullName(),
+			Role:     comment.AuthorRole,
+			Avatar:   comment.Author.Avatar,
+		},
+		Body:      comment.Body,
+		IsRead:    comment.ReadAt != nil,
+		CreatedAt: comment.CreatedAt,
+	}
+}
+
+// commentPreview начало сообщения для уведомления
+func commentPreview(body string) string {
+	const previewLength = 140
+	runes := []rune(body)
+	if len(runes) <= previewLength {
+		return body
+	}
+	return string(runes[:previewLength]) + "…"
+}
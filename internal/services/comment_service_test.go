@@ -0,0 +1,85 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/repositories"
+	"englishlessons.back/internal/testdb"
+)
+
+// Проверки, которые отклоняют запрос до обращения к базе
+func TestResolveTargetValidation(t *testing.T) {
+	s := NewCommentService(nil, nil)
+	tests := []struct {
+		targetType models.CommentTarget
+		targetID   uint
+		wantErr    string
+	}{
+		{"lesson", 1, "Неверный тип обсуждения"},
+		{"", 1, "Неверный тип обсуждения"},
+		{models.CommentTestAttempt, 0, "Неверный ID работы"},
+		{models.CommentStudent, 0, "Неверный ID работы"},
+	}
+	for _, tt := range tests {
+		_, err := s.resolveTarget(1, models.RoleTeacher, tt.targetType, tt.targetID)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("resolveTarget(%q, %d) error = %v, want %q", tt.targetType, tt.targetID, err, tt.wantErr)
+		}
+	}
+}
+
+func TestResolveTargetAccess(t *testing.T) {
+	db := testdb.Open(t)
+	s := NewCommentService(repositories.NewCommentRepository(db), nil)
+
+	// У второго ученика есть попытка теста и результат игры, у первого работ нет
+	seeded := testdb.Seed(t, db, 2, 1)
+	classmate, owner := seeded.Students[0], seeded.Students[1]
+	teacher := models.User{Username: "comment_teacher", Password: "x", Role: models.RoleTeacher}
+	testdb.Create(t, db, &teacher)
+
+	var attempt models.TestAttempt
+	if err := db.Where("user_id = ?", owner.ID).First(&attempt).Error; err != nil {
+		t.Fatalf("attempt: %v", err)
+	}
+	var result models.GameResult
+	if err := db.Where("user_id = ?", owner.ID).First(&result).Error; err != nil {
+		t.Fatalf("game result: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		user       models.User
+		targetType models.CommentTarget
+		targetID   uint
+		wantErr    string
+	}{
+		{"owner opens own attempt", owner, models.CommentTestAttempt, attempt.ID, ""},
+		{"owner opens own game", owner, models.CommentGameResult, result.ID, ""},
+		{"owner opens own student thread", owner, models.CommentStudent, owner.ID, ""},
+		{"teacher opens any attempt", teacher, models.CommentTestAttempt, attempt.ID, ""},
+		{"teacher opens student thread", teacher, models.CommentStudent, owner.ID, ""},
+
+		{"classmate opens attempt", classmate, models.CommentTestAttempt, attempt.ID, "только ученику и учителям"},
+		{"classmate opens game", classmate, models.CommentGameResult, result.ID, "только ученику и учителям"},
+		{"classmate opens student thread", classmate, models.CommentStudent, owner.ID, "только ученику и учителям"},
+		{"missing attempt", owner, models.CommentTestAttempt, attempt.ID + 1000000, "Работа не найдена"},
+		// Обсуждение ученика в целом открывается только по ID ученика
+
+		{"teacher is not a student", teacher, models.CommentStudent, teacher.ID, "Работа не найдена"},
+	}
+	for _, tt := range tests {
+		studentID, err := s.resolveTarget(tt.user.ID, tt.user.Role, tt.targetType, tt.targetID)
+		if tt.wantErr == "" {
+			if err != nil || studentID != owner.ID {
+				t.Errorf("%s: got %d, %v; want owner %d", tt.name, studentID, err, owner.ID)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"englishlessons.back/internal/models"
+	"log"
+	"sync"
+	"time"
+)
+
+// Event событие предметной области. Публикуется только после фиксации
+// транзакции, поэтому обработчики видят уже сохраненные данные.
+type Event struct {
+	Type       string
+	UserID     uint
+	OccurredAt time.Time
+
+	TestAttempt    *models.TestAttempt
+	IsFirstAttempt bool
+	// LessonCompleted урок пройден впервые этой попыткой
+	LessonCompleted bool
+	GameResult      *models.GameResult
+	Streak          *models.UserStreak
+	Achievement     *models.Achievement
+	User            *models.User
+
+	// Все вопросы теста и задания раунда игры и те из них, на которые
+	// ученик ответил неверно
+	AnsweredQuestionIDs []uint
+	MissedQuestionIDs   []uint
+	PlayedGameItemIDs   []uint
+	MissedGameItemIDs   []uint
+}
+
+// EventOutcome собирает результаты обработки события, которые нужно вернуть клиенту
+type EventOutcome struct {
+	Achievements []models.Achievement
+	XPAwarded    int
+}
+
+// EventListener обработчик событий
+type EventListener interface {
+	HandleEvent(event Event, outcome *EventOutcome) error
+}
+
+// EventBus синхронно доставляет события подписчикам в порядке подписки.
+// Ошибки обработчиков логируются и не прерывают доставку остальным.
+type EventBus struct {
+	mu        sync.RWMutex
+	listeners map[string][]EventListener
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{
+		listeners: make(map[string][]EventListener),
+	}
+}
+
+func (b *EventBus) Subscribe(eventType string, listener EventListener) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.listeners[eventType] = append(b.listeners[eventType], listener)
+}
+
+// Publish доставляет событие и возвращает собранный результат
+func (b *EventBus) Publish(event Event) *EventOutcome {
+	outcome := &EventOutcome{}
+	b.PublishTo(event, outcome)
+	return outcome
+}
+
+// PublishTo доставляет событие, дописывая результаты в outcome.
+// Используется обработчиками, которые порождают производные события.
+func (b *EventBus) PublishTo(event Event, outcome *EventOutcome) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	b.mu.RLock()
+	listeners := b.listeners[event.Type]
+	b.mu.RUnlock()
+
+	for _, listener := range listeners {
+		if err := listener.HandleEvent(event, outcome); err != nil {
+			log.Printf("Failed to handle %s event for user %d: %v", event.Type, event.UserID, err)
+		}
+	}
+}
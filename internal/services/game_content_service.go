@@ -0,0 +1,182 @@
+package services
+
+import (
+	"encoding/json"
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/repositories"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultGameItemPage = 50
+	maxGameItemPage     = 200
+)
+
+type GameContentService struct {
+	itemRepo *repositories.GameItemRepository
+}
+
+func NewGameContentService(itemRepo *repositories.GameItemRepository) *GameContentService {
+	return &GameContentService{itemRepo: itemRepo}
+}
+
+// GameItemRequest создание или изменение задания учителем
+type GameItemRequest struct {
+	GameType string         `json:"game_type" binding:"required"`
+	Level    int            `json:"level"`
+	Payload  models.JSONMap `json:"payload" binding:"required"`
+	IsActive *bool          `json:"is_active"`
+}
+
+// GameItemListRequest фильтры списка заданий
+type GameItemListRequest struct {
+	GameType        string
+	Level           *int
+	TagID           uint
+	IncludeInactive bool
+	Limit           int
+	Offset          int
+}
+
+// ListItems возвращает задания с фильтрами и общее количество
+func (s *GameContentService) ListItems(req GameItemListRequest) ([]models.GameItem, int64, error) {
+	if req.Limit <= 0 {
+		req.Limit = defaultGameItemPage
+	}
+	if req.Limit > maxGameItemPage {
+		req.Limit = maxGameItemPage
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+	return s.itemRepo.FindAll(repositories.GameItemFilter{
+		GameType:        models.GameType(req.GameType),
+		Level:           req.Level,
+		TagID:           req.TagID,
+		IncludeInactive: req.IncludeInactive,
+		Limit:           req.Limit,
+		Offset:          req.Offset,
+	})
+}
+
+func (s *GameContentService) GetItem(id uint) (*models.GameItem, error) {
+	item, err := s.itemRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Задание не найдено")
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *GameContentService) CreateItem(teacherID uint, req GameItemRequest) (*models.GameItem, error) {
+	payload, err := normalizeGameItem(models.GameType(req.GameType), req.Level, req.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &models.GameItem{
+		GameType:  models.GameType(req.GameType),
+		Level:     req.Level,
+		Payload:   payload,
+		IsActive:  req.IsActive == nil || *req.IsActive,
+		CreatedBy: &teacherID,
+	}
+	if err := s.itemRepo.Create(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// UpdateItem изменяет задание. Уже выданные раунды хранят свою копию заданий,
+// поэтому правка не влияет на начатые игры.
+func (s *GameContentService) UpdateItem(id uint, req GameItemRequest) (*models.GameItem, error) {
+	item, err := s.GetItem(id)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := normalizeGameItem(models.GameType(req.GameType), req.Level, req.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	item.GameType = models.GameType(req.GameType)
+	item.Level = req.Level
+	item.Payload = payload
+	if req.IsActive != nil {
+		item.IsActive = *req.IsActive
+	}
+	if err := s.itemRepo.Update(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// DeactivateItem убирает задание из новых раундов
+func (s *GameContentService) DeactivateItem(id uint) error {
+	item, err := s.GetItem(id)
+	if err != nil {
+		return err
+	}
+	item.IsActive = false
+	return s.itemRepo.Update(item)
+}
+
+// ImportItems загружает задания из файла формата models.GameContentFile.
+// Файл проверяется целиком: при ошибке в любом задании ничего не сохраняется.
+func (s *GameContentService) ImportItems(teacherID uint, data []byte) (int, error) {
+	var file models.GameContentFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return 0, errors.New("Неверный формат файла: ожидается JSON с полем items")
+	}
+	if len(file.Items) == 0 {
+		return 0, errors.New("Необходимо указать хотя бы одно задание")
+	}
+
+	items := make([]models.GameItem, len(file.Items))
+	for i, entry := range file.Items {
+		payload, err := normalizeGameItem(entry.GameType, entry.Level, entry.Payload)
+		if err != nil {
+			var gameErr *GameValidationError
+			if !errors.As(err, &gameErr) {
+				return 0, err
+			}
+			return 0, newGameError(gameErr.Code,
+				fmt.Sprintf("items[%d].%s", i, gameErr.Field),
+				fmt.Sprintf("Задание №%d: %s", i+1, gameErr.Message))
+		}
+		items[i] = models.GameItem{
+			GameType:  entry.GameType,
+			Level:     entry.Level,
+			Payload:   payload,
+			IsActive:  true,
+			CreatedBy: &teacherID,
+		}
+	}
+
+	if err := s.itemRepo.CreateBatch(items); err != nil {
+		return 0, err
+	}
+	return len(items), nil
+}
+
+// normalizeGameItem проверяет задание по реестру и типу игры и оставляет в нем только известные поля
+func normalizeGameItem(gameType models.GameType, level int, payload models.JSONMap) (models.JSONMap, error) {
+	if _, err := findGame(string(gameType), level); err != nil {
+		return nil, err
+	}
+
+	item := gameGraders[gameType].newPayload()
+	if err := models.DecodeJSONMap(payload, item); err != nil {
+		return nil, newGameError(GameErrorInvalidItem, "payload", "Неверный формат задания")
+	}
+	if err := item.Validate(); err != nil {
+		return nil, newGameError(GameErrorInvalidItem, "payload", err.Error())
+	}
+	return models.EncodeJSONMap(item)
+}
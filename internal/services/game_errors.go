@@ -0,0 +1,49 @@
+package services
+
+import (
+	"englishlessons.back/internal/models"
+	"fmt"
+)
+
+// Коды ошибок проверки игр, по ним фронтенд отличает причины отказа
+const (
+	GameErrorUnknownGame        = "unknown_game"
+	GameErrorInvalidLevel       = "invalid_level"
+	GameErrorInvalidItem        = "invalid_item"
+	GameErrorInvalidAnswers     = "invalid_answers"
+	GameErrorSessionExpired     = "session_expired"
+	GameErrorSessionSubmitted   = "session_submitted"
+	GameErrorInconsistentResult = "inconsistent_result"
+	GameErrorEndpointRemoved    = "endpoint_removed"
+)
+
+// GameValidationError отказ в запросе к игре с машинным кодом и полем запроса
+type GameValidationError struct {
+	Code    string `json:"code"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"error"`
+}
+
+func (e *GameValidationError) Error() string {
+	return e.Message
+}
+
+func newGameError(code, field, message string) *GameValidationError {
+	return &GameValidationError{Code: code, Field: field, Message: message}
+}
+
+// errNoGameItems уровень есть в реестре, но в банке нет активных заданий для него
+var errNoGameItems = newGameError(GameErrorInvalidLevel, "level", "Для этой игры и уровня нет заданий")
+
+// findGame возвращает описание игры и проверяет уровень
+func findGame(gameType string, level int) (*models.GameDefinition, error) {
+	definition, ok := models.FindGameDefinition(models.GameType(gameType))
+	if !ok {
+		return nil, newGameError(GameErrorUnknownGame, "game_type", fmt.Sprintf("Неизвестная игра: %q", gameType))
+	}
+	if !definition.HasLevel(level) {
+		return nil, newGameError(GameErrorInvalidLevel, "level",
+			fmt.Sprintf("Неверный уровень: у игры %s уровни от 0 до %d", definition.Name, definition.LevelCount-1))
+	}
+	return definition, nil
+}
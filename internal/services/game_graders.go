@@ -0,0 +1,368 @@
+package services
+
+import (
+	"englishlessons.back/internal/models"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// gameGrade итог проверки раунда. Missed - номера заданий раунда, решенных неверно;
+// Feedback - разбор введенных слов по номеру задания.
+type gameGrade struct {
+	Correct  int
+	Total    int
+	Score    int
+	MaxScore int
+	Missed   []int
+	Feedback map[int]*SpellingResult
+}
+
+// gameGrader готовит раунд для клиента и проверяет ответы.
+// prepare возвращает то, что видит клиент, и ключ, который остается на сервере.
+// newPayload создает пустое задание этой игры для разбора и проверки.
+type gameGrader interface {
+	newPayload() models.GameItemPayload
+	prepare(items []models.GameItem, rng *rand.Rand) (round models.JSONMap, key models.JSONMap, err error)
+	grade(key models.JSONMap, answers models.JSONMap) (*gameGrade, error)
+}
+
+var gameGraders = map[models.GameType]gameGrader{
+	models.GameGrammarDetective: grammarDetectiveGrader{},
+	models.GameSentenceBuilder:  sentenceBuilderGrader{},
+	models.GameMemoryCards:      memoryCardsGrader{},
+	models.GameFillGapRace:      fillGapGrader{},
+	models.GameQuizShow:         quizGrader{},
+}
+
+var errInvalidAnswers = newGameError(GameErrorInvalidAnswers, "answers", "Неверный формат ответов")
+
+// questionAnswers ответы на раунд из отдельных заданий: {"answers": [...]}, по одному на задание
+func questionAnswers(answers models.JSONMap, total int) ([]map[string]interface{}, error) {
+	var parsed struct {
+		Answers []map[string]interface{} `json:"answers"`
+	}
+	if err := models.DecodeJSONMap(answers, &parsed); err != nil {
+		return nil, errInvalidAnswers
+	}
+	if len(parsed.Answers) > total {
+		return nil, errInvalidAnswers
+	}
+	return parsed.Answers, nil
+}
+
+// questionGrade оценка игр, где каждое верное задание приносит одно очко
+func questionGrade(solved []bool) *gameGrade {
+	grade := &gameGrade{Total: len(solved), MaxScore: len(solved)}
+	for i, ok := range solved {
+		if ok {
+			grade.Correct++
+		} else {
+			grade.Missed = append(grade.Missed, i)
+		}
+	}
+	grade.Score = grade.Correct
+	return grade
+}
+
+func intAnswer(answer map[string]interface{}, field string) (int, bool) {
+	value, ok := answer[field].(float64)
+	if !ok || value != math.Trunc(value) {
+		return 0, false
+	}
+	return int(value), true
+}
+
+type grammarDetectiveGrader struct{}
+
+func (grammarDetectiveGrader) newPayload() models.GameItemPayload {
+	return &models.GrammarDetectiveItem{}
+}
+
+func (grammarDetectiveGrader) prepare(items []models.GameItem, rng *rand.Rand) (models.JSONMap, models.JSONMap, error) {
+	key := make([]models.GrammarDetectiveItem, len(items))
+	round := make([]map[string]interface{}, len(items))
+	for i := range items {
+		if err := items[i].DecodePayload(&key[i]); err != nil {
+			return nil, nil, err
+		}
+		round[i] = map[string]interface{}{"sentence": key[i].Sentence}
+	}
+	return models.JSONMap{"items": round}, models.JSONMap{"items": key}, nil
+}
+
+func (grammarDetectiveGrader) grade(keyMap models.JSONMap, answersMap models.JSONMap) (*gameGrade, error) {
+	var key struct {
+		Items []models.GrammarDetectiveItem `json:"items"`
+	}
+	if err := models.DecodeJSONMap(keyMap, &key); err != nil {
+		return nil, err
+	}
+	answers, err := questionAnswers(answersMap, len(key.Items))
+	if err != nil {
+		return nil, err
+	}
+
+	solved := make([]bool, len(key.Items))
+	for i, answer := range answers {
+		if index, ok := intAnswer(answer, "word_index"); ok && index == key.Items[i].ErrorIndex {
+			solved[i] = true
+		}
+	}
+	return questionGrade(solved), nil
+}
+
+type sentenceBuilderGrader struct{}
+
+func (sentenceBuilderGrader) newPayload() models.GameItemPayload {
+	return &models.SentenceBuilderItem{}
+}
+
+// prepare перемешивает слова; в ключе правильный порядок записан
+// номерами слов в том виде, в котором их видит клиент
+func (sentenceBuilderGrader) prepare(items []models.GameItem, rng *rand.Rand) (models.JSONMap, models.JSONMap, error) {
+	key := make([]models.SentenceBuilderItem, len(items))
+	round := make([]map[string]interface{}, len(items))
+	for i := range items {
+		var item models.SentenceBuilderItem
+		if err := items[i].DecodePayload(&item); err != nil {
+			return nil, nil, err
+		}
+
+		perm := rng.Perm(len(item.Words))
+		shown := make([]string, len(perm))
+		position := make([]int, len(perm))
+		for shownIndex, wordIndex := range perm {
+			shown[shownIndex] = item.Words[wordIndex]
+			position[wordIndex] = shownIndex
+		}
+		order := make([]int, len(item.CorrectOrder))
+		for j, wordIndex := range item.CorrectOrder {
+			order[j] = position[wordIndex]
+		}
+
+		key[i] = models.SentenceBuilderItem{Words: shown, CorrectOrder: order, Translation: item.Translation}
+		round[i] = map[string]interface{}{
+			"words":       shown,
+			"translation": item.Translation,
+		}
+	}
+	return models.JSONMap{"items": round}, models.JSONMap{"items": key}, nil
+}
+
+// grade сравнивает собранное предложение по словам, чтобы одинаковые слова
+// можно было ставить в любом порядке
+func (sentenceBuilderGrader) grade(keyMap models.JSONMap, answersMap models.JSONMap) (*gameGrade, error) {
+	var key struct {
+		Items []models.SentenceBuilderItem `json:"items"`
+	}
+	if err := models.DecodeJSONMap(keyMap, &key); err != nil {
+		return nil, err
+	}
+	answers, err := questionAnswers(answersMap, len(key.Items))
+	if err != nil {
+		return nil, err
+	}
+
+	solved := make([]bool, len(key.Items))
+	for i, answer := range answers {
+		if sentenceMatches(key.Items[i], answer["order"]) {
+			solved[i] = true
+		}
+	}
+	return questionGrade(solved), nil
+}
+
+func sentenceMatches(item models.SentenceBuilderItem, value interface{}) bool {
+	order, ok := value.([]interface{})
+	if !ok || len(order) != len(item.CorrectOrder) {
+		return false
+	}
+	used := make([]bool, len(item.Words))
+	for j, raw := range order {
+		index, ok := raw.(float64)
+		if !ok || index < 0 || int(index) >= len(item.Words) || used[int(index)] {
+			return false
+		}
+		used[int(index)] = true
+		if item.Words[int(index)] != item.Words[item.CorrectOrder[j]] {
+			return false
+		}
+	}
+	return true
+}
+
+type fillGapGrader struct{}
+
+func (fillGapGrader) newPayload() models.GameItemPayload { return &models.FillGapItem{} }
+
+func (fillGapGrader) prepare(items []models.GameItem, rng *rand.Rand) (models.JSONMap, models.JSONMap, error) {
+	key := make([]models.FillGapItem, len(items))
+	round := make([]map[string]interface{}, len(items))
+	for i := range items {
+		if err := items[i].DecodePayload(&key[i]); err != nil {
+			return nil, nil, err
+		}
+		options := append([]string(nil), key[i].Options...)
+		rng.Shuffle(len(options), func(a, b int) { options[a], options[b] = options[b], options[a] })
+		round[i] = map[string]interface{}{
+			"sentence": key[i].Sentence,
+			"options":  options,
+		}
+	}
+	return models.JSONMap{"items": round}, models.JSONMap{"items": key}, nil
+}
+
+func (fillGapGrader) grade(keyMap models.JSONMap, answersMap models.JSONMap) (*gameGrade, error) {
+	var key struct {
+		Items []models.FillGapItem `json:"items"`
+	}
+	if err := models.DecodeJSONMap(keyMap, &key); err != nil {
+		return nil, err
+	}
+	answers, err := questionAnswers(answersMap, len(key.Items))
+	if err != nil {
+		return nil, err
+	}
+
+	// Ответ - выбранный вариант {"option": ...} или введенное слово {"text": ...}.
+	// Введенное слово засчитывается только при точном написании.
+	solved := make([]bool, len(key.Items))
+	feedback := make(map[int]*SpellingResult)
+	for i, answer := range answers {
+		if text, ok := answer["text"].(string); ok {
+			result := gradeSpelling(text, key.Items[i].AcceptedAnswers())
+			feedback[i] = result
+			solved[i] = result.Correct
+			continue
+		}
+		option, ok := answer["option"].(string)
+		if ok && strings.TrimSpace(option) == key.Items[i].CorrectAnswer {
+			solved[i] = true
+		}
+	}
+	grade := questionGrade(solved)
+	if len(feedback) > 0 {
+		grade.Feedback = feedback
+	}
+	return grade, nil
+}
+
+type quizGrader struct{}
+
+func (quizGrader) newPayload() models.GameItemPayload { return &models.QuizItem{} }
+
+func (quizGrader) prepare(items []models.GameItem, rng *rand.Rand) (models.JSONMap, models.JSONMap, error) {
+	key := make([]models.QuizItem, len(items))
+	round := make([]map[string]interface{}, len(items))
+	for i := range items {
+		if err := items[i].DecodePayload(&key[i]); err != nil {
+			return nil, nil, err
+		}
+		round[i] = map[string]interface{}{
+			"question": key[i].Question,
+			"options":  key[i].Options,
+		}
+	}
+	return models.JSONMap{"items": round}, models.JSONMap{"items": key}, nil
+}
+
+func (quizGrader) grade(keyMap models.JSONMap, answersMap models.JSONMap) (*gameGrade, error) {
+	var key struct {
+		Items []models.QuizItem `json:"items"`
+	}
+	if err := models.DecodeJSONMap(keyMap, &key); err != nil {
+		return nil, err
+	}
+	answers, err := questionAnswers(answersMap, len(key.Items))
+	if err != nil {
+		return nil, err
+	}
+
+	solved := make([]bool, len(key.Items))
+	for i, answer := range answers {
+		if index, ok := intAnswer(answer, "option"); ok && index == key.Items[i].CorrectAnswer {
+			solved[i] = true
+		}
+	}
+	return questionGrade(solved), nil
+}
+
+// memoryCardsGrader раскладывает пары в перемешанные карточки со случайными ID.
+// Клиент присылает все попытки совпадения: {"matches": [[id1, id2], ...]}.
+// Счет - эффективность: доля найденных пар к числу попыток, в процентах.
+type memoryCardsGrader struct{}
+
+func (memoryCardsGrader) newPayload() models.GameItemPayload { return &models.MemoryCardItem{} }
+
+func (memoryCardsGrader) prepare(items []models.GameItem, rng *rand.Rand) (models.JSONMap, models.JSONMap, error) {
+	ids := rng.Perm(len(items) * 2)
+	cards := make([]map[string]interface{}, 0, len(items)*2)
+	cardPairs := make(map[string]interface{}, len(items)*2)
+
+	for i := range items {
+		var pair models.MemoryCardItem
+		if err := items[i].DecodePayload(&pair); err != nil {
+			return nil, nil, err
+		}
+		englishID, russianID := ids[2*i]+1, ids[2*i+1]+1
+		cards = append(cards,
+			map[string]interface{}{"id": englishID, "text": pair.English},
+			map[string]interface{}{"id": russianID, "text": pair.Russian},
+		)
+		cardPairs[strconv.Itoa(englishID)] = i
+		cardPairs[strconv.Itoa(russianID)] = i
+	}
+	rng.Shuffle(len(cards), func(a, b int) { cards[a], cards[b] = cards[b], cards[a] })
+
+	return models.JSONMap{"cards": cards},
+		models.JSONMap{"card_pairs": cardPairs, "pair_count": len(items)},
+		nil
+}
+
+func (memoryCardsGrader) grade(keyMap models.JSONMap, answersMap models.JSONMap) (*gameGrade, error) {
+	var key struct {
+		CardPairs map[string]int `json:"card_pairs"`
+		PairCount int            `json:"pair_count"`
+	}
+	if err := models.DecodeJSONMap(keyMap, &key); err != nil {
+		return nil, err
+	}
+	var answers struct {
+		Matches [][2]int `json:"matches"`
+	}
+	if err := models.DecodeJSONMap(answersMap, &answers); err != nil {
+		return nil, errInvalidAnswers
+	}
+
+	matched := make(map[int]bool, key.PairCount)
+	confused := make(map[int]bool, key.PairCount)
+	for _, match := range answers.Matches {
+		first, ok1 := key.CardPairs[strconv.Itoa(match[0])]
+		second, ok2 := key.CardPairs[strconv.Itoa(match[1])]
+		if !ok1 || !ok2 || match[0] == match[1] {
+			continue
+		}
+		if first == second {
+			matched[first] = true
+		} else {
+			confused[first] = true
+			confused[second] = true
+		}
+	}
+
+	// Ошибкой считается пара, которую путали с другой или так и не нашли
+	var missed []int
+	for pair := 0; pair < key.PairCount; pair++ {
+		if confused[pair] || !matched[pair] {
+			missed = append(missed, pair)
+		}
+	}
+
+	score := 0
+	if moves := len(answers.Matches); moves > 0 {
+		score = int(math.Round(float64(len(matched)) / float64(moves) * 100))
+	}
+	return &gameGrade{Correct: len(matched), Total: key.PairCount, Score: score, MaxScore: 100, Missed: missed}, nil
+}
@@ -7,50 +7,19 @@ import (
 
 type GameResultService struct {
 	gameResultRepo *repositories.GameResultRepository
+	privacyService *PrivacyService
 }
 
-func NewGameResultService(gameResultRepo *repositories.GameResultRepository) *GameResultService {
+func NewGameResultService(
+	gameResultRepo *repositories.GameResultRepository,
+	privacyService *PrivacyService,
+) *GameResultService {
 	return &GameResultService{
 		gameResultRepo: gameResultRepo,
+		privacyService: privacyService,
 	}
 }
 
-// SubmitGameResultRequest запрос на сохранение результата игры
-type SubmitGameResultRequest struct {
-	GameType     string `json:"game_type" binding:"required"`
-	Level        int    `json:"level" binding:"gte=0,lte=10"`
-	Score        int    `json:"score" binding:"gte=0"`
-	MaxScore     int    `json:"max_score" binding:"required,min=1"`
-	TimeSpent    int    `json:"time_spent" binding:"gte=0"`
-	CorrectCount int    `json:"correct_count" binding:"gte=0"`
-	TotalCount   int    `json:"total_count" binding:"required,min=1"`
-}
-
-// SubmitResult сохраняет результат игры
-func (s *GameResultService) SubmitResult(userID uint, req SubmitGameResultRequest) (*models.GameResult, error) {
-	// Вычисляем процент
-	percentage := float64(req.CorrectCount) / float64(req.TotalCount) * 100
-
-	result := &models.GameResult{
-		UserID:       userID,
-		GameType:     models.GameType(req.GameType),
-		Level:        req.Level,
-		Score:        req.Score,
-		MaxScore:     req.MaxScore,
-		Percentage:   percentage,
-		TimeSpent:    req.TimeSpent,
-		CorrectCount: req.CorrectCount,
-		TotalCount:   req.TotalCount,
-	}
-
-	err := s.gameResultRepo.Create(result)
-	if err != nil {
-		return nil, err
-	}
-
-	return result, nil
-}
-
 // GetUserResults получает результаты пользователя
 func (s *GameResultService) GetUserResults(userID uint, gameType string, level *int) ([]models.GameResult, error) {
 	if gameType != "" && level != nil {
@@ -82,12 +51,54 @@ func (s *GameResultService) GetClassStats(level *int, levelLetter string) ([]mod
 	return s.gameResultRepo.GetClassStats(level, levelLetter)
 }
 
-// GetLeaderboard получает рейтинг по игре
-func (s *GameResultService) GetLeaderboard(gameType string, level int, limit int) ([]models.GameResult, error) {
+// GetLeaderboard получает рейтинг по игре с учетом настроек приватности зрителя
+func (s *GameResultService) GetLeaderboard(viewerID uint, viewerRole string, gameType string, level int, limit int) ([]models.GameResult, error) {
 	if limit <= 0 {
 		limit = 10
 	}
-	return s.gameResultRepo.GetLeaderboard(models.GameType(gameType), level, limit)
+
+	policy, err := s.privacyService.PolicyFor(viewerID, viewerRole)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := s.gameResultRepo.GetLeaderboard(models.GameType(gameType), level, limit, policy.ExcludesHidden())
+	if err != nil {
+		return nil, err
+	}
+
+	// Нижняя половина считается от всех учеников рейтинга, а не от показанной страницы
+	total, err := s.gameResultRepo.CountLeaderboard(models.GameType(gameType), level, policy.ExcludesHidden())
+	if err != nil {
+		return nil, err
+	}
+
+	return visibleGameResults(policy, results, int(total)), nil
+}
+
+// visibleGameResults убирает строки, скрытые от зрителя, и подменяет имена
+// по настройкам приватности. results - по одной строке на ученика в порядке
+// мест, total — число учеников во всем рейтинге
+func visibleGameResults(policy *LeaderboardPolicy, results []models.GameResult, total int) []models.GameResult {
+	visible := make([]models.GameResult, 0, len(results))
+	for i, result := range results {
+		if policy.HidesPosition(&result.User, i+1, total) {
+			continue
+		}
+		if policy.NameMode(&result.User) != models.NameModeFull {
+			identity := policy.Identity(&result.User)
+			result.User = models.User{
+				ID:          result.User.ID,
+				FirstName:   identity.FullName,
+				Avatar:      identity.Avatar,
+				Role:        result.User.Role,
+				Level:       result.User.Level,
+				LevelLetter: result.User.LevelLetter,
+			}
+		}
+		visible = append(visible, result)
+	}
+	return visible
 }
 
 // GetRecentResults получает последние результаты (для учителя)
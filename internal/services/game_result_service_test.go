@@ -0,0 +1,58 @@
+package services
+
+import (
+	"testing"
+
+	"englishlessons.back/internal/models"
+)
+
+func TestVisibleGameResultsUsesWholeBoard(t *testing.T) {
+	level := 7
+	policy := &LeaderboardPolicy{
+		viewerID: 100,
+		classSettings: map[string]*models.ClassPrivacySettings{
+			classKey(7, "А"): {Level: 7, LevelLetter: "А", NameMode: models.NameModeFull, HideBottomHalf: true},
+		},
+	}
+	results := make([]models.GameResult, 4)
+	for i := range results {
+		results[i] = models.GameResult{
+			UserID: uint(i + 1),
+			User:   models.User{ID: uint(i + 1), FirstName: "Ученик", Level: &level, LevelLetter: "А"},
+		}
+	}
+
+	// Первые 4 строки из 10 — верхняя половина, видны все
+	if visible := visibleGameResults(policy, results, 10); len(visible) != 4 {
+		t.Fatalf("top 4 of 10: %d visible, want 4", len(visible))
+	}
+	// Если в рейтинге только эти 4 ученика, нижняя половина скрыта
+	if visible := visibleGameResults(policy, results, 4); len(visible) != 2 {
+		t.Fatalf("4 of 4: %d visible, want 2", len(visible))
+	}
+
+	// Свою строку ученик видит всегда
+	policy.viewerID = 4
+	visible := visibleGameResults(policy, results, 4)
+	if len(visible) != 3 || visible[2].UserID != 4 {
+		t.Fatalf("viewer's own row hidden: %+v", visible)
+	}
+}
+
+func TestVisibleGameResultsMasksNames(t *testing.T) {
+	policy := &LeaderboardPolicy{viewerID: 100, classSettings: map[string]*models.ClassPrivacySettings{}}
+	results := []models.GameResult{
+		{UserID: 1, User: models.User{ID: 1, FirstName: "Alice", LastName: "Adams", Username: "alice",
+			Nickname: "Fox", LeaderboardMode: models.NameModeNickname}},
+		{UserID: 2, User: models.User{ID: 2, FirstName: "Bob", LastName: "Brown", Username: "bob",
+			LeaderboardMode: models.NameModeFull}},
+	}
+
+	visible := visibleGameResults(policy, results, 2)
+	if visible[0].User.FirstName != "Fox" || visible[0].User.LastName != "" || visible[0].User.Username != "" {
+		t.Errorf("nickname row = %+v", visible[0].User)
+	}
+	if visible[1].User.Username != "bob" {
+		t.Errorf("full name row = %+v", visible[1].User)
+	}
+}
@@ -0,0 +1,372 @@
+package services
+
+import (
+	"encoding/json"
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/repositories"
+	"errors"
+	"math/rand"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// gameSessionGrace запас к максимальному времени игры на задержки сети
+const gameSessionGrace = 30 * time.Second
+
+// gameSessionMaxMoves сколько попыток открыть пару можно записать в раунд Memory Cards
+const gameSessionMaxMoves = 100
+
+type GameSessionService struct {
+	itemRepo       *repositories.GameItemRepository
+	sessionRepo    *repositories.GameSessionRepository
+	gameResultRepo *repositories.GameResultRepository
+	eventBus       *EventBus
+}
+
+func NewGameSessionService(
+	itemRepo *repositories.GameItemRepository,
+	sessionRepo *repositories.GameSessionRepository,
+	gameResultRepo *repositories.GameResultRepository,
+	eventBus *EventBus,
+) *GameSessionService {
+	return &GameSessionService{
+		itemRepo:       itemRepo,
+		sessionRepo:    sessionRepo,
+		gameResultRepo: gameResultRepo,
+		eventBus:       eventBus,
+	}
+}
+
+// StartGameSessionRequest запрос на новый раунд игры
+type StartGameSessionRequest struct {
+	GameType string `json:"game_type" binding:"required"`
+	Level    int    `json:"level"`
+}
+
+// GameSessionResult результат сданной сессии вместе с достижениями и опытом
+type GameSessionResult struct {
+	GameResult *models.GameResult
+	Outcome    *EventOutcome
+	// Разбор введенных слов по номеру задания раунда
+	Feedback map[int]*SpellingResult
+}
+
+// GameAnswerResult проверка одного ответа: Index - номер ответа в раунде
+// (в Memory Cards - номер попытки), Feedback - разбор введенного слова
+type GameAnswerResult struct {
+	Index    int             `json:"index"`
+	Correct  bool            `json:"correct"`
+	Feedback *SpellingResult `json:"feedback,omitempty"`
+}
+
+// GameRoundPreview раунд вместе с ключом ответов, для проверки заданий учителем
+type GameRoundPreview struct {
+	GameType  models.GameType `json:"game_type"`
+	Level     int             `json:"level"`
+	Round     models.JSONMap  `json:"round"`
+	AnswerKey models.JSONMap  `json:"answer_key"`
+}
+
+// StartSession выбирает случайные задания и выдает раунд без ответов
+func (s *GameSessionService) StartSession(userID uint, req StartGameSessionRequest) (*models.GameSession, error) {
+	definition, err := findGame(req.GameType, req.Level)
+	if err != nil {
+		return nil, err
+	}
+	preview, err := s.buildRound(definition, req.Level)
+	if err != nil {
+		return nil, err
+	}
+	return s.createSession(userID, definition, preview, nil)
+}
+
+// StartChallengeSession выдает игроку раунд вызова: те же задания, что у соперника
+func (s *GameSessionService) StartChallengeSession(userID uint, challenge *models.Challenge) (*models.GameSession, error) {
+	definition, err := findGame(string(challenge.GameType), challenge.Level)
+	if err != nil {
+		return nil, err
+	}
+	preview := &GameRoundPreview{
+		GameType:  challenge.GameType,
+		Level:     challenge.Level,
+		Round:     challenge.Round,
+		AnswerKey: challenge.AnswerKey,
+	}
+	return s.createSession(userID, definition, preview, &challenge.ID)
+}
+
+// HasChallengeSession проверяет, выдавался ли игроку раунд вызова
+func (s *GameSessionService) HasChallengeSession(challengeID, userID uint) (bool, error) {
+	count, err := s.sessionRepo.CountByChallengeAndUser(challengeID, userID)
+	return count > 0, err
+}
+
+func (s *GameSessionService) createSession(userID uint, definition *models.GameDefinition, preview *GameRoundPreview, challengeID *uint) (*models.GameSession, error) {
+	now := time.Now()
+	session := &models.GameSession{
+		UserID:      userID,
+		GameType:    preview.GameType,
+		Level:       preview.Level,
+		Round:       preview.Round,
+		AnswerKey:   preview.AnswerKey,
+		StartedAt:   now,
+		ExpiresAt:   now.Add(time.Duration(definition.MaxTimeSeconds)*time.Second + gameSessionGrace),
+		ChallengeID: challengeID,
+	}
+	if err := s.sessionRepo.Create(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// PreviewRound собирает случайный раунд без создания сессии
+func (s *GameSessionService) PreviewRound(gameType string, level int) (*GameRoundPreview, error) {
+	definition, err := findGame(gameType, level)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildRound(definition, level)
+}
+
+func (s *GameSessionService) buildRound(definition *models.GameDefinition, level int) (*GameRoundPreview, error) {
+	items, err := s.itemRepo.FindActive(definition.ID, level)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, errNoGameItems
+	}
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	rng.Shuffle(len(items), func(a, b int) { items[a], items[b] = items[b], items[a] })
+	if len(items) > definition.RoundSize {
+		items = items[:definition.RoundSize]
+	}
+
+	round, key, err := gameGraders[definition.ID].prepare(items, rng)
+	if err != nil {
+		return nil, err
+	}
+
+	// Номера заданий в порядке раунда нужны, чтобы вернуть ошибки ученика на повторение
+	itemIDs := make([]uint, len(items))
+	for i := range items {
+		itemIDs[i] = items[i].ID
+	}
+	key["item_ids"] = itemIDs
+	return &GameRoundPreview{GameType: definition.ID, Level: level, Round: round, AnswerKey: key}, nil
+}
+
+// SubmitSession проверяет ответы по ключу сессии и сохраняет результат.
+// Время игры считается по часам сервера, повторная сдача невозможна.
+func (s *GameSessionService) SubmitSession(userID, sessionID uint, answers models.JSONMap) (*GameSessionResult, error) {
+	var result *models.GameResult
+	var playedItemIDs, missedItemIDs []uint
+	var feedback map[int]*SpellingResult
+
+	err := s.sessionRepo.DB().Transaction(func(tx *gorm.DB) error {
+		sessionRepo := s.sessionRepo.WithTx(tx)
+
+		now := time.Now()
+		session, err := lockOpenSession(sessionRepo, userID, sessionID, now)
+		if err != nil {
+			return err
+		}
+
+		definition, ok := models.FindGameDefinition(session.GameType)
+		if !ok {
+			return newGameError(GameErrorUnknownGame, "game_type", "Игра больше не поддерживается")
+		}
+
+		// Ответы, отправленные по одному, уже проверены и показаны игроку,
+		// поэтому при сдаче их нельзя заменить другими
+		if len(session.Answers) > 0 {
+			answers = session.Answers
+		}
+
+		grade, err := gameGraders[session.GameType].grade(session.AnswerKey, answers)
+		if err != nil {
+			return err
+		}
+		if err := checkGrade(definition, grade); err != nil {
+			return err
+		}
+		feedback = grade.Feedback
+		playedItemIDs = roundGameItems(session.AnswerKey)
+		missedItemIDs = missedGameItems(playedItemIDs, grade.Missed)
+
+		percentage := 0.0
+		if grade.Total > 0 {
+			percentage = float64(grade.Correct) / float64(grade.Total) * 100
+		}
+
+		result = &models.GameResult{
+			UserID:       userID,
+			GameType:     session.GameType,
+			Level:        session.Level,
+			Score:        grade.Score,
+			MaxScore:     grade.MaxScore,
+			Percentage:   percentage,
+			TimeSpent:    min(int(now.Sub(session.StartedAt).Seconds()), definition.MaxTimeSeconds),
+			CorrectCount: grade.Correct,
+			TotalCount:   grade.Total,
+			SessionID:    &session.ID,
+			ChallengeID:  session.ChallengeID,
+		}
+		if err := s.gameResultRepo.WithTx(tx).Create(result); err != nil {
+			return err
+		}
+
+		session.SubmittedAt = &now
+		session.GameResultID = &result.ID
+		return sessionRepo.Update(session)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	outcome := s.eventBus.Publish(Event{
+		Type:              models.EventGameFinished,
+		UserID:            userID,
+		OccurredAt:        result.CreatedAt,
+		GameResult:        result,
+		PlayedGameItemIDs: playedItemIDs,
+		MissedGameItemIDs: missedItemIDs,
+	})
+
+	return &GameSessionResult{GameResult: result, Outcome: outcome, Feedback: feedback}, nil
+}
+
+// AnswerSession записывает один ответ раунда и сразу сообщает, верен ли он.
+// В Memory Cards ответ - попытка открыть пару {"cards": [id1, id2]}, в остальных
+// играх - ответ на следующее задание в том же виде, что и при сдаче раунда.
+// Записанный ответ изменить нельзя: при сдаче проверяются именно записанные ответы.
+func (s *GameSessionService) AnswerSession(userID, sessionID uint, answer models.JSONMap) (*GameAnswerResult, error) {
+	var result *GameAnswerResult
+
+	err := s.sessionRepo.DB().Transaction(func(tx *gorm.DB) error {
+		sessionRepo := s.sessionRepo.WithTx(tx)
+
+		session, err := lockOpenSession(sessionRepo, userID, sessionID, time.Now())
+		if err != nil {
+			return err
+		}
+		grader, ok := gameGraders[session.GameType]
+		if !ok {
+			return newGameError(GameErrorUnknownGame, "game_type", "Игра больше не поддерживается")
+		}
+
+		var recorded struct {
+			Answers []interface{} `json:"answers"`
+			Matches [][2]int      `json:"matches"`
+		}
+		if err := models.DecodeJSONMap(session.Answers, &recorded); err != nil {
+			return err
+		}
+
+		if session.GameType == models.GameMemoryCards {
+			var move struct {
+				Cards [2]int `json:"cards"`
+			}
+			if err := models.DecodeJSONMap(answer, &move); err != nil || move.Cards[0] == move.Cards[1] {
+				return errInvalidAnswers
+			}
+			if len(recorded.Matches) >= gameSessionMaxMoves {
+				return newGameError(GameErrorInvalidAnswers, "answer", "Слишком много попыток в одном раунде")
+			}
+			grade, err := grader.grade(session.AnswerKey, models.JSONMap{"matches": [][2]int{move.Cards}})
+			if err != nil {
+				return err
+			}
+			recorded.Matches = append(recorded.Matches, move.Cards)
+			session.Answers = models.JSONMap{"matches": recorded.Matches}
+			result = &GameAnswerResult{Index: len(recorded.Matches) - 1, Correct: grade.Correct == 1}
+			return sessionRepo.Update(session)
+		}
+
+		if len(recorded.Answers) >= roundLength(session.Round) {
+			return newGameError(GameErrorInvalidAnswers, "answer", "На все задания раунда уже есть ответы")
+		}
+		recorded.Answers = append(recorded.Answers, map[string]interface{}(answer))
+		grade, err := grader.grade(session.AnswerKey, models.JSONMap{"answers": recorded.Answers})
+		if err != nil {
+			return err
+		}
+
+		index := len(recorded.Answers) - 1
+		session.Answers = models.JSONMap{"answers": recorded.Answers}
+		result = &GameAnswerResult{Index: index, Correct: !slices.Contains(grade.Missed, index), Feedback: grade.Feedback[index]}
+		return sessionRepo.Update(session)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// lockOpenSession блокирует сессию игрока до конца транзакции и проверяет,
+// что ее еще можно отвечать и сдавать
+func lockOpenSession(sessionRepo *repositories.GameSessionRepository, userID, sessionID uint, now time.Time) (*models.GameSession, error) {
+	session, err := sessionRepo.FindByIDForUpdate(sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Сессия не найдена")
+		}
+		return nil, err
+	}
+	if session.UserID != userID {
+		return nil, errors.New("Сессия не найдена")
+	}
+	if session.SubmittedAt != nil {
+		return nil, newGameError(GameErrorSessionSubmitted, "", "Сессия уже завершена")
+	}
+	if now.After(session.ExpiresAt) {
+		return nil, newGameError(GameErrorSessionExpired, "", "Время сессии истекло")
+	}
+	return session, nil
+}
+
+// roundLength число заданий в раунде из отдельных заданий
+func roundLength(round models.JSONMap) int {
+	var parsed struct {
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := models.DecodeJSONMap(round, &parsed); err != nil {
+		return 0
+	}
+	return len(parsed.Items)
+}
+
+// checkGrade страховка от ошибок в проверке: результат должен укладываться в правила игры
+func checkGrade(definition *models.GameDefinition, grade *gameGrade) error {
+	if grade.Total <= 0 || grade.Total > definition.RoundSize ||
+		grade.Correct < 0 || grade.Correct > grade.Total ||
+		grade.Score < 0 || grade.Score > grade.MaxScore || grade.MaxScore > definition.MaxScore {
+		return newGameError(GameErrorInconsistentResult, "", "Результат игры не согласуется с правилами игры")
+	}
+	return nil
+}
+
+// roundGameItems ID заданий банка в порядке заданий раунда
+func roundGameItems(key models.JSONMap) []uint {
+	var parsed struct {
+		ItemIDs []uint `json:"item_ids"`
+	}
+	if err := models.DecodeJSONMap(key, &parsed); err != nil {
+		return nil
+	}
+	return parsed.ItemIDs
+}
+
+// missedGameItems переводит номера заданий раунда в ID заданий банка
+func missedGameItems(itemIDs []uint, missed []int) []uint {
+	ids := make([]uint, 0, len(missed))
+	for _, index := range missed {
+		if index >= 0 && index < len(itemIDs) {
+			ids = append(ids, itemIDs[index])
+		}
+	}
+	return ids
+}
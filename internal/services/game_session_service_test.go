@@ -0,0 +1,121 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/repositories"
+	"englishlessons.back/internal/testdb"
+	"gorm.io/gorm"
+)
+
+func newTestGameSessionService(db *gorm.DB) *GameSessionService {
+	return NewGameSessionService(
+		repositories.NewGameItemRepository(db),
+		repositories.NewGameSessionRepository(db),
+		repositories.NewGameResultRepository(db),
+		NewEventBus(),
+	)
+}
+
+func TestAnswerSessionRecordsAnswers(t *testing.T) {
+	db := testdb.Open(t)
+	student := testdb.Seed(t, db, 1, 0).Students[0]
+	for i := 0; i < 8; i++ {
+		testdb.Create(t, db, &models.GameItem{GameType: models.GameQuizShow, Level: 0, IsActive: true, Payload: models.JSONMap{
+			"question": fmt.Sprintf("%d + 1?", i), "options": []string{strconv.Itoa(i + 1), strconv.Itoa(i + 2)}, "correct_answer": 0,
+		}})
+	}
+	service := newTestGameSessionService(db)
+
+	session, err := service.StartSession(student.ID, StartGameSessionRequest{GameType: string(models.GameQuizShow)})
+	if err != nil {
+		t.Fatalf("StartSession: %v", err)
+	}
+	var key struct {
+		Items []models.QuizItem `json:"items"`
+	}
+	if err := models.DecodeJSONMap(session.AnswerKey, &key); err != nil || len(key.Items) != 8 {
+		t.Fatalf("answer key: %d items, %v", len(key.Items), err)
+	}
+
+	answer, err := service.AnswerSession(student.ID, session.ID, models.JSONMap{"option": key.Items[0].CorrectAnswer})
+	if err != nil || answer.Index != 0 || !answer.Correct {
+		t.Fatalf("correct answer = %+v, %v", answer, err)
+	}
+	wrong := (key.Items[1].CorrectAnswer + 1) % len(key.Items[1].Options)
+	answer, err = service.AnswerSession(student.ID, session.ID, models.JSONMap{"option": wrong})
+	if err != nil || answer.Index != 1 || answer.Correct {
+		t.Fatalf("wrong answer = %+v, %v", answer, err)
+	}
+
+	// При сдаче проверяются записанные ответы, а не присланные заново
+	forged := make([]interface{}, len(key.Items))
+	for i, item := range key.Items {
+		forged[i] = map[string]interface{}{"option": item.CorrectAnswer}
+	}
+	result, err := service.SubmitSession(student.ID, session.ID, models.JSONMap{"answers": forged})
+	if err != nil {
+		t.Fatalf("SubmitSession: %v", err)
+	}
+	if result.GameResult.CorrectCount != 1 || result.GameResult.TotalCount != 8 {
+		t.Errorf("result = %d/%d, want 1/8", result.GameResult.CorrectCount, result.GameResult.TotalCount)
+	}
+
+	var gameErr *GameValidationError
+	_, err = service.AnswerSession(student.ID, session.ID, models.JSONMap{"option": 0})
+	if !errors.As(err, &gameErr) || gameErr.Code != GameErrorSessionSubmitted {
+		t.Errorf("answer after submit = %v, want %s", err, GameErrorSessionSubmitted)
+	}
+}
+
+func TestAnswerSessionChecksMemoryCardMoves(t *testing.T) {
+	db := testdb.Open(t)
+	student := testdb.Seed(t, db, 1, 0).Students[0]
+	for i := 0; i < 6; i++ {
+		testdb.Create(t, db, &models.GameItem{GameType: models.GameMemoryCards, Level: 0, IsActive: true, Payload: models.JSONMap{
+			"english": fmt.Sprintf("word %d", i), "russian": fmt.Sprintf("слово %d", i),
+		}})
+	}
+	service := newTestGameSessionService(db)
+
+	session, err := service.StartSession(student.ID, StartGameSessionRequest{GameType: string(models.GameMemoryCards)})
+	if err != nil {
+		t.Fatalf("StartSession: %v", err)
+	}
+	var key struct {
+		CardPairs map[string]int `json:"card_pairs"`
+	}
+	if err := models.DecodeJSONMap(session.AnswerKey, &key); err != nil {
+		t.Fatalf("answer key: %v", err)
+	}
+	cardsByPair := make(map[int][]int)
+	for id, pair := range key.CardPairs {
+		cardID, _ := strconv.Atoi(id)
+		cardsByPair[pair] = append(cardsByPair[pair], cardID)
+	}
+
+	miss, err := service.AnswerSession(student.ID, session.ID, models.JSONMap{"cards": []int{cardsByPair[0][0], cardsByPair[1][0]}})
+	if err != nil || miss.Correct {
+		t.Fatalf("different pairs = %+v, %v", miss, err)
+	}
+	hit, err := service.AnswerSession(student.ID, session.ID, models.JSONMap{"cards": cardsByPair[0]})
+	if err != nil || !hit.Correct || hit.Index != 1 {
+		t.Fatalf("same pair = %+v, %v", hit, err)
+	}
+	if _, err := service.AnswerSession(student.ID, session.ID, models.JSONMap{"cards": []int{cardsByPair[2][0], cardsByPair[2][0]}}); err == nil {
+		t.Error("the same card twice must be rejected")
+	}
+
+	// Эффективность считается по записанным попыткам: 1 пара за 2 попытки
+	result, err := service.SubmitSession(student.ID, session.ID, models.JSONMap{"matches": [][2]int{}})
+	if err != nil {
+		t.Fatalf("SubmitSession: %v", err)
+	}
+	if result.GameResult.Score != 50 || result.GameResult.CorrectCount != 1 {
+		t.Errorf("result score %d, correct %d, want 50 and 1", result.GameResult.Score, result.GameResult.CorrectCount)
+	}
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/repositories"
+)
+
+// GradeScale переводит процент в оценку по пятибалльной шкале: элементы -
+// минимальный процент для оценок 5, 4, 3 и 2, ниже последнего порога - 1
+type GradeScale [4]float64
+
+// ParseGradeScale читает пороги вида "90,75,60,40"
+func ParseGradeScale(value string) (GradeScale, error) {
+	var scale GradeScale
+	parts := strings.Split(value, ",")
+	if len(parts) != len(scale) {
+		return scale, errors.New("Неверная шкала оценок: нужно 4 порога для оценок 5, 4, 3 и 2")
+	}
+	for i, part := range parts {
+		threshold, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || threshold <= 0 || threshold > 100 {
+			return scale, errors.New("Неверная шкала оценок: пороги должны быть от 0 до 100")
+		}
+		if i > 0 && threshold >= scale[i-1] {
+			return scale, errors.New("Неверная шкала оценок: пороги должны убывать")
+		}
+		scale[i] = threshold
+	}
+	return scale, nil
+}
+
+// Grade оценка от 1 до 5
+func (s GradeScale) Grade(percentage float64) int {
+	for i, threshold := range s {
+		if percentage >= threshold {
+			return 5 - i
+		}
+	}
+	return 1
+}
+
+func (s GradeScale) String() string {
+	parts := make([]string, len(s))
+	for i, threshold := range s {
+		parts[i] = strconv.FormatFloat(threshold, 'f', -1, 64)
+	}
+	return strings.Join(parts, ",")
+}
+
+type GradebookService struct {
+	progressRepo *repositories.ProgressRepository
+	userRepo     *repositories.UserRepository
+	lessonRepo   *repositories.LessonRepository
+	scale        GradeScale
+	location     *time.Location
+}
+
+func NewGradebookService(
+	progressRepo *repositories.ProgressRepository,
+	userRepo *repositories.UserRepository,
+	lessonRepo *repositories.LessonRepository,
+	scale GradeScale,
+	location *time.Location,
+) *GradebookService {
+	if location == nil {
+		location = time.UTC
+	}
+	return &GradebookService{
+		progressRepo: progressRepo,
+		userRepo:     userRepo,
+		lessonRepo:   lessonRepo,
+		scale:        scale,
+		location:     location,
+	}
+}
+
+// GradebookRequest фильтры выгрузки: класс (level и level_letter),
+// период from..to включительно в формате 2006-01-02 и своя шкала оценок
+type GradebookRequest struct {
+	Level       string
+	LevelLetter string
+	From        string
+	To          string
+	Scale       string
+}
+
+// GradebookClass класс школы: параллель и буква
+type GradebookClass struct {
+	Level       int
+	LevelLetter string
+}
+
+// Title название класса вида "7-А"
+func (c GradebookClass) Title() string {
+	return fmt.Sprintf("%d-%s", c.Level, c.LevelLetter)
+}
+
+// GradebookCell результат ученика по уроку
+type GradebookCell struct {
+	Percentage    float64
+	Grade         int
+	AttemptsCount int
+	LastAttemptAt time.Time
+}
+
+// Gradebook журнал оценок: ученики по классам, уроки по порядку и
+// оценки Cells[ученик][урок]; урок без попыток в Cells отсутствует
+type Gradebook struct {
+	Students    []models.User
+	Teachers    []models.User
+	Classes     []GradebookClass
+	Lessons     []models.Lesson
+	Cells       map[uint]map[uint]GradebookCell
+	Scale       GradeScale
+	From        *time.Time
+	To          *time.Time // не включая
+	GeneratedAt time.Time
+}
+
+// AverageGrade средняя оценка ученика по урокам с попытками
+func (g *Gradebook) AverageGrade(studentID uint) (float64, bool) {
+	cells := g.Cells[studentID]
+	if len(cells) == 0 {
+		return 0, false
+	}
+	total := 0
+	for _, cell := range cells {
+		total += cell.Grade
+	}
+	return math.Round(float64(total)/float64(len(cells))*100) / 100, true
+}
+
+// Build собирает журнал оценок по фильтрам
+func (s *GradebookService) Build(req GradebookRequest) (*Gradebook, error) {
+	scale := s.scale
+	if req.Scale != "" {
+		parsed, err := ParseGradeScale(req.Scale)
+		if err != nil {
+			return nil, err
+		}
+		scale = parsed
+	}
+
+	from, err := s.parseDate(req.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.parseDate(req.To)
+	if err != nil {
+		return nil, err
+	}
+	if to != nil {
+		// Дата окончания включительно
+		next := to.AddDate(0, 0, 1)
+		to = &next
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, errors.New("Неверный период: дата начала позже даты окончания")
+	}
+
+	filters := make(map[string]interface{})
+	if req.Level != "" {
+		level, err := strconv.Atoi(req.Level)
+		if err != nil || level < 1 || level > 11 {
+			return nil, errors.New("Неверный класс: от 1 до 11")
+		}
+		filters["level"] = level
+	}
+	if req.LevelLetter != "" {
+		letter := strings.ToUpper(strings.TrimSpace(req.LevelLetter))
+		if len([]rune(letter)) != 1 {
+			return nil, errors.New("Неверная буква класса")
+		}
+		filters["level_letter"] = letter
+	}
+
+	students, err := s.userRepo.FindStudents(filters)
+	if err != nil {
+		return nil, err
+	}
+	teachers, err := s.userRepo.FindTeachers()
+	if err != nil {
+		return nil, err
+	}
+	lessons, err := s.lessonRepo.FindAll(true)
+	if err != nil {
+		return nil, err
+	}
+
+	studentIDs := make([]uint, len(students))
+	for i, student := range students {
+		studentIDs[i] = student.ID
+	}
+	entries, err := s.progressRepo.FindGradebookEntries(studentIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	activeLessons := make(map[uint]bool, len(lessons))
+	for _, lesson := range lessons {
+		activeLessons[lesson.ID] = true
+	}
+	cells := make(map[uint]map[uint]GradebookCell, len(students))
+	for _, entry := range entries {
+		if !activeLessons[entry.LessonID] {
+			continue
+		}
+		if cells[entry.UserID] == nil {
+			cells[entry.UserID] = make(map[uint]GradebookCell)
+		}
+		cells[entry.UserID][entry.LessonID] = GradebookCell{
+			Percentage:    entry.BestPercentage,
+			Grade:         scale.Grade(entry.BestPercentage),
+			AttemptsCount: entry.AttemptsCount,
+			LastAttemptAt: entry.LastAttemptAt,
+		}
+	}
+
+	// Ученики уже отсортированы по классу, поэтому классы идут по порядку
+	var classes []GradebookClass
+	seen := make(map[GradebookClass]bool)
+	for _, student := range students {
+		class, ok := studentClass(&student)
+		if ok && !seen[class] {
+			seen[class] = true
+			classes = append(classes, class)
+		}
+	}
+
+	return &Gradebook{
+		Students:    students,
+		Teachers:    teachers,
+		Classes:     classes,
+		Lessons:     lessons,
+		Cells:       cells,
+		Scale:       scale,
+		From:        from,
+		To:          to,
+		GeneratedAt: time.Now().In(s.location),
+	}, nil
+}
+
+func (s *GradebookService) parseDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.ParseInLocation(dayLayout, value, s.location)
+	if err != nil {
+		return nil, errors.New("Неверная дата: нужен формат ГГГГ-ММ-ДД")
+	}
+	return &date, nil
+}
+
+// studentClass класс ученика; ученики без класса в выгрузку классов не попадают
+func studentClass(student *models.User) (GradebookClass, bool) {
+	if student.Level == nil || student.LevelLetter == "" {
+		return GradebookClass{}, false
+	}
+	return GradebookClass{Level: *student.Level, LevelLetter: strings.ToUpper(student.LevelLetter)}, true
+}
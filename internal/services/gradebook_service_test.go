@@ -0,0 +1,90 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseGradeScale(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    GradeScale
+		wantErr string
+	}{
+		{"90,75,60,40", GradeScale{90, 75, 60, 40}, ""},
+		{" 95 , 80.5 ,65, 50 ", GradeScale{95, 80.5, 65, 50}, ""},
+		{"100,1.5,1,0.5", GradeScale{100, 1.5, 1, 0.5}, ""},
+
+		{"", GradeScale{}, "нужно 4 порога"},
+		{"90,75,60", GradeScale{}, "нужно 4 порога"},
+		{"90,75,60,40,20", GradeScale{}, "нужно 4 порога"},
+		{"90;75;60;40", GradeScale{}, "нужно 4 порога"},
+		{"90,75,sixty,40", GradeScale{}, "от 0 до 100"},
+		{"90,75,60,", GradeScale{}, "от 0 до 100"},
+		{"90,75,60,0", GradeScale{}, "от 0 до 100"},
+		{"101,75,60,40", GradeScale{}, "от 0 до 100"},
+		{"90,75,60,-40", GradeScale{}, "от 0 до 100"},
+		{"90,75,75,40", GradeScale{}, "должны убывать"},
+		{"40,60,75,90", GradeScale{}, "должны убывать"},
+	}
+	for _, tt := range tests {
+		got, err := ParseGradeScale(tt.value)
+		if tt.wantErr == "" {
+			if err != nil || got != tt.want {
+				t.Errorf("ParseGradeScale(%q) = %v, %v; want %v", tt.value, got, err, tt.want)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ParseGradeScale(%q) error = %v, want %q", tt.value, err, tt.wantErr)
+		}
+	}
+}
+
+func TestGradeScaleGrade(t *testing.T) {
+	scale := GradeScale{90, 75, 60, 40}
+	tests := []struct {
+		percentage float64
+		want       int
+	}{
+		{100, 5},
+		{90, 5},
+		{89.99, 4},
+		{75, 4},
+		{74.5, 3},
+		{60, 3},
+		{59, 2},
+		{40, 2},
+		{39.9, 1},
+		{0, 1},
+	}
+	for _, tt := range tests {
+		if got := scale.Grade(tt.percentage); got != tt.want {
+			t.Errorf("Grade(%v) = %d, want %d", tt.percentage, got, tt.want)
+		}
+	}
+}
+
+func TestGradeScaleString(t *testing.T) {
+	for _, value := range []string{"90,75,60,40", "95,80.5,65,50"} {
+		scale, err := ParseGradeScale(value)
+		if err != nil {
+			t.Fatalf("ParseGradeScale(%q): %v", value, err)
+		}
+		if scale.String() != value {
+			t.Errorf("String() = %q, want %q", scale.String(), value)
+		}
+	}
+}
+
+func TestGradebookAverageGrade(t *testing.T) {
+	gradebook := &Gradebook{Cells: map[uint]map[uint]GradebookCell{
+		1: {10: {Grade: 5}, 11: {Grade: 4}, 12: {Grade: 4}},
+	}}
+	if got, ok := gradebook.AverageGrade(1); !ok || got != 4.33 {
+		t.Errorf("AverageGrade(1) = %v, %v; want 4.33", got, ok)
+	}
+	if _, ok := gradebook.AverageGrade(2); ok {
+		t.Error("student without attempts must have no average")
+	}
+}
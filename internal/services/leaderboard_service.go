@@ -4,23 +4,48 @@ import (
 	"englishlessons.back/internal/models"
 	"englishlessons.back/internal/repositories"
 	"errors"
-	"sort"
 	"strconv"
 	"strings"
+	"time"
+)
+
+// Периоды рейтинга
+const (
+	PeriodAll    = "all"
+	PeriodWeek   = "week"
+	PeriodMonth  = "month"
+	PeriodSeason = "season"
+)
+
+const (
+	defaultLeaderboardLimit = 50
+	maxLeaderboardLimit     = 200
 )
 
 type LeaderboardService struct {
-	userRepo     *repositories.UserRepository
-	progressRepo *repositories.ProgressRepository
+	userRepo        *repositories.UserRepository
+	leaderboardRepo *repositories.LeaderboardRepository
+	seasonRepo      *repositories.SeasonRepository
+	privacyService  *PrivacyService
+	location        *time.Location
 }
 
 func NewLeaderboardService(
 	userRepo *repositories.UserRepository,
-	progressRepo *repositories.ProgressRepository,
+	leaderboardRepo *repositories.LeaderboardRepository,
+	seasonRepo *repositories.SeasonRepository,
+	privacyService *PrivacyService,
+	location *time.Location,
 ) *LeaderboardService {
+	if location == nil {
+		location = time.UTC
+	}
 	return &LeaderboardService{
-		userRepo:     userRepo,
-		progressRepo: progressRepo,
+		userRepo:        userRepo,
+		leaderboardRepo: leaderboardRepo,
+		seasonRepo:      seasonRepo,
+		privacyService:  privacyService,
+		location:        location,
 	}
 }
 
@@ -28,115 +53,179 @@ type LeaderboardEntry struct {
 	UserID            uint    `json:"user_id"`
 	Username          string  `json:"username"`
 	FullName          string  `json:"full_name"`
+	Avatar            string  `json:"avatar"`
 	ClassDisplay      string  `json:"class_display"`
 	TotalPoints       int     `json:"total_points"`
 	CompletedLessons  int     `json:"completed_lessons"`
 	AveragePercentage float64 `json:"average_percentage"`
+	GamePoints        int     `json:"game_points"`
+	TotalXP           int     `json:"total_xp"`
+	PlayerLevel       int     `json:"player_level"`
 	Rank              int     `json:"rank"`
 }
 
-func (s *LeaderboardService) GetLeaderboard(userID uint, userRole string, filters map[string]string) ([]LeaderboardEntry, error) {
+// LeaderboardWindow период, за который считается рейтинг.
+// Нулевые From/To означают рейтинг за все время.
+type LeaderboardWindow struct {
+	Period string
+	From   time.Time
+	To     time.Time
+	Season *models.Season
+}
+
+// LeaderboardPage страница рейтинга. MyEntry - строка текущего ученика,
+// даже если она не попала на страницу.
+type LeaderboardPage struct {
+	Period  string             `json:"period"`
+	Items   []LeaderboardEntry `json:"items"`
+	Total   int64              `json:"total"`
+	Limit   int                `json:"limit"`
+	Offset  int                `json:"offset"`
+	MyEntry *LeaderboardEntry  `json:"my_entry"`
+}
+
+func (s *LeaderboardService) GetLeaderboard(userID uint, userRole string, filters map[string]string) (*LeaderboardPage, error) {
 	// Получаем пользователя для фильтрации по классу
 	user, err := s.userRepo.FindByID(userID)
 	if err != nil {
 		return nil, errors.New("User not found")
 	}
 
+	policy, err := s.privacyService.PolicyFor(userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	query := repositories.LeaderboardQuery{
+		SortByXP:       filters["sort"] == "xp",
+		ExcludeHidden:  policy.ExcludesHidden(),
+		Limit:          defaultLeaderboardLimit,
+		IncludeUserID:  userID,
+		HideBottomHalf: policy.BottomHalfHiding(),
+	}
+
 	// Фильтр по классу (для студентов - только их класс)
-	repoFilters := make(map[string]interface{})
 	if userRole == string(models.RoleStudent) && user.Level != nil {
-		repoFilters["level"] = *user.Level
-		repoFilters["level_letter"] = user.LevelLetter
+		query.Level = *user.Level
+		query.LevelLetter = user.LevelLetter
 	}
 
 	// Дополнительные фильтры из query параметров
 	if levelStr := filters["level"]; levelStr != "" {
 		if levelInt, err := strconv.Atoi(levelStr); err == nil && levelInt >= 1 && levelInt <= 11 {
-			repoFilters["level"] = levelInt
+			query.Level = levelInt
 		}
 	}
 
 	if levelLetter := filters["level_letter"]; levelLetter != "" {
 		levelLetter = strings.TrimSpace(strings.ToUpper(levelLetter))
 		if len([]rune(levelLetter)) == 1 {
-			repoFilters["level_letter"] = levelLetter
+			query.LevelLetter = levelLetter
 		}
 	}
 
-	students, err := s.userRepo.FindStudents(repoFilters)
+	if limit, err := strconv.Atoi(filters["limit"]); err == nil && limit > 0 && limit <= maxLeaderboardLimit {
+		query.Limit = limit
+	}
+	if offset, err := strconv.Atoi(filters["offset"]); err == nil && offset > 0 {
+		query.Offset = offset
+	}
+
+	window, err := s.ResolveWindow(filters["period"], filters["season_id"], time.Now())
 	if err != nil {
 		return nil, err
 	}
+	query.From, query.To = window.From, window.To
 
-	// Подсчитываем статистику для каждого студента
-	type StudentStats struct {
-		UserID            uint
-		TotalPoints       int
-		CompletedLessons  int
-		AveragePercentage float64
+	rows, err := s.leaderboardRepo.Rank(query)
+	if err != nil {
+		return nil, err
 	}
 
-	statsMap := make(map[uint]*StudentStats)
-	for _, student := range students {
-		progress, _ := s.progressRepo.FindByUserID(student.ID)
-
-		totalPoints := 0
-		completedLessons := 0
-		var completedProgress []models.LessonProgress
-
-		for _, p := range progress {
-			totalPoints += p.BestScore
-			if p.IsCompleted {
-				completedLessons++
-				completedProgress = append(completedProgress, p)
-			}
+	page := &LeaderboardPage{
+		Period: window.Period,
+		Items:  make([]LeaderboardEntry, 0, len(rows)),
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	}
+	for i := range rows {
+		row := &rows[i]
+		page.Total = row.TotalCount
+		entry := newLeaderboardEntry(row, policy)
+		if row.UserID == userID {
+			page.MyEntry = &entry
 		}
+		// Строка текущего ученика вне страницы возвращается только в MyEntry
+		if row.RowNumber <= query.Offset || row.RowNumber > query.Offset+query.Limit {
+			continue
+		}
+		page.Items = append(page.Items, entry)
+	}
 
-		avgPercentage := 0.0
-		if len(completedProgress) > 0 {
-			sum := 0.0
-			for _, p := range completedProgress {
-				sum += p.BestPercentage
+	return page, nil
+}
+
+// ResolveWindow переводит период из запроса в границы по часовому поясу школы.
+// Неделя начинается с понедельника.
+func (s *LeaderboardService) ResolveWindow(period, seasonID string, now time.Time) (*LeaderboardWindow, error) {
+	now = now.In(s.location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, s.location)
+
+	switch period {
+	case "", PeriodAll:
+		return &LeaderboardWindow{Period: PeriodAll}, nil
+	case PeriodWeek:
+		weekday := (int(today.Weekday()) + 6) % 7
+		from := today.AddDate(0, 0, -weekday)
+		return &LeaderboardWindow{Period: PeriodWeek, From: from, To: from.AddDate(0, 0, 7)}, nil
+	case PeriodMonth:
+		from := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, s.location)
+		return &LeaderboardWindow{Period: PeriodMonth, From: from, To: from.AddDate(0, 1, 0)}, nil
+	case PeriodSeason:
+		var season *models.Season
+		var err error
+		if seasonID != "" {
+			id, parseErr := strconv.ParseUint(seasonID, 10, 32)
+			if parseErr != nil {
+				return nil, errors.New("Неверный ID сезона")
 			}
-			avgPercentage = sum / float64(len(completedProgress))
+			season, err = s.seasonRepo.FindByID(uint(id))
+		} else {
+			season, err = s.seasonRepo.FindCurrent(now)
 		}
-
-		statsMap[student.ID] = &StudentStats{
-			UserID:            student.ID,
-			TotalPoints:       totalPoints,
-			CompletedLessons:  completedLessons,
-			AveragePercentage: avgPercentage,
+		if err != nil {
+			return nil, errors.New("Сезон не найден")
 		}
+		return &LeaderboardWindow{Period: PeriodSeason, From: season.StartsAt, To: season.EndsAt, Season: season}, nil
+	default:
+		return nil, errors.New("Неверный период: допустимо all, week, month, season")
 	}
+}
 
-	// Сортируем по total_points
-	leaderboard := make([]LeaderboardEntry, 0, len(students))
-	for _, student := range students {
-		stats := statsMap[student.ID]
-		leaderboard = append(leaderboard, LeaderboardEntry{
-			UserID:            student.ID,
-			Username:          student.Username,
-			FullName:          student.GetFullName(),
-			ClassDisplay:      student.GetClassDisplay(),
-			TotalPoints:       stats.TotalPoints,
-			CompletedLessons:  stats.CompletedLessons,
-			AveragePercentage: stats.AveragePercentage,
-		})
-	}
-
-	// Сортируем по total_points (по убыванию)
-	sort.Slice(leaderboard, func(i, j int) bool {
-		if leaderboard[i].TotalPoints != leaderboard[j].TotalPoints {
-			return leaderboard[i].TotalPoints > leaderboard[j].TotalPoints
-		}
-		return leaderboard[i].AveragePercentage > leaderboard[j].AveragePercentage
+// RankAll ранжирует всех учеников школы за период, без пагинации
+func (s *LeaderboardService) RankAll(window *LeaderboardWindow) ([]models.LeaderboardRow, error) {
+	return s.leaderboardRepo.Rank(repositories.LeaderboardQuery{
+		From: window.From,
+		To:   window.To,
 	})
+}
 
-	// Добавляем ранги
-	for i := range leaderboard {
-		leaderboard[i].Rank = i + 1
+// newLeaderboardEntry строка ответа с учетом настроек приватности зрителя
+func newLeaderboardEntry(row *models.LeaderboardRow, policy *LeaderboardPolicy) LeaderboardEntry {
+	student := row.Student()
+	identity := policy.Identity(student)
+	return LeaderboardEntry{
+		UserID:            row.UserID,
+		Username:          identity.Username,
+		FullName:          identity.FullName,
+		Avatar:            identity.Avatar,
+		ClassDisplay:      student.GetClassDisplay(),
+		TotalPoints:       row.TotalPoints,
+		CompletedLessons:  row.CompletedLessons,
+		AveragePercentage: row.AveragePercentage,
+		GamePoints:        row.GamePoints,
+		TotalXP:           row.TotalXP,
+		PlayerLevel:       LevelForXP(row.AllTimeXP),
+		Rank:              row.Rank,
 	}
-
-	return leaderboard, nil
 }
-
@@ -4,6 +4,7 @@ import (
 	"englishlessons.back/internal/models"
 	"englishlessons.back/internal/repositories"
 	"errors"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -62,6 +63,7 @@ func (s *LessonService) GetLessons(userID uint) ([]map[string]interface{}, error
 
 			lessonData["progress"] = map[string]interface{}{
 				"is_completed":    lp.IsCompleted,
+				"is_unlocked":     lp.IsUnlocked,
 				"best_percentage":  lp.BestPercentage,
 				"best_score":      lp.BestScore,
 				"attempts_count": lp.AttemptsCount,
@@ -86,13 +88,9 @@ func (s *LessonService) GetLesson(lessonID uint, userID uint) (*models.Lesson, m
 	}
 
 	// Проверяем доступность урока (только для студентов)
-	if userID > 0 && lesson.Order > 1 {
-		prevLesson, err := s.lessonRepo.FindByOrder(lesson.Order-1, true)
-		if err == nil {
-			prevProgress, err := s.progressRepo.FindByUserAndLesson(userID, prevLesson.ID)
-			if err != nil || !prevProgress.IsCompleted {
-				return nil, nil, errors.New("Сначала необходимо пройти предыдущий урок '" + prevLesson.Title + "' с результатом >=70%")
-			}
+	if userID > 0 {
+		if err := checkLessonAccess(s.lessonRepo, s.progressRepo, userID, lesson); err != nil {
+			return nil, nil, err
 		}
 	}
 
@@ -107,6 +105,7 @@ func (s *LessonService) GetLesson(lessonID uint, userID uint) (*models.Lesson, m
 
 		progressData = map[string]interface{}{
 			"is_completed":    progress.IsCompleted,
+			"is_unlocked":     progress.IsUnlocked,
 			"best_percentage":  progress.BestPercentage,
 			"best_score":      progress.BestScore,
 			"attempts_count":  progress.AttemptsCount,
@@ -118,10 +117,100 @@ func (s *LessonService) GetLesson(lessonID uint, userID uint) (*models.Lesson, m
 	return lesson, progressData, nil
 }
 
+// checkLessonAccess урок доступен, если пройден предыдущий урок или урок
+// открыт по итогам теста на уровень
+func checkLessonAccess(lessonRepo *repositories.LessonRepository, progressRepo *repositories.ProgressRepository, userID uint, lesson *models.Lesson) error {
+	if lesson.Order <= 1 {
+		return nil
+	}
+	if progress, err := progressRepo.FindByUserAndLesson(userID, lesson.ID); err == nil && progress.IsUnlocked {
+		return nil
+	}
+
+	prevLesson, err := lessonRepo.FindByOrder(lesson.Order-1, true)
+	if err == nil {
+		prevProgress, err := progressRepo.FindByUserAndLesson(userID, prevLesson.ID)
+		if err != nil || !prevProgress.IsCompleted {
+			return errors.New("Сначала необходимо пройти предыдущий урок '" + prevLesson.Title + "' с результатом >=70%")
+		}
+	}
+	return nil
+}
+
 func (s *LessonService) GetLessonQuestions(lessonID uint) ([]models.Question, error) {
 	return s.lessonRepo.FindQuestionsByLessonID(lessonID)
 }
 
+// QuestionOptionRequest вариант ответа вопроса с выбором
+type QuestionOptionRequest struct {
+	Text      string `json:"text"`
+	IsCorrect bool   `json:"is_correct"`
+}
+
+// CreateQuestionRequest новый вопрос урока. Для вопроса с выбором нужны
+// варианты Options, для вопроса с вводом ответа - допустимые написания
+// AcceptedAnswers, первое из них основное.
+type CreateQuestionRequest struct {
+	Text            string                  `json:"text" binding:"required"`
+	Type            models.QuestionType     `json:"type"`
+	Options         []QuestionOptionRequest `json:"options"`
+	AcceptedAnswers []string                `json:"accepted_answers"`
+}
+
+// CreateQuestion добавляет вопрос в конец урока
+func (s *LessonService) CreateQuestion(lessonID uint, req CreateQuestionRequest) (*models.Question, error) {
+	if _, err := s.lessonRepo.FindByID(lessonID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Lesson not found")
+		}
+		return nil, err
+	}
+
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return nil, errors.New("Необходимо указать текст вопроса")
+	}
+	question := &models.Question{LessonID: lessonID, Text: text, Type: req.Type}
+
+	switch req.Type {
+	case models.QuestionTyped:
+		for i, answer := range req.AcceptedAnswers {
+			answer = strings.TrimSpace(answer)
+			if normalizeSpelling(answer) == "" {
+				return nil, errors.New("Неверные варианты написания: пустая строка")
+			}
+			question.AnswerOptions = append(question.AnswerOptions, models.AnswerOption{Text: answer, IsCorrect: true, Order: i + 1})
+		}
+		if len(question.AnswerOptions) == 0 {
+			return nil, errors.New("Необходимо указать хотя бы один правильный ответ")
+		}
+	case models.QuestionChoice, "":
+		question.Type = models.QuestionChoice
+		if len(req.Options) < 2 {
+			return nil, errors.New("Необходимо указать хотя бы два варианта ответа")
+		}
+		hasCorrect := false
+		for i, option := range req.Options {
+			optionText := strings.TrimSpace(option.Text)
+			if optionText == "" {
+				return nil, errors.New("Неверные варианты ответа: пустая строка")
+			}
+			hasCorrect = hasCorrect || option.IsCorrect
+			question.AnswerOptions = append(question.AnswerOptions, models.AnswerOption{Text: optionText, IsCorrect: option.IsCorrect, Order: i + 1})
+		}
+		if !hasCorrect {
+			return nil, errors.New("Необходимо отметить правильный вариант ответа")
+		}
+	default:
+		return nil, errors.New("Неверный тип вопроса")
+	}
+
+	if err := s.lessonRepo.CreateQuestion(question); err != nil {
+		return nil, err
+	}
+	return question, nil
+}
+
 func (s *LessonService) GetUserProgress(userID uint) ([]map[string]interface{}, error) {
 	progress, err := s.progressRepo.FindByUserID(userID)
 	if err != nil {
@@ -150,6 +239,7 @@ func (s *LessonService) GetUserProgress(userID uint) ([]map[string]interface{},
 			"best_score":      p.BestScore,
 			"attempts_count":  p.AttemptsCount,
 			"is_completed":    p.IsCompleted,
+			"is_unlocked":     p.IsUnlocked,
 			"completed_at":    completedAt,
 			"last_attempt_at": p.LastAttemptAt.Format(time.RFC3339),
 		}
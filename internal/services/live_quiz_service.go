@@ -0,0 +1,670 @@
+package services
+
+import (
+	"crypto/rand"
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/repositories"
+	"errors"
+	"math"
+	mathrand "math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	liveQuizCodeAlphabet    = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	liveQuizCodeLength      = 6
+	liveQuizQuestionTime    = 20 * time.Second
+	liveQuizMaxPoints       = 1000
+	liveQuizMaxPlayers      = 60
+	liveQuizRoomLifetime    = 3 * time.Hour
+	liveQuizSweepInterval   = 10 * time.Minute
+	liveQuizSubscriberQueue = 32
+)
+
+// Состояния комнаты
+const (
+	LiveQuizStatusLobby    = "lobby"
+	LiveQuizStatusQuestion = "question"
+	LiveQuizStatusReveal   = "reveal"
+	LiveQuizStatusFinished = "finished"
+)
+
+// События, которые получают подписчики комнаты
+const (
+	LiveQuizEventState          = "state"
+	LiveQuizEventPlayers        = "players"
+	LiveQuizEventQuestion       = "question"
+	LiveQuizEventScoreboard     = "scoreboard"
+	LiveQuizEventQuestionClosed = "question_closed"
+	LiveQuizEventFinished       = "finished"
+)
+
+// LiveQuizEvent сообщение для подписчиков комнаты
+type LiveQuizEvent struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// LiveQuizStanding строка таблицы участников
+type LiveQuizStanding struct {
+	Rank     int    `json:"rank"`
+	UserID   uint   `json:"user_id"`
+	Name     string `json:"name"`
+	Points   int    `json:"points"`
+	Correct  int    `json:"correct"`
+	Answered bool   `json:"answered"`
+}
+
+// LiveQuizQuestion вопрос в том виде, в котором его видят ученики
+type LiveQuizQuestion struct {
+	Index      int       `json:"index"`
+	Total      int       `json:"total"`
+	Question   string    `json:"question"`
+	Options    []string  `json:"options"`
+	DeadlineAt time.Time `json:"deadline_at"`
+}
+
+// LiveQuizState снимок комнаты для только что подключившихся
+type LiveQuizState struct {
+	Code       string             `json:"code"`
+	Level      int                `json:"level"`
+	Status     string             `json:"status"`
+	Total      int                `json:"total"`
+	Question   *LiveQuizQuestion  `json:"question,omitempty"`
+	Scoreboard []LiveQuizStanding `json:"scoreboard"`
+}
+
+// CreateLiveQuizRequest запрос учителя на новую комнату. Вопросов не больше,
+// чем в раунде одиночного Quiz Show; по умолчанию - целый раунд.
+type CreateLiveQuizRequest struct {
+	Level         int `json:"level"`
+	QuestionCount int `json:"question_count"`
+}
+
+type liveQuizPlayer struct {
+	userID uint
+	user   models.User
+	// policy правила рейтинга, с которыми игрок видит остальных участников
+	policy       *LeaderboardPolicy
+	points       int
+	correct      int
+	answeredAt   map[int]time.Duration
+	lastAnswered int
+}
+
+type liveQuizRoom struct {
+	mu sync.Mutex
+
+	code          string
+	teacherID     uint
+	teacherPolicy *LeaderboardPolicy
+	definition    *models.GameDefinition
+	level         int
+	items         []models.QuizItem
+	createdAt     time.Time
+	startedAt     time.Time
+
+	status     string
+	current    int
+	questionAt time.Time
+	closeTimer *time.Timer
+
+	players map[uint]*liveQuizPlayer
+	// subscribers каналы подписчиков и правила рейтинга зрителя: таблицу
+	// участников каждый получает со своими именами и скрытыми строками
+	subscribers map[chan LiveQuizEvent]*LeaderboardPolicy
+}
+
+// LiveQuizService комнаты "Quiz Show" в реальном времени. Комнаты живут в
+// памяти процесса; в базу попадают только итоговые результаты игры.
+type LiveQuizService struct {
+	mu        sync.Mutex
+	rooms     map[string]*liveQuizRoom
+	startOnce sync.Once
+
+	itemRepo       *repositories.GameItemRepository
+	gameResultRepo *repositories.GameResultRepository
+	userRepo       *repositories.UserRepository
+	privacyService *PrivacyService
+	eventBus       *EventBus
+}
+
+func NewLiveQuizService(
+	itemRepo *repositories.GameItemRepository,
+	gameResultRepo *repositories.GameResultRepository,
+	userRepo *repositories.UserRepository,
+	privacyService *PrivacyService,
+	eventBus *EventBus,
+) *LiveQuizService {
+	return &LiveQuizService{
+		rooms:          make(map[string]*liveQuizRoom),
+		itemRepo:       itemRepo,
+		gameResultRepo: gameResultRepo,
+		userRepo:       userRepo,
+		privacyService: privacyService,
+		eventBus:       eventBus,
+	}
+}
+
+// CreateRoom открывает комнату с кодом для входа и случайными вопросами уровня
+func (s *LiveQuizService) CreateRoom(teacherID uint, req CreateLiveQuizRequest) (*LiveQuizState, error) {
+	definition, err := findGame(string(models.GameQuizShow), req.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	count := req.QuestionCount
+	if count <= 0 || count > definition.RoundSize {
+		count = definition.RoundSize
+	}
+
+	gameItems, err := s.itemRepo.FindActive(definition.ID, req.Level)
+	if err != nil {
+		return nil, err
+	}
+	if len(gameItems) == 0 {
+		return nil, errNoGameItems
+	}
+
+	rng := mathrand.New(mathrand.NewSource(time.Now().UnixNano()))
+	rng.Shuffle(len(gameItems), func(a, b int) { gameItems[a], gameItems[b] = gameItems[b], gameItems[a] })
+	if len(gameItems) > count {
+		gameItems = gameItems[:count]
+	}
+
+	items := make([]models.QuizItem, len(gameItems))
+	for i := range gameItems {
+		if err := gameItems[i].DecodePayload(&items[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	teacherPolicy, err := s.privacyService.PolicyFor(teacherID, string(models.RoleTeacher))
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	code, err := s.newCodeLocked()
+	if err != nil {
+		return nil, err
+	}
+
+	room := &liveQuizRoom{
+		code:          code,
+		teacherID:     teacherID,
+		teacherPolicy: teacherPolicy,
+		definition:    definition,
+		level:         req.Level,
+		items:         items,
+		createdAt:     time.Now(),
+		status:        LiveQuizStatusLobby,
+		current:       -1,
+		players:       make(map[uint]*liveQuizPlayer),
+		subscribers:   make(map[chan LiveQuizEvent]*LeaderboardPolicy),
+	}
+	s.rooms[code] = room
+
+	room.mu.Lock()
+	defer room.mu.Unlock()
+	return room.stateLocked(teacherPolicy), nil
+}
+
+// Join добавляет ученика в комнату. Повторный вход не сбрасывает очки.
+func (s *LiveQuizService) Join(code string, userID uint) (*LiveQuizState, error) {
+	room, err := s.room(code)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := s.privacyService.PolicyFor(userID, string(models.RoleStudent))
+	if err != nil {
+		return nil, err
+	}
+
+	room.mu.Lock()
+	defer room.mu.Unlock()
+
+	player, ok := room.players[userID]
+	if !ok {
+		if room.status == LiveQuizStatusFinished {
+			return nil, errors.New("Игра уже завершена")
+		}
+		if len(room.players) >= liveQuizMaxPlayers {
+			return nil, errors.New("Комната заполнена")
+		}
+		player = &liveQuizPlayer{
+			userID:       userID,
+			user:         *user,
+			policy:       policy,
+			answeredAt:   make(map[int]time.Duration),
+			lastAnswered: -1,
+		}
+		room.players[userID] = player
+		room.broadcastScoreboardLocked(LiveQuizEventPlayers)
+	}
+	return room.stateLocked(player.policy), nil
+}
+
+// Subscribe подписывает учителя комнаты или ее участника на события.
+// Первым событием приходит текущее состояние. Возвращает функцию отписки.
+func (s *LiveQuizService) Subscribe(code string, userID uint) (<-chan LiveQuizEvent, func(), error) {
+	room, err := s.room(code)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	room.mu.Lock()
+	defer room.mu.Unlock()
+
+	policy := room.teacherPolicy
+	if room.teacherID != userID {
+		player, ok := room.players[userID]
+		if !ok {
+			return nil, nil, errors.New("Сначала необходимо войти в комнату")
+		}
+		policy = player.policy
+	}
+
+	ch := make(chan LiveQuizEvent, liveQuizSubscriberQueue)
+	ch <- LiveQuizEvent{Type: LiveQuizEventState, Data: room.stateLocked(policy)}
+	room.subscribers[ch] = policy
+
+	unsubscribe := func() {
+		room.mu.Lock()
+		defer room.mu.Unlock()
+		if _, ok := room.subscribers[ch]; ok {
+			delete(room.subscribers, ch)
+			close(ch)
+		}
+	}
+	return ch, unsubscribe, nil
+}
+
+// NextQuestion показывает следующий вопрос; предыдущий закрывается
+func (s *LiveQuizService) NextQuestion(code string, teacherID uint) (*LiveQuizState, error) {
+	room, err := s.teacherRoom(code, teacherID)
+	if err != nil {
+		return nil, err
+	}
+
+	room.mu.Lock()
+	defer room.mu.Unlock()
+
+	if room.status == LiveQuizStatusFinished {
+		return nil, errors.New("Игра уже завершена")
+	}
+	if room.current+1 >= len(room.items) {
+		return nil, errors.New("Вопросы закончились, завершите игру")
+	}
+	if room.status == LiveQuizStatusQuestion {
+		room.closeQuestionLocked()
+	}
+
+	now := time.Now()
+	if room.startedAt.IsZero() {
+		room.startedAt = now
+	}
+	room.current++
+	room.questionAt = now
+	room.status = LiveQuizStatusQuestion
+
+	index := room.current
+	room.closeTimer = time.AfterFunc(liveQuizQuestionTime, func() {
+		room.mu.Lock()
+		defer room.mu.Unlock()
+		if room.status == LiveQuizStatusQuestion && room.current == index {
+			room.closeQuestionLocked()
+		}
+	})
+
+	question := room.questionLocked()
+	room.broadcastLocked(LiveQuizEventQuestion, func(*LeaderboardPolicy) interface{} {
+		return question
+	})
+	return room.stateLocked(room.teacherPolicy), nil
+}
+
+// Answer принимает ответ ученика на текущий вопрос. За верный ответ
+// начисляется от половины до полного числа очков в зависимости от скорости.
+func (s *LiveQuizService) Answer(code string, userID uint, questionIndex, option int) (*LiveQuizStanding, error) {
+	room, err := s.room(code)
+	if err != nil {
+		return nil, err
+	}
+
+	room.mu.Lock()
+	defer room.mu.Unlock()
+
+	player, ok := room.players[userID]
+	if !ok {
+		return nil, errors.New("Сначала необходимо войти в комнату")
+	}
+	if room.status != LiveQuizStatusQuestion || questionIndex != room.current {
+		return nil, errors.New("Вопрос уже закрыт")
+	}
+	if player.lastAnswered == room.current {
+		return nil, errors.New("Ответ на этот вопрос уже принят")
+	}
+
+	elapsed := time.Since(room.questionAt)
+	if elapsed > liveQuizQuestionTime {
+		return nil, errors.New("Вопрос уже закрыт")
+	}
+
+	item := room.items[room.current]
+	if option < 0 || option >= len(item.Options) {
+		return nil, errors.New("Неверный вариант ответа")
+	}
+
+	player.lastAnswered = room.current
+	player.answeredAt[room.current] = elapsed
+	if option == item.CorrectAnswer {
+		player.correct++
+		player.points += speedPoints(elapsed)
+	}
+
+	room.broadcastScoreboardLocked(LiveQuizEventScoreboard)
+
+	if room.allAnsweredLocked() {
+		room.closeQuestionLocked()
+	}
+
+	// Свою строку игрок видит всегда, даже в скрытой нижней половине
+	scoreboard := room.scoreboardLocked(player.policy)
+	for i := range scoreboard {
+		if scoreboard[i].UserID == userID {
+			return &scoreboard[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Finish завершает игру, сохраняет результаты участников и закрывает комнату
+func (s *LiveQuizService) Finish(code string, teacherID uint) ([]LiveQuizStanding, error) {
+	room, err := s.teacherRoom(code, teacherID)
+	if err != nil {
+		return nil, err
+	}
+
+	room.mu.Lock()
+	if room.status == LiveQuizStatusFinished {
+		room.mu.Unlock()
+		return nil, errors.New("Игра уже завершена")
+	}
+	if room.status == LiveQuizStatusQuestion {
+		room.closeQuestionLocked()
+	}
+	room.status = LiveQuizStatusFinished
+
+	standings := room.scoreboardLocked(room.teacherPolicy)
+	results := room.resultsLocked()
+	room.broadcastScoreboardLocked(LiveQuizEventFinished)
+	for ch := range room.subscribers {
+		delete(room.subscribers, ch)
+		close(ch)
+	}
+	room.mu.Unlock()
+
+	s.mu.Lock()
+	delete(s.rooms, code)
+	s.mu.Unlock()
+
+	if err := s.gameResultRepo.CreateBatch(results); err != nil {
+		return nil, err
+	}
+
+	// Опыт, серия и достижения начисляются так же, как за одиночную игру
+	for i := range results {
+		s.eventBus.Publish(Event{
+			Type:       models.EventGameFinished,
+			UserID:     results[i].UserID,
+			OccurredAt: results[i].CreatedAt,
+			GameResult: &results[i],
+		})
+	}
+
+	return standings, nil
+}
+
+func (s *LiveQuizService) room(code string) (*liveQuizRoom, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	room, ok := s.rooms[code]
+	if !ok {
+		return nil, errors.New("Комната не найдена")
+	}
+	return room, nil
+}
+
+func (s *LiveQuizService) teacherRoom(code string, teacherID uint) (*liveQuizRoom, error) {
+	room, err := s.room(code)
+	if err != nil {
+		return nil, err
+	}
+	if room.teacherID != teacherID {
+		return nil, errors.New("Управлять комнатой может только ее учитель")
+	}
+	return room, nil
+}
+
+// Start запускает фоновую очистку брошенных комнат. Повторный вызов ничего не делает.
+func (s *LiveQuizService) Start() {
+	s.startOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(liveQuizSweepInterval)
+			defer ticker.Stop()
+			for now := range ticker.C {
+				s.RemoveStaleRooms(now)
+			}
+		}()
+	})
+}
+
+// RemoveStaleRooms закрывает комнаты старше liveQuizRoomLifetime без
+// сохранения результатов; подписчики получают закрытый канал
+func (s *LiveQuizService) RemoveStaleRooms(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for code, room := range s.rooms {
+		if now.Sub(room.createdAt) < liveQuizRoomLifetime {
+			continue
+		}
+		room.mu.Lock()
+		if room.closeTimer != nil {
+			room.closeTimer.Stop()
+		}
+		room.status = LiveQuizStatusFinished
+		for ch := range room.subscribers {
+			delete(room.subscribers, ch)
+			close(ch)
+		}
+		room.mu.Unlock()
+		delete(s.rooms, code)
+	}
+}
+
+func (s *LiveQuizService) newCodeLocked() (string, error) {
+	for attempt := 0; attempt < 10; attempt++ {
+		buf := make([]byte, liveQuizCodeLength)
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for i := range buf {
+			buf[i] = liveQuizCodeAlphabet[int(buf[i])%len(liveQuizCodeAlphabet)]
+		}
+		if _, exists := s.rooms[string(buf)]; !exists {
+			return string(buf), nil
+		}
+	}
+	return "", errors.New("Не удалось создать код комнаты")
+}
+
+// speedPoints очки за верный ответ: от половины до максимума, чем быстрее, тем больше
+func speedPoints(elapsed time.Duration) int {
+	remaining := 1 - float64(elapsed)/float64(liveQuizQuestionTime)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return int(math.Round(liveQuizMaxPoints / 2 * (1 + remaining)))
+}
+
+func (r *liveQuizRoom) closeQuestionLocked() {
+	if r.closeTimer != nil {
+		r.closeTimer.Stop()
+		r.closeTimer = nil
+	}
+	r.status = LiveQuizStatusReveal
+
+	item := r.items[r.current]
+	index := r.current
+	r.broadcastLocked(LiveQuizEventQuestionClosed, func(policy *LeaderboardPolicy) interface{} {
+		return map[string]interface{}{
+			"index":          index,
+			"correct_answer": item.CorrectAnswer,
+			"explanation":    item.Explanation,
+			"scoreboard":     r.scoreboardLocked(policy),
+		}
+	})
+}
+
+func (r *liveQuizRoom) allAnsweredLocked() bool {
+	if len(r.players) == 0 {
+		return false
+	}
+	for _, player := range r.players {
+		if player.lastAnswered != r.current {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *liveQuizRoom) questionLocked() *LiveQuizQuestion {
+	if r.status != LiveQuizStatusQuestion {
+		return nil
+	}
+	item := r.items[r.current]
+	return &LiveQuizQuestion{
+		Index:      r.current,
+		Total:      len(r.items),
+		Question:   item.Question,
+		Options:    item.Options,
+		DeadlineAt: r.questionAt.Add(liveQuizQuestionTime),
+	}
+}
+
+func (r *liveQuizRoom) stateLocked(policy *LeaderboardPolicy) *LiveQuizState {
+	return &LiveQuizState{
+		Code:       r.code,
+		Level:      r.level,
+		Status:     r.status,
+		Total:      len(r.items),
+		Question:   r.questionLocked(),
+		Scoreboard: r.scoreboardLocked(policy),
+	}
+}
+
+// scoreboardLocked таблица участников для зрителя: по убыванию очков, при
+// равенстве очков места общие. Имена и скрытие нижней половины - по тем же
+// правилам приватности, что и в рейтингах.
+func (r *liveQuizRoom) scoreboardLocked(policy *LeaderboardPolicy) []LiveQuizStanding {
+	players := make([]*liveQuizPlayer, 0, len(r.players))
+	for _, player := range r.players {
+		players = append(players, player)
+	}
+	// При равных очках порядок по ID, а не по имени: имена у зрителей разные
+	sort.Slice(players, func(i, j int) bool {
+		if players[i].points != players[j].points {
+			return players[i].points > players[j].points
+		}
+		return players[i].userID < players[j].userID
+	})
+
+	standings := make([]LiveQuizStanding, 0, len(players))
+	rank := 0
+	for i, player := range players {
+		if i == 0 || player.points != players[i-1].points {
+			rank = i + 1
+		}
+		if policy.HidesPosition(&player.user, rank, len(players)) {
+			continue
+		}
+		standings = append(standings, LiveQuizStanding{
+			Rank:     rank,
+			UserID:   player.userID,
+			Name:     policy.Identity(&player.user).FullName,
+			Points:   player.points,
+			Correct:  player.correct,
+			Answered: r.current >= 0 && player.lastAnswered == r.current,
+		})
+	}
+	return standings
+}
+
+// resultsLocked результаты игры в формате одиночного Quiz Show: очко за верный ответ.
+// В рейтинг и опыт попадают только полные раунды: если показано меньше вопросов,
+// чем в раунде одиночной игры, результаты не сохраняются.
+func (r *liveQuizRoom) resultsLocked() []models.GameResult {
+	asked := r.current + 1
+	if r.definition == nil || asked < r.definition.RoundSize {
+		return nil
+	}
+
+	results := make([]models.GameResult, 0, len(r.players))
+	for _, player := range r.players {
+		grade := gameGrade{Correct: player.correct, Total: asked, Score: player.correct, MaxScore: asked}
+		if err := checkGrade(r.definition, &grade); err != nil {
+			continue
+		}
+
+		var timeSpent time.Duration
+		for _, elapsed := range player.answeredAt {
+			timeSpent += elapsed
+		}
+		results = append(results, models.GameResult{
+			UserID:       player.userID,
+			GameType:     models.GameQuizShow,
+			Level:        r.level,
+			Score:        grade.Score,
+			MaxScore:     grade.MaxScore,
+			Percentage:   float64(grade.Correct) / float64(grade.Total) * 100,
+			TimeSpent:    int(timeSpent.Seconds()),
+			CorrectCount: grade.Correct,
+			TotalCount:   grade.Total,
+		})
+	}
+	return results
+}
+
+// broadcastLocked рассылает событие подписчикам; данные собираются для
+// каждого зрителя отдельно. Медленный подписчик, у которого переполнена
+// очередь, отключается, чтобы не задерживать комнату.
+func (r *liveQuizRoom) broadcastLocked(eventType string, data func(policy *LeaderboardPolicy) interface{}) {
+	for ch, policy := range r.subscribers {
+		event := LiveQuizEvent{Type: eventType, Data: data(policy)}
+		select {
+		case ch <- event:
+		default:
+			delete(r.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// broadcastScoreboardLocked рассылает таблицу участников
+func (r *liveQuizRoom) broadcastScoreboardLocked(eventType string) {
+	r.broadcastLocked(eventType, func(policy *LeaderboardPolicy) interface{} {
+		return r.scoreboardLocked(policy)
+	})
+}
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"englishlessons.back/internal/models"
+)
+
+const liveQuizTestTeacher = 1
+
+// newTestLiveQuiz комната с двумя вопросами без базы: игроки и правила
+// рейтинга добавляются напрямую, как это сделали бы CreateRoom и Join
+func newTestLiveQuiz(t *testing.T) (*LiveQuizService, *liveQuizRoom) {
+	t.Helper()
+	s := NewLiveQuizService(nil, nil, nil, nil, nil)
+	room := &liveQuizRoom{
+		code:          "ABC234",
+		teacherID:     liveQuizTestTeacher,
+		teacherPolicy: &LeaderboardPolicy{viewerID: liveQuizTestTeacher, isTeacher: true},
+		items: []models.QuizItem{
+			{Question: "2+2?", Options: []string{"3", "4"}, CorrectAnswer: 1},
+			{Question: "3+3?", Options: []string{"6", "7"}, CorrectAnswer: 0},
+		},
+		createdAt:   time.Now(),
+		status:      LiveQuizStatusLobby,
+		current:     -1,
+		players:     make(map[uint]*liveQuizPlayer),
+		subscribers: make(map[chan LiveQuizEvent]*LeaderboardPolicy),
+	}
+	s.rooms[room.code] = room
+	return s, room
+}
+
+// addTestPlayer ученик 7-А; в классе включено скрытие нижней половины рейтинга
+func addTestPlayer(room *liveQuizRoom, user models.User) {
+	level := 7
+	user.Level = &level
+	user.LevelLetter = "А"
+	room.players[user.ID] = &liveQuizPlayer{
+		userID: user.ID,
+		user:   user,
+		policy: &LeaderboardPolicy{
+			viewerID: user.ID,
+			classSettings: map[string]*models.ClassPrivacySettings{
+				classKey(7, "А"): {Level: 7, LevelLetter: "А", NameMode: models.NameModeFull, HideBottomHalf: true},
+			},
+		},
+		answeredAt:   make(map[int]time.Duration),
+		lastAnswered: -1,
+	}
+}
+
+// liveQuizClient подписчик комнаты, читающий события как SSE-обработчик
+type liveQuizClient struct {
+	t      *testing.T
+	events <-chan LiveQuizEvent
+}
+
+func subscribeClient(t *testing.T, s *LiveQuizService, userID uint) *liveQuizClient {
+	t.Helper()
+	events, unsubscribe, err := s.Subscribe("ABC234", userID)
+	if err != nil {
+		t.Fatalf("Subscribe(%d): %v", userID, err)
+	}
+	t.Cleanup(unsubscribe)
+	return &liveQuizClient{t: t, events: events}
+}
+
+// next следующее событие; рассылка синхронная, поэтому ждать не нужно
+func (c *liveQuizClient) next(eventType string) LiveQuizEvent {
+	c.t.Helper()
+	select {
+	case event, ok := <-c.events:
+		if !ok {
+			c.t.Fatalf("channel closed, want %s", eventType)
+		}
+		if event.Type != eventType {
+			c.t.Fatalf("event = %s, want %s", event.Type, eventType)
+		}
+		return event
+	default:
+		c.t.Fatalf("no event, want %s", eventType)
+		return LiveQuizEvent{}
+	}
+}
+
+func (c *liveQuizClient) scoreboard(eventType string) []LiveQuizStanding {
+	c.t.Helper()
+	event := c.next(eventType)
+	switch data := event.Data.(type) {
+	case []LiveQuizStanding:
+		return data
+	case map[string]interface{}:
+		return data["scoreboard"].([]LiveQuizStanding)
+	case *LiveQuizState:
+		return data.Scoreboard
+	}
+	c.t.Fatalf("%s has no scoreboard: %T", eventType, event.Data)
+	return nil
+}
+
+func standingNames(standings []LiveQuizStanding) map[uint]string {
+	names := make(map[uint]string, len(standings))
+	for _, standing := range standings {
+		names[standing.UserID] = standing.Name
+	}
+	return names
+}
+
+func TestLiveQuizRoundWithInProcessClients(t *testing.T) {
+	s, room := newTestLiveQuiz(t)
+	addTestPlayer(room, models.User{ID: 10, Username: "alice", FirstName: "Alice", LastName: "Adams"})
+	addTestPlayer(room, models.User{ID: 11, Username: "bob", FirstName: "Bob", LastName: "Brown",
+		Nickname: "Bobby", LeaderboardMode: models.NameModeNickname})
+	addTestPlayer(room, models.User{ID: 12, Username: "carl", FirstName: "Carl", LastName: "Cole"})
+
+	teacher := subscribeClient(t, s, liveQuizTestTeacher)
+	alice := subscribeClient(t, s, 10)
+	bob := subscribeClient(t, s, 11)
+	carl := subscribeClient(t, s, 12)
+	for _, client := range []*liveQuizClient{teacher, alice, bob, carl} {
+		client.next(LiveQuizEventState)
+	}
+
+	if _, _, err := s.Subscribe("ABC234", 99); err == nil {
+		t.Fatal("outsider must not subscribe")
+	}
+
+	if _, err := s.NextQuestion("ABC234", 10); err == nil {
+		t.Fatal("student must not control the room")
+	}
+	if _, err := s.NextQuestion("ABC234", liveQuizTestTeacher); err != nil {
+		t.Fatalf("NextQuestion: %v", err)
+	}
+	for _, client := range []*liveQuizClient{teacher, alice, bob, carl} {
+		question := client.next(LiveQuizEventQuestion).Data.(*LiveQuizQuestion)
+		if question.Index != 0 || question.Total != 2 || question.Question != "2+2?" {
+			t.Fatalf("question = %+v", question)
+		}
+	}
+
+	answers := []struct {
+		userID uint
+		option int
+	}{{10, 1}, {11, 0}, {12, 1}}
+	for i, answer := range answers {
+		standing, err := s.Answer("ABC234", answer.userID, 0, answer.option)
+		if err != nil {
+			t.Fatalf("Answer(%d): %v", answer.userID, err)
+		}
+		if standing == nil || standing.UserID != answer.userID {
+			t.Fatalf("own standing = %+v", standing)
+		}
+		for _, client := range []*liveQuizClient{teacher, alice, bob, carl} {
+			client.scoreboard(LiveQuizEventScoreboard)
+		}
+		if i < len(answers)-1 {
+			if _, err := s.Answer("ABC234", answer.userID, 0, answer.option); err == nil {
+				t.Fatal("second answer to the same question must be rejected")
+			}
+		}
+	}
+
+	// Все ответили: вопрос закрывается сам, каждый видит таблицу по своим правилам
+	teacherBoard := teacher.scoreboard(LiveQuizEventQuestionClosed)
+	aliceBoard := alice.scoreboard(LiveQuizEventQuestionClosed)
+	bobBoard := bob.scoreboard(LiveQuizEventQuestionClosed)
+	carl.scoreboard(LiveQuizEventQuestionClosed)
+
+	want := map[uint]string{10: "Alice Adams", 11: "Bob Brown", 12: "Carl Cole"}
+	if got := standingNames(teacherBoard); len(got) != 3 || got[10] != want[10] || got[11] != want[11] || got[12] != want[12] {
+		t.Fatalf("teacher scoreboard = %+v", teacherBoard)
+	}
+	if teacherBoard[2].UserID != 11 || teacherBoard[2].Rank != 3 || teacherBoard[2].Points != 0 {
+		t.Fatalf("last place = %+v", teacherBoard[2])
+	}
+	if teacherBoard[0].Points <= teacherBoard[2].Points {
+		t.Fatalf("correct answers must score points: %+v", teacherBoard)
+	}
+
+	// Bob на третьем месте из трех: одноклассники его не видят, сам он себя видит
+	if names := standingNames(aliceBoard); len(names) != 2 || names[11] != "" {
+		t.Fatalf("alice sees the bottom half: %+v", aliceBoard)
+	}
+	if names := standingNames(bobBoard); names[11] != "Bob Brown" {
+		t.Fatalf("bob must see himself: %+v", bobBoard)
+	}
+
+	if _, err := s.Answer("ABC234", 10, 0, 1); err == nil {
+		t.Fatal("answer after close must be rejected")
+	}
+}
+
+func TestLiveQuizNicknameInScoreboard(t *testing.T) {
+	s, room := newTestLiveQuiz(t)
+	addTestPlayer(room, models.User{ID: 10, Username: "alice", FirstName: "Alice", LastName: "Adams"})
+	addTestPlayer(room, models.User{ID: 11, Username: "bob", FirstName: "Bob", LastName: "Brown",
+		Nickname: "Bobby", LeaderboardMode: models.NameModeNickname})
+	room.players[11].points = 500
+
+	alice := subscribeClient(t, s, 10)
+	names := standingNames(alice.scoreboard(LiveQuizEventState))
+	if names[11] != "Bobby" || names[10] != "Alice Adams" {
+		t.Fatalf("alice sees %+v", names)
+	}
+}
+
+func TestLiveQuizSlowClientIsDisconnected(t *testing.T) {
+	s, room := newTestLiveQuiz(t)
+	addTestPlayer(room, models.User{ID: 10, Username: "alice"})
+
+	events, unsubscribe, err := s.Subscribe("ABC234", 10)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer unsubscribe()
+
+	room.mu.Lock()
+	for i := 0; i < liveQuizSubscriberQueue+1; i++ {
+		room.broadcastScoreboardLocked(LiveQuizEventPlayers)
+	}
+	subscribers := len(room.subscribers)
+	room.mu.Unlock()
+
+	if subscribers != 0 {
+		t.Fatalf("slow subscriber kept, %d subscribers", subscribers)
+	}
+	count := 0
+	for range events {
+		count++
+	}
+	if count != liveQuizSubscriberQueue {
+		t.Fatalf("received %d events before close, want %d", count, liveQuizSubscriberQueue)
+	}
+}
+
+func TestLiveQuizRemoveStaleRooms(t *testing.T) {
+	s, room := newTestLiveQuiz(t)
+	addTestPlayer(room, models.User{ID: 10, Username: "alice"})
+	events, _, err := s.Subscribe("ABC234", 10)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	<-events
+
+	s.RemoveStaleRooms(room.createdAt.Add(liveQuizRoomLifetime - time.Minute))
+	if _, err := s.room("ABC234"); err != nil {
+		t.Fatal("fresh room must be kept")
+	}
+
+	s.RemoveStaleRooms(room.createdAt.Add(liveQuizRoomLifetime))
+	if _, err := s.room("ABC234"); err == nil {
+		t.Fatal("stale room must be removed")
+	}
+	if _, ok := <-events; ok {
+		t.Fatal("subscriber channel of a removed room must be closed")
+	}
+}
+
+// Результаты сохраняются только за полный раунд Quiz Show и не превышают его максимум
+func TestLiveQuizResultsOnlyForFullRound(t *testing.T) {
+	_, room := newTestLiveQuiz(t)
+	definition, _ := models.FindGameDefinition(models.GameQuizShow)
+	room.definition = definition
+	addTestPlayer(room, models.User{ID: 10, Username: "alice"})
+	room.players[10].correct = 2
+
+	room.current = definition.RoundSize - 2
+	if results := room.resultsLocked(); len(results) != 0 {
+		t.Fatalf("room ended after %d questions saved %d results", room.current+1, len(results))
+	}
+
+	room.current = definition.RoundSize - 1
+	results := room.resultsLocked()
+	if len(results) != 1 {
+		t.Fatalf("full round saved %d results, want 1", len(results))
+	}
+	if got := results[0]; got.Score != 2 || got.MaxScore != definition.MaxScore || got.TotalCount != definition.RoundSize {
+		t.Errorf("result = %d/%d of %d, want 2/%d of %d", got.Score, got.MaxScore, got.TotalCount, definition.MaxScore, definition.RoundSize)
+	}
+
+	// Очков больше, чем вопросов, не бывает: такая строка не сохраняется
+	room.players[10].correct = definition.RoundSize + 1
+	if results := room.resultsLocked(); len(results) != 0 {
+		t.Errorf("inconsistent result was saved: %+v", results)
+	}
+}
+
+func TestSpeedPoints(t *testing.T) {
+	tests := []struct {
+		elapsed time.Duration
+		want    int
+	}{
+		{0, liveQuizMaxPoints},
+		{liveQuizQuestionTime / 2, 750},
+		{liveQuizQuestionTime, liveQuizMaxPoints / 2},
+		{2 * liveQuizQuestionTime, liveQuizMaxPoints / 2},
+	}
+	for _, tt := range tests {
+		if got := speedPoints(tt.elapsed); got != tt.want {
+			t.Errorf("speedPoints(%s) = %d, want %d", tt.elapsed, got, tt.want)
+		}
+	}
+}
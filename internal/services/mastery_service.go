@@ -0,0 +1,479 @@
+package services
+
+import (
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/repositories"
+	"errors"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Параметры модели Эло. Шаг обновления убывает с числом ответов:
+// первые ответы быстро сдвигают оценку, дальше она стабилизируется.
+const (
+	masteryStepBase  = 0.8
+	masteryStepDecay = 0.05
+)
+
+// Адаптивная практика подбирает вопрос, на который ученик ответит верно
+// с вероятностью около 70-85%
+const (
+	practiceTargetMin = 0.70
+	practiceTargetMax = 0.85
+)
+
+type MasteryService struct {
+	masteryRepo   *repositories.MasteryRepository
+	lessonRepo    *repositories.LessonRepository
+	userRepo      *repositories.UserRepository
+	lessonService *LessonService
+}
+
+func NewMasteryService(
+	masteryRepo *repositories.MasteryRepository,
+	lessonRepo *repositories.LessonRepository,
+	userRepo *repositories.UserRepository,
+	lessonService *LessonService,
+) *MasteryService {
+	return &MasteryService{
+		masteryRepo:   masteryRepo,
+		lessonRepo:    lessonRepo,
+		userRepo:      userRepo,
+		lessonService: lessonService,
+	}
+}
+
+// MasteryLevel оценка освоения навыка: Probability - шанс верного ответа
+// на вопрос средней сложности, в процентах
+type MasteryLevel struct {
+	Skill       string  `json:"skill"`
+	Rating      float64 `json:"rating"`
+	Probability float64 `json:"probability"`
+	Attempts    int     `json:"attempts"`
+}
+
+// PracticeOption вариант ответа без признака правильности
+type PracticeOption struct {
+	ID   uint   `json:"id"`
+	Text string `json:"text"`
+}
+
+// PracticeQuestion следующий вопрос адаптивной практики
+type PracticeQuestion struct {
+	QuestionID       uint                `json:"question_id"`
+	LessonID         uint                `json:"lesson_id"`
+	Text             string              `json:"text"`
+	Type             models.QuestionType `json:"type"`
+	Audio            *models.MediaFile   `json:"audio,omitempty"`
+	Options          []PracticeOption    `json:"options"`
+	PredictedSuccess float64             `json:"predicted_success"`
+	Mastery          MasteryLevel        `json:"mastery"`
+}
+
+// PracticeAnswerRequest ответ практики: вариант для вопроса с выбором,
+// текст для вопроса с вводом ответа
+type PracticeAnswerRequest struct {
+	QuestionID     uint    `json:"question_id" binding:"required"`
+	AnswerOptionID uint    `json:"answer_option_id"`
+	Text           *string `json:"text"`
+}
+
+// PracticeAnswerResult итог ответа: правильный вариант, новая оценка навыка
+// и следующий вопрос
+type PracticeAnswerResult struct {
+	Correct          bool              `json:"correct"`
+	CorrectOptionIDs []uint            `json:"correct_option_ids"`
+	Feedback         *SpellingResult   `json:"feedback,omitempty"`
+	Mastery          MasteryLevel      `json:"mastery"`
+	Next             *PracticeQuestion `json:"next"`
+}
+
+// SkillInfo столбец тепловой карты
+type SkillInfo struct {
+	Key   string `json:"key"`
+	Kind  string `json:"kind"`
+	Title string `json:"title"`
+}
+
+// StudentMastery строка тепловой карты; навыки без ответов отсутствуют в Cells
+type StudentMastery struct {
+	StudentID uint                    `json:"student_id"`
+	FullName  string                  `json:"full_name"`
+	Cells     map[string]MasteryLevel `json:"cells"`
+}
+
+// MasteryHeatmap освоение навыков учениками класса
+type MasteryHeatmap struct {
+	Level       int              `json:"level"`
+	LevelLetter string           `json:"level_letter"`
+	Skills      []SkillInfo      `json:"skills"`
+	Students    []StudentMastery `json:"students"`
+}
+
+// HandleEvent обновляет оценки по ответам в тестах и играх
+func (s *MasteryService) HandleEvent(event Event, outcome *EventOutcome) error {
+	switch event.Type {
+	case models.EventTestSubmitted:
+		if event.TestAttempt == nil {
+			return nil
+		}
+		return s.recordAnswers(event.UserID, models.LessonSkill(event.TestAttempt.LessonID),
+			models.ReviewSourceQuestion, event.AnsweredQuestionIDs, event.MissedQuestionIDs)
+	case models.EventGameFinished:
+		if event.GameResult == nil {
+			return nil
+		}
+		return s.recordAnswers(event.UserID, models.GameSkill(event.GameResult.GameType, event.GameResult.Level),
+			models.ReviewSourceGameItem, event.PlayedGameItemIDs, event.MissedGameItemIDs)
+	}
+	return nil
+}
+
+// recordAnswers применяет ответы ученика к его рейтингу и сложности заданий.
+// Все ответы оцениваются относительно рейтинга до попытки, чтобы порядок
+// вопросов в тесте не влиял на итог.
+func (s *MasteryService) recordAnswers(userID uint, skill string, sourceType models.ReviewSource, answeredIDs, missedIDs []uint) error {
+	answeredIDs = uniqueIDs(answeredIDs)
+	if len(answeredIDs) == 0 {
+		return nil
+	}
+	missed := make(map[uint]bool, len(missedIDs))
+	for _, id := range missedIDs {
+		missed[id] = true
+	}
+
+	return s.masteryRepo.DB().Transaction(func(tx *gorm.DB) error {
+		masteryRepo := s.masteryRepo.WithTx(tx)
+
+		mastery, err := masteryRepo.LockMastery(userID, skill)
+		if err != nil {
+			return err
+		}
+		difficulties, err := masteryRepo.LockDifficulties(sourceType, skill, answeredIDs)
+		if err != nil {
+			return err
+		}
+
+		applyEloAnswers(mastery, difficulties, missed)
+		if err := masteryRepo.SaveDifficulties(difficulties); err != nil {
+			return err
+		}
+		return masteryRepo.SaveMastery(mastery)
+	})
+}
+
+// GetMastery оценка навыка ученика; без ответов - начальная
+func (s *MasteryService) GetMastery(userID uint, skill string) (*models.SkillMastery, error) {
+	mastery, err := s.masteryRepo.FindMastery(userID, skill)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.SkillMastery{UserID: userID, Skill: skill}, nil
+		}
+		return nil, err
+	}
+	return mastery, nil
+}
+
+// NextPracticeQuestion подбирает вопрос урока, предсказанная вероятность
+// верного ответа на который ближе всего к целевому коридору. Вопрос,
+// заданный последним, не повторяется подряд.
+func (s *MasteryService) NextPracticeQuestion(userID, lessonID uint) (*PracticeQuestion, error) {
+	lesson, _, err := s.lessonService.GetLesson(lessonID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(lesson.Questions) == 0 {
+		return nil, errors.New("В уроке нет вопросов")
+	}
+
+	skill := models.LessonSkill(lesson.ID)
+	mastery, err := s.GetMastery(userID, skill)
+	if err != nil {
+		return nil, err
+	}
+
+	questionIDs := make([]uint, len(lesson.Questions))
+	for i, question := range lesson.Questions {
+		questionIDs[i] = question.ID
+	}
+	difficulties, err := s.masteryRepo.FindDifficulties(models.ReviewSourceQuestion, questionIDs)
+	if err != nil {
+		return nil, err
+	}
+	difficultyByID := make(map[uint]float64, len(difficulties))
+	for _, difficulty := range difficulties {
+		difficultyByID[difficulty.SourceID] = difficulty.Difficulty
+	}
+
+	// Перемешиваем, чтобы при равных оценках вопросы чередовались
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	candidates := make([]models.Question, len(lesson.Questions))
+	copy(candidates, lesson.Questions)
+	rng.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+
+	var best *models.Question
+	bestSuccess, bestDistance := 0.0, math.Inf(1)
+	for i := range candidates {
+		question := &candidates[i]
+		if question.ID == mastery.LastItemID && len(candidates) > 1 {
+			continue
+		}
+		success := predictSuccess(mastery.Rating, difficultyByID[question.ID])
+		if distance := practiceDistance(success); distance < bestDistance {
+			best, bestSuccess, bestDistance = question, success, distance
+		}
+	}
+
+	// У вопроса с вводом ответа варианты - это допустимые написания
+	options := []PracticeOption{}
+	if best.Type != models.QuestionTyped {
+		for _, option := range best.AnswerOptions {
+			options = append(options, PracticeOption{ID: option.ID, Text: option.Text})
+		}
+	}
+
+	return &PracticeQuestion{
+		QuestionID:       best.ID,
+		LessonID:         lesson.ID,
+		Text:             best.Text,
+		Type:             best.Type,
+		Audio:            best.Audio,
+		Options:          options,
+		PredictedSuccess: math.Round(bestSuccess*1000) / 10,
+		Mastery:          masteryLevel(mastery),
+	}, nil
+}
+
+// SubmitPracticeAnswer проверяет ответ, обновляет оценку и выдает следующий вопрос.
+// Ответы практики не создают попыток теста и не влияют на прогресс урока.
+func (s *MasteryService) SubmitPracticeAnswer(userID uint, req PracticeAnswerRequest) (*PracticeAnswerResult, error) {
+	question, err := s.lessonRepo.FindQuestionByID(req.QuestionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Вопрос не найден")
+		}
+		return nil, err
+	}
+	// Отвечать можно только на вопросы доступного ученику урока
+	if _, _, err := s.lessonService.GetLesson(question.LessonID, userID); err != nil {
+		return nil, err
+	}
+
+	correct := false
+	var correctOptionIDs []uint
+	var feedback *SpellingResult
+	if question.Type == models.QuestionTyped {
+		if req.Text == nil {
+			return nil, errors.New("Неверный формат ответа")
+		}
+		feedback = gradeSpelling(*req.Text, question.AcceptedAnswers())
+		correct = feedback.Correct
+	} else {
+		validOption := false
+		for _, option := range question.AnswerOptions {
+			if option.ID == req.AnswerOptionID {
+				validOption = true
+				correct = option.IsCorrect
+			}
+			if option.IsCorrect {
+				correctOptionIDs = append(correctOptionIDs, option.ID)
+			}
+		}
+		if !validOption {
+			return nil, errors.New("Неверный вариант ответа")
+		}
+	}
+
+	var missed []uint
+	if !correct {
+		missed = []uint{question.ID}
+	}
+	skill := models.LessonSkill(question.LessonID)
+	if err := s.recordAnswers(userID, skill, models.ReviewSourceQuestion, []uint{question.ID}, missed); err != nil {
+		return nil, err
+	}
+
+	if err := s.masteryRepo.SetLastItem(userID, skill, question.ID); err != nil {
+		return nil, err
+	}
+	mastery, err := s.GetMastery(userID, skill)
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := s.NextPracticeQuestion(userID, question.LessonID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PracticeAnswerResult{
+		Correct:          correct,
+		CorrectOptionIDs: correctOptionIDs,
+		Feedback:         feedback,
+		Mastery:          masteryLevel(mastery),
+		Next:             next,
+	}, nil
+}
+
+// GetClassHeatmap тепловая карта освоения: ученики класса по строкам,
+// навыки по столбцам. Уроки показываются всегда, игры - только сыгранные.
+func (s *MasteryService) GetClassHeatmap(level int, levelLetter string) (*MasteryHeatmap, error) {
+	if level < 1 || level > 11 {
+		return nil, errors.New("Класс должен быть от 1 до 11")
+	}
+	levelLetter = strings.TrimSpace(strings.ToUpper(levelLetter))
+	if len([]rune(levelLetter)) > 1 {
+		return nil, errors.New("Неверный формат буквы класса")
+	}
+
+	filters := map[string]interface{}{"level": level}
+	if levelLetter != "" {
+		filters["level_letter"] = levelLetter
+	}
+	students, err := s.userRepo.FindStudents(filters)
+	if err != nil {
+		return nil, err
+	}
+	studentIDs := make([]uint, len(students))
+	for i, student := range students {
+		studentIDs[i] = student.ID
+	}
+	masteries, err := s.masteryRepo.FindByUsers(studentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	lessons, err := s.lessonRepo.FindAll(true)
+	if err != nil {
+		return nil, err
+	}
+	skills := make([]SkillInfo, 0, len(lessons))
+	for _, lesson := range lessons {
+		skills = append(skills, SkillInfo{Key: models.LessonSkill(lesson.ID), Kind: models.SkillKindLesson, Title: lesson.Title})
+	}
+
+	played := make(map[string]bool)
+	for _, mastery := range masteries {
+		played[mastery.Skill] = true
+	}
+	for _, definition := range models.GameRegistry {
+		for gameLevel := 0; gameLevel < definition.LevelCount; gameLevel++ {
+			key := models.GameSkill(definition.ID, gameLevel)
+			if played[key] {
+				skills = append(skills, SkillInfo{
+					Key:   key,
+					Kind:  models.SkillKindGame,
+					Title: definition.Name + ", уровень " + strconv.Itoa(gameLevel),
+				})
+			}
+		}
+	}
+
+	cellsByStudent := make(map[uint]map[string]MasteryLevel, len(students))
+	for i := range masteries {
+		mastery := &masteries[i]
+		if cellsByStudent[mastery.UserID] == nil {
+			cellsByStudent[mastery.UserID] = make(map[string]MasteryLevel)
+		}
+		cellsByStudent[mastery.UserID][mastery.Skill] = masteryLevel(mastery)
+	}
+
+	heatmap := &MasteryHeatmap{
+		Level:       level,
+		LevelLetter: levelLetter,
+		Skills:      skills,
+		Students:    make([]StudentMastery, len(students)),
+	}
+	for i := range students {
+		cells := cellsByStudent[students[i].ID]
+		if cells == nil {
+			cells = make(map[string]MasteryLevel)
+		}
+		heatmap.Students[i] = StudentMastery{
+			StudentID: students[i].ID,
+			FullName:  students[i].GetFullName(),
+			Cells:     cells,
+		}
+	}
+	return heatmap, nil
+}
+
+// applyEloAnswers обновляет рейтинг ученика и сложность заданий по ответам;
+// missed - задания, на которые ученик ответил неверно
+func applyEloAnswers(mastery *models.SkillMastery, difficulties []models.ItemDifficulty, missed map[uint]bool) {
+	if len(difficulties) == 0 {
+		return
+	}
+	ratingDelta := 0.0
+	for i := range difficulties {
+		difficulty := &difficulties[i]
+		correct := !missed[difficulty.SourceID]
+		surprise := eloOutcome(correct) - predictSuccess(mastery.Rating, difficulty.Difficulty)
+
+		ratingDelta += eloStep(mastery.Attempts) * surprise
+		difficulty.Difficulty -= eloStep(difficulty.Attempts) * surprise
+		difficulty.Attempts++
+		if correct {
+			mastery.CorrectCount++
+		}
+	}
+	// Тест дает много ответов сразу, поэтому шаг усредняется по ним
+	mastery.Rating += ratingDelta / math.Sqrt(float64(len(difficulties)))
+	mastery.Attempts += len(difficulties)
+}
+
+// predictSuccess вероятность верного ответа ученика с рейтингом rating
+// на задание сложности difficulty
+func predictSuccess(rating, difficulty float64) float64 {
+	return 1 / (1 + math.Exp(difficulty-rating))
+}
+
+func eloStep(attempts int) float64 {
+	return masteryStepBase / (1 + masteryStepDecay*float64(attempts))
+}
+
+func eloOutcome(correct bool) float64 {
+	if correct {
+		return 1
+	}
+	return 0
+}
+
+// practiceDistance насколько вероятность успеха выходит за целевой коридор;
+// внутри коридора предпочтение отдается его середине
+func practiceDistance(success float64) float64 {
+	middle := (practiceTargetMin + practiceTargetMax) / 2
+	distance := math.Abs(success - middle)
+	if success < practiceTargetMin || success > practiceTargetMax {
+		distance += 1
+	}
+	return distance
+}
+
+func masteryLevel(mastery *models.SkillMastery) MasteryLevel {
+	return MasteryLevel{
+		Skill:       mastery.Skill,
+		Rating:      math.Round(mastery.Rating*100) / 100,
+		Probability: math.Round(predictSuccess(mastery.Rating, 0)*1000) / 10,
+		Attempts:    mastery.Attempts,
+	}
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"englishlessons.back/internal/models"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestPredictSuccess(t *testing.T) {
+	if got := predictSuccess(0, 0); !almostEqual(got, 0.5) {
+		t.Errorf("equal rating and difficulty: %v, want 0.5", got)
+	}
+	if got := predictSuccess(1, 0); got <= 0.5 || !almostEqual(got+predictSuccess(0, 1), 1) {
+		t.Errorf("predictSuccess must be symmetric around 0.5: %v", got)
+	}
+	if predictSuccess(2, 0) <= predictSuccess(1, 0) {
+		t.Error("higher rating must predict more success")
+	}
+}
+
+func TestEloStepDecays(t *testing.T) {
+	if got := eloStep(0); !almostEqual(got, masteryStepBase) {
+		t.Errorf("eloStep(0) = %v, want %v", got, masteryStepBase)
+	}
+	if got := eloStep(20); !almostEqual(got, masteryStepBase/2) {
+		t.Errorf("eloStep(20) = %v, want %v", got, masteryStepBase/2)
+	}
+	for attempts := 1; attempts < 100; attempts++ {
+		if eloStep(attempts) >= eloStep(attempts-1) {
+			t.Fatalf("eloStep(%d) does not decrease", attempts)
+		}
+	}
+}
+
+func TestApplyEloAnswers(t *testing.T) {
+	tests := []struct {
+		name           string
+		answers        int
+		missed         map[uint]bool
+		wantRating     float64
+		wantDifficulty float64
+		wantCorrect    int
+	}{
+		// Ожидаемый успех 0.5, шаг 0.8: оценка сдвигается на 0.4
+		{"one correct", 1, nil, 0.4, -0.4, 1},
+		{"one wrong", 1, map[uint]bool{1: true}, -0.4, 0.4, 0},
+		// Четыре ответа из теста: сумма 1.6 делится на sqrt(4)
+		{"test of four correct", 4, nil, 0.8, -0.4, 4},
+		{"test half wrong", 4, map[uint]bool{1: true, 2: true}, 0, 0.4, 2},
+	}
+	for _, tt := range tests {
+		mastery := &models.SkillMastery{}
+		difficulties := make([]models.ItemDifficulty, tt.answers)
+		for i := range difficulties {
+			difficulties[i] = models.ItemDifficulty{SourceID: uint(i + 1)}
+		}
+
+		applyEloAnswers(mastery, difficulties, tt.missed)
+		if !almostEqual(mastery.Rating, tt.wantRating) {
+			t.Errorf("%s: rating = %v, want %v", tt.name, mastery.Rating, tt.wantRating)
+		}
+		if !almostEqual(difficulties[0].Difficulty, tt.wantDifficulty) || difficulties[0].Attempts != 1 {
+			t.Errorf("%s: first item = %+v, want difficulty %v", tt.name, difficulties[0], tt.wantDifficulty)
+		}
+		if mastery.Attempts != tt.answers || mastery.CorrectCount != tt.wantCorrect {
+			t.Errorf("%s: attempts %d, correct %d", tt.name, mastery.Attempts, mastery.CorrectCount)
+		}
+	}
+}
+
+func TestApplyEloAnswersWithoutItems(t *testing.T) {
+	mastery := &models.SkillMastery{Rating: 1.5, Attempts: 3}
+	applyEloAnswers(mastery, nil, nil)
+	if mastery.Rating != 1.5 || mastery.Attempts != 3 {
+		t.Fatalf("mastery changed without answers: %+v", mastery)
+	}
+}
+
+// Ученик, всегда отвечающий верно, растет все медленнее; ошибка на легком
+// задании после этого сдвигает оценку сильнее, чем верный ответ
+func TestApplyEloAnswersConverges(t *testing.T) {
+	mastery := &models.SkillMastery{}
+	item := []models.ItemDifficulty{{SourceID: 1, Attempts: 1000}}
+	previousGain := math.Inf(1)
+	for i := 0; i < 30; i++ {
+		before := mastery.Rating
+		applyEloAnswers(mastery, item, nil)
+		gain := mastery.Rating - before
+		if gain <= 0 || gain >= previousGain {
+			t.Fatalf("answer %d: gain %v after %v", i+1, gain, previousGain)
+		}
+		previousGain = gain
+	}
+
+	before := mastery.Rating
+	applyEloAnswers(mastery, item, map[uint]bool{1: true})
+	if loss := before - mastery.Rating; loss <= previousGain {
+		t.Fatalf("surprising mistake moved rating by %v, less than a correct answer (%v)", loss, previousGain)
+	}
+}
+
+func TestPracticeDistance(t *testing.T) {
+	middle := (practiceTargetMin + practiceTargetMax) / 2
+	if got := practiceDistance(middle); !almostEqual(got, 0) {
+		t.Errorf("middle of the corridor: %v, want 0", got)
+	}
+	inside := practiceDistance(practiceTargetMax)
+	if inside >= 1 {
+		t.Errorf("edge of the corridor must be inside: %v", inside)
+	}
+	for _, success := range []float64{0.1, 0.69, 0.86, 0.99} {
+		if got := practiceDistance(success); got < 1 || got <= inside {
+			t.Errorf("practiceDistance(%v) = %v, want outside the corridor", success, got)
+		}
+	}
+}
+
+func TestMasteryLevel(t *testing.T) {
+	got := masteryLevel(&models.SkillMastery{Skill: "lesson:1", Rating: 1.23456, Attempts: 7})
+	want := MasteryLevel{Skill: "lesson:1", Rating: 1.23, Probability: 77.5, Attempts: 7}
+	if got != want {
+		t.Errorf("masteryLevel = %+v, want %+v", got, want)
+	}
+}
+
+func TestUniqueIDs(t *testing.T) {
+	if got := uniqueIDs([]uint{3, 1, 3, 2, 1}); !reflect.DeepEqual(got, []uint{3, 1, 2}) {
+		t.Errorf("uniqueIDs = %v", got)
+	}
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/repositories"
+	"englishlessons.back/internal/storage"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultMediaPage = 50
+	maxMediaPage     = 200
+)
+
+type MediaService struct {
+	mediaRepo    *repositories.MediaRepository
+	lessonRepo   *repositories.LessonRepository
+	store        storage.Storage
+	maxAudioSize int64
+}
+
+func NewMediaService(
+	mediaRepo *repositories.MediaRepository,
+	lessonRepo *repositories.LessonRepository,
+	store storage.Storage,
+	maxAudioSize int64,
+) *MediaService {
+	return &MediaService{
+		mediaRepo:    mediaRepo,
+		lessonRepo:   lessonRepo,
+		store:        store,
+		maxAudioSize: maxAudioSize,
+	}
+}
+
+// LessonBlockRequest новый блок урока. Для аудио Text - необязательная расшифровка.
+type LessonBlockRequest struct {
+	Kind    models.LessonBlockKind `json:"kind" binding:"required"`
+	Text    string                 `json:"text"`
+	MediaID *uint                  `json:"media_id"`
+}
+
+// SetAudioRequest привязка аудио к вопросу; пустой media_id отвязывает
+type SetAudioRequest struct {
+	MediaID *uint `json:"media_id"`
+}
+
+// MaxAudioSize предельный размер аудиофайла в байтах
+func (s *MediaService) MaxAudioSize() int64 {
+	return s.maxAudioSize
+}
+
+// UploadAudio проверяет формат по содержимому и сохраняет файл. Повторная
+// загрузка того же файла возвращает уже сохраненную запись.
+func (s *MediaService) UploadAudio(userID uint, name string, data []byte) (*models.MediaFile, error) {
+	if len(data) == 0 {
+		return nil, errors.New("Файл пустой")
+	}
+	if int64(len(data)) > s.maxAudioSize {
+		return nil, errors.New("Файл слишком большой: максимум " + strconv.FormatInt(s.maxAudioSize>>20, 10) + " МБ")
+	}
+	contentType, ext := sniffAudio(data)
+	if contentType == "" {
+		return nil, errors.New("Неподдерживаемый формат аудио: нужен MP3, M4A, AAC, OGG, WAV, FLAC или WebM")
+	}
+
+	sum := sha256.Sum256(data)
+	key := string(models.MediaAudio) + "/" + hex.EncodeToString(sum[:]) + ext
+	if existing, err := s.mediaRepo.FindByKey(key); err == nil {
+		return existing, nil
+	}
+
+	if err := s.store.Put(key, data, contentType); err != nil {
+		return nil, err
+	}
+	file := &models.MediaFile{
+		Key:          key,
+		Kind:         models.MediaAudio,
+		ContentType:  contentType,
+		Size:         int64(len(data)),
+		OriginalName: truncateName(name, 255),
+		UploadedBy:   userID,
+	}
+	if err := s.mediaRepo.Create(file); err != nil {
+		// Тот же файл могли загрузить одновременно
+		if existing, findErr := s.mediaRepo.FindByKey(key); findErr == nil {
+			return existing, nil
+		}
+		return nil, err
+	}
+	file.URL = models.MediaURL(key)
+	return file, nil
+}
+
+// ListAudio библиотека загруженных аудиофайлов
+func (s *MediaService) ListAudio(limit, offset int) ([]models.MediaFile, int64, error) {
+	if limit <= 0 {
+		limit = defaultMediaPage
+	}
+	if limit > maxMediaPage {
+		limit = maxMediaPage
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.mediaRepo.FindAll(models.MediaAudio, limit, offset)
+}
+
+// Open открывает файл хранилища для отдачи клиенту
+func (s *MediaService) Open(key string) (*storage.Object, error) {
+	return s.store.Open(key)
+}
+
+// SetQuestionAudio привязывает запись к вопросу ("послушай и выбери", диктант)
+func (s *MediaService) SetQuestionAudio(questionID uint, req SetAudioRequest) (*models.Question, error) {
+	if _, err := s.lessonRepo.FindQuestionByID(questionID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Вопрос не найден")
+		}
+		return nil, err
+	}
+	if req.MediaID != nil {
+		if _, err := s.findAudio(*req.MediaID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.mediaRepo.SetQuestionAudio(questionID, req.MediaID); err != nil {
+		return nil, err
+	}
+	return s.lessonRepo.FindQuestionByID(questionID)
+}
+
+// CreateLessonBlock добавляет в конец урока блок с текстом или аудио
+func (s *MediaService) CreateLessonBlock(lessonID uint, req LessonBlockRequest) (*models.LessonBlock, error) {
+	if _, err := s.lessonRepo.FindByID(lessonID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Урок не найден")
+		}
+		return nil, err
+	}
+
+	block := &models.LessonBlock{LessonID: lessonID, Kind: req.Kind, Text: strings.TrimSpace(req.Text)}
+	switch req.Kind {
+	case models.LessonBlockText:
+		if block.Text == "" {
+			return nil, errors.New("Необходимо указать текст блока")
+		}
+	case models.LessonBlockAudio:
+		if req.MediaID == nil {
+			return nil, errors.New("Необходимо указать аудиофайл")
+		}
+		media, err := s.findAudio(*req.MediaID)
+		if err != nil {
+			return nil, err
+		}
+		block.MediaID = &media.ID
+		block.Media = media
+	default:
+		return nil, errors.New("Неверный вид блока")
+	}
+
+	if err := s.mediaRepo.CreateBlock(block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// DeleteLessonBlock удаляет блок урока; сам файл остается в библиотеке
+func (s *MediaService) DeleteLessonBlock(lessonID, blockID uint) error {
+	block, err := s.mediaRepo.FindBlock(blockID)
+	if err != nil || block.LessonID != lessonID {
+		if err == nil || errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("Блок не найден")
+		}
+		return err
+	}
+	return s.mediaRepo.DeleteBlock(blockID)
+}
+
+func (s *MediaService) findAudio(id uint) (*models.MediaFile, error) {
+	media, err := s.mediaRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Аудиофайл не найден")
+		}
+		return nil, err
+	}
+	if media.Kind != models.MediaAudio {
+		return nil, errors.New("Аудиофайл не найден")
+	}
+	return media, nil
+}
+
+// sniffAudio определяет формат аудио по сигнатуре в начале файла. Расширение
+// и Content-Type от клиента не учитываются.
+func sniffAudio(data []byte) (contentType string, ext string) {
+	switch {
+	case bytes.HasPrefix(data, []byte("ID3")):
+		return "audio/mpeg", ".mp3"
+	case len(data) >= 12 && bytes.HasPrefix(data, []byte("RIFF")) && string(data[8:12]) == "WAVE":
+		return "audio/wav", ".wav"
+	case bytes.HasPrefix(data, []byte("OggS")):
+		return "audio/ogg", ".ogg"
+	case bytes.HasPrefix(data, []byte("fLaC")):
+		return "audio/flac", ".flac"
+	case bytes.HasPrefix(data, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		// Контейнер Matroska/WebM: так записывает звук браузер
+		return "audio/webm", ".webm"
+	case len(data) >= 12 && string(data[4:8]) == "ftyp" &&
+		(string(data[8:12]) == "M4A " || string(data[8:12]) == "M4B "):
+		return "audio/mp4", ".m4a"
+	case len(data) >= 2 && data[0] == 0xFF && data[1]&0xE0 == 0xE0:
+		// Кадр MPEG без тега ID3; слой 00 означает поток AAC (ADTS)
+		if data[1]&0x06 == 0 {
+			return "audio/aac", ".aac"
+		}
+		return "audio/mpeg", ".mp3"
+	}
+	return "", ""
+}
+
+// truncateName обрезает имя файла по числу символов
+func truncateName(name string, limit int) string {
+	runes := []rune(name)
+	if len(runes) > limit {
+		return string(runes[:limit])
+	}
+	return name
+}
@@ -0,0 +1,38 @@
+package services
+
+import "testing"
+
+func TestSniffAudio(t *testing.T) {
+	tests := []struct {
+		name            string
+		data            []byte
+		wantContentType string
+		wantExt         string
+	}{
+		{"mp3 with ID3", []byte("ID3\x04\x00\x00\x00\x00\x00\x00"), "audio/mpeg", ".mp3"},
+		// Заголовок кадра MPEG-1 Layer III
+		{"mp3 frame", []byte{0xFF, 0xFB, 0x90, 0x64}, "audio/mpeg", ".mp3"},
+		// ADTS: слой 00
+		{"aac", []byte{0xFF, 0xF1, 0x50, 0x80}, "audio/aac", ".aac"},
+		{"wav", []byte("RIFF\x24\x08\x00\x00WAVEfmt "), "audio/wav", ".wav"},
+		{"ogg", []byte("OggS\x00\x02"), "audio/ogg", ".ogg"},
+		{"flac", []byte("fLaC\x00\x00\x00\x22"), "audio/flac", ".flac"},
+		{"webm", []byte{0x1A, 0x45, 0xDF, 0xA3, 0x9F, 0x42}, "audio/webm", ".webm"},
+		{"m4a", []byte("\x00\x00\x00\x20ftypM4A \x00\x00\x00\x00"), "audio/mp4", ".m4a"},
+		{"m4b", []byte("\x00\x00\x00\x20ftypM4B \x00\x00\x00\x00"), "audio/mp4", ".m4a"},
+
+		// RIFF без WAVE (например, AVI) и видео MP4 не принимаются
+		{"avi", []byte("RIFF\x24\x08\x00\x00AVI LIST"), "", ""},
+		{"mp4 video", []byte("\x00\x00\x00\x20ftypisom\x00\x00\x02\x00"), "", ""},
+		{"png", []byte("\x89PNG\r\n\x1a\n"), "", ""},
+		{"text", []byte("hello world"), "", ""},
+		{"short", []byte{0xFF}, "", ""},
+		{"empty", nil, "", ""},
+	}
+	for _, tt := range tests {
+		contentType, ext := sniffAudio(tt.data)
+		if contentType != tt.wantContentType || ext != tt.wantExt {
+			t.Errorf("%s: sniffAudio = %q, %q; want %q, %q", tt.name, contentType, ext, tt.wantContentType, tt.wantExt)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/repositories"
+)
+
+const (
+	defaultNotificationPage = 20
+	maxNotificationPage     = 100
+	maxMarkReadIDs          = 500
+	// maxOvertakenNotified сколько обогнанных учеников уведомлять за один результат
+	maxOvertakenNotified = 20
+	// streamBuffer уведомления сверх буфера медленному клиенту не отправляются:
+	// он получит их из списка при следующем запросе
+	streamBuffer = 16
+)
+
+// NotificationService сохраняет уведомления и доставляет их открытым потокам SSE.
+// Подписки живут в памяти процесса: при нескольких экземплярах сервера живая
+// доставка работает только для клиентов того же экземпляра.
+type NotificationService struct {
+	notificationRepo *repositories.NotificationRepository
+	gameResultRepo   *repositories.GameResultRepository
+
+	mu          sync.Mutex
+	subscribers map[uint]map[chan models.Notification]struct{}
+}
+
+func NewNotificationService(
+	notificationRepo *repositories.NotificationRepository,
+	gameResultRepo *repositories.GameResultRepository,
+) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		gameResultRepo:   gameResultRepo,
+		subscribers:      make(map[uint]map[chan models.Notification]struct{}),
+	}
+}
+
+// NotificationListRequest страница уведомлений
+type NotificationListRequest struct {
+	UnreadOnly bool
+	Limit      int
+	Offset     int
+}
+
+// MarkReadRequest уведомления, которые нужно отметить прочитанными
+type MarkReadRequest struct {
+	IDs []uint `json:"ids" binding:"required"`
+}
+
+// Notify сохраняет уведомление и отправляет его в открытые потоки получателя.
+// Ошибка только логируется: уведомление не должно ломать основное действие.
+func (s *NotificationService) Notify(userID uint, notificationType models.NotificationType, title, body string, payload models.JSONMap) {
+	notification := &models.Notification{
+		UserID:  userID,
+		Type:    notificationType,
+		Title:   title,
+		Body:    body,
+		Payload: payload,
+	}
+	if err := s.notificationRepo.Create(notification); err != nil {
+		log.Printf("Failed to create %s notification for user %d: %v", notificationType, userID, err)
+		return
+	}
+	s.deliver(*notification)
+}
+
+// NotifyMany одно уведомление нескольким пользователям
+func (s *NotificationService) NotifyMany(userIDs []uint, notificationType models.NotificationType, title, body string, payload models.JSONMap) {
+	notifications := make([]models.Notification, len(userIDs))
+	for i, userID := range userIDs {
+		notifications[i] = models.Notification{
+			UserID:  userID,
+			Type:    notificationType,
+			Title:   title,
+			Body:    body,
+			Payload: payload,
+		}
+	}
+	if err := s.notificationRepo.CreateBatch(notifications); err != nil {
+		log.Printf("Failed to create %s notifications: %v", notificationType, err)
+		return
+	}
+	for _, notification := range notifications {
+		s.deliver(notification)
+	}
+}
+
+// HandleEvent уведомляет о пройденном тесте, полученном достижении и о том,
+// что ученика обогнали в рейтинге игры
+func (s *NotificationService) HandleEvent(event Event, outcome *EventOutcome) error {
+	switch event.Type {
+	case models.EventTestSubmitted:
+		attempt := event.TestAttempt
+		if attempt == nil || !attempt.IsPassed {
+			return nil
+		}
+		s.Notify(attempt.UserID, models.NotificationTestPassed,
+			"Тест пройден",
+			fmt.Sprintf("Урок %d пройден: %.0f%% правильных ответов", attempt.LessonID, attempt.Percentage),
+			models.JSONMap{"lesson_id": attempt.LessonID, "attempt_id": attempt.ID, "percentage": attempt.Percentage})
+	case models.EventAchievementEarned:
+		achievement := event.Achievement
+		if achievement == nil {
+			return nil
+		}
+		s.Notify(achievement.UserID, models.NotificationAchievementEarned,
+			"Новое достижение",
+			achievement.Title,
+			models.JSONMap{"achievement_id": achievement.ID, "type": achievement.Type, "icon": achievement.Icon})
+	case models.EventGameFinished:
+		result := event.GameResult
+		if result == nil {
+			return nil
+		}
+		userIDs, err := s.gameResultRepo.FindOvertakenUsers(result, maxOvertakenNotified)
+		if err != nil {
+			return err
+		}
+		// Имя обогнавшего не раскрываем: его могли скрыть настройки приватности
+		s.NotifyMany(userIDs, models.NotificationLeaderboardOvertaken,
+			"Вас обогнали в рейтинге",
+			fmt.Sprintf("Кто-то набрал больше в игре %s, уровень %d. Попробуйте вернуть место!", result.GameType, result.Level),
+			models.JSONMap{"game_type": result.GameType, "level": result.Level})
+	}
+	return nil
+}
+
+// List уведомления пользователя и число непрочитанных
+func (s *NotificationService) List(userID uint, req NotificationListRequest) ([]models.Notification, int64, int64, error) {
+	if req.Limit <= 0 {
+		req.Limit = defaultNotificationPage
+	}
+	if req.Limit > maxNotificationPage {
+		req.Limit = maxNotificationPage
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	notifications, total, err := s.notificationRepo.FindByUser(userID, req.UnreadOnly, req.Limit, req.Offset)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if notifications == nil {
+		notifications = []models.Notification{}
+	}
+	unread, err := s.notificationRepo.CountUnread(userID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return notifications, total, unread, nil
+}
+
+func (s *NotificationService) UnreadCount(userID uint) (int64, error) {
+	return s.notificationRepo.CountUnread(userID)
+}
+
+// MarkRead отмечает уведомления прочитанными и возвращает число оставшихся
+func (s *NotificationService) MarkRead(userID uint, req MarkReadRequest) (int64, error) {
+	if len(req.IDs) == 0 {
+		return 0, errors.New("Необходимо указать уведомления")
+	}
+	if len(req.IDs) > maxMarkReadIDs {
+		return 0, errors.New("Неверный запрос: не более 500 уведомлений за раз")
+	}
+	if err := s.notificationRepo.MarkRead(userID, req.IDs); err != nil {
+		return 0, err
+	}
+	return s.notificationRepo.CountUnread(userID)
+}
+
+func (s *NotificationService) MarkAllRead(userID uint) error {
+	return s.notificationRepo.MarkAllRead(userID)
+}
+
+// Subscribe открывает живой поток уведомлений пользователя.
+// Возвращенную функцию нужно вызвать при закрытии соединения.
+func (s *NotificationService) Subscribe(userID uint) (<-chan models.Notification, func()) {
+	ch := make(chan models.Notification, streamBuffer)
+
+	s.mu.Lock()
+	if s.subscribers[userID] == nil {
+		s.subscribers[userID] = make(map[chan models.Notification]struct{})
+	}
+	s.subscribers[userID][ch] = struct{}{}
+	s.mu.Unlock()
+
+	return ch, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.subscribers[userID], ch)
+		if len(s.subscribers[userID]) == 0 {
+			delete(s.subscribers, userID)
+		}
+	}
+}
+
+// deliver отправляет уведомление во все потоки получателя, не блокируясь
+func (s *NotificationService) deliver(notification models.Notification) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.subscribers[notification.UserID] {
+		select {
+		case ch <- notification:
+		default:
+		}
+	}
+}
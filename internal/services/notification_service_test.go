@@ -0,0 +1,168 @@
+package services
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/repositories"
+	"englishlessons.back/internal/testdb"
+)
+
+// received забирает из потока все уже доставленные уведомления
+func received(ch <-chan models.Notification) []models.Notification {
+	var result []models.Notification
+	for {
+		select {
+		case notification := <-ch:
+			result = append(result, notification)
+		default:
+			return result
+		}
+	}
+}
+
+func TestSubscribeDeliversToAllStreamsOfUser(t *testing.T) {
+	s := NewNotificationService(nil, nil)
+	// Одна вкладка и телефон одного ученика, плюс другой ученик
+	first, unsubscribeFirst := s.Subscribe(1)
+	defer unsubscribeFirst()
+	second, unsubscribeSecond := s.Subscribe(1)
+	defer unsubscribeSecond()
+	other, unsubscribeOther := s.Subscribe(2)
+	defer unsubscribeOther()
+
+	s.deliver(models.Notification{ID: 10, UserID: 1, Title: "Тест пройден"})
+
+	for name, ch := range map[string]<-chan models.Notification{"first": first, "second": second} {
+		got := received(ch)
+		if len(got) != 1 || got[0].ID != 10 {
+			t.Errorf("%s stream got %+v", name, got)
+		}
+	}
+	if got := received(other); len(got) != 0 {
+		t.Errorf("other user got %+v", got)
+	}
+
+	// Уведомление пользователю без потоков просто не доставляется
+	s.deliver(models.Notification{ID: 11, UserID: 3})
+}
+
+func TestUnsubscribe(t *testing.T) {
+	s := NewNotificationService(nil, nil)
+	kept, unsubscribeKept := s.Subscribe(1)
+	defer unsubscribeKept()
+	closed, unsubscribe := s.Subscribe(1)
+
+	unsubscribe()
+	s.deliver(models.Notification{ID: 1, UserID: 1})
+	if got := received(closed); len(got) != 0 {
+		t.Errorf("unsubscribed stream got %+v", got)
+	}
+	if got := received(kept); len(got) != 1 {
+		t.Errorf("remaining stream got %+v", got)
+	}
+
+	// Повторный вызов безопасен, пустая запись пользователя удаляется
+	unsubscribe()
+	unsubscribeKept()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subscribers[1]; ok {
+		t.Errorf("subscribers not cleaned up: %v", s.subscribers)
+	}
+}
+
+// Медленный клиент не блокирует отправителя: лишнее отбрасывается,
+// а порядок доставленного сохраняется
+func TestDeliverDropsWhenBufferIsFull(t *testing.T) {
+	s := NewNotificationService(nil, nil)
+	ch, unsubscribe := s.Subscribe(1)
+	defer unsubscribe()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 1; i <= streamBuffer+5; i++ {
+			s.deliver(models.Notification{ID: uint(i), UserID: 1})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("deliver blocked on a full stream")
+	}
+
+	got := received(ch)
+	if len(got) != streamBuffer {
+		t.Fatalf("got %d notifications, want %d", len(got), streamBuffer)
+	}
+	for i, notification := range got {
+		if notification.ID != uint(i+1) {
+			t.Fatalf("notification %d has ID %d", i, notification.ID)
+		}
+	}
+
+	// Освободившийся буфер снова принимает уведомления
+	s.deliver(models.Notification{ID: 100, UserID: 1})
+	if got := received(ch); len(got) != 1 || got[0].ID != 100 {
+		t.Errorf("after draining got %+v", got)
+	}
+}
+
+// Подписка, отписка и доставка из разных горутин (запускать с -race)
+func TestSubscribeDeliverConcurrently(t *testing.T) {
+	s := NewNotificationService(nil, nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		userID := uint(i%3 + 1)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			ch, unsubscribe := s.Subscribe(userID)
+			defer unsubscribe()
+			received(ch)
+		}()
+		go func() {
+			defer wg.Done()
+			s.deliver(models.Notification{UserID: userID})
+		}()
+	}
+	wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.subscribers) != 0 {
+		t.Errorf("subscribers left after all streams closed: %v", s.subscribers)
+	}
+}
+
+// Notify сначала сохраняет уведомление, поэтому в поток оно приходит с ID
+func TestNotifyStoresAndDelivers(t *testing.T) {
+	db := testdb.Open(t)
+	s := NewNotificationService(repositories.NewNotificationRepository(db), nil)
+	users := testdb.Seed(t, db, 2, 0).Students
+
+	chA, unsubscribeA := s.Subscribe(users[0].ID)
+	defer unsubscribeA()
+	chB, unsubscribeB := s.Subscribe(users[1].ID)
+	defer unsubscribeB()
+
+	s.Notify(users[0].ID, models.NotificationTestPassed, "Тест пройден", "Урок 1", models.JSONMap{"lesson_id": 1})
+	got := received(chA)
+	if len(got) != 1 || got[0].ID == 0 || got[0].Type != models.NotificationTestPassed {
+		t.Fatalf("Notify delivered %+v", got)
+	}
+
+	s.NotifyMany([]uint{users[0].ID, users[1].ID}, models.NotificationLeaderboardOvertaken, "Вас обогнали", "", nil)
+	gotA, gotB := received(chA), received(chB)
+	if len(gotA) != 1 || len(gotB) != 1 || gotA[0].ID == 0 || gotA[0].ID == gotB[0].ID {
+		t.Fatalf("NotifyMany delivered %+v and %+v", gotA, gotB)
+	}
+
+	unread, err := s.UnreadCount(users[0].ID)
+	if err != nil || unread != 2 {
+		t.Errorf("UnreadCount = %d, %v; want 2", unread, err)
+	}
+}
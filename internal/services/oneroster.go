@@ -0,0 +1,225 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"englishlessons.back/internal/models"
+)
+
+// Идентификаторы OneRoster для объектов, которых нет в базе: школа одна,
+// курс один, все результаты уроков относятся к одной категории
+const (
+	oneRosterOrgID      = "school"
+	oneRosterCourseID   = "course-english"
+	oneRosterCategoryID = "category-lesson-tests"
+	oneRosterDateLayout = "2006-01-02"
+)
+
+// oneRosterFiles файлы пакета OneRoster 1.2 и их заголовки; порядок задает
+// порядок файлов в архиве. Выгрузка полная (bulk), поэтому status и
+// dateLastModified остаются пустыми.
+var oneRosterFiles = []struct {
+	name   string
+	header []string
+}{
+	{"orgs.csv", []string{"sourcedId", "status", "dateLastModified", "name", "type", "identifier", "parentSourcedId"}},
+	{"academicSessions.csv", []string{"sourcedId", "status", "dateLastModified", "title", "type", "startDate", "endDate", "parentSourcedId", "schoolYear"}},
+	{"courses.csv", []string{"sourcedId", "status", "dateLastModified", "schoolYearSourcedId", "title", "courseCode", "grades", "orgSourcedId", "subjects", "subjectCodes"}},
+	{"classes.csv", []string{"sourcedId", "status", "dateLastModified", "title", "grades", "courseSourcedId", "classCode", "classType", "location", "schoolSourcedId", "termSourcedIds", "subjects", "subjectCodes", "periods"}},
+	{"users.csv", []string{"sourcedId", "status", "dateLastModified", "enabledUser", "username", "userIds", "givenName", "familyName", "middleName", "identifier", "email", "sms", "phone", "agentSourcedIds", "grades", "password", "userMasterIdentifier", "resourceSourcedIds", "preferredGivenName", "preferredMiddleName", "preferredFamilyName", "primaryOrgSourcedId", "pronouns"}},
+	{"roles.csv", []string{"sourcedId", "status", "dateLastModified", "userSourcedId", "roleType", "role", "beginDate", "endDate", "orgSourcedId", "userProfileSourcedId"}},
+	{"enrollments.csv", []string{"sourcedId", "status", "dateLastModified", "classSourcedId", "schoolSourcedId", "userSourcedId", "role", "primary", "beginDate", "endDate"}},
+	{"categories.csv", []string{"sourcedId", "status", "dateLastModified", "title", "weight"}},
+	{"lineItems.csv", []string{"sourcedId", "status", "dateLastModified", "title", "description", "assignDate", "dueDate", "classSourcedId", "categorySourcedId", "gradingPeriodSourcedId", "academicSessionSourcedId", "resultValueMin", "resultValueMax", "schoolSourcedId"}},
+	{"results.csv", []string{"sourcedId", "status", "dateLastModified", "lineItemSourcedId", "userSourcedId", "scoreStatus", "score", "scoreDate", "comment", "scoreScaleSourcedId", "inProgress", "incomplete", "late", "missing"}},
+}
+
+// oneRosterAbsent файлы спецификации, которых нет в пакете
+var oneRosterAbsent = []string{
+	"classResources", "courseResources", "demographics", "lineItemLearningObjectiveIds",
+	"lineItemScoreScales", "resources", "resultLearningObjectiveIds", "resultScoreScales",
+	"scoreScales", "userProfiles", "userResources",
+}
+
+// WriteOneRoster пишет журнал оценок zip-архивом OneRoster 1.2 CSV: классы -
+// это классы школы, у каждого урока в каждом классе своя графа (lineItem),
+// результат - оценка по пятибалльной шкале с процентом в комментарии
+func WriteOneRoster(w io.Writer, book *Gradebook) error {
+	session := schoolYearSession(book)
+	rows := map[string][][]string{
+		"orgs.csv": {{oneRosterOrgID, "", "", "Школа", "school", "", ""}},
+		"academicSessions.csv": {{
+			session.id, "", "", session.title, "schoolYear",
+			session.start.Format(oneRosterDateLayout), session.end.Format(oneRosterDateLayout),
+			"", strconv.Itoa(session.end.Year()),
+		}},
+		"courses.csv":    {{oneRosterCourseID, "", "", session.id, "Английский язык", "ENG", "", oneRosterOrgID, "English", ""}},
+		"categories.csv": {{oneRosterCategoryID, "", "", "Тесты уроков", ""}},
+	}
+
+	for _, class := range book.Classes {
+		rows["classes.csv"] = append(rows["classes.csv"], []string{
+			oneRosterClassID(class), "", "", class.Title() + " Английский язык", oneRosterGrade(class.Level),
+			oneRosterCourseID, class.Title(), "scheduled", "", oneRosterOrgID, session.id, "English", "", "",
+		})
+		for _, lesson := range book.Lessons {
+			rows["lineItems.csv"] = append(rows["lineItems.csv"], []string{
+				oneRosterLineItemID(lesson.ID, class), "", "",
+				fmt.Sprintf("%d. %s", lesson.Order, lesson.Title), lesson.Description,
+				lesson.CreatedAt.Format(oneRosterDateLayout), session.end.Format(oneRosterDateLayout),
+				oneRosterClassID(class), oneRosterCategoryID, session.id, session.id, "1", "5", oneRosterOrgID,
+			})
+		}
+		for _, teacher := range book.Teachers {
+			rows["enrollments.csv"] = append(rows["enrollments.csv"], []string{
+				fmt.Sprintf("enrollment-%s-%d", oneRosterClassID(class), teacher.ID), "", "",
+				oneRosterClassID(class), oneRosterOrgID, oneRosterUserID(teacher.ID), "teacher", "false", "", "",
+			})
+		}
+	}
+
+	for _, teacher := range book.Teachers {
+		rows["users.csv"] = append(rows["users.csv"], oneRosterUser(&teacher, ""))
+		rows["roles.csv"] = append(rows["roles.csv"], oneRosterRole(teacher.ID, "teacher"))
+	}
+	for _, student := range book.Students {
+		class, ok := studentClass(&student)
+		grade := ""
+		if ok {
+			grade = oneRosterGrade(class.Level)
+		}
+		rows["users.csv"] = append(rows["users.csv"], oneRosterUser(&student, grade))
+		rows["roles.csv"] = append(rows["roles.csv"], oneRosterRole(student.ID, "student"))
+		if !ok {
+			continue
+		}
+
+		rows["enrollments.csv"] = append(rows["enrollments.csv"], []string{
+			fmt.Sprintf("enrollment-%s-%d", oneRosterClassID(class), student.ID), "", "",
+			oneRosterClassID(class), oneRosterOrgID, oneRosterUserID(student.ID), "student", "false", "", "",
+		})
+		for _, lesson := range book.Lessons {
+			cell, ok := book.Cells[student.ID][lesson.ID]
+			if !ok {
+				continue
+			}
+			rows["results.csv"] = append(rows["results.csv"], []string{
+				fmt.Sprintf("result-%d-%d", lesson.ID, student.ID), "", "",
+				oneRosterLineItemID(lesson.ID, class), oneRosterUserID(student.ID),
+				"fully graded", strconv.Itoa(cell.Grade), cell.LastAttemptAt.Format(oneRosterDateLayout),
+				fmt.Sprintf("%.0f%%", cell.Percentage), "", "false", "false", "false", "false",
+			})
+		}
+	}
+
+	archive := zip.NewWriter(w)
+	if err := writeOneRosterCSV(archive, "manifest.csv", oneRosterManifest()); err != nil {
+		return err
+	}
+	for _, file := range oneRosterFiles {
+		if err := writeOneRosterCSV(archive, file.name, append([][]string{file.header}, rows[file.name]...)); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+func writeOneRosterCSV(archive *zip.Writer, name string, records [][]string) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(file)
+	if err := writer.WriteAll(records); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+func oneRosterManifest() [][]string {
+	records := [][]string{
+		{"propertyName", "value"},
+		{"manifest.version", "1.0"},
+		{"oneroster.version", "1.2"},
+	}
+	for _, file := range oneRosterFiles {
+		name := file.name[:len(file.name)-len(".csv")]
+		records = append(records, []string{"file." + name, "bulk"})
+	}
+	for _, name := range oneRosterAbsent {
+		records = append(records, []string{"file." + name, "absent"})
+	}
+	return append(records,
+		[]string{"source.systemName", "English Lessons"},
+		[]string{"source.systemCode", "englishlessons"},
+	)
+}
+
+// oneRosterSession учебный год с 1 сентября по 31 августа
+type oneRosterSession struct {
+	id    string
+	title string
+	start time.Time
+	end   time.Time
+}
+
+// schoolYearSession учебный год начала периода выгрузки или текущий
+func schoolYearSession(book *Gradebook) oneRosterSession {
+	day := book.GeneratedAt
+	if book.From != nil {
+		day = *book.From
+	}
+	year := day.Year()
+	if day.Month() < time.September {
+		year--
+	}
+	return oneRosterSession{
+		id:    fmt.Sprintf("school-year-%d-%d", year, year+1),
+		title: fmt.Sprintf("%d-%d", year, year+1),
+		start: time.Date(year, time.September, 1, 0, 0, 0, 0, day.Location()),
+		end:   time.Date(year+1, time.August, 31, 0, 0, 0, 0, day.Location()),
+	}
+}
+
+func oneRosterUser(user *models.User, grade string) []string {
+	givenName, familyName := user.FirstName, user.LastName
+	if givenName == "" {
+		givenName = user.Username
+	}
+	if familyName == "" {
+		familyName = user.Username
+	}
+	return []string{
+		oneRosterUserID(user.ID), "", "", "true", user.Username, "", givenName, familyName, "",
+		strconv.FormatUint(uint64(user.ID), 10), user.Email, "", "", "", grade, "", "", "", "", "", "",
+		oneRosterOrgID, "",
+	}
+}
+
+func oneRosterRole(userID uint, role string) []string {
+	return []string{
+		fmt.Sprintf("role-%d", userID), "", "", oneRosterUserID(userID), "primary", role, "", "", oneRosterOrgID, "",
+	}
+}
+
+func oneRosterUserID(userID uint) string {
+	return fmt.Sprintf("user-%d", userID)
+}
+
+func oneRosterClassID(class GradebookClass) string {
+	return fmt.Sprintf("class-%d-%s", class.Level, class.LevelLetter)
+}
+
+func oneRosterLineItemID(lessonID uint, class GradebookClass) string {
+	return fmt.Sprintf("lesson-%d-%s", lessonID, oneRosterClassID(class))
+}
+
+// oneRosterGrade параллель в кодах CEDS: "01".."11"
+func oneRosterGrade(level int) string {
+	return fmt.Sprintf("%02d", level)
+}
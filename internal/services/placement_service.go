@@ -0,0 +1,528 @@
+package services
+
+import (
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/repositories"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// На каждом уровне задается до четырех вопросов. Уровень засчитывается
+// после трех верных ответов и не засчитывается после двух ошибок, поэтому
+// часто решение принимается раньше.
+const (
+	placementQuestionsPerLevel = 4
+	placementPassCorrect       = 3
+)
+
+type PlacementService struct {
+	placementRepo       *repositories.PlacementRepository
+	tagRepo             *repositories.TagRepository
+	lessonRepo          *repositories.LessonRepository
+	progressRepo        *repositories.ProgressRepository
+	userRepo            *repositories.UserRepository
+	notificationService *NotificationService
+}
+
+func NewPlacementService(
+	placementRepo *repositories.PlacementRepository,
+	tagRepo *repositories.TagRepository,
+	lessonRepo *repositories.LessonRepository,
+	progressRepo *repositories.ProgressRepository,
+	userRepo *repositories.UserRepository,
+	notificationService *NotificationService,
+) *PlacementService {
+	return &PlacementService{
+		placementRepo:       placementRepo,
+		tagRepo:             tagRepo,
+		lessonRepo:          lessonRepo,
+		progressRepo:        progressRepo,
+		userRepo:            userRepo,
+		notificationService: notificationService,
+	}
+}
+
+// PlacementQuestion текущий вопрос теста без признака правильности
+type PlacementQuestion struct {
+	QuestionID uint             `json:"question_id"`
+	Level      string           `json:"level"`
+	Number     int              `json:"number"`
+	Text       string           `json:"text"`
+	Options    []PracticeOption `json:"options"`
+}
+
+// PlacementLevelResult ответы на одном уровне; Passed пуст, пока решение не принято
+type PlacementLevelResult struct {
+	Level   string `json:"level"`
+	Asked   int    `json:"asked"`
+	Correct int    `json:"correct"`
+	Passed  *bool  `json:"passed"`
+}
+
+// PlacementState состояние теста: следующий вопрос или итог
+type PlacementState struct {
+	Test     *models.PlacementTest  `json:"test"`
+	Level    string                 `json:"level,omitempty"`
+	Levels   []PlacementLevelResult `json:"levels"`
+	Question *PlacementQuestion     `json:"question,omitempty"`
+}
+
+type PlacementAnswerRequest struct {
+	QuestionID     uint `json:"question_id" binding:"required"`
+	AnswerOptionID uint `json:"answer_option_id" binding:"required"`
+}
+
+type PlacementOverrideRequest struct {
+	Level string `json:"level" binding:"required"`
+	Note  string `json:"note"`
+}
+
+// StartPlacement начинает тест или возвращает незавершенный.
+// Пройденный тест повторно не выдается: уровень может изменить учитель.
+func (s *PlacementService) StartPlacement(userID uint) (*PlacementState, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("Пользователь не найден")
+	}
+	if user.Role != models.RoleStudent || user.Level == nil || *user.Level < 5 || *user.Level > 11 {
+		return nil, errors.New("Тест на уровень доступен ученикам 5-11 классов")
+	}
+
+	latest, err := s.placementRepo.FindLatestByUser(userID)
+	if err == nil {
+		if latest.Status == models.PlacementInProgress {
+			return s.buildState(latest, nil)
+		}
+		return nil, errors.New("Тест на уровень уже пройден")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	pools, err := s.levelPools()
+	if err != nil {
+		return nil, err
+	}
+	level := nearestLevelWithQuestions(pools, startPlacementLevel(*user.Level))
+	if level == "" {
+		return nil, errors.New("Нет вопросов с тегами уровней CEFR для теста на уровень")
+	}
+
+	questionID := pickPlacementQuestion(pools[level], nil)
+	test := &models.PlacementTest{
+		UserID:            userID,
+		Status:            models.PlacementInProgress,
+		CurrentLevel:      level,
+		CurrentQuestionID: &questionID,
+	}
+	if err := s.placementRepo.Create(test); err != nil {
+		return nil, err
+	}
+	return s.buildState(test, nil)
+}
+
+// GetMyPlacement последний тест ученика с текущим вопросом
+func (s *PlacementService) GetMyPlacement(userID uint) (*PlacementState, error) {
+	test, err := s.placementRepo.FindLatestByUser(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Тест на уровень не найден")
+		}
+		return nil, err
+	}
+	answers, err := s.placementRepo.FindAnswers(test.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildState(test, answers)
+}
+
+// AnswerPlacement принимает ответ на текущий вопрос и выбирает следующий шаг:
+// вопрос того же уровня, переход на соседний уровень или завершение
+func (s *PlacementService) AnswerPlacement(userID, testID uint, req PlacementAnswerRequest) (*PlacementState, error) {
+	pools, err := s.levelPools()
+	if err != nil {
+		return nil, err
+	}
+
+	var test *models.PlacementTest
+	var answers []models.PlacementAnswer
+	err = s.placementRepo.DB().Transaction(func(tx *gorm.DB) error {
+		placementRepo := s.placementRepo.WithTx(tx)
+
+		var err error
+		test, err = placementRepo.FindByIDForUpdate(testID)
+		if err != nil || test.UserID != userID {
+			return errors.New("Тест на уровень не найден")
+		}
+		if test.Status != models.PlacementInProgress {
+			return errors.New("Тест на уровень уже завершен")
+		}
+		if test.CurrentQuestionID == nil || *test.CurrentQuestionID != req.QuestionID {
+			return errors.New("Неверный вопрос: ответьте на текущий вопрос теста")
+		}
+
+		question, err := s.lessonRepo.FindQuestionByID(req.QuestionID)
+		if err != nil {
+			return errors.New("Вопрос не найден")
+		}
+		correct, valid := false, false
+		for _, option := range question.AnswerOptions {
+			if option.ID == req.AnswerOptionID {
+				valid, correct = true, option.IsCorrect
+			}
+		}
+		if !valid {
+			return errors.New("Неверный вариант ответа")
+		}
+
+		if err := placementRepo.CreateAnswer(&models.PlacementAnswer{
+			PlacementTestID: test.ID,
+			QuestionID:      question.ID,
+			Level:           test.CurrentLevel,
+			AnswerOptionID:  req.AnswerOptionID,
+			IsCorrect:       correct,
+		}); err != nil {
+			return err
+		}
+		answers, err = placementRepo.FindAnswers(test.ID)
+		if err != nil {
+			return err
+		}
+
+		s.advance(test, answers, pools)
+		if err := placementRepo.Update(test); err != nil {
+			return err
+		}
+		if test.Status != models.PlacementCompleted {
+			return nil
+		}
+		return s.unlockLessons(s.progressRepo.WithTx(tx), test.UserID, test.Level())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.buildState(test, answers)
+}
+
+// advance решает, что делать после ответа: продолжить уровень, сменить его или завершить тест
+func (s *PlacementService) advance(test *models.PlacementTest, answers []models.PlacementAnswer, pools map[string][]uint) {
+	asked := make([]uint, len(answers))
+	for i, answer := range answers {
+		asked[i] = answer.QuestionID
+	}
+	results := placementLevelResults(answers)
+
+	level := test.CurrentLevel
+	remaining := pickPlacementQuestion(pools[level], asked)
+	passed := levelDecision(results[level], remaining == 0)
+	if passed == nil {
+		test.CurrentQuestionID = &remaining
+		return
+	}
+
+	// Уровень решен: идем вверх после успеха и вниз после неудачи,
+	// пропуская уровни без вопросов. Уже проверенный уровень не повторяем.
+	step := -1
+	if *passed {
+		step = 1
+	}
+	for index := models.CEFRIndex(level) + step; index >= 0 && index < len(models.CEFRLevels); index += step {
+		next := models.CEFRLevels[index]
+		if _, tested := results[next]; tested {
+			break
+		}
+		if questionID := pickPlacementQuestion(pools[next], asked); questionID != 0 {
+			test.CurrentLevel = next
+			test.CurrentQuestionID = &questionID
+			return
+		}
+	}
+
+	now := time.Now()
+	test.Status = models.PlacementCompleted
+	test.EstimatedLevel = estimatePlacementLevel(results)
+	test.CurrentLevel = ""
+	test.CurrentQuestionID = nil
+	test.CompletedAt = &now
+}
+
+// GetClassPlacements тесты учеников класса для проверки учителем
+func (s *PlacementService) GetClassPlacements(level int, levelLetter string) ([]models.PlacementTest, error) {
+	if level < 1 || level > 11 {
+		return nil, errors.New("Класс должен быть от 1 до 11")
+	}
+	levelLetter = strings.TrimSpace(strings.ToUpper(levelLetter))
+	if len([]rune(levelLetter)) > 1 {
+		return nil, errors.New("Неверный формат буквы класса")
+	}
+	return s.placementRepo.FindByClass(level, levelLetter)
+}
+
+// GetPlacement тест со всеми ответами (для учителя)
+func (s *PlacementService) GetPlacement(testID uint) (*PlacementState, error) {
+	test, err := s.placementRepo.FindByID(testID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Тест на уровень не найден")
+		}
+		return nil, err
+	}
+	state, err := s.buildState(test, test.Answers)
+	if err != nil {
+		return nil, err
+	}
+	// Учителю текущий вопрос не нужен, ответы уже в test.Answers
+	state.Question = nil
+	return state, nil
+}
+
+// OverridePlacement заменяет найденный уровень решением учителя и
+// пересчитывает открытые уроки
+func (s *PlacementService) OverridePlacement(teacherID, testID uint, req PlacementOverrideRequest) (*PlacementState, error) {
+	level := strings.ToUpper(strings.TrimSpace(req.Level))
+	if models.CEFRIndex(level) < 0 {
+		return nil, errors.New("Неверный уровень CEFR: допустимо A1, A2, B1, B2, C1, C2")
+	}
+	note := strings.TrimSpace(req.Note)
+	if len([]rune(note)) > 500 {
+		return nil, errors.New("Неверный комментарий: не более 500 символов")
+	}
+
+	var studentID uint
+	err := s.placementRepo.DB().Transaction(func(tx *gorm.DB) error {
+		placementRepo := s.placementRepo.WithTx(tx)
+
+		test, err := placementRepo.FindByIDForUpdate(testID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("Тест на уровень не найден")
+			}
+			return err
+		}
+		if test.Status != models.PlacementCompleted {
+			return errors.New("Тест на уровень еще не завершен")
+		}
+
+		test.OverrideLevel = level
+		test.OverriddenBy = &teacherID
+		test.OverrideNote = note
+		if err := placementRepo.Update(test); err != nil {
+			return err
+		}
+		studentID = test.UserID
+		return s.unlockLessons(s.progressRepo.WithTx(tx), test.UserID, level)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	body := fmt.Sprintf("Учитель установил ваш уровень: %s. Уроки ниже этого уровня открыты.", level)
+	if note != "" {
+		body += " " + note
+	}
+	s.notificationService.Notify(studentID, models.NotificationPlacementOverridden,
+		"Уровень изменен учителем", body,
+		models.JSONMap{"placement_test_id": testID, "level": level})
+
+	return s.GetPlacement(testID)
+}
+
+// unlockLessons открывает уроки ниже уровня level и первый урок самого уровня,
+// с которого ученик продолжит обучение. Прохождение уроков не засчитывается.
+func (s *PlacementService) unlockLessons(progressRepo *repositories.ProgressRepository, userID uint, level string) error {
+	lessons, err := s.lessonRepo.FindAllWithTags()
+	if err != nil {
+		return err
+	}
+	return progressRepo.SetUnlocked(userID, placementUnlockedLessons(lessons, level))
+}
+
+// placementUnlockedLessons уроки (по Order), открываемые для уровня level
+func placementUnlockedLessons(lessons []models.Lesson, level string) []uint {
+	target := models.CEFRIndex(level)
+	var lessonIDs []uint
+	lessonLevel := 0
+	for _, lesson := range lessons {
+		// Урок без тега уровня относится к уровню предыдущего урока
+		if index := lessonCEFRIndex(lesson); index >= 0 {
+			lessonLevel = index
+		}
+		lessonIDs = append(lessonIDs, lesson.ID)
+		if lessonLevel >= target {
+			break
+		}
+	}
+	return lessonIDs
+}
+
+// levelPools вопросы каждого уровня CEFR
+func (s *PlacementService) levelPools() (map[string][]uint, error) {
+	tags, err := s.tagRepo.FindAll(models.TagKindCEFR)
+	if err != nil {
+		return nil, err
+	}
+	pools := make(map[string][]uint, len(tags))
+	for i := range tags {
+		if models.CEFRIndex(tags[i].Name) < 0 {
+			continue
+		}
+		ids, err := s.tagRepo.FindChoiceQuestionIDsByTag(&tags[i])
+		if err != nil {
+			return nil, err
+		}
+		pools[tags[i].Name] = ids
+	}
+	return pools, nil
+}
+
+func (s *PlacementService) buildState(test *models.PlacementTest, answers []models.PlacementAnswer) (*PlacementState, error) {
+	state := &PlacementState{
+		Test:   test,
+		Level:  test.Level(),
+		Levels: orderedLevelResults(placementLevelResults(answers), test),
+	}
+	if test.Status != models.PlacementInProgress || test.CurrentQuestionID == nil {
+		return state, nil
+	}
+
+	question, err := s.lessonRepo.FindQuestionByID(*test.CurrentQuestionID)
+	if err != nil {
+		return nil, err
+	}
+	options := make([]PracticeOption, len(question.AnswerOptions))
+	for i, option := range question.AnswerOptions {
+		options[i] = PracticeOption{ID: option.ID, Text: option.Text}
+	}
+	state.Question = &PlacementQuestion{
+		QuestionID: question.ID,
+		Level:      test.CurrentLevel,
+		Number:     len(answers) + 1,
+		Text:       question.Text,
+		Options:    options,
+	}
+	return state, nil
+}
+
+// startPlacementLevel стартовый уровень по классу ученика
+func startPlacementLevel(grade int) string {
+	switch {
+	case grade <= 6:
+		return "A1"
+	case grade <= 8:
+		return "A2"
+	default:
+		return "B1"
+	}
+}
+
+// nearestLevelWithQuestions ближайший к level уровень, для которого есть вопросы
+func nearestLevelWithQuestions(pools map[string][]uint, level string) string {
+	start := models.CEFRIndex(level)
+	for distance := 0; distance < len(models.CEFRLevels); distance++ {
+		for _, index := range []int{start + distance, start - distance} {
+			if index >= 0 && index < len(models.CEFRLevels) && len(pools[models.CEFRLevels[index]]) > 0 {
+				return models.CEFRLevels[index]
+			}
+		}
+	}
+	return ""
+}
+
+// pickPlacementQuestion случайный еще не заданный вопрос; 0 - вопросы кончились
+func pickPlacementQuestion(pool []uint, asked []uint) uint {
+	used := make(map[uint]bool, len(asked))
+	for _, id := range asked {
+		used[id] = true
+	}
+	var candidates []uint
+	for _, id := range pool {
+		if !used[id] {
+			candidates = append(candidates, id)
+		}
+	}
+	if len(candidates) == 0 {
+		return 0
+	}
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return candidates[rng.Intn(len(candidates))]
+}
+
+func placementLevelResults(answers []models.PlacementAnswer) map[string]*PlacementLevelResult {
+	results := make(map[string]*PlacementLevelResult)
+	for _, answer := range answers {
+		result, ok := results[answer.Level]
+		if !ok {
+			result = &PlacementLevelResult{Level: answer.Level}
+			results[answer.Level] = result
+		}
+		result.Asked++
+		if answer.IsCorrect {
+			result.Correct++
+		}
+	}
+	return results
+}
+
+// levelDecision засчитан ли уровень; nil - нужны еще вопросы. Если вопросы
+// уровня закончились раньше, решает доля верных ответов.
+func levelDecision(result *PlacementLevelResult, exhausted bool) *bool {
+	passed := result.Correct >= placementPassCorrect
+	failed := result.Asked-result.Correct > placementQuestionsPerLevel-placementPassCorrect
+	if !passed && !failed && exhausted {
+		passed = result.Correct*placementQuestionsPerLevel >= result.Asked*placementPassCorrect
+		failed = !passed
+	}
+	if !passed && !failed {
+		return nil
+	}
+	return &passed
+}
+
+// orderedLevelResults результаты по уровням в порядке CEFR с принятыми решениями
+func orderedLevelResults(results map[string]*PlacementLevelResult, test *models.PlacementTest) []PlacementLevelResult {
+	ordered := make([]PlacementLevelResult, 0, len(results))
+	for _, level := range models.CEFRLevels {
+		result, ok := results[level]
+		if !ok {
+			continue
+		}
+		inProgress := test.Status == models.PlacementInProgress && level == test.CurrentLevel
+		result.Passed = levelDecision(result, !inProgress)
+		ordered = append(ordered, *result)
+	}
+	return ordered
+}
+
+// estimatePlacementLevel уровень, с которого ученику стоит продолжить:
+// следующий за наивысшим засчитанным. К завершению теста решение принято
+// по каждому проверенному уровню.
+func estimatePlacementLevel(results map[string]*PlacementLevelResult) string {
+	highestPassed := -1
+	for level, result := range results {
+		if passed := levelDecision(result, true); passed != nil && *passed {
+			highestPassed = max(highestPassed, models.CEFRIndex(level))
+		}
+	}
+	return models.CEFRLevels[min(highestPassed+1, len(models.CEFRLevels)-1)]
+}
+
+// lessonCEFRIndex наименьший уровень CEFR среди тегов урока; -1 без тега
+func lessonCEFRIndex(lesson models.Lesson) int {
+	index := -1
+	for _, tag := range lesson.Tags {
+		if tag.Kind != models.TagKindCEFR {
+			continue
+		}
+		if i := models.CEFRIndex(tag.Name); i >= 0 && (index < 0 || i < index) {
+			index = i
+		}
+	}
+	return index
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"englishlessons.back/internal/models"
+)
+
+func TestLevelDecision(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		name      string
+		asked     int
+		correct   int
+		exhausted bool
+		want      *bool
+	}{
+		{"first correct answer", 1, 1, false, nil},
+		{"one mistake", 2, 1, false, nil},
+		{"three correct stop early", 3, 3, false, &yes},
+		{"two mistakes stop early", 2, 0, false, &no},
+		{"two of three", 3, 2, false, nil},
+		{"three of four", 4, 3, false, &yes},
+		{"two of four", 4, 2, false, &no},
+		// Вопросы уровня кончились: решает доля верных (не меньше 3 из 4)
+		{"exhausted one of one", 1, 1, true, &yes},
+		{"exhausted two of two", 2, 2, true, &yes},
+		{"exhausted one of two", 2, 1, true, &no},
+		{"exhausted two of three", 3, 2, true, &no},
+	}
+	for _, tt := range tests {
+		got := levelDecision(&PlacementLevelResult{Asked: tt.asked, Correct: tt.correct}, tt.exhausted)
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("%s: levelDecision(%d/%d, %v) = %v, want %v",
+				tt.name, tt.correct, tt.asked, tt.exhausted, boolString(got), boolString(tt.want))
+		}
+	}
+}
+
+func boolString(value *bool) string {
+	if value == nil {
+		return "nil"
+	}
+	if *value {
+		return "passed"
+	}
+	return "failed"
+}
+
+// placementRun проигрывает ответы через advance так же, как AnswerPlacement
+type placementRun struct {
+	t       *testing.T
+	service *PlacementService
+	pools   map[string][]uint
+	test    *models.PlacementTest
+	answers []models.PlacementAnswer
+}
+
+func newPlacementRun(t *testing.T, pools map[string][]uint, level string) *placementRun {
+	question := pickPlacementQuestion(pools[level], nil)
+	return &placementRun{
+		t:       t,
+		service: &PlacementService{},
+		pools:   pools,
+		test: &models.PlacementTest{
+			Status:            models.PlacementInProgress,
+			CurrentLevel:      level,
+			CurrentQuestionID: &question,
+		},
+	}
+}
+
+func (r *placementRun) answer(correct bool) {
+	r.t.Helper()
+	if r.test.Status != models.PlacementInProgress {
+		r.t.Fatalf("answer after the test is completed")
+	}
+	r.answers = append(r.answers, models.PlacementAnswer{
+		QuestionID: *r.test.CurrentQuestionID,
+		Level:      r.test.CurrentLevel,
+		IsCorrect:  correct,
+	})
+	r.service.advance(r.test, r.answers, r.pools)
+}
+
+func placementPools() map[string][]uint {
+	return map[string][]uint{
+		"A1": {1, 2, 3, 4, 5},
+		"A2": {11, 12, 13, 14, 15},
+		"B1": {21, 22, 23, 24, 25},
+		"B2": {31, 32},
+	}
+}
+
+func TestPlacementStopsLevelEarly(t *testing.T) {
+	run := newPlacementRun(t, placementPools(), "A2")
+
+	// Три верных ответа подряд: четвертый вопрос A2 не задается
+	for i := 0; i < 3; i++ {
+		if run.test.CurrentLevel != "A2" {
+			t.Fatalf("answer %d: level = %s, want A2", i+1, run.test.CurrentLevel)
+		}
+		run.answer(true)
+	}
+	if run.test.CurrentLevel != "B1" {
+		t.Fatalf("after passing A2: level = %s, want B1", run.test.CurrentLevel)
+	}
+	if id := *run.test.CurrentQuestionID; id < 21 || id > 25 {
+		t.Fatalf("question %d is not from B1", id)
+	}
+
+	// Две ошибки: B1 не засчитан; A2 уже проверен, поэтому тест завершается
+	run.answer(false)
+	if run.test.Status != models.PlacementInProgress {
+		t.Fatal("one mistake must not finish the level")
+	}
+	run.answer(false)
+	if run.test.Status != models.PlacementCompleted {
+		t.Fatalf("status = %s, want completed", run.test.Status)
+	}
+	if run.test.EstimatedLevel != "B1" {
+		t.Errorf("estimated level = %s, want B1", run.test.EstimatedLevel)
+	}
+	if len(run.answers) != 5 {
+		t.Errorf("asked %d questions, want 5", len(run.answers))
+	}
+	if run.test.CurrentQuestionID != nil || run.test.CurrentLevel != "" || run.test.CompletedAt == nil {
+		t.Errorf("completed test keeps current question: %+v", run.test)
+	}
+}
+
+func TestPlacementGoesDownAfterFailure(t *testing.T) {
+	run := newPlacementRun(t, placementPools(), "A2")
+	run.answer(false)
+	run.answer(false)
+	if run.test.CurrentLevel != "A1" {
+		t.Fatalf("after failing A2: level = %s, want A1", run.test.CurrentLevel)
+	}
+	run.answer(true)
+	run.answer(false)
+	run.answer(true)
+	run.answer(true)
+	if run.test.Status != models.PlacementCompleted || run.test.EstimatedLevel != "A2" {
+		t.Fatalf("status = %s, level = %s; want completed at A2", run.test.Status, run.test.EstimatedLevel)
+	}
+}
+
+func TestPlacementSkipsLevelsWithoutQuestions(t *testing.T) {
+	pools := placementPools()
+	delete(pools, "B1")
+	run := newPlacementRun(t, pools, "A2")
+	for i := 0; i < 3; i++ {
+		run.answer(true)
+	}
+	if run.test.CurrentLevel != "B2" {
+		t.Fatalf("level = %s, want B2 after skipping B1", run.test.CurrentLevel)
+	}
+
+	// В B2 только два вопроса: решение по доле верных ответов
+	run.answer(true)
+	run.answer(true)
+	if run.test.Status != models.PlacementCompleted || run.test.EstimatedLevel != "C1" {
+		t.Fatalf("status = %s, level = %s; want completed at C1", run.test.Status, run.test.EstimatedLevel)
+	}
+}
+
+func TestEstimatePlacementLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		results map[string]*PlacementLevelResult
+		want    string
+	}{
+		{"nothing passed", map[string]*PlacementLevelResult{"A1": {Asked: 2, Correct: 0}}, "A1"},
+		{"A1 passed", map[string]*PlacementLevelResult{
+			"A1": {Asked: 3, Correct: 3}, "A2": {Asked: 2, Correct: 0}}, "A2"},
+		{"gap uses the highest passed", map[string]*PlacementLevelResult{
+			"A1": {Asked: 2, Correct: 0}, "B1": {Asked: 3, Correct: 3}}, "B2"},
+		{"top level passed", map[string]*PlacementLevelResult{"C2": {Asked: 3, Correct: 3}}, "C2"},
+	}
+	for _, tt := range tests {
+		if got := estimatePlacementLevel(tt.results); got != tt.want {
+			t.Errorf("%s: estimatePlacementLevel = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPlacementUnlockedLessons(t *testing.T) {
+	cefr := func(level string) []models.Tag {
+		return []models.Tag{{Kind: models.TagKindCEFR, Name: level}}
+	}
+	lessons := []models.Lesson{
+		{ID: 1, Tags: cefr("A1")},
+		{ID: 2},
+		{ID: 3, Tags: append(cefr("B1"), models.Tag{Kind: models.TagKindCEFR, Name: "A2"})},
+		{ID: 4, Tags: []models.Tag{{Kind: models.TagKindTopic, Name: "A2"}}},
+		{ID: 5, Tags: cefr("B1")},
+		{ID: 6, Tags: cefr("B2")},
+	}
+
+	tests := []struct {
+		level string
+		want  []uint
+	}{
+		// Первый урок уровня открывается, следующие — нет
+		{"A1", []uint{1}},
+		// Урок без тега уровня относится к уровню предыдущего; у урока
+		// с несколькими уровнями берется наименьший
+		{"A2", []uint{1, 2, 3}},
+		{"B1", []uint{1, 2, 3, 4, 5}},
+		{"B2", []uint{1, 2, 3, 4, 5, 6}},
+		// Выше последнего уровня курса открывается все
+		{"C2", []uint{1, 2, 3, 4, 5, 6}},
+	}
+	for _, tt := range tests {
+		if got := placementUnlockedLessons(lessons, tt.level); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("placementUnlockedLessons(%s) = %v, want %v", tt.level, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/repositories"
+	"errors"
+	"strings"
+)
+
+const (
+	maxNicknameLength = 30
+	anonymousName     = "Аноним"
+)
+
+type PrivacyService struct {
+	privacyRepo *repositories.PrivacyRepository
+	userRepo    *repositories.UserRepository
+}
+
+func NewPrivacyService(privacyRepo *repositories.PrivacyRepository, userRepo *repositories.UserRepository) *PrivacyService {
+	return &PrivacyService{
+		privacyRepo: privacyRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// UserPrivacyRequest настройки ученика
+type UserPrivacyRequest struct {
+	Nickname        *string `json:"nickname"`
+	LeaderboardMode *string `json:"leaderboard_mode"`
+}
+
+// ClassPrivacyRequest настройки класса; пустая буква - для всей параллели
+type ClassPrivacyRequest struct {
+	Level          int    `json:"level" binding:"required"`
+	LevelLetter    string `json:"level_letter"`
+	NameMode       string `json:"name_mode" binding:"required"`
+	HideBottomHalf bool   `json:"hide_bottom_half"`
+}
+
+// LeaderboardIdentity то, что видно о ученике в рейтинге
+type LeaderboardIdentity struct {
+	FullName string
+	Username string
+	Avatar   string
+}
+
+// LeaderboardPolicy правила отображения рейтингов для конкретного зрителя.
+// Учитель и сам ученик всегда видят настоящие имена.
+type LeaderboardPolicy struct {
+	viewerID      uint
+	isTeacher     bool
+	classSettings map[string]*models.ClassPrivacySettings
+}
+
+// PolicyFor собирает правила для зрителя
+func (s *PrivacyService) PolicyFor(viewerID uint, role string) (*LeaderboardPolicy, error) {
+	policy := &LeaderboardPolicy{
+		viewerID:      viewerID,
+		isTeacher:     role == string(models.RoleTeacher),
+		classSettings: make(map[string]*models.ClassPrivacySettings),
+	}
+	if policy.isTeacher {
+		return policy, nil
+	}
+
+	settings, err := s.privacyRepo.FindAllClassSettings()
+	if err != nil {
+		return nil, err
+	}
+	for i := range settings {
+		policy.classSettings[classKey(settings[i].Level, settings[i].LevelLetter)] = &settings[i]
+	}
+	return policy, nil
+}
+
+// ExcludesHidden скрывать ли из рейтинга учеников, отказавшихся от участия
+func (p *LeaderboardPolicy) ExcludesHidden() bool {
+	return !p.isTeacher
+}
+
+// settingsFor настройки класса ученика: сначала для класса с буквой, затем для параллели
+func (p *LeaderboardPolicy) settingsFor(user *models.User) *models.ClassPrivacySettings {
+	if user.Level == nil {
+		return nil
+	}
+	if settings, ok := p.classSettings[classKey(*user.Level, user.LevelLetter)]; ok {
+		return settings
+	}
+	return p.classSettings[classKey(*user.Level, "")]
+}
+
+// NameMode итоговый режим: более закрытый из настроек ученика и класса
+func (p *LeaderboardPolicy) NameMode(user *models.User) models.LeaderboardNameMode {
+	if p.isTeacher || user.ID == p.viewerID {
+		return models.NameModeFull
+	}
+	mode := user.LeaderboardMode
+	if settings := p.settingsFor(user); settings != nil {
+		mode = models.StricterNameMode(mode, settings.NameMode)
+	}
+	return mode
+}
+
+// Identity имя, логин и аватар ученика, которые можно показать зрителю
+func (p *LeaderboardPolicy) Identity(user *models.User) LeaderboardIdentity {
+	switch p.NameMode(user) {
+	case models.NameModeNickname:
+		name := user.Nickname
+		if name == "" {
+			name = anonymousName
+		}
+		return LeaderboardIdentity{FullName: name}
+	case models.NameModeAvatar, models.NameModeHidden:
+		return LeaderboardIdentity{FullName: anonymousName, Avatar: user.Avatar}
+	default:
+		return LeaderboardIdentity{
+			FullName: user.GetFullName(),
+			Username: user.Username,
+			Avatar:   user.Avatar,
+		}
+	}
+}
+
+// HidesPosition скрывать ли строку из нижней половины рейтинга (position из total)
+func (p *LeaderboardPolicy) HidesPosition(user *models.User, position, total int) bool {
+	if p.isTeacher || user.ID == p.viewerID {
+		return false
+	}
+	settings := p.settingsFor(user)
+	if settings == nil || !settings.HideBottomHalf {
+		return false
+	}
+	return position > (total+1)/2
+}
+
+// BottomHalfHiding правило HidesPosition в виде условия для запроса рейтинга:
+// скрытые строки убираются до пагинации. Для учителя - nil.
+func (p *LeaderboardPolicy) BottomHalfHiding() *repositories.LeaderboardHiding {
+	if p.isTeacher {
+		return nil
+	}
+	hiding := &repositories.LeaderboardHiding{ViewerID: p.viewerID}
+	for _, settings := range p.classSettings {
+		class := repositories.LeaderboardClass{Level: settings.Level, LevelLetter: settings.LevelLetter}
+		switch {
+		case settings.LevelLetter == "":
+			if settings.HideBottomHalf {
+				hiding.Levels = append(hiding.Levels, settings.Level)
+			}
+		case settings.HideBottomHalf:
+			hiding.Classes = append(hiding.Classes, class)
+			hiding.OwnSettings = append(hiding.OwnSettings, class)
+		default:
+			hiding.OwnSettings = append(hiding.OwnSettings, class)
+		}
+	}
+	return hiding
+}
+
+// GetUserPrivacy текущие настройки ученика
+func (s *PrivacyService) GetUserPrivacy(userID uint) (*models.User, error) {
+	return s.userRepo.FindByID(userID)
+}
+
+// UpdateUserPrivacy изменяет псевдоним и режим отображения ученика
+func (s *PrivacyService) UpdateUserPrivacy(userID uint, req UserPrivacyRequest) (*models.User, error) {
+	updates := make(map[string]interface{})
+
+	if req.Nickname != nil {
+		nickname := strings.TrimSpace(*req.Nickname)
+		if len([]rune(nickname)) > maxNicknameLength {
+			return nil, errors.New("Неверный псевдоним: не более 30 символов")
+		}
+		updates["nickname"] = nickname
+	}
+
+	if req.LeaderboardMode != nil {
+		mode := models.LeaderboardNameMode(*req.LeaderboardMode)
+		if !mode.IsValid(true) {
+			return nil, errors.New("Неверный режим: допустимо full, nickname, avatar, hidden")
+		}
+		updates["leaderboard_mode"] = mode
+	}
+
+	if len(updates) == 0 {
+		return nil, errors.New("Необходимо указать nickname или leaderboard_mode")
+	}
+
+	if err := s.userRepo.UpdateProfile(userID, updates); err != nil {
+		return nil, err
+	}
+	return s.userRepo.FindByID(userID)
+}
+
+func (s *PrivacyService) GetClassSettings() ([]models.ClassPrivacySettings, error) {
+	return s.privacyRepo.FindAllClassSettings()
+}
+
+// SaveClassSettings сохраняет настройки класса (учитель)
+func (s *PrivacyService) SaveClassSettings(teacherID uint, req ClassPrivacyRequest) (*models.ClassPrivacySettings, error) {
+	if req.Level < 1 || req.Level > 11 {
+		return nil, errors.New("Неверный класс: допустимо от 1 до 11")
+	}
+	levelLetter := strings.TrimSpace(strings.ToUpper(req.LevelLetter))
+	if len([]rune(levelLetter)) > 1 {
+		return nil, errors.New("Неверный формат буквы класса")
+	}
+	mode := models.LeaderboardNameMode(req.NameMode)
+	if !mode.IsValid(false) {
+		return nil, errors.New("Неверный режим: допустимо full, nickname, avatar")
+	}
+
+	settings := &models.ClassPrivacySettings{
+		Level:          req.Level,
+		LevelLetter:    levelLetter,
+		NameMode:       mode,
+		HideBottomHalf: req.HideBottomHalf,
+		UpdatedBy:      teacherID,
+	}
+	if err := s.privacyRepo.SaveClassSettings(settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
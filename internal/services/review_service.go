@@ -0,0 +1,371 @@
+package services
+
+import (
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/repositories"
+	"errors"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultReviewBatch = 20
+	maxReviewBatch     = 100
+)
+
+// Оценки качества ответа по шкале SM-2
+const (
+	reviewQualityWrong = 1
+	reviewQualityHard  = 3
+	reviewQualityGood  = 4
+	reviewQualityEasy  = 5
+)
+
+type ReviewService struct {
+	reviewRepo   *repositories.ReviewRepository
+	lessonRepo   *repositories.LessonRepository
+	gameItemRepo *repositories.GameItemRepository
+	location     *time.Location
+}
+
+func NewReviewService(
+	reviewRepo *repositories.ReviewRepository,
+	lessonRepo *repositories.LessonRepository,
+	gameItemRepo *repositories.GameItemRepository,
+	location *time.Location,
+) *ReviewService {
+	if location == nil {
+		location = time.UTC
+	}
+	return &ReviewService{
+		reviewRepo:   reviewRepo,
+		lessonRepo:   lessonRepo,
+		gameItemRepo: gameItemRepo,
+		location:     location,
+	}
+}
+
+// SubmitReviewRequest ответ на элемент повторения. Формат answer зависит от источника:
+// вопрос теста - {"answer_option_id": 5}; Memory Cards - {"text": "перевод"};
+// остальные игры - ответ на одно задание в формате сессии игры.
+// Difficulty уточняет верный ответ: hard, good (по умолчанию) или easy.
+type SubmitReviewRequest struct {
+	Answer     models.JSONMap `json:"answer" binding:"required"`
+	Difficulty string         `json:"difficulty"`
+}
+
+// ReviewResult итог повторения: верно ли, правильный ответ и новое расписание
+type ReviewResult struct {
+	Correct bool               `json:"correct"`
+	Quality int                `json:"quality"`
+	Answer  models.JSONMap     `json:"answer"`
+	Item    *models.ReviewItem `json:"item"`
+}
+
+// HandleEvent добавляет в очередь повторения ошибки из тестов и игр
+func (s *ReviewService) HandleEvent(event Event, outcome *EventOutcome) error {
+	switch event.Type {
+	case models.EventTestSubmitted:
+		for _, questionID := range event.MissedQuestionIDs {
+			if err := s.recordMiss(event.UserID, models.ReviewSourceQuestion, questionID); err != nil {
+				return err
+			}
+		}
+	case models.EventGameFinished:
+		for _, itemID := range event.MissedGameItemIDs {
+			if err := s.recordMiss(event.UserID, models.ReviewSourceGameItem, itemID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// recordMiss новая ошибка ставит элемент на повторение сегодня; повторная
+// ошибка сбрасывает интервал, как неверный ответ в SM-2
+func (s *ReviewService) recordMiss(userID uint, sourceType models.ReviewSource, sourceID uint) error {
+	item, err := s.reviewRepo.FindBySource(userID, sourceType, sourceID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		item = &models.ReviewItem{
+			UserID:     userID,
+			SourceType: sourceType,
+			SourceID:   sourceID,
+			EaseFactor: models.DefaultEaseFactor,
+		}
+	} else {
+		scheduleReview(item, reviewQualityWrong)
+	}
+	item.DueDay = s.today()
+
+	if err := s.preparePrompt(item); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Вопрос или задание удалены, повторять нечего
+			return nil
+		}
+		return err
+	}
+	return s.reviewRepo.Save(item)
+}
+
+// GetDue элементы, которые пора повторить сегодня
+func (s *ReviewService) GetDue(userID uint, limit int) ([]models.ReviewItem, int64, error) {
+	if limit <= 0 {
+		limit = defaultReviewBatch
+	}
+	if limit > maxReviewBatch {
+		limit = maxReviewBatch
+	}
+
+	today := s.today()
+	items, err := s.reviewRepo.FindDue(userID, today, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.reviewRepo.CountDue(userID, today)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// CountDue сколько элементов ждет повторения сегодня (для статистики ученика)
+func (s *ReviewService) CountDue(userID uint) (int64, error) {
+	return s.reviewRepo.CountDue(userID, s.today())
+}
+
+// SubmitReview проверяет ответ и переносит следующее повторение по SM-2
+func (s *ReviewService) SubmitReview(userID, itemID uint, req SubmitReviewRequest) (*ReviewResult, error) {
+	item, err := s.reviewRepo.FindByID(itemID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Элемент повторения не найден")
+		}
+		return nil, err
+	}
+	if item.UserID != userID {
+		return nil, errors.New("Элемент повторения не найден")
+	}
+	today := s.today()
+	if item.DueDay > today {
+		return nil, errors.New("Время повторения еще не наступило")
+	}
+
+	correct, err := s.checkAnswer(item, req.Answer)
+	if err != nil {
+		return nil, err
+	}
+
+	quality := reviewQualityWrong
+	if correct {
+		switch req.Difficulty {
+		case "hard":
+			quality = reviewQualityHard
+		case "easy":
+			quality = reviewQualityEasy
+		case "", "good":
+			quality = reviewQualityGood
+		default:
+			return nil, errors.New("Неверная сложность: допустимо hard, good, easy")
+		}
+	}
+
+	answer := item.AnswerKey
+	scheduleReview(item, quality)
+	item.DueDay = addDays(today, item.IntervalDays)
+	now := time.Now()
+	item.LastReviewedAt = &now
+
+	// Следующее повторение показывается в новом порядке
+	if err := s.preparePrompt(item); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err := s.reviewRepo.Save(item); err != nil {
+		return nil, err
+	}
+
+	return &ReviewResult{Correct: correct, Quality: quality, Answer: answer, Item: item}, nil
+}
+
+// scheduleReview пересчитывает интервал и легкость по SM-2. При ответе хуже
+// 3 серия повторений начинается заново; интервал 0 означает "сегодня".
+func scheduleReview(item *models.ReviewItem, quality int) {
+	if quality < reviewQualityHard {
+		item.Repetitions = 0
+		item.IntervalDays = 0
+		item.Lapses++
+	} else {
+		switch item.Repetitions {
+		case 0:
+			item.IntervalDays = 1
+		case 1:
+			item.IntervalDays = 6
+		default:
+			item.IntervalDays = int(math.Round(float64(item.IntervalDays) * item.EaseFactor))
+		}
+		item.Repetitions++
+	}
+
+	miss := float64(reviewQualityEasy - quality)
+	item.EaseFactor += 0.1 - miss*(0.08+miss*0.02)
+	if item.EaseFactor < models.MinEaseFactor {
+		item.EaseFactor = models.MinEaseFactor
+	}
+}
+
+// preparePrompt собирает то, что увидит ученик, и ключ ответа
+func (s *ReviewService) preparePrompt(item *models.ReviewItem) error {
+	switch item.SourceType {
+	case models.ReviewSourceQuestion:
+		question, err := s.lessonRepo.FindQuestionByID(item.SourceID)
+		if err != nil {
+			return err
+		}
+
+		// Вопрос с вводом ответа: варианты не показываем, ключ - допустимые написания
+		if question.Type == models.QuestionTyped {
+			item.Prompt = models.JSONMap{
+				"lesson_id": question.LessonID,
+				"text":      question.Text,
+				"type":      question.Type,
+			}
+			if question.Audio != nil {
+				item.Prompt["audio_url"] = question.Audio.URL
+			}
+			item.AnswerKey = models.JSONMap{"accepted": question.AcceptedAnswers()}
+			return nil
+		}
+
+		options := make([]map[string]interface{}, len(question.AnswerOptions))
+		var correctIDs []uint
+		for i, option := range question.AnswerOptions {
+			options[i] = map[string]interface{}{"id": option.ID, "text": option.Text}
+			if option.IsCorrect {
+				correctIDs = append(correctIDs, option.ID)
+			}
+		}
+		item.Prompt = models.JSONMap{
+			"lesson_id": question.LessonID,
+			"text":      question.Text,
+			"options":   options,
+		}
+		if question.Audio != nil {
+			item.Prompt["audio_url"] = question.Audio.URL
+		}
+		item.AnswerKey = models.JSONMap{"correct_option_ids": correctIDs}
+		return nil
+
+	case models.ReviewSourceGameItem:
+		gameItem, err := s.gameItemRepo.FindByID(item.SourceID)
+		if err != nil {
+			return err
+		}
+		item.GameType = gameItem.GameType
+
+		// Пару Memory Cards повторяем как карточку: слово - перевод
+		if gameItem.GameType == models.GameMemoryCards {
+			var pair models.MemoryCardItem
+			if err := gameItem.DecodePayload(&pair); err != nil {
+				return err
+			}
+			item.Prompt = models.JSONMap{"english": pair.English}
+			item.AnswerKey = models.JSONMap{"russian": pair.Russian}
+			return nil
+		}
+
+		grader, ok := gameGraders[gameItem.GameType]
+		if !ok {
+			return newGameError(GameErrorUnknownGame, "game_type", "Неизвестная игра")
+		}
+		rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+		round, key, err := grader.prepare([]models.GameItem{*gameItem}, rng)
+		if err != nil {
+			return err
+		}
+		var parsed struct {
+			Items []map[string]interface{} `json:"items"`
+		}
+		if err := models.DecodeJSONMap(round, &parsed); err != nil || len(parsed.Items) != 1 {
+			return errors.New("Не удалось подготовить задание")
+		}
+		item.Prompt = parsed.Items[0]
+		item.AnswerKey = key
+		return nil
+	}
+	return errors.New("Неизвестный источник повторения")
+}
+
+func (s *ReviewService) checkAnswer(item *models.ReviewItem, answer models.JSONMap) (bool, error) {
+	switch item.SourceType {
+	case models.ReviewSourceQuestion:
+		var key struct {
+			CorrectOptionIDs []uint   `json:"correct_option_ids"`
+			Accepted         []string `json:"accepted"`
+		}
+		if err := models.DecodeJSONMap(item.AnswerKey, &key); err != nil {
+			return false, err
+		}
+		if len(key.Accepted) > 0 {
+			text, ok := answer["text"].(string)
+			if !ok {
+				return false, newGameError(GameErrorInvalidAnswers, "answer.text", "Неверный формат ответа")
+			}
+			return gradeSpelling(text, key.Accepted).Correct, nil
+		}
+		optionID, ok := intAnswer(answer, "answer_option_id")
+		if !ok {
+			return false, newGameError(GameErrorInvalidAnswers, "answer.answer_option_id", "Неверный формат ответа")
+		}
+		for _, id := range key.CorrectOptionIDs {
+			if int(id) == optionID {
+				return true, nil
+			}
+		}
+		return false, nil
+
+	case models.ReviewSourceGameItem:
+		if item.GameType == models.GameMemoryCards {
+			text, ok := answer["text"].(string)
+			if !ok {
+				return false, newGameError(GameErrorInvalidAnswers, "answer.text", "Неверный формат ответа")
+			}
+			russian, _ := item.AnswerKey["russian"].(string)
+			return normalizeAnswerText(text) == normalizeAnswerText(russian), nil
+		}
+
+		grader, ok := gameGraders[item.GameType]
+		if !ok {
+			return false, newGameError(GameErrorUnknownGame, "game_type", "Неизвестная игра")
+		}
+		grade, err := grader.grade(item.AnswerKey, models.JSONMap{"answers": []interface{}{map[string]interface{}(answer)}})
+		if err != nil {
+			return false, err
+		}
+		return grade.Correct == 1, nil
+	}
+	return false, errors.New("Неизвестный источник повторения")
+}
+
+func (s *ReviewService) today() string {
+	return time.Now().In(s.location).Format(dayLayout)
+}
+
+// normalizeAnswerText сравнение ответов без учета регистра и лишних пробелов
+func normalizeAnswerText(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// addDays сдвигает день формата YYYY-MM-DD на days дней
+func addDays(day string, days int) string {
+	t, err := time.Parse(dayLayout, day)
+	if err != nil {
+		return day
+	}
+	return t.AddDate(0, 0, days).Format(dayLayout)
+}
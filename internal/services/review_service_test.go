@@ -0,0 +1,73 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"englishlessons.back/internal/models"
+)
+
+func TestScheduleReview(t *testing.T) {
+	item := &models.ReviewItem{EaseFactor: 2.5}
+
+	steps := []struct {
+		quality      int
+		wantInterval int
+		wantReps     int
+		wantLapses   int
+		wantEase     float64
+	}{
+		{reviewQualityGood, 1, 1, 0, 2.5},
+		{reviewQualityGood, 6, 2, 0, 2.5},
+		{reviewQualityGood, 15, 3, 0, 2.5},
+		// Интервал считается по легкости до ответа: 15 * 2.5 = 37.5
+		{reviewQualityEasy, 38, 4, 0, 2.6},
+		// Ошибка начинает серию заново и снижает легкость
+		{reviewQualityWrong, 0, 0, 1, 2.06},
+		{reviewQualityHard, 1, 1, 1, 1.92},
+	}
+	for i, step := range steps {
+		scheduleReview(item, step.quality)
+		if item.IntervalDays != step.wantInterval || item.Repetitions != step.wantReps || item.Lapses != step.wantLapses {
+			t.Fatalf("step %d (quality %d): interval %d, repetitions %d, lapses %d; want %d, %d, %d", i+1, step.quality,
+				item.IntervalDays, item.Repetitions, item.Lapses, step.wantInterval, step.wantReps, step.wantLapses)
+		}
+		if math.Abs(item.EaseFactor-step.wantEase) > 1e-9 {
+			t.Fatalf("step %d (quality %d): ease %.4f, want %.4f", i+1, step.quality, item.EaseFactor, step.wantEase)
+		}
+	}
+}
+
+func TestScheduleReviewKeepsMinEase(t *testing.T) {
+	item := &models.ReviewItem{EaseFactor: models.MinEaseFactor + 0.1, Repetitions: 3, IntervalDays: 10}
+	scheduleReview(item, reviewQualityWrong)
+	if item.EaseFactor != models.MinEaseFactor {
+		t.Errorf("ease = %.2f, want %.2f", item.EaseFactor, models.MinEaseFactor)
+	}
+
+	// На минимальной легкости интервал все равно растет
+	scheduleReview(item, reviewQualityGood)
+	scheduleReview(item, reviewQualityGood)
+	scheduleReview(item, reviewQualityGood)
+	if want := int(math.Round(6 * models.MinEaseFactor)); item.IntervalDays != want {
+		t.Errorf("interval = %d, want %d", item.IntervalDays, want)
+	}
+}
+
+func TestAddDays(t *testing.T) {
+	tests := []struct {
+		day  string
+		days int
+		want string
+	}{
+		{"2026-03-10", 0, "2026-03-10"},
+		{"2026-02-27", 2, "2026-03-01"},
+		{"2026-12-31", 6, "2027-01-06"},
+		{"bad", 1, "bad"},
+	}
+	for _, tt := range tests {
+		if got := addDays(tt.day, tt.days); got != tt.want {
+			t.Errorf("addDays(%q, %d) = %q, want %q", tt.day, tt.days, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/repositories"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// seasonCloseInterval как часто фоновая задача ищет завершившиеся сезоны
+const seasonCloseInterval = 5 * time.Minute
+
+type SeasonService struct {
+	seasonRepo         *repositories.SeasonRepository
+	leaderboardService *LeaderboardService
+	location           *time.Location
+	startOnce          sync.Once
+}
+
+func NewSeasonService(
+	seasonRepo *repositories.SeasonRepository,
+	leaderboardService *LeaderboardService,
+	location *time.Location,
+) *SeasonService {
+	if location == nil {
+		location = time.UTC
+	}
+	return &SeasonService{
+		seasonRepo:         seasonRepo,
+		leaderboardService: leaderboardService,
+		location:           location,
+	}
+}
+
+// CreateSeasonRequest даты включительно, в формате YYYY-MM-DD по часовому поясу школы
+type CreateSeasonRequest struct {
+	Name     string `json:"name" binding:"required"`
+	StartsOn string `json:"starts_on" binding:"required"`
+	EndsOn   string `json:"ends_on" binding:"required"`
+}
+
+// SeasonHistoryEntry место ученика в одном из прошедших сезонов
+type SeasonHistoryEntry struct {
+	SeasonID     uint      `json:"season_id"`
+	SeasonName   string    `json:"season_name"`
+	StartsAt     time.Time `json:"starts_at"`
+	EndsAt       time.Time `json:"ends_at"`
+	Rank         int       `json:"rank"`
+	ClassRank    int       `json:"class_rank"`
+	Participants int       `json:"participants"`
+	ClassDisplay string    `json:"class_display"`
+	TotalPoints  int       `json:"total_points"`
+	GamePoints   int       `json:"game_points"`
+	TotalXP      int       `json:"total_xp"`
+}
+
+func (s *SeasonService) CreateSeason(teacherID uint, req CreateSeasonRequest) (*models.Season, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("Необходимо указать название сезона")
+	}
+
+	startsAt, err := time.ParseInLocation(dayLayout, req.StartsOn, s.location)
+	if err != nil {
+		return nil, errors.New("Неверная дата начала сезона")
+	}
+	endsOn, err := time.ParseInLocation(dayLayout, req.EndsOn, s.location)
+	if err != nil {
+		return nil, errors.New("Неверная дата окончания сезона")
+	}
+	endsAt := endsOn.AddDate(0, 0, 1)
+	if !endsAt.After(startsAt) {
+		return nil, errors.New("Неверные даты: сезон должен заканчиваться не раньше начала")
+	}
+
+	season := &models.Season{
+		Name:      name,
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		CreatedBy: teacherID,
+	}
+	if err := s.seasonRepo.Create(season); err != nil {
+		return nil, err
+	}
+	return season, nil
+}
+
+// GetSeasons возвращает все сезоны; завершившиеся закрывает фоновая задача
+func (s *SeasonService) GetSeasons() ([]models.Season, error) {
+	return s.seasonRepo.FindAll()
+}
+
+// Start запускает фоновое закрытие завершившихся сезонов: сразу и затем
+// раз в seasonCloseInterval. Повторный вызов ничего не делает.
+func (s *SeasonService) Start() {
+	s.startOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(seasonCloseInterval)
+			defer ticker.Stop()
+			for {
+				s.CloseExpired()
+				<-ticker.C
+			}
+		}()
+	})
+}
+
+// CloseExpired сохраняет итоги всех завершившихся сезонов.
+// Ошибки логируются: закрытие повторится при следующем запуске.
+func (s *SeasonService) CloseExpired() {
+	seasons, err := s.seasonRepo.FindExpiredOpen(time.Now())
+	if err != nil {
+		log.Printf("Failed to find expired seasons: %v", err)
+		return
+	}
+	for i := range seasons {
+		if err := s.closeSeason(&seasons[i]); err != nil {
+			log.Printf("Failed to close season %d: %v", seasons[i].ID, err)
+		}
+	}
+}
+
+// CloseSeason досрочно или повторно сохраняет итоги сезона
+func (s *SeasonService) CloseSeason(id uint) (*models.Season, error) {
+	season, err := s.seasonRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("Сезон не найден")
+	}
+	if err := s.closeSeason(season); err != nil {
+		return nil, err
+	}
+	return season, nil
+}
+
+// closeSeason ранжирует всех учеников за период сезона и сохраняет места:
+// общее по школе и внутри класса
+func (s *SeasonService) closeSeason(season *models.Season) error {
+	window := &LeaderboardWindow{Period: PeriodSeason, From: season.StartsAt, To: season.EndsAt, Season: season}
+	rows, err := s.leaderboardService.RankAll(window)
+	if err != nil {
+		return err
+	}
+
+	standings := make([]models.SeasonStanding, 0, len(rows))
+	for _, row := range rows {
+		standings = append(standings, models.SeasonStanding{
+			SeasonID:         season.ID,
+			UserID:           row.UserID,
+			Rank:             row.Rank,
+			ClassRank:        row.ClassRank,
+			Level:            row.Level,
+			LevelLetter:      row.LevelLetter,
+			TotalPoints:      row.TotalPoints,
+			GamePoints:       row.GamePoints,
+			TotalXP:          row.TotalXP,
+			CompletedLessons: row.CompletedLessons,
+		})
+	}
+
+	now := time.Now()
+	season.ClosedAt = &now
+	return s.seasonRepo.SaveStandings(season, standings)
+}
+
+// GetHistory траектория мест ученика по закрытым сезонам
+func (s *SeasonService) GetHistory(userID uint) ([]SeasonHistoryEntry, error) {
+	standings, err := s.seasonRepo.FindStandingsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	seasonIDs := make([]uint, len(standings))
+	for i, standing := range standings {
+		seasonIDs[i] = standing.SeasonID
+	}
+	participants, err := s.seasonRepo.CountStandings(seasonIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]SeasonHistoryEntry, 0, len(standings))
+	for _, standing := range standings {
+		class := models.User{Level: standing.Level, LevelLetter: standing.LevelLetter}
+		history = append(history, SeasonHistoryEntry{
+			SeasonID:     standing.SeasonID,
+			SeasonName:   standing.Season.Name,
+			StartsAt:     standing.Season.StartsAt,
+			EndsAt:       standing.Season.EndsAt,
+			Rank:         standing.Rank,
+			ClassRank:    standing.ClassRank,
+			Participants: participants[standing.SeasonID],
+			ClassDisplay: class.GetClassDisplay(),
+			TotalPoints:  standing.TotalPoints,
+			GamePoints:   standing.GamePoints,
+			TotalXP:      standing.TotalXP,
+		})
+	}
+	return history, nil
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Операции посимвольного разбора ответа
+const (
+	DiffEqual   = "equal"   // совпадает
+	DiffMissing = "missing" // пропущено учеником
+	DiffExtra   = "extra"   // лишнее у ученика
+	DiffWrong   = "wrong"   // написано неверно
+)
+
+// DiffSegment участок разбора ответа: Expected - как нужно, Given - как написал ученик
+type DiffSegment struct {
+	Op       string `json:"op"`
+	Expected string `json:"expected,omitempty"`
+	Given    string `json:"given,omitempty"`
+}
+
+// SpellingResult проверка письменного ответа. Score от 0 до 1 считается по
+// расстоянию Левенштейна до ближайшего допустимого ответа.
+type SpellingResult struct {
+	Correct  bool          `json:"correct"`
+	Score    float64       `json:"score"`
+	Given    string        `json:"given"`
+	Expected string        `json:"expected"`
+	Diff     []DiffSegment `json:"diff"`
+}
+
+// gradeSpelling сравнивает ответ со списком допустимых вариантов (например,
+// британское и американское написание). Регистр, пунктуация и лишние
+// пробелы не учитываются.
+func gradeSpelling(given string, accepted []string) *SpellingResult {
+	normalizedGiven := []rune(normalizeSpelling(given))
+
+	var best *SpellingResult
+	bestDistance := math.MaxInt
+	for _, answer := range accepted {
+		expected := []rune(normalizeSpelling(answer))
+		if len(expected) == 0 {
+			continue
+		}
+		distance, diff := spellingDiff(expected, normalizedGiven)
+		if distance >= bestDistance {
+			continue
+		}
+		bestDistance = distance
+		best = &SpellingResult{
+			Correct:  distance == 0,
+			Score:    math.Max(0, 1-float64(distance)/float64(max(len(expected), len(normalizedGiven)))),
+			Given:    string(normalizedGiven),
+			Expected: string(expected),
+			Diff:     diff,
+		}
+	}
+	if best == nil {
+		return &SpellingResult{Given: string(normalizedGiven), Diff: []DiffSegment{}}
+	}
+	best.Score = math.Round(best.Score*100) / 100
+	return best
+}
+
+// normalizeSpelling приводит ответ к виду для сравнения: нижний регистр,
+// без знаков препинания, одинарные пробелы. Апострофы внутри слов
+// сохраняются, типографские заменяются обычными.
+func normalizeSpelling(text string) string {
+	var builder strings.Builder
+	runes := []rune(strings.ToLower(text))
+	for i, r := range runes {
+		switch {
+		case r == '\'' || r == '’' || r == '`':
+			if i > 0 && i < len(runes)-1 && unicode.IsLetter(runes[i-1]) && unicode.IsLetter(runes[i+1]) {
+				builder.WriteRune('\'')
+			}
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			builder.WriteRune(' ')
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	return strings.Join(strings.Fields(builder.String()), " ")
+}
+
+// spellingDiff расстояние Левенштейна и посимвольный разбор ошибок
+func spellingDiff(expected, given []rune) (int, []DiffSegment) {
+	rows, cols := len(expected)+1, len(given)+1
+	dist := make([][]int, rows)
+	for i := range dist {
+		dist[i] = make([]int, cols)
+		dist[i][0] = i
+	}
+	for j := 0; j < cols; j++ {
+		dist[0][j] = j
+	}
+	for i := 1; i < rows; i++ {
+		for j := 1; j < cols; j++ {
+			cost := 1
+			if expected[i-1] == given[j-1] {
+				cost = 0
+			}
+			dist[i][j] = min(dist[i-1][j]+1, dist[i][j-1]+1, dist[i-1][j-1]+cost)
+		}
+	}
+
+	// Восстанавливаем путь с конца и склеиваем соседние операции одного типа
+	var segments []DiffSegment
+	add := func(op string, expectedRune, givenRune rune) {
+		if n := len(segments); n > 0 && segments[n-1].Op == op {
+			segments[n-1].Expected = appendRune(segments[n-1].Expected, expectedRune)
+			segments[n-1].Given = appendRune(segments[n-1].Given, givenRune)
+			return
+		}
+		segments = append(segments, DiffSegment{Op: op, Expected: appendRune("", expectedRune), Given: appendRune("", givenRune)})
+	}
+	i, j := len(expected), len(given)
+	for i > 0 || j > 0 {
+		switch {
+		case i > 0 && j > 0 && expected[i-1] == given[j-1] && dist[i][j] == dist[i-1][j-1]:
+			add(DiffEqual, expected[i-1], given[j-1])
+			i, j = i-1, j-1
+		case i > 0 && j > 0 && dist[i][j] == dist[i-1][j-1]+1:
+			add(DiffWrong, expected[i-1], given[j-1])
+			i, j = i-1, j-1
+		case i > 0 && dist[i][j] == dist[i-1][j]+1:
+			add(DiffMissing, expected[i-1], 0)
+			i--
+		default:
+			add(DiffExtra, 0, given[j-1])
+			j--
+		}
+	}
+
+	// Сегменты собраны в обратном порядке, как и символы в них
+	for left, right := 0, len(segments)-1; left < right; left, right = left+1, right-1 {
+		segments[left], segments[right] = segments[right], segments[left]
+	}
+	for k := range segments {
+		segments[k].Expected = reverseString(segments[k].Expected)
+		segments[k].Given = reverseString(segments[k].Given)
+	}
+	if segments == nil {
+		segments = []DiffSegment{}
+	}
+	return dist[len(expected)][len(given)], segments
+}
+
+func appendRune(text string, r rune) string {
+	if r == 0 {
+		return text
+	}
+	return text + string(r)
+}
+
+func reverseString(text string) string {
+	runes := []rune(text)
+	for left, right := 0, len(runes)-1; left < right; left, right = left+1, right-1 {
+		runes[left], runes[right] = runes[right], runes[left]
+	}
+	return string(runes)
+}
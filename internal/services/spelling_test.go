@@ -0,0 +1,135 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGradeSpelling(t *testing.T) {
+	tests := []struct {
+		name     string
+		given    string
+		accepted []string
+		correct  bool
+		score    float64
+		expected string
+		diff     []DiffSegment
+	}{
+		{
+			name: "case, punctuation and spaces", given: "  Hello,  WORLD! ", accepted: []string{"hello world"},
+			correct: true, score: 1, expected: "hello world",
+			diff: []DiffSegment{{Op: DiffEqual, Expected: "hello world", Given: "hello world"}},
+		},
+		{
+			name: "typographic apostrophe", given: "Don’t", accepted: []string{"don't"},
+			correct: true, score: 1, expected: "don't",
+			diff: []DiffSegment{{Op: DiffEqual, Expected: "don't", Given: "don't"}},
+		},
+		{
+			name: "quotes around the word", given: "'hello'", accepted: []string{"hello"},
+			correct: true, score: 1, expected: "hello",
+			diff: []DiffSegment{{Op: DiffEqual, Expected: "hello", Given: "hello"}},
+		},
+		{
+			name: "american spelling accepted", given: "color", accepted: []string{"colour", "color"},
+			correct: true, score: 1, expected: "color",
+			diff: []DiffSegment{{Op: DiffEqual, Expected: "color", Given: "color"}},
+		},
+		{
+			name: "british spelling accepted", given: "Colour", accepted: []string{"colour", "color"},
+			correct: true, score: 1, expected: "colour",
+			diff: []DiffSegment{{Op: DiffEqual, Expected: "colour", Given: "colour"}},
+		},
+		{
+			name: "nearest variant, first on tie", given: "colur", accepted: []string{"colour", "color"},
+			score: 0.83, expected: "colour",
+			diff: []DiffSegment{
+				{Op: DiffEqual, Expected: "col", Given: "col"},
+				{Op: DiffMissing, Expected: "o"},
+				{Op: DiffEqual, Expected: "ur", Given: "ur"},
+			},
+		},
+		{
+			name: "nearest variant wins", given: "organisaton", accepted: []string{"organization", "organisation"},
+			score: 0.92, expected: "organisation",
+			diff: []DiffSegment{
+				{Op: DiffEqual, Expected: "organisat", Given: "organisat"},
+				{Op: DiffMissing, Expected: "i"},
+				{Op: DiffEqual, Expected: "on", Given: "on"},
+			},
+		},
+		{
+			name: "missing letter", given: "hous", accepted: []string{"house"},
+			score: 0.8, expected: "house",
+			diff: []DiffSegment{
+				{Op: DiffEqual, Expected: "hous", Given: "hous"},
+				{Op: DiffMissing, Expected: "e"},
+			},
+		},
+		{
+			name: "wrong letter", given: "cat", accepted: []string{"cut"},
+			score: 0.67, expected: "cut",
+			diff: []DiffSegment{
+				{Op: DiffEqual, Expected: "c", Given: "c"},
+				{Op: DiffWrong, Expected: "u", Given: "a"},
+				{Op: DiffEqual, Expected: "t", Given: "t"},
+			},
+		},
+		{
+			name: "extra letter", given: "bigg", accepted: []string{"big"},
+			score: 0.75, expected: "big",
+			diff: []DiffSegment{
+				{Op: DiffEqual, Expected: "bi", Given: "bi"},
+				{Op: DiffExtra, Given: "g"},
+				{Op: DiffEqual, Expected: "g", Given: "g"},
+			},
+		},
+		{
+			name: "nothing in common", given: "dog", accepted: []string{"cat"},
+			score: 0, expected: "cat",
+			diff: []DiffSegment{{Op: DiffWrong, Expected: "cat", Given: "dog"}},
+		},
+		{
+			name: "empty answer", given: " ?! ", accepted: []string{"cat"},
+			score: 0, expected: "cat",
+			diff: []DiffSegment{{Op: DiffMissing, Expected: "cat"}},
+		},
+		{
+			name: "no accepted answers", given: "cat", accepted: []string{"", "..."},
+			score: 0, expected: "",
+			diff: []DiffSegment{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := gradeSpelling(tt.given, tt.accepted)
+			if got.Correct != tt.correct || got.Score != tt.score || got.Expected != tt.expected {
+				t.Fatalf("gradeSpelling(%q) = correct %v, score %v, expected %q; want %v, %v, %q",
+					tt.given, got.Correct, got.Score, got.Expected, tt.correct, tt.score, tt.expected)
+			}
+			if !reflect.DeepEqual(got.Diff, tt.diff) {
+				t.Fatalf("diff = %+v, want %+v", got.Diff, tt.diff)
+			}
+		})
+	}
+}
+
+func TestNormalizeSpelling(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"  The   Cat  ", "the cat"},
+		{"well-known", "well known"},
+		{"It's", "it's"},
+		{"rock`n`roll", "rock'n'roll"},
+		{"'quoted'", "quoted"},
+		{"Привет, мир!", "привет мир"},
+		{"a+b=c", "a b c"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeSpelling(tt.in); got != tt.want {
+			t.Errorf("normalizeSpelling(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
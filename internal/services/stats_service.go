@@ -0,0 +1,219 @@
+package services
+
+import (
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/repositories"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// statsTolerance допустимое расхождение сумм процентов из-за округления float
+const statsTolerance = 0.01
+
+type StatsService struct {
+	statsRepo *repositories.StatsRepository
+	userRepo  *repositories.UserRepository
+}
+
+func NewStatsService(statsRepo *repositories.StatsRepository, userRepo *repositories.UserRepository) *StatsService {
+	return &StatsService{
+		statsRepo: statsRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// StatsMismatch расхождение кэша с полным пересчетом
+type StatsMismatch struct {
+	Scope    string               `json:"scope"`
+	Cached   models.StatsCounters `json:"cached"`
+	Expected models.StatsCounters `json:"expected"`
+}
+
+func (m StatsMismatch) String() string {
+	return fmt.Sprintf("%s: cached %+v, expected %+v", m.Scope, m.Cached, m.Expected)
+}
+
+// HandleEvent обновляет кэш после записи попытки теста или результата игры
+func (s *StatsService) HandleEvent(event Event, outcome *EventOutcome) error {
+	switch event.Type {
+	case models.EventTestSubmitted, models.EventGameFinished:
+		_, err := s.RefreshUser(event.UserID)
+		return err
+	}
+	return nil
+}
+
+// RefreshUser пересчитывает строку ученика (например, после смены класса)
+func (s *StatsService) RefreshUser(userID uint) (*models.UserStats, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.statsRepo.Refresh(user)
+}
+
+// GetUserStats кэшированная статистика; строка создается при первом обращении
+func (s *StatsService) GetUserStats(userID uint) (*models.UserStats, error) {
+	stats, err := s.statsRepo.FindUser(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return s.RefreshUser(userID)
+	}
+	return stats, err
+}
+
+// GetUsersStats кэшированная статистика набора учеников одним запросом
+func (s *StatsService) GetUsersStats(userIDs []uint) (map[uint]*models.UserStats, error) {
+	return s.statsRepo.FindUsers(userIDs)
+}
+
+// GetClassStats кэшированная статистика класса (без буквы - всей параллели)
+func (s *StatsService) GetClassStats(level int, levelLetter string) (*models.ClassStats, error) {
+	return s.statsRepo.FindClass(level, levelLetter)
+}
+
+// RebuildIfEmpty строит кэш при первом запуске на существующих данных
+func (s *StatsService) RebuildIfEmpty() error {
+	count, err := s.statsRepo.CountUsers()
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return s.Rebuild()
+}
+
+// Rebuild пересчитывает весь кэш с нуля
+func (s *StatsService) Rebuild() error {
+	users, classes, err := s.computeAll()
+	if err != nil {
+		return err
+	}
+	return s.statsRepo.ReplaceAll(users, classes)
+}
+
+// computeAll полный пересчет статистики всех учеников и классов по исходным данным
+func (s *StatsService) computeAll() ([]models.UserStats, []models.ClassStats, error) {
+	students, err := s.userRepo.FindStudents(map[string]interface{}{})
+	if err != nil {
+		return nil, nil, err
+	}
+	counters, err := s.statsRepo.Compute(nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	users := make([]models.UserStats, 0, len(students))
+	classesByKey := make(map[string]*models.ClassStats)
+	var classKeys []string
+	for _, student := range students {
+		users = append(users, models.UserStats{
+			UserID:        student.ID,
+			Level:         student.Level,
+			LevelLetter:   student.LevelLetter,
+			StatsCounters: counters[student.ID],
+		})
+
+		if student.Level == nil {
+			continue
+		}
+		key := classKey(*student.Level, student.LevelLetter)
+		class, ok := classesByKey[key]
+		if !ok {
+			class = &models.ClassStats{Level: *student.Level, LevelLetter: student.LevelLetter}
+			classesByKey[key] = class
+			classKeys = append(classKeys, key)
+		}
+		class.StatsCounters = class.StatsCounters.Add(counters[student.ID])
+	}
+
+	sort.Strings(classKeys)
+	classes := make([]models.ClassStats, 0, len(classKeys))
+	for _, key := range classKeys {
+		classes = append(classes, *classesByKey[key])
+	}
+	return users, classes, nil
+}
+
+// Check сравнивает кэш с полным пересчетом и возвращает расхождения
+func (s *StatsService) Check() ([]StatsMismatch, error) {
+	expectedUsers, expectedClasses, err := s.computeAll()
+	if err != nil {
+		return nil, err
+	}
+	cachedUsers, err := s.statsRepo.FindAllUsers()
+	if err != nil {
+		return nil, err
+	}
+	cachedClasses, err := s.statsRepo.FindAllClasses()
+	if err != nil {
+		return nil, err
+	}
+
+	var mismatches []StatsMismatch
+
+	cachedByUser := make(map[uint]models.StatsCounters, len(cachedUsers))
+	for _, stats := range cachedUsers {
+		cachedByUser[stats.UserID] = stats.StatsCounters
+	}
+	for _, expected := range expectedUsers {
+		cached, ok := cachedByUser[expected.UserID]
+		if !ok && expected.StatsCounters == (models.StatsCounters{}) {
+			// Строки неактивных учеников создаются лениво
+			continue
+		}
+		if !countersEqual(cached, expected.StatsCounters) {
+			mismatches = append(mismatches, StatsMismatch{
+				Scope:    fmt.Sprintf("user %d", expected.UserID),
+				Cached:   cached,
+				Expected: expected.StatsCounters,
+			})
+		}
+	}
+
+	cachedByClass := make(map[string]models.StatsCounters, len(cachedClasses))
+	for _, stats := range cachedClasses {
+		cachedByClass[classKey(stats.Level, stats.LevelLetter)] = stats.StatsCounters
+	}
+	expectedByClass := make(map[string]models.StatsCounters, len(expectedClasses))
+	for _, stats := range expectedClasses {
+		expectedByClass[classKey(stats.Level, stats.LevelLetter)] = stats.StatsCounters
+	}
+	for key := range cachedByClass {
+		if _, ok := expectedByClass[key]; !ok {
+			expectedByClass[key] = models.StatsCounters{}
+		}
+	}
+	keys := make([]string, 0, len(expectedByClass))
+	for key := range expectedByClass {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !countersEqual(cachedByClass[key], expectedByClass[key]) {
+			mismatches = append(mismatches, StatsMismatch{
+				Scope:    "class " + key,
+				Cached:   cachedByClass[key],
+				Expected: expectedByClass[key],
+			})
+		}
+	}
+
+	return mismatches, nil
+}
+
+func classKey(level int, levelLetter string) string {
+	return fmt.Sprintf("%d-%s", level, levelLetter)
+}
+
+func countersEqual(a, b models.StatsCounters) bool {
+	floatsEqual := math.Abs(a.CompletedPercentageSum-b.CompletedPercentageSum) < statsTolerance &&
+		math.Abs(a.ScoredPercentageSum-b.ScoredPercentageSum) < statsTolerance
+	a.CompletedPercentageSum, b.CompletedPercentageSum = 0, 0
+	a.ScoredPercentageSum, b.ScoredPercentageSum = 0, 0
+	return floatsEqual && a == b
+}
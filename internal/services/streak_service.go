@@ -0,0 +1,277 @@
+package services
+
+import (
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/repositories"
+	"errors"
+	"time"
+)
+
+const (
+	dayLayout      = "2006-01-02"
+	calendarDays   = 30
+	minDailyGoalXP = 10
+	maxDailyGoalXP = 500
+)
+
+type StreakService struct {
+	activityRepo *repositories.ActivityRepository
+	location     *time.Location
+	eventBus     *EventBus
+}
+
+func NewStreakService(activityRepo *repositories.ActivityRepository, location *time.Location, eventBus *EventBus) *StreakService {
+	if location == nil {
+		location = time.UTC
+	}
+	return &StreakService{
+		activityRepo: activityRepo,
+		location:     location,
+		eventBus:     eventBus,
+	}
+}
+
+// StreakDay день в календаре активности
+type StreakDay struct {
+	Day         string `json:"day"`
+	XP          int    `json:"xp"`
+	GoalReached bool   `json:"goal_reached"`
+	FreezeUsed  bool   `json:"freeze_used"`
+}
+
+// StreakInfo данные о серии для страницы профиля
+type StreakInfo struct {
+	CurrentStreak    int         `json:"current_streak"`
+	LongestStreak    int         `json:"longest_streak"`
+	DailyGoalXP      int         `json:"daily_goal_xp"`
+	TodayXP          int         `json:"today_xp"`
+	GoalReachedToday bool        `json:"goal_reached_today"`
+	FreezeTokens     int         `json:"freeze_tokens"`
+	LastGoalDay      string      `json:"last_goal_day"`
+	Calendar         []StreakDay `json:"calendar"`
+}
+
+// HandleEvent учитывает тесты и игры в дневной активности. XP дня - это
+// опыт, который журнал XP начислил за событие (outcome.XPAwarded), поэтому
+// серия подписывается на события после XPService. Если день засчитан в
+// серию, публикуется событие streak_updated.
+func (s *StreakService) HandleEvent(event Event, outcome *EventOutcome) error {
+	var streak *models.UserStreak
+	var advanced bool
+	var err error
+
+	switch event.Type {
+	case models.EventTestSubmitted:
+		attempt := event.TestAttempt
+		streak, advanced, err = s.recordActivity(attempt.UserID, attempt.CreatedAt, outcome.XPAwarded, 1, 0)
+	case models.EventGameFinished:
+		result := event.GameResult
+		streak, advanced, err = s.recordActivity(result.UserID, result.CreatedAt, outcome.XPAwarded, 0, 1)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if advanced {
+		s.publishStreakUpdated(streak, outcome)
+	}
+	return nil
+}
+
+func (s *StreakService) publishStreakUpdated(streak *models.UserStreak, outcome *EventOutcome) {
+	s.eventBus.PublishTo(Event{
+		Type:   models.EventStreakUpdated,
+		UserID: streak.UserID,
+		Streak: streak,
+	}, outcome)
+}
+
+func (s *StreakService) recordActivity(userID uint, at time.Time, xp, tests, games int) (*models.UserStreak, bool, error) {
+	if at.IsZero() {
+		at = time.Now()
+	}
+	day := s.dayKey(at)
+
+	activity, err := s.activityRepo.AddActivity(userID, day, xp, tests, games)
+	if err != nil {
+		return nil, false, err
+	}
+
+	streak, err := s.activityRepo.FindOrCreateStreak(userID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	advanced, err := s.checkGoal(streak, activity)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return streak, advanced, nil
+}
+
+// checkGoal засчитывает день в серию, если дневная цель выполнена впервые
+func (s *StreakService) checkGoal(streak *models.UserStreak, activity *models.DailyActivity) (bool, error) {
+	if activity.GoalReached || activity.XP < streak.DailyGoalXP {
+		return false, nil
+	}
+
+	if err := s.activityRepo.MarkGoalReached(activity.ID); err != nil {
+		return false, err
+	}
+	activity.GoalReached = true
+
+	frozenDays := advanceStreak(streak, activity.Day)
+	if len(frozenDays) > 0 {
+		if err := s.activityRepo.MarkFrozen(streak.UserID, frozenDays); err != nil {
+			return false, err
+		}
+	}
+
+	if err := s.activityRepo.SaveStreak(streak); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// advanceStreak продлевает серию днем day и возвращает пропущенные дни,
+// закрытые заморозками
+func advanceStreak(streak *models.UserStreak, day string) []string {
+	var frozenDays []string
+
+	gap := daysBetween(streak.LastGoalDay, day)
+	switch {
+	case streak.LastGoalDay == "":
+		streak.CurrentStreak = 1
+	case gap < 1:
+		// День уже засчитан
+		return nil
+	case gap == 1:
+		streak.CurrentStreak++
+	case gap-1 <= streak.FreezeTokens:
+		// Пропущенные дни закрываются заморозками, серия не прерывается
+		last, _ := time.Parse(dayLayout, streak.LastGoalDay)
+		for i := 1; i < gap; i++ {
+			frozenDays = append(frozenDays, last.AddDate(0, 0, i).Format(dayLayout))
+		}
+		streak.FreezeTokens -= gap - 1
+		streak.CurrentStreak++
+	default:
+		streak.CurrentStreak = 1
+	}
+
+	streak.LastGoalDay = day
+	if streak.CurrentStreak > streak.LongestStreak {
+		streak.LongestStreak = streak.CurrentStreak
+	}
+	if streak.CurrentStreak%models.FreezeTokenStreakStep == 0 && streak.FreezeTokens < models.MaxFreezeTokens {
+		streak.FreezeTokens++
+	}
+
+	return frozenDays
+}
+
+// GetStreakInfo возвращает серию с учетом пропущенных дней и календарь активности
+func (s *StreakService) GetStreakInfo(userID uint) (*StreakInfo, error) {
+	streak, err := s.activityRepo.FindOrCreateStreak(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().In(s.location)
+	today := now.Format(dayLayout)
+	fromDay := now.AddDate(0, 0, -(calendarDays - 1)).Format(dayLayout)
+
+	activities, err := s.activityRepo.FindRange(userID, fromDay, today)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &StreakInfo{
+		CurrentStreak: s.effectiveStreak(streak, today),
+		LongestStreak: streak.LongestStreak,
+		DailyGoalXP:   streak.DailyGoalXP,
+		FreezeTokens:  streak.FreezeTokens,
+		LastGoalDay:   streak.LastGoalDay,
+		Calendar:      make([]StreakDay, 0, len(activities)),
+	}
+
+	for _, a := range activities {
+		if a.Day == today {
+			info.TodayXP = a.XP
+			info.GoalReachedToday = a.GoalReached
+		}
+		info.Calendar = append(info.Calendar, StreakDay{
+			Day:         a.Day,
+			XP:          a.XP,
+			GoalReached: a.GoalReached,
+			FreezeUsed:  a.FreezeUsed,
+		})
+	}
+
+	return info, nil
+}
+
+// effectiveStreak показывает 0, если серия уже прервана и заморозок не хватит
+// (сама запись обновится при следующей активности)
+func (s *StreakService) effectiveStreak(streak *models.UserStreak, today string) int {
+	if streak.LastGoalDay == "" {
+		return 0
+	}
+	// Сегодняшний день еще можно выполнить, поэтому пропуском считается только вчерашний и ранее
+	missed := daysBetween(streak.LastGoalDay, today) - 1
+	if missed > streak.FreezeTokens {
+		return 0
+	}
+	return streak.CurrentStreak
+}
+
+// SetDailyGoal изменяет дневную цель; если сегодня она уже выполнена, день засчитывается сразу
+func (s *StreakService) SetDailyGoal(userID uint, goalXP int) (*models.UserStreak, []models.Achievement, error) {
+	if goalXP < minDailyGoalXP || goalXP > maxDailyGoalXP {
+		return nil, nil, errors.New("Неверная дневная цель: допустимо от 10 до 500 XP")
+	}
+
+	streak, err := s.activityRepo.FindOrCreateStreak(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	streak.DailyGoalXP = goalXP
+	if err := s.activityRepo.SaveStreak(streak); err != nil {
+		return nil, nil, err
+	}
+
+	outcome := &EventOutcome{}
+	activity, err := s.activityRepo.FindByUserAndDay(userID, s.dayKey(time.Now()))
+	if err == nil {
+		advanced, err := s.checkGoal(streak, activity)
+		if err != nil {
+			return nil, nil, err
+		}
+		if advanced {
+			s.publishStreakUpdated(streak, outcome)
+		}
+	}
+
+	return streak, outcome.Achievements, nil
+}
+
+func (s *StreakService) dayKey(t time.Time) string {
+	return t.In(s.location).Format(dayLayout)
+}
+
+// daysBetween количество календарных дней между двумя датами формата YYYY-MM-DD
+func daysBetween(fromDay, toDay string) int {
+	from, err := time.Parse(dayLayout, fromDay)
+	if err != nil {
+		return 0
+	}
+	to, err := time.Parse(dayLayout, toDay)
+	if err != nil {
+		return 0
+	}
+	return int(to.Sub(from).Hours() / 24)
+}
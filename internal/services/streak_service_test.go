@@ -0,0 +1,143 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"englishlessons.back/internal/models"
+)
+
+func TestAdvanceStreak(t *testing.T) {
+	tests := []struct {
+		name       string
+		streak     models.UserStreak
+		day        string
+		want       models.UserStreak
+		wantFrozen []string
+	}{
+		{
+			name:   "first day",
+			streak: models.UserStreak{},
+			day:    "2026-03-02",
+			want:   models.UserStreak{CurrentStreak: 1, LongestStreak: 1, LastGoalDay: "2026-03-02"},
+		},
+		{
+			name:   "same day is counted once",
+			streak: models.UserStreak{CurrentStreak: 3, LongestStreak: 3, LastGoalDay: "2026-03-02"},
+			day:    "2026-03-02",
+			want:   models.UserStreak{CurrentStreak: 3, LongestStreak: 3, LastGoalDay: "2026-03-02"},
+		},
+		{
+			name:   "next day across the month boundary",
+			streak: models.UserStreak{CurrentStreak: 3, LongestStreak: 5, LastGoalDay: "2026-02-28"},
+			day:    "2026-03-01",
+			want:   models.UserStreak{CurrentStreak: 4, LongestStreak: 5, LastGoalDay: "2026-03-01"},
+		},
+		{
+			name:       "missed days are covered by freezes",
+			streak:     models.UserStreak{CurrentStreak: 4, LongestStreak: 4, FreezeTokens: 2, LastGoalDay: "2026-03-01"},
+			day:        "2026-03-04",
+			want:       models.UserStreak{CurrentStreak: 5, LongestStreak: 5, LastGoalDay: "2026-03-04"},
+			wantFrozen: []string{"2026-03-02", "2026-03-03"},
+		},
+		{
+			name:   "not enough freezes resets the streak",
+			streak: models.UserStreak{CurrentStreak: 4, LongestStreak: 6, FreezeTokens: 1, LastGoalDay: "2026-03-01"},
+			day:    "2026-03-04",
+			want:   models.UserStreak{CurrentStreak: 1, LongestStreak: 6, FreezeTokens: 1, LastGoalDay: "2026-03-04"},
+		},
+		{
+			name:   "every seventh day earns a freeze",
+			streak: models.UserStreak{CurrentStreak: 6, LongestStreak: 6, LastGoalDay: "2026-03-06"},
+			day:    "2026-03-07",
+			want:   models.UserStreak{CurrentStreak: 7, LongestStreak: 7, FreezeTokens: 1, LastGoalDay: "2026-03-07"},
+		},
+		{
+			name:   "freezes are capped",
+			streak: models.UserStreak{CurrentStreak: 13, LongestStreak: 13, FreezeTokens: models.MaxFreezeTokens, LastGoalDay: "2026-03-13"},
+			day:    "2026-03-14",
+			want:   models.UserStreak{CurrentStreak: 14, LongestStreak: 14, FreezeTokens: models.MaxFreezeTokens, LastGoalDay: "2026-03-14"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			streak := tt.streak
+			frozen := advanceStreak(&streak, tt.day)
+			if streak != tt.want {
+				t.Errorf("streak = %+v, want %+v", streak, tt.want)
+			}
+			if !reflect.DeepEqual(frozen, tt.wantFrozen) {
+				t.Errorf("frozen days = %v, want %v", frozen, tt.wantFrozen)
+			}
+		})
+	}
+}
+
+func TestEffectiveStreak(t *testing.T) {
+	s := NewStreakService(nil, nil, nil)
+	streak := &models.UserStreak{CurrentStreak: 5, FreezeTokens: 1, LastGoalDay: "2026-03-10"}
+
+	tests := []struct {
+		today string
+		want  int
+	}{
+		{"2026-03-10", 5},
+		// Сегодняшнюю цель еще можно выполнить
+		{"2026-03-11", 5},
+		// Один пропущенный день закроет заморозка
+		{"2026-03-12", 5},
+		{"2026-03-13", 0},
+	}
+	for _, tt := range tests {
+		if got := s.effectiveStreak(streak, tt.today); got != tt.want {
+			t.Errorf("effectiveStreak on %s = %d, want %d", tt.today, got, tt.want)
+		}
+	}
+	if got := s.effectiveStreak(&models.UserStreak{}, "2026-03-10"); got != 0 {
+		t.Errorf("effectiveStreak without goal days = %d, want 0", got)
+	}
+}
+
+// День считается по часовому поясу школы, а не по UTC
+func TestStreakDayKeyUsesSchoolTimezone(t *testing.T) {
+	school := time.FixedZone("UTC+5", 5*60*60)
+	s := NewStreakService(nil, school, nil)
+
+	tests := []struct {
+		at   time.Time
+		want string
+	}{
+		{time.Date(2026, 3, 9, 18, 59, 0, 0, time.UTC), "2026-03-09"},
+		{time.Date(2026, 3, 9, 19, 0, 0, 0, time.UTC), "2026-03-10"},
+		{time.Date(2026, 3, 10, 0, 30, 0, 0, school), "2026-03-10"},
+	}
+	for _, tt := range tests {
+		if got := s.dayKey(tt.at); got != tt.want {
+			t.Errorf("dayKey(%s) = %s, want %s", tt.at, got, tt.want)
+		}
+	}
+
+	// Без часового пояса сервис работает в UTC
+	if got := NewStreakService(nil, nil, nil).dayKey(time.Date(2026, 3, 9, 19, 0, 0, 0, time.UTC)); got != "2026-03-09" {
+		t.Errorf("UTC dayKey = %s, want 2026-03-09", got)
+	}
+}
+
+func TestDaysBetween(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     int
+	}{
+		{"2026-03-01", "2026-03-01", 0},
+		{"2026-02-28", "2026-03-01", 1},
+		{"2025-12-31", "2026-01-02", 2},
+		{"2026-03-05", "2026-03-01", -4},
+		{"", "2026-03-01", 0},
+	}
+	for _, tt := range tests {
+		if got := daysBetween(tt.from, tt.to); got != tt.want {
+			t.Errorf("daysBetween(%q, %q) = %d, want %d", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/repositories"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type TagService struct {
+	tagRepo      *repositories.TagRepository
+	lessonRepo   *repositories.LessonRepository
+	gameItemRepo *repositories.GameItemRepository
+}
+
+func NewTagService(
+	tagRepo *repositories.TagRepository,
+	lessonRepo *repositories.LessonRepository,
+	gameItemRepo *repositories.GameItemRepository,
+) *TagService {
+	return &TagService{
+		tagRepo:      tagRepo,
+		lessonRepo:   lessonRepo,
+		gameItemRepo: gameItemRepo,
+	}
+}
+
+type CreateTagRequest struct {
+	Kind string `json:"kind" binding:"required"`
+	Name string `json:"name" binding:"required"`
+}
+
+// SetTagsRequest полный список тегов; пустой список снимает все теги
+type SetTagsRequest struct {
+	TagIDs []uint `json:"tag_ids"`
+}
+
+// parseTagKind проверяет раздел тегов; allowEmpty - пустой раздел означает "все"
+func parseTagKind(kind string, allowEmpty bool) (models.TagKind, error) {
+	switch models.TagKind(kind) {
+	case models.TagKindTopic, models.TagKindCEFR, models.TagKindSkill:
+		return models.TagKind(kind), nil
+	case "":
+		if allowEmpty {
+			return "", nil
+		}
+	}
+	return "", errors.New("Неверный раздел тегов: допустимо topic, cefr, skill")
+}
+
+// HandleEvent сохраняет ответы на отдельные вопросы и задания для статистики по тегам
+func (s *TagService) HandleEvent(event Event, outcome *EventOutcome) error {
+	switch event.Type {
+	case models.EventTestSubmitted:
+		return s.recordAnswers(event, models.ReviewSourceQuestion, event.AnsweredQuestionIDs, event.MissedQuestionIDs)
+	case models.EventGameFinished:
+		return s.recordAnswers(event, models.ReviewSourceGameItem, event.PlayedGameItemIDs, event.MissedGameItemIDs)
+	}
+	return nil
+}
+
+func (s *TagService) recordAnswers(event Event, sourceType models.ReviewSource, answeredIDs, missedIDs []uint) error {
+	return s.tagRepo.CreateAnswerRecords(answerRecords(event, sourceType, answeredIDs, missedIDs))
+}
+
+// answerRecords по одной записи на каждый вопрос или задание; повторы
+// в answeredIDs не дублируются
+func answerRecords(event Event, sourceType models.ReviewSource, answeredIDs, missedIDs []uint) []models.AnswerRecord {
+	missed := make(map[uint]bool, len(missedIDs))
+	for _, id := range missedIDs {
+		missed[id] = true
+	}
+
+	records := make([]models.AnswerRecord, 0, len(answeredIDs))
+	for _, id := range uniqueIDs(answeredIDs) {
+		records = append(records, models.AnswerRecord{
+			UserID:     event.UserID,
+			SourceType: sourceType,
+			SourceID:   id,
+			IsCorrect:  !missed[id],
+			CreatedAt:  event.OccurredAt,
+		})
+	}
+	return records
+}
+
+func (s *TagService) ListTags(kind string) ([]models.Tag, error) {
+	tagKind, err := parseTagKind(kind, true)
+	if err != nil {
+		return nil, err
+	}
+	return s.tagRepo.FindAll(tagKind)
+}
+
+// CreateTag заводит тег. Уровни CEFR и навыки встроены, учитель добавляет
+// грамматические темы; повторное создание возвращает существующий тег.
+func (s *TagService) CreateTag(req CreateTagRequest) (*models.Tag, error) {
+	kind, err := parseTagKind(req.Kind, false)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.Join(strings.Fields(req.Name), " ")
+	if name == "" || len([]rune(name)) > 100 {
+		return nil, errors.New("Название тега должно быть от 1 до 100 символов")
+	}
+
+	existing, err := s.tagRepo.FindByKindAndName(kind, name)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if kind != models.TagKindTopic {
+		return nil, errors.New("Неверный тег: уровни CEFR и навыки встроены, добавлять можно только темы")
+	}
+
+	tag := &models.Tag{Kind: kind, Name: name}
+	if err := s.tagRepo.Create(tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// DeleteTag удаляет тег темы; встроенные теги удалить нельзя
+func (s *TagService) DeleteTag(id uint) error {
+	tag, err := s.tagRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("Тег не найден")
+		}
+		return err
+	}
+	if tag.Kind != models.TagKindTopic {
+		return errors.New("Неверный тег: встроенные теги удалить нельзя")
+	}
+	return s.tagRepo.Delete(id)
+}
+
+func (s *TagService) SetLessonTags(lessonID uint, req SetTagsRequest) ([]models.Tag, error) {
+	lesson, err := s.lessonRepo.FindByID(lessonID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Урок не найден")
+		}
+		return nil, err
+	}
+	return s.replaceTags(lesson, req.TagIDs)
+}
+
+func (s *TagService) SetQuestionTags(questionID uint, req SetTagsRequest) ([]models.Tag, error) {
+	question, err := s.lessonRepo.FindQuestionByID(questionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Вопрос не найден")
+		}
+		return nil, err
+	}
+	return s.replaceTags(question, req.TagIDs)
+}
+
+func (s *TagService) SetGameItemTags(itemID uint, req SetTagsRequest) ([]models.Tag, error) {
+	item, err := s.gameItemRepo.FindByID(itemID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Задание не найдено")
+		}
+		return nil, err
+	}
+	return s.replaceTags(item, req.TagIDs)
+}
+
+func (s *TagService) replaceTags(owner interface{}, tagIDs []uint) ([]models.Tag, error) {
+	tagIDs = uniqueIDs(tagIDs)
+	tags, err := s.tagRepo.FindByIDs(tagIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) != len(tagIDs) {
+		return nil, errors.New("Тег не найден")
+	}
+	if err := s.tagRepo.ReplaceTags(owner, tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/repositories"
+)
+
+func TestParseTagKind(t *testing.T) {
+	tests := []struct {
+		kind       string
+		allowEmpty bool
+		want       models.TagKind
+		wantErr    bool
+	}{
+		{"topic", false, models.TagKindTopic, false},
+		{"cefr", false, models.TagKindCEFR, false},
+		{"skill", true, models.TagKindSkill, false},
+		{"", true, "", false},
+		{"", false, "", true},
+		{"Topic", true, "", true},
+		{"level", true, "", true},
+	}
+	for _, tt := range tests {
+		got, err := parseTagKind(tt.kind, tt.allowEmpty)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseTagKind(%q, %v) = %q, %v", tt.kind, tt.allowEmpty, got, err)
+		}
+	}
+}
+
+// Проверки, которые отклоняют запрос до обращения к базе
+func TestCreateTagValidation(t *testing.T) {
+	s := NewTagService(nil, nil, nil)
+	tests := []struct {
+		req     CreateTagRequest
+		wantErr string
+	}{
+		{CreateTagRequest{Kind: "genre", Name: "Present Perfect"}, "Неверный раздел тегов"},
+		{CreateTagRequest{Kind: "topic", Name: "   "}, "от 1 до 100 символов"},
+		{CreateTagRequest{Kind: "topic", Name: strings.Repeat("я", 101)}, "от 1 до 100 символов"},
+	}
+	for _, tt := range tests {
+		if _, err := s.CreateTag(tt.req); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("CreateTag(%+v) error = %v, want %q", tt.req, err, tt.wantErr)
+		}
+	}
+}
+
+func TestAnswerRecords(t *testing.T) {
+	at := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	event := Event{UserID: 7, OccurredAt: at}
+
+	records := answerRecords(event, models.ReviewSourceQuestion, []uint{3, 1, 3, 2}, []uint{1, 9})
+	want := []models.AnswerRecord{
+		{UserID: 7, SourceType: models.ReviewSourceQuestion, SourceID: 3, IsCorrect: true, CreatedAt: at},
+		{UserID: 7, SourceType: models.ReviewSourceQuestion, SourceID: 1, IsCorrect: false, CreatedAt: at},
+		{UserID: 7, SourceType: models.ReviewSourceQuestion, SourceID: 2, IsCorrect: true, CreatedAt: at},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Fatalf("answerRecords = %+v, want %+v", records, want)
+	}
+
+	if records := answerRecords(event, models.ReviewSourceGameItem, nil, nil); len(records) != 0 {
+		t.Fatalf("no answers must give no records: %+v", records)
+	}
+}
+
+func TestTagPerformance(t *testing.T) {
+	stats := []repositories.TagStats{
+		{TagID: 1, TagKind: models.TagKindTopic, TagName: "Present Perfect", Answers: 3, CorrectCount: 2, Students: 2},
+		{TagID: 2, TagKind: models.TagKindSkill, TagName: "reading", Answers: 0, Students: 0},
+	}
+	got := tagPerformance(stats)
+	want := []TagPerformance{
+		{TagID: 1, Kind: models.TagKindTopic, Name: "Present Perfect", Answers: 3, CorrectCount: 2, Accuracy: 66.7, Students: 2},
+		{TagID: 2, Kind: models.TagKindSkill, Name: "reading"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("tagPerformance = %+v, want %+v", got, want)
+	}
+}
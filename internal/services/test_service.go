@@ -4,6 +4,7 @@ import (
 	"englishlessons.back/internal/models"
 	"englishlessons.back/internal/repositories"
 	"errors"
+	"math"
 	"strconv"
 	"time"
 
@@ -14,33 +15,40 @@ type TestService struct {
 	testRepo     *repositories.TestRepository
 	lessonRepo   *repositories.LessonRepository
 	progressRepo *repositories.ProgressRepository
-	achievementRepo *repositories.AchievementRepository
+	eventBus     *EventBus
 }
 
 func NewTestService(
 	testRepo *repositories.TestRepository,
 	lessonRepo *repositories.LessonRepository,
 	progressRepo *repositories.ProgressRepository,
-	achievementRepo *repositories.AchievementRepository,
+	eventBus *EventBus,
 ) *TestService {
 	return &TestService{
-		testRepo:      testRepo,
-		lessonRepo:    lessonRepo,
-		progressRepo:  progressRepo,
-		achievementRepo: achievementRepo,
+		testRepo:     testRepo,
+		lessonRepo:   lessonRepo,
+		progressRepo: progressRepo,
+		eventBus:     eventBus,
 	}
 }
 
+// SubmitTestRequest ответы на тест: Answers - выбранные варианты,
+// TextAnswers - введенный текст для вопросов с вводом ответа
 type SubmitTestRequest struct {
-	LessonID int
-	Answers  map[string]int
-	UserID   uint
+	LessonID    int
+	Answers     map[string]int
+	TextAnswers map[string]string
+	UserID      uint
 }
 
 type TestResult struct {
-	TestAttempt   *models.TestAttempt
-	Progress      *models.LessonProgress
-	IsNewProgress bool
+	TestAttempt     *models.TestAttempt
+	Progress        *models.LessonProgress
+	IsNewProgress   bool
+	NewAchievements []models.Achievement
+	XPAwarded       int
+	// Разбор ответов на вопросы с вводом ответа по ID вопроса
+	Feedback map[string]*SpellingResult
 }
 
 func (s *TestService) SubmitTest(req SubmitTestRequest) (*TestResult, error) {
@@ -54,114 +62,165 @@ func (s *TestService) SubmitTest(req SubmitTestRequest) (*TestResult, error) {
 	}
 
 	// Проверяем доступность урока
-	if lesson.Order > 1 {
-		prevLesson, err := s.lessonRepo.FindByOrder(lesson.Order-1, true)
-		if err == nil {
-			prevProgress, err := s.progressRepo.FindByUserAndLesson(req.UserID, prevLesson.ID)
-			if err != nil || !prevProgress.IsCompleted {
-				return nil, errors.New("Сначала необходимо пройти предыдущий урок '" + prevLesson.Title + "' с результатом >=70%")
-			}
-		}
+	if err := checkLessonAccess(s.lessonRepo, s.progressRepo, req.UserID, lesson); err != nil {
+		return nil, err
 	}
 
 	// Проверяем что все вопросы отвечены
-	if len(req.Answers) != len(lesson.Questions) {
+	if len(req.Answers)+len(req.TextAnswers) != len(lesson.Questions) {
 		return nil, errors.New("Необходимо ответить на все вопросы")
 	}
 
-	// Подсчитываем результаты
+	// Подсчитываем результаты. Вопрос с вводом ответа может принести часть
+	// балла, засчитанным он считается только при точном ответе.
 	totalQuestions := len(lesson.Questions)
 	correctAnswers := 0
+	credit := 0.0
+	answeredQuestionIDs := make([]uint, 0, totalQuestions)
+	var missedQuestionIDs []uint
+	feedback := make(map[string]*SpellingResult)
 
 	for _, question := range lesson.Questions {
-		selectedAnswerID, exists := req.Answers[strconv.Itoa(int(question.ID))]
-		if !exists {
-			return nil, errors.New("Не все вопросы отвечены")
-		}
+		key := strconv.Itoa(int(question.ID))
+		isCorrect := false
 
-		for _, answerOption := range question.AnswerOptions {
-			if answerOption.ID == uint(selectedAnswerID) {
-				if answerOption.IsCorrect {
-					correctAnswers++
+		if question.Type == models.QuestionTyped {
+			text, exists := req.TextAnswers[key]
+			if !exists {
+				return nil, errors.New("Не все вопросы отвечены")
+			}
+			result := gradeSpelling(text, question.AcceptedAnswers())
+			feedback[key] = result
+			isCorrect = result.Correct
+			credit += result.Score
+		} else {
+			selectedAnswerID, exists := req.Answers[key]
+			if !exists {
+				return nil, errors.New("Не все вопросы отвечены")
+			}
+			for _, answerOption := range question.AnswerOptions {
+				if answerOption.ID == uint(selectedAnswerID) {
+					isCorrect = answerOption.IsCorrect
+					break
 				}
-				break
+			}
+			if isCorrect {
+				credit++
 			}
 		}
+
+		answeredQuestionIDs = append(answeredQuestionIDs, question.ID)
+		if isCorrect {
+			correctAnswers++
+		} else {
+			missedQuestionIDs = append(missedQuestionIDs, question.ID)
+		}
 	}
 
-	percentage := float64(correctAnswers) / float64(totalQuestions) * 100
+	percentage := credit / float64(totalQuestions) * 100
 	isPassed := percentage >= 70
 
 	// Создаем попытку теста
 	testAttempt := &models.TestAttempt{
 		UserID:         req.UserID,
 		LessonID:       uint(req.LessonID),
-		Score:          correctAnswers * 10,
+		Score:          int(math.Round(credit * 10)),
 		Percentage:     percentage,
 		TotalQuestions: totalQuestions,
 		CorrectAnswers: correctAnswers,
 		IsPassed:       isPassed,
 	}
 
-	if err := s.testRepo.Create(testAttempt); err != nil {
-		return nil, errors.New("Failed to save test attempt")
+	// Попытка и прогресс сохраняются в одной транзакции
+	var progress *models.LessonProgress
+	isNewProgress := false
+	lessonCompleted := false
+	err = s.progressRepo.DB().Transaction(func(tx *gorm.DB) error {
+		testRepo := s.testRepo.WithTx(tx)
+		progressRepo := s.progressRepo.WithTx(tx)
+
+		if err := testRepo.Create(testAttempt); err != nil {
+			return errors.New("Failed to save test attempt")
+		}
+
+		var err error
+		progress, isNewProgress, lessonCompleted, err = applyAttemptToProgress(progressRepo, testAttempt)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Обновляем прогресс
-	progress, err := s.progressRepo.FindByUserAndLesson(req.UserID, uint(req.LessonID))
-	isNewProgress := false
+	// Достижения, серия и прочие подписчики обрабатывают уже зафиксированную попытку
+	outcome := s.eventBus.Publish(Event{
+		Type:                models.EventTestSubmitted,
+		UserID:              req.UserID,
+		OccurredAt:          testAttempt.CreatedAt,
+		TestAttempt:         testAttempt,
+		IsFirstAttempt:      isNewProgress,
+		LessonCompleted:     lessonCompleted,
+		AnsweredQuestionIDs: answeredQuestionIDs,
+		MissedQuestionIDs:   missedQuestionIDs,
+	})
 
+	return &TestResult{
+		TestAttempt:     testAttempt,
+		Progress:        progress,
+		IsNewProgress:   isNewProgress,
+		NewAchievements: outcome.Achievements,
+		XPAwarded:       outcome.XPAwarded,
+		Feedback:        feedback,
+	}, nil
+}
+
+// applyAttemptToProgress создает или обновляет прогресс урока по результату попытки.
+// Возвращает также, первая ли это попытка и пройден ли урок впервые именно ею.
+func applyAttemptToProgress(progressRepo *repositories.ProgressRepository, attempt *models.TestAttempt) (*models.LessonProgress, bool, bool, error) {
 	now := time.Now()
+
+	progress, err := progressRepo.FindByUserAndLessonForUpdate(attempt.UserID, attempt.LessonID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			// Создаем новый прогресс
-			progress = &models.LessonProgress{
-				UserID:         req.UserID,
-				LessonID:       uint(req.LessonID),
-				BestScore:      testAttempt.Score,
-				BestPercentage: percentage,
-				AttemptsCount:  1,
-				IsCompleted:    isPassed,
-				LastAttemptAt:  now,
-			}
-			if isPassed {
-				progress.CompletedAt = &now
-			}
-			if err := s.progressRepo.Create(progress); err != nil {
-				return nil, errors.New("Failed to save progress")
-			}
-			isNewProgress = true
-		} else {
-			return nil, errors.New("Database error")
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, false, errors.New("Database error")
 		}
-	} else {
-		// Обновляем существующий прогресс
-		progress.AttemptsCount++
-		if testAttempt.Score > progress.BestScore {
-			progress.BestScore = testAttempt.Score
-			progress.BestPercentage = percentage
+
+		// Создаем новый прогресс
+		progress = &models.LessonProgress{
+			UserID:         attempt.UserID,
+			LessonID:       attempt.LessonID,
+			BestScore:      attempt.Score,
+			BestPercentage: attempt.Percentage,
+			AttemptsCount:  1,
+			IsCompleted:    attempt.IsPassed,
+			LastAttemptAt:  now,
 		}
-		if isPassed && !progress.IsCompleted {
-			progress.IsCompleted = true
+		if attempt.IsPassed {
 			progress.CompletedAt = &now
 		}
-		progress.LastAttemptAt = now
-		if err := s.progressRepo.Update(progress); err != nil {
-			return nil, errors.New("Failed to update progress")
+		if err := progressRepo.Create(progress); err != nil {
+			return nil, false, false, errors.New("Failed to save progress")
 		}
+		return progress, true, attempt.IsPassed, nil
 	}
 
-	// Проверяем достижения
-	if isNewProgress && isPassed {
-		// Можно добавить проверку достижений здесь
-		// s.checkAchievements(req.UserID, lesson)
+	// Обновляем существующий прогресс. Строка без попыток могла появиться
+	// после теста на уровень - тогда это все равно первая попытка.
+	isFirstAttempt := progress.AttemptsCount == 0
+	progress.AttemptsCount++
+	if attempt.Score > progress.BestScore {
+		progress.BestScore = attempt.Score
+		progress.BestPercentage = attempt.Percentage
 	}
-
-	return &TestResult{
-		TestAttempt:   testAttempt,
-		Progress:      progress,
-		IsNewProgress: isNewProgress,
-	}, nil
+	completed := attempt.IsPassed && !progress.IsCompleted
+	if completed {
+		progress.IsCompleted = true
+		progress.CompletedAt = &now
+	}
+	progress.LastAttemptAt = now
+	if err := progressRepo.Update(progress); err != nil {
+		return nil, false, false, errors.New("Failed to update progress")
+	}
+	return progress, isFirstAttempt, completed, nil
 }
 
 func (s *TestService) GetAttemptsByUser(userID uint, lessonID *uint) ([]models.TestAttempt, error) {
@@ -171,4 +230,3 @@ func (s *TestService) GetAttemptsByUser(userID uint, lessonID *uint) ([]models.T
 func (s *TestService) GetAttemptsByLesson(lessonID uint, userID *uint) ([]models.TestAttempt, error) {
 	return s.testRepo.FindByLessonID(lessonID, userID)
 }
-
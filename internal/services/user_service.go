@@ -15,17 +15,26 @@ import (
 )
 
 type UserService struct {
-	userRepo     *repositories.UserRepository
-	progressRepo *repositories.ProgressRepository
+	userRepo            *repositories.UserRepository
+	progressRepo        *repositories.ProgressRepository
+	statsService        *StatsService
+	reviewService       *ReviewService
+	notificationService *NotificationService
 }
 
 func NewUserService(
 	userRepo *repositories.UserRepository,
 	progressRepo *repositories.ProgressRepository,
+	statsService *StatsService,
+	reviewService *ReviewService,
+	notificationService *NotificationService,
 ) *UserService {
 	return &UserService{
-		userRepo:     userRepo,
-		progressRepo: progressRepo,
+		userRepo:            userRepo,
+		progressRepo:        progressRepo,
+		statsService:        statsService,
+		reviewService:       reviewService,
+		notificationService: notificationService,
 	}
 }
 
@@ -58,27 +67,19 @@ func (s *UserService) GetUserStats(userID uint) (map[string]interface{}, error)
 		return nil, err
 	}
 
-	totalPoints := 0
-	completedLessons := 0
-	totalAttempts := 0
-	var completedProgress []models.LessonProgress
-
-	for _, p := range progress {
-		totalPoints += p.BestScore
-		totalAttempts += p.AttemptsCount
-		if p.IsCompleted {
-			completedLessons++
-			completedProgress = append(completedProgress, p)
-		}
+	// Итоговые показатели берутся из кэша статистики
+	stats, err := s.statsService.GetUserStats(userID)
+	if err != nil {
+		return nil, err
 	}
+	totalPoints := stats.TotalPoints
+	completedLessons := stats.CompletedLessons
+	totalAttempts := stats.TotalAttempts
+	avgPercentage := stats.AveragePercentage()
 
-	avgPercentage := 0.0
-	if len(completedProgress) > 0 {
-		sum := 0.0
-		for _, p := range completedProgress {
-			sum += p.BestPercentage
-		}
-		avgPercentage = sum / float64(len(completedProgress))
+	reviewsDue, err := s.reviewService.CountDue(userID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Получаем общее количество уроков
@@ -128,6 +129,7 @@ func (s *UserService) GetUserStats(userID uint) (map[string]interface{}, error)
 		"total_lessons":       totalLessons,
 		"total_completed_lessons": completedLessons,
 		"overall_progress":    overallProgress,
+		"reviews_due":         reviewsDue,
 		"lessons_detail":      lessonsDetail,
 	}, nil
 }
@@ -146,27 +148,19 @@ func (s *UserService) GetStudentStats(studentID uint) (map[string]interface{}, e
 		return nil, err
 	}
 
-	totalPoints := 0
-	completedLessons := 0
-	totalAttempts := 0
-	var completedProgress []models.LessonProgress
-
-	for _, p := range progress {
-		totalPoints += p.BestScore
-		totalAttempts += p.AttemptsCount
-		if p.IsCompleted {
-			completedLessons++
-			completedProgress = append(completedProgress, p)
-		}
+	// Итоговые показатели берутся из кэша статистики
+	stats, err := s.statsService.GetUserStats(studentID)
+	if err != nil {
+		return nil, err
 	}
+	totalPoints := stats.TotalPoints
+	completedLessons := stats.CompletedLessons
+	totalAttempts := stats.TotalAttempts
+	avgPercentage := stats.AveragePercentage()
 
-	avgPercentage := 0.0
-	if len(completedProgress) > 0 {
-		sum := 0.0
-		for _, p := range completedProgress {
-			sum += p.BestPercentage
-		}
-		avgPercentage = sum / float64(len(completedProgress))
+	reviewsDue, err := s.reviewService.CountDue(studentID)
+	if err != nil {
+		return nil, err
 	}
 
 	var totalLessons int64
@@ -220,6 +214,7 @@ func (s *UserService) GetStudentStats(studentID uint) (map[string]interface{}, e
 		"total_attempts":    totalAttempts,
 		"total_lessons":      totalLessons,
 		"total_completed_lessons": completedLessons,
+		"reviews_due":        reviewsDue,
 		"lessons_detail":     lessonsDetail,
 	}, nil
 }
@@ -255,6 +250,12 @@ func (s *UserService) ResetStudentPassword(username string) (string, error) {
 		return "", errors.New("ошибка при обновлении пароля")
 	}
 
+	// Ученик узнает о сбросе, даже если не просил о нем
+	s.notificationService.Notify(user.ID, models.NotificationPasswordReset,
+		"Пароль сброшен",
+		"Учитель сбросил ваш пароль. Новый пароль выдаст учитель.",
+		models.JSONMap{})
+
 	return newPassword, nil
 }
 
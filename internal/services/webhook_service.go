@@ -0,0 +1,669 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Паузы между попытками: 30с, 1м, 2м, ... но не больше webhookMaxRetryDelay.
+	// После webhookMaxAttempts неудач доставка помечается failed.
+	webhookRetryBase     = 30 * time.Second
+	webhookMaxRetryDelay = 6 * time.Hour
+	webhookMaxAttempts   = 10
+
+	webhookPollInterval = 5 * time.Second
+	webhookBatchSize    = 20
+	webhookTimeout      = 10 * time.Second
+	// webhookLease больше таймаута запроса: пока идет отправка, доставку не возьмут повторно
+	webhookLease = 2 * time.Minute
+
+	maxWebhookURLLength = 2000
+	defaultDeliveryPage = 50
+	maxDeliveryPage     = 200
+)
+
+// WebhookService управляет подписками и доставляет события из исходящей
+// очереди (таблица webhook_deliveries). События только ставятся в очередь,
+// отправляет их фоновый обработчик, запущенный Start.
+type WebhookService struct {
+	webhookRepo *repositories.WebhookRepository
+	userRepo    *repositories.UserRepository
+	lessonRepo  *repositories.LessonRepository
+	client      *http.Client
+
+	wake      chan struct{}
+	startOnce sync.Once
+}
+
+func NewWebhookService(
+	webhookRepo *repositories.WebhookRepository,
+	userRepo *repositories.UserRepository,
+	lessonRepo *repositories.LessonRepository,
+) *WebhookService {
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		userRepo:    userRepo,
+		lessonRepo:  lessonRepo,
+		client:      newWebhookClient(webhookAddressAllowed),
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// WebhookRequest создание или изменение подписки
+type WebhookRequest struct {
+	URL         string                `json:"url" binding:"required"`
+	Description string                `json:"description"`
+	Events      []models.WebhookEvent `json:"events" binding:"required"`
+	IsActive    *bool                 `json:"is_active"`
+}
+
+// WebhookWithSecret подписка вместе с секретом подписи; секрет виден только
+// при создании и при смене
+type WebhookWithSecret struct {
+	*models.Webhook
+	Secret string `json:"secret"`
+}
+
+// DeliveryListRequest страница журнала доставок
+type DeliveryListRequest struct {
+	Status models.WebhookDeliveryStatus
+	Limit  int
+	Offset int
+}
+
+// WebhookPayload тело запроса к получателю
+type WebhookPayload struct {
+	Event      models.WebhookEvent    `json:"event"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	Data       map[string]interface{} `json:"data"`
+}
+
+func (s *WebhookService) ListWebhooks() ([]models.Webhook, error) {
+	webhooks, err := s.webhookRepo.FindAll()
+	if webhooks == nil {
+		webhooks = []models.Webhook{}
+	}
+	return webhooks, err
+}
+
+func (s *WebhookService) CreateWebhook(userID uint, req WebhookRequest) (*WebhookWithSecret, error) {
+	webhook := &models.Webhook{CreatedBy: userID, IsActive: true}
+	if err := applyWebhookRequest(webhook, req); err != nil {
+		return nil, err
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	webhook.Secret = secret
+
+	if err := s.webhookRepo.Create(webhook); err != nil {
+		return nil, err
+	}
+	return &WebhookWithSecret{Webhook: webhook, Secret: secret}, nil
+}
+
+func (s *WebhookService) UpdateWebhook(id uint, req WebhookRequest) (*models.Webhook, error) {
+	webhook, err := s.findWebhook(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyWebhookRequest(webhook, req); err != nil {
+		return nil, err
+	}
+	if err := s.webhookRepo.Update(webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// RotateSecret выдает новый секрет; старые подписи сразу перестают совпадать
+func (s *WebhookService) RotateSecret(id uint) (*WebhookWithSecret, error) {
+	webhook, err := s.findWebhook(id)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	webhook.Secret = secret
+	if err := s.webhookRepo.Update(webhook); err != nil {
+		return nil, err
+	}
+	return &WebhookWithSecret{Webhook: webhook, Secret: secret}, nil
+}
+
+func (s *WebhookService) DeleteWebhook(id uint) error {
+	if err := s.webhookRepo.Delete(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("Вебхук не найден")
+		}
+		return err
+	}
+	return nil
+}
+
+// ListDeliveries журнал доставок вебхука
+func (s *WebhookService) ListDeliveries(webhookID uint, req DeliveryListRequest) ([]models.WebhookDelivery, int64, error) {
+	if _, err := s.findWebhook(webhookID); err != nil {
+		return nil, 0, err
+	}
+	switch req.Status {
+	case "", models.WebhookPending, models.WebhookDelivered, models.WebhookFailed:
+	default:
+		return nil, 0, errors.New("Неверный статус: допустимо pending, delivered, failed")
+	}
+	if req.Limit <= 0 {
+		req.Limit = defaultDeliveryPage
+	}
+	if req.Limit > maxDeliveryPage {
+		req.Limit = maxDeliveryPage
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	deliveries, total, err := s.webhookRepo.FindDeliveries(webhookID, req.Status, req.Limit, req.Offset)
+	if deliveries == nil {
+		deliveries = []models.WebhookDelivery{}
+	}
+	return deliveries, total, err
+}
+
+// RetryDelivery возвращает неудавшуюся доставку в очередь с новым счетчиком попыток
+func (s *WebhookService) RetryDelivery(id uint) (*models.WebhookDelivery, error) {
+	delivery, err := s.webhookRepo.FindDelivery(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Доставка не найдена")
+		}
+		return nil, err
+	}
+	if delivery.Status != models.WebhookFailed {
+		return nil, errors.New("Повторить можно только неудавшуюся доставку")
+	}
+
+	delivery.Status = models.WebhookPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	if err := s.webhookRepo.UpdateDelivery(delivery); err != nil {
+		return nil, err
+	}
+	s.signal()
+	return delivery, nil
+}
+
+// HandleEvent ставит в очередь доставки всем вебхукам, подписанным на событие
+func (s *WebhookService) HandleEvent(event Event, outcome *EventOutcome) error {
+	events := webhookEventsFor(event)
+	if len(events) == 0 {
+		return nil
+	}
+
+	webhooks, err := s.webhookRepo.FindActive()
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+
+	var deliveries []models.WebhookDelivery
+	for _, webhookEvent := range events {
+		var subscribers []models.Webhook
+		for _, webhook := range webhooks {
+			if webhook.Subscribes(webhookEvent) {
+				subscribers = append(subscribers, webhook)
+			}
+		}
+		if len(subscribers) == 0 {
+			continue
+		}
+
+		data, err := s.eventData(webhookEvent, event)
+		if err != nil {
+			return err
+		}
+		payload, err := json.Marshal(WebhookPayload{
+			Event:      webhookEvent,
+			OccurredAt: event.OccurredAt,
+			Data:       data,
+		})
+		if err != nil {
+			return err
+		}
+		for _, webhook := range subscribers {
+			deliveries = append(deliveries, models.WebhookDelivery{
+				WebhookID:     webhook.ID,
+				Event:         webhookEvent,
+				Payload:       string(payload),
+				Status:        models.WebhookPending,
+				NextAttemptAt: time.Now(),
+			})
+		}
+	}
+
+	if err := s.webhookRepo.CreateDeliveries(deliveries); err != nil {
+		return err
+	}
+	if len(deliveries) > 0 {
+		s.signal()
+	}
+	return nil
+}
+
+// Start запускает фоновую отправку очереди. Повторный вызов ничего не делает.
+func (s *WebhookService) Start() {
+	s.startOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(webhookPollInterval)
+			defer ticker.Stop()
+			for {
+				s.ProcessDue()
+				select {
+				case <-ticker.C:
+				case <-s.wake:
+				}
+			}
+		}()
+	})
+}
+
+// ProcessDue отправляет доставки, которым подошел срок, пока очередь не опустеет
+func (s *WebhookService) ProcessDue() {
+	for {
+		deliveries, err := s.webhookRepo.ClaimDue(time.Now(), webhookLease, webhookBatchSize)
+		if err != nil {
+			log.Printf("Failed to claim webhook deliveries: %v", err)
+			return
+		}
+		if len(deliveries) == 0 {
+			return
+		}
+
+		webhooks := make(map[uint]*models.Webhook)
+		for i := range deliveries {
+			id := deliveries[i].WebhookID
+			if _, ok := webhooks[id]; ok {
+				continue
+			}
+			webhook, err := s.webhookRepo.FindByID(id)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("Failed to load webhook %d: %v", id, err)
+				return
+			}
+			webhooks[id] = webhook
+		}
+
+		// Получатели отвечают независимо, поэтому пачка отправляется параллельно
+		var wg sync.WaitGroup
+		for i := range deliveries {
+			wg.Add(1)
+			go func(delivery *models.WebhookDelivery) {
+				defer wg.Done()
+				s.attempt(delivery, webhooks[delivery.WebhookID])
+			}(&deliveries[i])
+		}
+		wg.Wait()
+	}
+}
+
+// attempt одна попытка доставки с записью результата в журнал
+func (s *WebhookService) attempt(delivery *models.WebhookDelivery, webhook *models.Webhook) {
+	s.tryDelivery(delivery, webhook, time.Now())
+	if err := s.webhookRepo.UpdateDelivery(delivery); err != nil {
+		log.Printf("Failed to save webhook delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// tryDelivery отправляет доставку и записывает итог попытки в delivery:
+// успех, следующую попытку с растущей паузой или окончательную неудачу
+func (s *WebhookService) tryDelivery(delivery *models.WebhookDelivery, webhook *models.Webhook, now time.Time) {
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+
+	var status int
+	var err error
+	if webhook == nil || !webhook.IsActive {
+		err = errors.New("webhook is disabled or deleted")
+		delivery.Attempts = webhookMaxAttempts
+	} else {
+		status, err = s.send(webhook, delivery)
+	}
+	delivery.ResponseStatus = status
+
+	switch {
+	case err == nil:
+		delivery.Status = models.WebhookDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	case delivery.Attempts >= webhookMaxAttempts:
+		delivery.Status = models.WebhookFailed
+		delivery.LastError = err.Error()
+	default:
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = now.Add(webhookRetryDelay(delivery.Attempts))
+	}
+}
+
+// send отправляет тело доставки. Получатель проверяет подпись
+// X-Webhook-Signature: sha256=HMAC(secret, timestamp + "." + body), где
+// timestamp из X-Webhook-Timestamp, и отбрасывает устаревшие запросы.
+func (s *WebhookService) send(webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "EnglishLessons-Webhooks/1.0")
+	req.Header.Set("X-Webhook-Event", string(delivery.Event))
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+SignWebhook(webhook.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	// Тело ответа не читаем и не сохраняем: журнал доступен учителям, и через
+	// него нельзя показывать содержимое чужих серверов
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// errWebhookAddress адрес получателя во внутренней сети
+var errWebhookAddress = errors.New("webhook address is not allowed")
+
+// webhookBlockedNets служебные сети, которых нет среди проверок net.IP:
+// CGNAT, протокольные адреса IETF и сети для тестов производительности
+var webhookBlockedNets = []*net.IPNet{
+	mustParseCIDR("100.64.0.0/10"),
+	mustParseCIDR("192.0.0.0/24"),
+	mustParseCIDR("198.18.0.0/15"),
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
+
+// webhookAddressAllowed вебхуки ходят только на публичные адреса: иначе
+// учитель мог бы отправлять запросы к самому серверу, метаданным облака
+// (169.254.169.254) или внутренней сети школы
+func webhookAddressAllowed(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range webhookBlockedNets {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// newWebhookClient HTTP-клиент, который проверяет адрес уже после разрешения
+// имени, в момент соединения: так смена DNS-записи после проверки при
+// создании подписки не обходит запрет. Прокси из окружения не используется
+// (соединение шло бы к прокси), переадресации не выполняются.
+func newWebhookClient(allowed func(net.IP) bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !allowed(ip) {
+				return errWebhookAddress
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: webhookTimeout,
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: webhookTimeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     time.Minute,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// validateWebhookHost проверяет адреса хоста при создании подписки, чтобы
+// учитель сразу увидел ошибку, а не только неудачные доставки
+func validateWebhookHost(host string) error {
+	ips, err := net.LookupIP(host)
+	if err != nil || len(ips) == 0 {
+		return errors.New("Неверный адрес: не удалось найти хост")
+	}
+	for _, ip := range ips {
+		if !webhookAddressAllowed(ip) {
+			return errors.New("Неверный адрес: внутренние адреса запрещены")
+		}
+	}
+	return nil
+}
+
+// SignWebhook подпись тела запроса в hex
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookRetryDelay пауза перед следующей попыткой после attempts неудач
+func webhookRetryDelay(attempts int) time.Duration {
+	delay := webhookRetryBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= webhookMaxRetryDelay {
+			return webhookMaxRetryDelay
+		}
+	}
+	return delay
+}
+
+// signal будит обработчик очереди, не дожидаясь следующего опроса
+func (s *WebhookService) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// webhookEventsFor внешние события, которые порождает событие шины
+func webhookEventsFor(event Event) []models.WebhookEvent {
+	switch event.Type {
+	case models.EventTestSubmitted:
+		if event.TestAttempt == nil {
+			return nil
+		}
+		if event.LessonCompleted {
+			return []models.WebhookEvent{models.WebhookTestSubmitted, models.WebhookLessonCompleted}
+		}
+		return []models.WebhookEvent{models.WebhookTestSubmitted}
+	case models.EventGameFinished:
+		if event.GameResult != nil {
+			return []models.WebhookEvent{models.WebhookGameFinished}
+		}
+	case models.EventAchievementEarned:
+		if event.Achievement != nil {
+			return []models.WebhookEvent{models.WebhookAchievementEarned}
+		}
+	case models.EventUserRegistered:
+		if event.User != nil {
+			return []models.WebhookEvent{models.WebhookUserRegistered}
+		}
+	}
+	return nil
+}
+
+// eventData данные события для получателя. Ученик описывается так, чтобы
+// журнал мог сопоставить его со своим списком: логин, имя и класс.
+func (s *WebhookService) eventData(webhookEvent models.WebhookEvent, event Event) (map[string]interface{}, error) {
+	if webhookEvent == models.WebhookUserRegistered {
+		return map[string]interface{}{"user": webhookUser(event.User)}, nil
+	}
+
+	user, err := s.userRepo.FindByID(event.UserID)
+	if err != nil {
+		return nil, err
+	}
+	data := map[string]interface{}{"user": webhookUser(user)}
+
+	switch webhookEvent {
+	case models.WebhookTestSubmitted, models.WebhookLessonCompleted:
+		attempt := event.TestAttempt
+		lesson, err := s.lessonRepo.FindByID(attempt.LessonID)
+		if err != nil {
+			return nil, err
+		}
+		data["lesson"] = map[string]interface{}{
+			"id":    lesson.ID,
+			"title": lesson.Title,
+			"order": lesson.Order,
+		}
+		data["attempt"] = map[string]interface{}{
+			"id":              attempt.ID,
+			"score":           attempt.Score,
+			"percentage":      attempt.Percentage,
+			"correct_answers": attempt.CorrectAnswers,
+			"total_questions": attempt.TotalQuestions,
+			"is_passed":       attempt.IsPassed,
+			"created_at":      attempt.CreatedAt,
+		}
+	case models.WebhookGameFinished:
+		result := event.GameResult
+		data["game"] = map[string]interface{}{
+			"result_id":     result.ID,
+			"game_type":     result.GameType,
+			"level":         result.Level,
+			"score":         result.Score,
+			"max_score":     result.MaxScore,
+			"percentage":    result.Percentage,
+			"correct_count": result.CorrectCount,
+			"total_count":   result.TotalCount,
+			"time_spent":    result.TimeSpent,
+			"created_at":    result.CreatedAt,
+		}
+	case models.WebhookAchievementEarned:
+		achievement := event.Achievement
+		data["achievement"] = map[string]interface{}{
+			"id":          achievement.ID,
+			"type":        achievement.Type,
+			"title":       achievement.Title,
+			"description": achievement.Description,
+			"earned_at":   achievement.EarnedAt,
+		}
+	}
+	return data, nil
+}
+
+func webhookUser(user *models.User) map[string]interface{} {
+	return map[string]interface{}{
+		"id":           user.ID,
+		"username":     user.Username,
+		"first_name":   user.FirstName,
+		"last_name":    user.LastName,
+		"role":         user.Role,
+		"level":        user.Level,
+		"level_letter": user.LevelLetter,
+	}
+}
+
+func (s *WebhookService) findWebhook(id uint) (*models.Webhook, error) {
+	webhook, err := s.webhookRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Вебхук не найден")
+		}
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// applyWebhookRequest проверяет адрес и список событий и переносит их в подписку
+func applyWebhookRequest(webhook *models.Webhook, req WebhookRequest) error {
+	address := strings.TrimSpace(req.URL)
+	parsed, err := url.Parse(address)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" ||
+		len(address) > maxWebhookURLLength {
+		return errors.New("Неверный адрес: нужен полный URL http(s)")
+	}
+	if parsed.User != nil {
+		return errors.New("Неверный адрес: логин и пароль в адресе не допускаются")
+	}
+	if err := validateWebhookHost(parsed.Hostname()); err != nil {
+		return err
+	}
+
+	if len(req.Events) == 0 {
+		return errors.New("Необходимо выбрать хотя бы одно событие")
+	}
+	events := make([]models.WebhookEvent, 0, len(req.Events))
+	seen := make(map[models.WebhookEvent]bool)
+	for _, event := range req.Events {
+		if !event.IsValid() {
+			return fmt.Errorf("Неверное событие: %s", event)
+		}
+		if !seen[event] {
+			seen[event] = true
+			events = append(events, event)
+		}
+	}
+
+	webhook.URL = address
+	webhook.Description = strings.TrimSpace(req.Description)
+	webhook.Events = events
+	if req.IsActive != nil {
+		webhook.IsActive = *req.IsActive
+	}
+	return nil
+}
+
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
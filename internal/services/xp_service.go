@@ -0,0 +1,178 @@
+package services
+
+import (
+	"englishlessons.back/internal/config"
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/repositories"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// levelXPStep опыт для перехода с 1 на 2 уровень; каждый следующий уровень
+	// требует на levelXPStep больше предыдущего
+	levelXPStep = 100
+	// streakXPMaxDays после этой длины серии бонус за день больше не растет
+	streakXPMaxDays = 7
+)
+
+type XPService struct {
+	xpRepo  *repositories.XPRepository
+	weights config.XPWeights
+}
+
+func NewXPService(xpRepo *repositories.XPRepository, weights config.XPWeights) *XPService {
+	return &XPService{
+		xpRepo:  xpRepo,
+		weights: weights,
+	}
+}
+
+// LevelInfo текущий уровень игрока и прогресс до следующего
+type LevelInfo struct {
+	TotalXP       int     `json:"total_xp"`
+	Level         int     `json:"level"`
+	LevelXP       int     `json:"level_xp"`
+	NextLevelXP   int     `json:"next_level_xp"`
+	XPToNextLevel int     `json:"xp_to_next_level"`
+	Progress      float64 `json:"progress"`
+}
+
+// XPForLevel суммарный опыт, с которого начинается уровень level:
+// 1 -> 0, 2 -> 100, 3 -> 300, 4 -> 600 ...
+func XPForLevel(level int) int {
+	if level <= 1 {
+		return 0
+	}
+	return levelXPStep * (level - 1) * level / 2
+}
+
+// LevelForXP уровень игрока по суммарному опыту
+func LevelForXP(totalXP int) int {
+	level := 1
+	for XPForLevel(level+1) <= totalXP {
+		level++
+	}
+	return level
+}
+
+func NewLevelInfo(totalXP int) LevelInfo {
+	level := LevelForXP(totalXP)
+	levelStart := XPForLevel(level)
+	nextLevel := XPForLevel(level + 1)
+
+	return LevelInfo{
+		TotalXP:       totalXP,
+		Level:         level,
+		LevelXP:       totalXP - levelStart,
+		NextLevelXP:   nextLevel - levelStart,
+		XPToNextLevel: nextLevel - totalXP,
+		Progress:      float64(totalXP-levelStart) / float64(nextLevel-levelStart) * 100,
+	}
+}
+
+// HandleEvent начисляет опыт за события шины
+func (s *XPService) HandleEvent(event Event, outcome *EventOutcome) error {
+	var entries []models.XPEntry
+
+	switch event.Type {
+	case models.EventTestSubmitted:
+		attempt := event.TestAttempt
+		// Опыт за урок начисляется один раз: SourceID - урок, а не попытка
+		if attempt.IsPassed {
+			entries = append(entries, models.XPEntry{
+				UserID:   attempt.UserID,
+				Source:   models.XPSourceTestPass,
+				SourceID: attempt.LessonID,
+				Amount:   s.weights.TestPass,
+				Reason:   fmt.Sprintf("Урок %d пройден", attempt.LessonID),
+			})
+		}
+		if attempt.Percentage >= 100 {
+			entries = append(entries, models.XPEntry{
+				UserID:   attempt.UserID,
+				Source:   models.XPSourcePerfectScore,
+				SourceID: attempt.LessonID,
+				Amount:   s.weights.PerfectScore,
+				Reason:   fmt.Sprintf("Урок %d пройден на 100%%", attempt.LessonID),
+			})
+		}
+	case models.EventGameFinished:
+		result := event.GameResult
+		entries = append(entries, models.XPEntry{
+			UserID:   result.UserID,
+			Source:   models.XPSourceGame,
+			SourceID: result.ID,
+			Amount:   int(float64(s.weights.GameCompletion) * result.Percentage / 100),
+			Reason:   fmt.Sprintf("Игра %s, уровень %d", result.GameType, result.Level),
+		})
+	case models.EventStreakUpdated:
+		streak := event.Streak
+		days := streak.CurrentStreak
+		if days > streakXPMaxDays {
+			days = streakXPMaxDays
+		}
+		entries = append(entries, models.XPEntry{
+			UserID:   streak.UserID,
+			Source:   models.XPSourceStreak,
+			SourceID: dayNumber(streak.LastGoalDay),
+			Amount:   s.weights.StreakDay * days,
+			Reason:   fmt.Sprintf("Серия %d дн.", streak.CurrentStreak),
+		})
+	case models.EventAchievementEarned:
+		achievement := event.Achievement
+		entries = append(entries, models.XPEntry{
+			UserID:   achievement.UserID,
+			Source:   models.XPSourceAchievement,
+			SourceID: achievement.ID,
+			Amount:   s.weights.Achievement,
+			Reason:   achievement.Title,
+		})
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Amount <= 0 {
+			continue
+		}
+		if !event.OccurredAt.IsZero() {
+			entry.CreatedAt = event.OccurredAt
+		}
+		created, err := s.xpRepo.Create(entry)
+		if err != nil {
+			return err
+		}
+		if created {
+			outcome.XPAwarded += entry.Amount
+		}
+	}
+	return nil
+}
+
+// dayNumber превращает день YYYY-MM-DD в число YYYYMMDD для SourceID
+func dayNumber(day string) uint {
+	n, _ := strconv.ParseUint(strings.ReplaceAll(day, "-", ""), 10, 32)
+	return uint(n)
+}
+
+// GetHistory журнал опыта пользователя, новые записи первыми
+func (s *XPService) GetHistory(userID uint, limit, offset int) ([]models.XPEntry, int64, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.xpRepo.FindByUserID(userID, limit, offset)
+}
+
+// GetLevel текущий уровень пользователя
+func (s *XPService) GetLevel(userID uint) (*LevelInfo, error) {
+	total, err := s.xpRepo.TotalByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	info := NewLevelInfo(total)
+	return &info, nil
+}
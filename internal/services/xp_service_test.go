@@ -0,0 +1,94 @@
+package services
+
+import (
+	"testing"
+
+	"englishlessons.back/internal/config"
+	"englishlessons.back/internal/models"
+	"englishlessons.back/internal/repositories"
+	"englishlessons.back/internal/testdb"
+)
+
+func TestXPForLevel(t *testing.T) {
+	tests := []struct {
+		level, want int
+	}{
+		{0, 0},
+		{1, 0},
+		{2, 100},
+		{3, 300},
+		{4, 600},
+		{10, 4500},
+	}
+	for _, tt := range tests {
+		if got := XPForLevel(tt.level); got != tt.want {
+			t.Errorf("XPForLevel(%d) = %d, want %d", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestLevelForXP(t *testing.T) {
+	tests := []struct {
+		totalXP, want int
+	}{
+		{0, 1},
+		{99, 1},
+		{100, 2},
+		{299, 2},
+		{300, 3},
+		{4499, 9},
+		{4500, 10},
+	}
+	for _, tt := range tests {
+		if got := LevelForXP(tt.totalXP); got != tt.want {
+			t.Errorf("LevelForXP(%d) = %d, want %d", tt.totalXP, got, tt.want)
+		}
+	}
+}
+
+func TestNewLevelInfo(t *testing.T) {
+	info := NewLevelInfo(450)
+	want := LevelInfo{TotalXP: 450, Level: 3, LevelXP: 150, NextLevelXP: 300, XPToNextLevel: 150, Progress: 50}
+	if info != want {
+		t.Errorf("NewLevelInfo(450) = %+v, want %+v", info, want)
+	}
+}
+
+// Опыт за урок начисляется один раз, сколько бы попыток ни было
+func TestXPLedgerCreditsLessonOnce(t *testing.T) {
+	db := testdb.Open(t)
+	seeded := testdb.Seed(t, db, 1, 1)
+	student := seeded.Students[0]
+	xpRepo := repositories.NewXPRepository(db)
+	service := NewXPService(xpRepo, config.XPWeights{TestPass: 50, PerfectScore: 20})
+
+	before, err := xpRepo.TotalByUserID(student.ID)
+	if err != nil {
+		t.Fatalf("TotalByUserID: %v", err)
+	}
+
+	// Урок из Seed: у ученика 0 по уроку 0 нет попыток, поэтому опыт еще не начислялся
+	lessonID := seeded.LessonIDs[0]
+	for i, want := range []int{70, 0} {
+		event := Event{
+			Type:        models.EventTestSubmitted,
+			UserID:      student.ID,
+			TestAttempt: &models.TestAttempt{UserID: student.ID, LessonID: lessonID, IsPassed: true, Percentage: 100},
+		}
+		outcome := &EventOutcome{}
+		if err := service.HandleEvent(event, outcome); err != nil {
+			t.Fatalf("HandleEvent #%d: %v", i+1, err)
+		}
+		if outcome.XPAwarded != want {
+			t.Errorf("attempt #%d awarded %d XP, want %d", i+1, outcome.XPAwarded, want)
+		}
+	}
+
+	after, err := xpRepo.TotalByUserID(student.ID)
+	if err != nil {
+		t.Fatalf("TotalByUserID: %v", err)
+	}
+	if after-before != 70 {
+		t.Errorf("ledger grew by %d XP, want 70", after-before)
+	}
+}
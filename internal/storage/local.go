@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"errors"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Local хранит файлы в каталоге на диске сервера
+type Local struct {
+	root string
+}
+
+func NewLocal(root string) *Local {
+	if root == "" {
+		root = "media"
+	}
+	return &Local{root: root}
+}
+
+func (s *Local) path(key string) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrNotFound
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Put записывает файл через временный файл, чтобы читатели не увидели его частично
+func (s *Local) Put(key string, data []byte, contentType string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return errors.New("Неверный ключ файла")
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+func (s *Local) Open(key string) (*Object, error) {
+	target, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(target)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		file.Close()
+		return nil, ErrNotFound
+	}
+	return &Object{
+		ReadSeekCloser: file,
+		Size:           info.Size(),
+		ContentType:    contentTypeByExt(path.Ext(key)),
+		ModTime:        info.ModTime(),
+	}, nil
+}
+
+// contentTypes форматы, которых нет во встроенной таблице mime
+var contentTypes = map[string]string{
+	".mp3":  "audio/mpeg",
+	".m4a":  "audio/mp4",
+	".aac":  "audio/aac",
+	".ogg":  "audio/ogg",
+	".wav":  "audio/wav",
+	".flac": "audio/flac",
+	".webm": "audio/webm",
+}
+
+func contentTypeByExt(ext string) string {
+	if contentType, ok := contentTypes[ext]; ok {
+		return contentType
+	}
+	return mime.TypeByExtension(ext)
+}
+
+func (s *Local) Delete(key string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// S3Config настройки S3-совместимого хранилища (AWS, MinIO, Yandex Object Storage)
+type S3Config struct {
+	Endpoint  string // например https://s3.eu-central-1.amazonaws.com
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PathStyle адресует бакет в пути (endpoint/bucket/key), как требует MinIO
+	PathStyle bool
+}
+
+// S3 хранит файлы в бакете S3. Запросы подписываются AWS Signature V4.
+type S3 struct {
+	cfg      S3Config
+	endpoint *url.URL
+	client   *http.Client
+}
+
+// emptyPayloadHash sha256 пустого тела для GET, HEAD и DELETE
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+func NewS3(cfg S3Config) (*S3, error) {
+	if cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, errors.New("S3 storage requires bucket, access key and secret key")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", cfg.Endpoint)
+	}
+	return &S3{cfg: cfg, endpoint: endpoint, client: &http.Client{Timeout: time.Minute}}, nil
+}
+
+func (s *S3) Put(key string, data []byte, contentType string) error {
+	if !ValidKey(key) {
+		return errors.New("Неверный ключ файла")
+	}
+	sum := sha256.Sum256(data)
+	req, err := s.newRequest(http.MethodPut, key, bytes.NewReader(data), hex.EncodeToString(sum[:]))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = int64(len(data))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Open запрашивает метаданные; содержимое читается по диапазонам при Read
+func (s *S3) Open(key string) (*Object, error) {
+	if !ValidKey(key) {
+		return nil, ErrNotFound
+	}
+	req, err := s.newRequest(http.MethodHead, key, nil, emptyPayloadHash)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return &Object{
+		ReadSeekCloser: &s3Reader{storage: s, key: key, size: resp.ContentLength},
+		Size:           resp.ContentLength,
+		ContentType:    resp.Header.Get("Content-Type"),
+		ModTime:        modTime,
+	}, nil
+}
+
+func (s *S3) Delete(key string) error {
+	if !ValidKey(key) {
+		return ErrNotFound
+	}
+	req, err := s.newRequest(http.MethodDelete, key, nil, emptyPayloadHash)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if resp != nil {
+		resp.Body.Close()
+	}
+	return nil
+}
+
+func (s *S3) newRequest(method, key string, body io.Reader, payloadHash string) (*http.Request, error) {
+	objectURL := *s.endpoint
+	objectPath := "/" + key
+	if s.cfg.PathStyle {
+		objectPath = "/" + s.cfg.Bucket + objectPath
+	} else {
+		objectURL.Host = s.cfg.Bucket + "." + objectURL.Host
+	}
+	objectURL.Path = strings.TrimSuffix(s.endpoint.Path, "/") + objectPath
+	objectURL.RawPath = s3Escape(objectURL.Path)
+
+	req, err := http.NewRequest(method, objectURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+	return req, nil
+}
+
+// do подписывает и выполняет запрос; 404 превращается в ErrNotFound
+func (s *S3) do(req *http.Request) (*http.Response, error) {
+	s.sign(req, time.Now().UTC())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("S3 %s %s: %s %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return resp, nil
+}
+
+// sign добавляет заголовок Authorization по схеме AWS Signature V4
+func (s *S3) sign(req *http.Request, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := req.Header.Get("x-amz-content-sha256")
+	req.Header.Set("x-amz-date", amzDate)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.cfg.Region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), date)
+	signingKey = hmacSHA256(signingKey, s.cfg.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.cfg.AccessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3Escape кодирует путь по правилам SigV4: все, кроме A-Z a-z 0-9 - . _ ~ и /
+func s3Escape(value string) string {
+	var builder strings.Builder
+	for _, b := range []byte(value) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9',
+			b == '-', b == '.', b == '_', b == '~', b == '/':
+			builder.WriteByte(b)
+		default:
+			fmt.Fprintf(&builder, "%%%02X", b)
+		}
+	}
+	return builder.String()
+}
+
+// s3Reader читает объект запросами GET с заголовком Range начиная с текущей позиции
+type s3Reader struct {
+	storage *S3
+	key     string
+	size    int64
+	offset  int64
+	body    io.ReadCloser
+}
+
+func (r *s3Reader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+	if r.body == nil {
+		req, err := r.storage.newRequest(http.MethodGet, r.key, nil, emptyPayloadHash)
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(r.offset, 10)+"-")
+		resp, err := r.storage.do(req)
+		if err != nil {
+			return 0, err
+		}
+		r.body = resp.Body
+	}
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+	return n, err
+}
+
+func (r *s3Reader) Seek(offset int64, whence int) (int64, error) {
+	var target int64
+	switch whence {
+	case io.SeekStart:
+		target = offset
+	case io.SeekCurrent:
+		target = r.offset + offset
+	case io.SeekEnd:
+		target = r.size + offset
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if target < 0 {
+		return 0, errors.New("negative position")
+	}
+	if target != r.offset && r.body != nil {
+		r.body.Close()
+		r.body = nil
+	}
+	r.offset = target
+	return target, nil
+}
+
+func (r *s3Reader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	err := r.body.Close()
+	r.body = nil
+	return err
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// ErrNotFound файла с таким ключом нет в хранилище
+var ErrNotFound = errors.New("Файл не найден")
+
+// Object открытый файл хранилища. Поддерживает Seek, поэтому его можно
+// отдавать через http.ServeContent с запросами диапазонов.
+type Object struct {
+	io.ReadSeekCloser
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Storage хранилище медиафайлов. Ключ - относительный путь вида
+// "audio/<sha256>.mp3", одинаковый для всех реализаций.
+type Storage interface {
+	Put(key string, data []byte, contentType string) error
+	Open(key string) (*Object, error)
+	Delete(key string) error
+}
+
+// Config выбор и настройки хранилища
+type Config struct {
+	Driver   string // local или s3
+	LocalDir string
+	S3       S3Config
+}
+
+// New создает хранилище по настройкам
+func New(cfg Config) (Storage, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocal(cfg.LocalDir), nil
+	case "s3":
+		return NewS3(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown media storage driver %q", cfg.Driver)
+	}
+}
+
+// ValidKey ключ не должен выходить за пределы хранилища
+func ValidKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	if path.Clean(key) != key {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || strings.HasPrefix(segment, ".") {
+			return false
+		}
+	}
+	return true
+}
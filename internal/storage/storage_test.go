@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"audio/abc.mp3", true},
+		{"abc.mp3", true},
+		{"audio/2025/abc.mp3", true},
+
+		{"", false},
+		{"/audio/abc.mp3", false},
+		{"../abc.mp3", false},
+		{"audio/../../abc.mp3", false},
+		{"audio/./abc.mp3", false},
+		{"audio//abc.mp3", false},
+		{"audio/", false},
+		{"audio\\abc.mp3", false},
+		{"..", false},
+		{".env", false},
+		{"audio/.hidden", false},
+	}
+	for _, tt := range tests {
+		if got := ValidKey(tt.key); got != tt.want {
+			t.Errorf("ValidKey(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestNewDriver(t *testing.T) {
+	if s, err := New(Config{LocalDir: t.TempDir()}); err != nil {
+		t.Errorf("default driver: %v", err)
+	} else if _, ok := s.(*Local); !ok {
+		t.Errorf("default driver = %T, want *Local", s)
+	}
+	if _, err := New(Config{Driver: "ftp"}); err == nil {
+		t.Error("unknown driver must be rejected")
+	}
+}
+
+func TestLocalPutOpenDelete(t *testing.T) {
+	root := t.TempDir()
+	s := NewLocal(root)
+
+	if err := s.Put("../outside.mp3", []byte("x"), ""); err == nil {
+		t.Fatal("invalid key must be rejected")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(root), "outside.mp3")); err == nil {
+		t.Fatal("file written outside the root")
+	}
+
+	if err := s.Put("audio/x.mp3", []byte("0123456789"), "audio/mpeg"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	// Временный файл после записи не остается
+	entries, err := os.ReadDir(filepath.Join(root, "audio"))
+	if err != nil || len(entries) != 1 || entries[0].Name() != "x.mp3" {
+		t.Fatalf("audio dir = %v, %v", entries, err)
+	}
+
+	object, err := s.Open("audio/x.mp3")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if object.Size != 10 || object.ContentType != "audio/mpeg" {
+		t.Errorf("object = %+v", object)
+	}
+	if _, err := object.Seek(7, io.SeekStart); err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+	data, _ := io.ReadAll(object)
+	object.Close()
+	if string(data) != "789" {
+		t.Errorf("read after seek = %q", data)
+	}
+
+	if _, err := s.Open("audio"); err != ErrNotFound {
+		t.Errorf("Open(dir) = %v, want ErrNotFound", err)
+	}
+	if _, err := s.Open("../x.mp3"); err != ErrNotFound {
+		t.Errorf("Open(invalid) = %v, want ErrNotFound", err)
+	}
+
+	if err := s.Delete("audio/x.mp3"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Open("audio/x.mp3"); err != ErrNotFound {
+		t.Fatalf("Open after delete = %v, want ErrNotFound", err)
+	}
+	if err := s.Delete("audio/x.mp3"); err != nil {
+		t.Fatalf("deleting a missing file = %v", err)
+	}
+}
+
+func TestContentTypeByExt(t *testing.T) {
+	tests := map[string]string{
+		".mp3":  "audio/mpeg",
+		".m4a":  "audio/mp4",
+		".webm": "audio/webm",
+		".png":  "image/png",
+		".zzz":  "",
+	}
+	for ext, want := range tests {
+		if got := contentTypeByExt(ext); got != want {
+			t.Errorf("contentTypeByExt(%q) = %q, want %q", ext, got, want)
+		}
+	}
+}
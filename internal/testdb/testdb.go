@@ -0,0 +1,140 @@
+// Package testdb общие заготовки для тестов, которым нужна PostgreSQL
+package testdb
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"englishlessons.back/internal/database"
+	"englishlessons.back/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Open транзакция в тестовой базе из DATABASE_URL, откатывается после
+// теста; без DATABASE_URL тест пропускается
+func Open(tb testing.TB) *gorm.DB {
+	tb.Helper()
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		tb.Skip("DATABASE_URL is not set")
+	}
+	db, err := database.Connect(url)
+	if err != nil {
+		tb.Fatalf("connect: %v", err)
+	}
+	db.Logger = logger.Default.LogMode(logger.Silent)
+	if err := database.Migrate(db); err != nil {
+		tb.Fatalf("migrate: %v", err)
+	}
+
+	tx := db.Begin()
+	tb.Cleanup(func() {
+		tx.Rollback()
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return tx
+}
+
+// Create сохраняет записи по порядку и прерывает тест при ошибке
+func Create(tb testing.TB, db *gorm.DB, values ...any) {
+	tb.Helper()
+	for _, value := range values {
+		if err := db.Create(value).Error; err != nil {
+			tb.Fatalf("create %T: %v", value, err)
+		}
+	}
+}
+
+// Seeded ученики и уроки, созданные Seed, в порядке создания
+type Seeded struct {
+	Students  []models.User
+	LessonIDs []uint
+}
+
+// Seed заполняет базу учениками с попытками тестов, играми и опытом.
+// Ученик i учится в классе 5+i%5 с буквой А, Б, В по кругу; у ученика i
+// нет попыток по уроку j, если (i+j) делится на 3.
+func Seed(tb testing.TB, db *gorm.DB, students, lessons int) *Seeded {
+	tb.Helper()
+	start := time.Now().AddDate(0, -3, 0)
+	seeded := &Seeded{LessonIDs: make([]uint, lessons)}
+
+	for i := range seeded.LessonIDs {
+		lesson := models.Lesson{Title: fmt.Sprintf("bench lesson %d", i), Order: 1000000 + i, IsActive: true}
+		Create(tb, db, &lesson)
+		seeded.LessonIDs[i] = lesson.ID
+	}
+
+	letters := []string{"А", "Б", "В"}
+	for i := 0; i < students; i++ {
+		level := 5 + i%5
+		user := models.User{
+			Username:    fmt.Sprintf("bench_student_%d", i),
+			Password:    "x",
+			Role:        models.RoleStudent,
+			Level:       &level,
+			LevelLetter: letters[i%len(letters)],
+		}
+		Create(tb, db, &user)
+		seeded.Students = append(seeded.Students, user)
+
+		var attempts []models.TestAttempt
+		var games []models.GameResult
+		var xp []models.XPEntry
+		for j, lessonID := range seeded.LessonIDs {
+			if (i+j)%3 == 0 {
+				continue
+			}
+			for k := 0; k < 2; k++ {
+				percentage := float64((i*7 + j*13 + k*29) % 101)
+				attempts = append(attempts, models.TestAttempt{
+					UserID:         user.ID,
+					LessonID:       lessonID,
+					Score:          int(percentage) / 10,
+					Percentage:     percentage,
+					TotalQuestions: 10,
+					CorrectAnswers: int(percentage) / 10,
+					IsPassed:       percentage >= 70,
+					CreatedAt:      start.Add(time.Duration(i*lessons+j) * time.Minute),
+				})
+			}
+			games = append(games, models.GameResult{
+				UserID:       user.ID,
+				GameType:     models.GameQuizShow,
+				Level:        j % 4,
+				Score:        (i + j) % 50,
+				MaxScore:     50,
+				Percentage:   float64((i+j)%50) * 2,
+				CorrectCount: (i + j) % 10,
+				TotalCount:   10,
+				CreatedAt:    start.Add(time.Duration(i*lessons+j) * time.Minute),
+			})
+			xp = append(xp, models.XPEntry{
+				UserID:    user.ID,
+				Source:    models.XPSourceTestPass,
+				SourceID:  uint(j + 1),
+				Amount:    10 + (i+j)%20,
+				CreatedAt: start.Add(time.Duration(i*lessons+j) * time.Minute),
+			})
+		}
+		if len(attempts) == 0 {
+			continue
+		}
+		if err := db.CreateInBatches(attempts, 500).Error; err != nil {
+			tb.Fatalf("seed attempts: %v", err)
+		}
+		if err := db.CreateInBatches(games, 500).Error; err != nil {
+			tb.Fatalf("seed games: %v", err)
+		}
+		if err := db.CreateInBatches(xp, 500).Error; err != nil {
+			tb.Fatalf("seed xp: %v", err)
+		}
+	}
+	return seeded
+}
@@ -1,17 +1,25 @@
 package main
 
 import (
+	"flag"
 	"log"
+	"os"
 
 	"englishlessons.back/internal/config"
 	"englishlessons.back/internal/database"
 	"englishlessons.back/internal/handlers"
 	"englishlessons.back/internal/middleware"
+	"englishlessons.back/internal/repositories"
+	"englishlessons.back/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	rebuildStats := flag.Bool("rebuild-stats", false, "пересчитать кэш статистики с нуля и выйти")
+	checkStats := flag.Bool("check-stats", false, "сравнить кэш статистики с полным пересчетом и выйти")
+	flag.Parse()
+
 	// Загружаем конфигурацию
 	cfg := config.Load()
 
@@ -31,6 +39,49 @@ func main() {
 		log.Printf("Warning: Failed to seed default lessons: %v", err)
 	}
 
+	// Создаем встроенные достижения
+	if err := database.SeedAchievementDefinitions(db); err != nil {
+		log.Printf("Warning: Failed to seed achievement definitions: %v", err)
+	}
+
+	// Создаем встроенные задания игр
+	if err := database.SeedGameItems(db); err != nil {
+		log.Printf("Warning: Failed to seed game items: %v", err)
+	}
+
+	// Создаем встроенные теги
+	if err := database.SeedTags(db); err != nil {
+		log.Printf("Warning: Failed to seed tags: %v", err)
+	}
+
+	// Кэш статистики
+	statsService := services.NewStatsService(repositories.NewStatsRepository(db), repositories.NewUserRepository(db))
+	if *rebuildStats {
+		if err := statsService.Rebuild(); err != nil {
+			log.Fatalf("Failed to rebuild stats: %v", err)
+		}
+		log.Println("Stats cache rebuilt")
+		return
+	}
+	if *checkStats {
+		mismatches, err := statsService.Check()
+		if err != nil {
+			log.Fatalf("Failed to check stats: %v", err)
+		}
+		for _, mismatch := range mismatches {
+			log.Println(mismatch)
+		}
+		if len(mismatches) > 0 {
+			log.Printf("Stats cache has %d mismatches, run with -rebuild-stats", len(mismatches))
+			os.Exit(1)
+		}
+		log.Println("Stats cache is consistent")
+		return
+	}
+	if err := statsService.RebuildIfEmpty(); err != nil {
+		log.Printf("Warning: Failed to build stats cache: %v", err)
+	}
+
 	// Инициализируем handlers
 	h := handlers.New(db, cfg)
 
@@ -54,6 +105,9 @@ func main() {
 	r.POST("/api/token", middleware.StrictRateLimit(), h.Login)
 	r.POST("/api/token/refresh", middleware.StrictRateLimit(), h.RefreshToken)
 
+	// Медиафайлы отдаются без токена: их загружают теги <audio> и <img>
+	r.GET("/media/*filepath", h.ServeMedia)
+
 	// Защищенные роуты
 	api := r.Group("/api")
 	api.Use(middleware.Auth(cfg.JWTSecret))
@@ -63,15 +117,60 @@ func main() {
 		api.GET("/users/students", h.GetStudents)
 		api.GET("/users/stats/me", h.GetMyStats)
 		api.GET("/users/stats/:id", h.GetStudentStats)
+		api.GET("/users/stats/me/tags", h.GetMyTagStats)
+		api.GET("/users/stats/:id/tags", h.GetStudentTagStats)
 		api.POST("/users/reset-password", h.ResetStudentPassword)
 		api.PUT("/users/profile", h.UpdateProfile)
 		api.PUT("/users/password", h.ChangePassword)
+		api.PUT("/users/avatar", h.UploadAvatar)
+		api.DELETE("/users/avatar", h.DeleteAvatar)
+		api.GET("/users/streak", h.GetMyStreak)
+		api.PUT("/users/streak/goal", h.SetDailyGoal)
+		api.GET("/users/xp/history", h.GetMyXPHistory)
+		api.GET("/users/xp/level", h.GetMyLevel)
+		api.GET("/users/privacy", h.GetMyPrivacy)
+		api.PUT("/users/privacy", h.UpdateMyPrivacy)
 
 		// Уроки
 		api.GET("/lessons", h.GetLessons)
 		api.GET("/lessons/:id", h.GetLesson)
 		api.GET("/lessons/:id/questions", h.GetLessonQuestions)
+		api.POST("/lessons/:id/questions", h.CreateLessonQuestion)
 		api.GET("/lessons/my-progress", h.GetMyProgress)
+		api.PUT("/lessons/:id/tags", h.SetLessonTags)
+		api.PUT("/questions/:id/tags", h.SetQuestionTags)
+		api.PUT("/questions/:id/audio", h.SetQuestionAudio)
+		api.POST("/lessons/:id/blocks", h.CreateLessonBlock)
+		api.DELETE("/lessons/:id/blocks/:block_id", h.DeleteLessonBlock)
+
+		// Обсуждения работ учеников
+		api.GET("/comments", h.GetCommentThread)
+		api.POST("/comments", h.AddComment)
+		api.GET("/comments/threads", h.GetCommentThreads)
+		api.GET("/comments/unread", h.GetUnreadComments)
+
+		// Уведомления
+		api.GET("/notifications", h.GetNotifications)
+		api.POST("/notifications/read", h.MarkNotificationsRead)
+		api.POST("/notifications/read-all", h.MarkAllNotificationsRead)
+		api.GET("/notifications/stream", h.StreamNotifications)
+
+		// Медиафайлы
+		api.GET("/media/audio", h.GetAudioLibrary)
+		api.POST("/media/audio", h.UploadAudio)
+
+		// Теги: темы, уровни CEFR, навыки
+		api.GET("/tags", h.GetTags)
+		api.POST("/tags", h.CreateTag)
+		api.DELETE("/tags/:id", h.DeleteTag)
+
+		// Тест на уровень CEFR
+		api.POST("/placement/start", h.StartPlacement)
+		api.GET("/placement/me", h.GetMyPlacement)
+		api.POST("/placement/:id/answers", h.AnswerPlacement)
+		api.GET("/placement/tests", h.GetClassPlacements)
+		api.GET("/placement/tests/:id", h.GetPlacement)
+		api.PUT("/placement/tests/:id/override", h.OverridePlacement)
 
 		// Тесты
 		api.POST("/lessons/submit-test", h.SubmitTest)
@@ -83,14 +182,39 @@ func main() {
 		api.GET("/progress/by-student", h.GetProgressByStudent)
 		api.GET("/progress/by-lesson", h.GetProgressByLesson)
 
+		// Повторение ошибок
+		api.GET("/reviews/due", h.GetDueReviews)
+		api.POST("/reviews/:id/answer", h.SubmitReview)
+
+		// Адаптивная практика
+		api.GET("/practice/next", h.GetNextPracticeQuestion)
+		api.POST("/practice/answers", h.SubmitPracticeAnswer)
+
 		// Достижения
 		api.GET("/achievements/me", h.GetMyAchievements)
+		api.GET("/achievements/rule-metrics", h.GetAchievementRuleMetrics)
+		api.GET("/achievements/definitions", h.GetAchievementDefinitions)
+		api.POST("/achievements/definitions", h.CreateAchievementDefinition)
+		api.PUT("/achievements/definitions/:id", h.UpdateAchievementDefinition)
+		api.DELETE("/achievements/definitions/:id", h.DeleteAchievementDefinition)
+		api.POST("/achievements/definitions/:id/reevaluate", h.ReevaluateAchievementDefinition)
 
 		// Рейтинг
 		api.GET("/leaderboard", h.GetLeaderboard)
+		api.GET("/leaderboard/class-privacy", h.GetClassPrivacySettings)
+		api.PUT("/leaderboard/class-privacy", h.SaveClassPrivacySettings)
+		api.GET("/seasons", h.GetSeasons)
+		api.POST("/seasons", h.CreateSeason)
+		api.POST("/seasons/:id/close", h.CloseSeason)
+		api.GET("/seasons/history/me", h.GetMySeasonHistory)
+		api.GET("/seasons/history/:id", h.GetStudentSeasonHistory)
 
 		// Игры - результаты и статистика
-		api.POST("/games/results", h.SubmitGameResult)
+		api.GET("/games", h.GetGames)
+		api.POST("/games/sessions", h.StartGameSession)
+		api.POST("/games/sessions/:id/answers", h.AnswerGameSession)
+		api.POST("/games/sessions/:id/submit", h.SubmitGameSession)
+		api.POST("/games/results", h.SubmitGameResult) // устарел, отвечает 410
 		api.GET("/games/results", h.GetMyGameResults)
 		api.GET("/games/stats", h.GetMyGameStats)
 		api.GET("/games/summary", h.GetMyGameSummary)
@@ -100,10 +224,48 @@ func main() {
 		api.GET("/games/recent", h.GetRecentGameResults)
 		api.GET("/games/student/:id/stats", h.GetStudentGameStats)
 
+		// Игры - банк заданий (для учителей)
+		api.GET("/games/items", h.GetGameItems)
+		api.POST("/games/items", h.CreateGameItem)
+		api.POST("/games/items/import", h.ImportGameItems)
+		api.PUT("/games/items/:id", h.UpdateGameItem)
+		api.DELETE("/games/items/:id", h.DeleteGameItem)
+		api.PUT("/games/items/:id/tags", h.SetGameItemTags)
+		api.GET("/games/round/preview", h.PreviewGameRound)
+
+		// Игры - Quiz Show в классе в реальном времени
+		api.POST("/games/live", h.CreateLiveQuiz)
+		api.POST("/games/live/:code/join", h.JoinLiveQuiz)
+		api.GET("/games/live/:code/events", h.StreamLiveQuiz)
+		api.POST("/games/live/:code/next", h.NextLiveQuizQuestion)
+		api.POST("/games/live/:code/answers", h.AnswerLiveQuiz)
+		api.POST("/games/live/:code/finish", h.FinishLiveQuiz)
+
+		// Игры - вызовы одноклассников
+		api.POST("/games/challenges", h.CreateChallenge)
+		api.GET("/games/challenges", h.GetMyChallenges)
+		api.GET("/games/challenges/:id", h.GetChallenge)
+		api.POST("/games/challenges/:id/accept", h.AcceptChallenge)
+		api.POST("/games/challenges/:id/decline", h.DeclineChallenge)
+		api.GET("/games/head-to-head/:id", h.GetHeadToHead)
+
+		// Вебхуки для внешних систем (для учителей)
+		api.GET("/webhooks", h.GetWebhooks)
+		api.POST("/webhooks", h.CreateWebhook)
+		api.PUT("/webhooks/:id", h.UpdateWebhook)
+		api.DELETE("/webhooks/:id", h.DeleteWebhook)
+		api.POST("/webhooks/:id/secret", h.RotateWebhookSecret)
+		api.GET("/webhooks/:id/deliveries", h.GetWebhookDeliveries)
+		api.POST("/webhooks/deliveries/:id/retry", h.RetryWebhookDelivery)
+
 		// Экспорт и аналитика (для учителей)
 		api.GET("/export/stats", h.ExportStats)
+		api.GET("/export/gradebook", h.ExportGradebook)
+		api.GET("/export/oneroster", h.ExportOneRoster)
 		api.GET("/analytics/class", h.GetClassAnalytics)
 		api.GET("/analytics/activity", h.GetClassActivityStats)
+		api.GET("/analytics/mastery", h.GetMasteryHeatmap)
+		api.GET("/analytics/tags", h.GetClassTagStats)
 	}
 
 	// Запускаем сервер